@@ -45,6 +45,17 @@ func init() {
 		},
 	}
 
+	imageFilters := append([]APITopologyOptionGroup{
+		{
+			ID:      "drift",
+			Default: "all",
+			Options: []APITopologyOption{
+				{"drifting", "Drifting image tags", render.IsImageDrifting},
+				{"all", "All image tags", nil},
+			},
+		},
+	}, containerFilters...)
+
 	unconnectedFilter := []APITopologyOptionGroup{
 		{
 			ID:      "unconnected",
@@ -57,6 +68,18 @@ func init() {
 		},
 	}
 
+	// Collapsed supervisors hand their children over to their own parent
+	supervisorFilter := []APITopologyOptionGroup{
+		{
+			ID:      "supervisors",
+			Default: "expanded",
+			Options: []APITopologyOption{
+				{"collapsed", "Supervisors collapsed", render.Complement(render.IsSupervisor)},
+				{"expanded", "Supervisors expanded", nil},
+			},
+		},
+	}
+
 	// Topology option labels should tell the current state. The first item must
 	// be the verb to get to that state
 	topologyRegistry.add(
@@ -74,6 +97,20 @@ func init() {
 			Name:     "by name",
 			Options:  unconnectedFilter,
 		},
+		APITopologyDesc{
+			id:       "processes-tree",
+			parent:   "processes",
+			renderer: render.ProcessTreeRenderer,
+			Name:     "as a tree",
+			Options:  supervisorFilter,
+		},
+		APITopologyDesc{
+			id:          "processes-by-systemd-unit",
+			parent:      "processes",
+			renderer:    render.SystemdUnitRenderer,
+			Name:        "by systemd unit",
+			HideIfEmpty: true,
+		},
 		APITopologyDesc{
 			id:       "containers",
 			renderer: render.ContainerWithImageNameRenderer,
@@ -93,7 +130,39 @@ func init() {
 			parent:   "containers",
 			renderer: render.ContainerImageRenderer,
 			Name:     "by image",
-			Options:  containerFilters,
+			Options:  imageFilters,
+		},
+		APITopologyDesc{
+			id:          "containers-by-compose-project",
+			parent:      "containers",
+			renderer:    render.ComposeProjectRenderer,
+			Name:        "by compose project",
+			Options:     containerFilters,
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "containers-by-compose-service",
+			parent:      "containers",
+			renderer:    render.ComposeServiceRenderer,
+			Name:        "by compose service",
+			Options:     containerFilters,
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "containers-by-network",
+			parent:      "containers",
+			renderer:    render.DockerNetworkRenderer,
+			Name:        "by network",
+			Options:     containerFilters,
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "containers-by-volume",
+			parent:      "containers",
+			renderer:    render.DockerVolumeRenderer,
+			Name:        "by volume",
+			Options:     containerFilters,
+			HideIfEmpty: true,
 		},
 		APITopologyDesc{
 			id:          "pods",
@@ -123,12 +192,75 @@ func init() {
 			Name:        "services",
 			HideIfEmpty: true,
 		},
+		APITopologyDesc{
+			id:          "persistent-volumes",
+			parent:      "pods",
+			renderer:    render.PersistentVolumeRenderer,
+			Name:        "volumes",
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "persistent-volume-claims",
+			parent:      "pods",
+			renderer:    render.PersistentVolumeClaimRenderer,
+			Name:        "volume claims",
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "storage-classes",
+			parent:      "pods",
+			renderer:    render.StorageClassRenderer,
+			Name:        "storage classes",
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "swarm-services",
+			renderer:    render.SwarmServiceRenderer,
+			Name:        "Swarm",
+			Rank:        3,
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "swarm-tasks",
+			parent:      "swarm-services",
+			renderer:    render.SwarmTaskRenderer,
+			Name:        "tasks",
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "swarm-nodes",
+			parent:      "swarm-services",
+			renderer:    render.SwarmNodeRenderer,
+			Name:        "nodes",
+			HideIfEmpty: true,
+		},
 		APITopologyDesc{
 			id:       "hosts",
 			renderer: render.HostRenderer,
 			Name:     "Hosts",
 			Rank:     4,
 		},
+		APITopologyDesc{
+			id:          "hosts-by-region",
+			parent:      "hosts",
+			renderer:    render.HostRegionRenderer,
+			Name:        "by region",
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "hosts-by-zone",
+			parent:      "hosts",
+			renderer:    render.HostZoneRenderer,
+			Name:        "by zone",
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          "network-interfaces",
+			parent:      "hosts",
+			renderer:    render.NetworkInterfaceRenderer,
+			Name:        "network interfaces",
+			HideIfEmpty: true,
+		},
 	)
 }
 
@@ -150,7 +282,7 @@ func kubernetesFilters(namespaces ...string) APITopologyOptionGroup {
 // Currently only kubernetes changes.
 func updateFilters(rpt report.Report, topologies []APITopologyDesc) []APITopologyDesc {
 	namespaces := map[string]struct{}{}
-	for _, t := range []report.Topology{rpt.Pod, rpt.Service, rpt.Deployment, rpt.ReplicaSet} {
+	for _, t := range []report.Topology{rpt.Pod, rpt.Service, rpt.Deployment, rpt.ReplicaSet, rpt.PersistentVolumeClaim} {
 		for _, n := range t.Nodes {
 			if state, ok := n.Latest.Lookup(kubernetes.State); ok && state == kubernetes.StateDeleted {
 				continue
@@ -166,7 +298,8 @@ func updateFilters(rpt report.Report, topologies []APITopologyDesc) []APITopolog
 	}
 	sort.Strings(ns)
 	for i, t := range topologies {
-		if t.id == "pods" || t.id == "services" || t.id == "deployments" || t.id == "replica-sets" {
+		// Volumes and storage classes aren't namespaced, so aren't filtered
+		if t.id == "pods" || t.id == "services" || t.id == "deployments" || t.id == "replica-sets" || t.id == "persistent-volume-claims" {
 			topologies[i] = updateTopologyFilters(t, []APITopologyOptionGroup{kubernetesFilters(ns...)})
 		}
 	}
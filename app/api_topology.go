@@ -2,6 +2,7 @@ package app
 
 import (
 	"net/http"
+	"sort"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -9,6 +10,7 @@ import (
 	"golang.org/x/net/context"
 
 	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/render"
 	"$GITHUB_URI/render/detailed"
 	"$GITHUB_URI/report"
@@ -28,6 +30,24 @@ type APINode struct {
 	Node detailed.Node `json:"node"`
 }
 
+// APIEvents is returned by the /api/topology/{name}/{id}/events handler.
+type APIEvents struct {
+	Events []APIEvent `json:"events"`
+}
+
+// APIEvent is a container lifecycle event on the timeline of a node.
+type APIEvent struct {
+	docker.ContainerEvent
+	NodeID string `json:"node_id"`
+	Label  string `json:"label"`
+}
+
+type apiEventsByTime []APIEvent
+
+func (e apiEventsByTime) Len() int           { return len(e) }
+func (e apiEventsByTime) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e apiEventsByTime) Less(i, j int) bool { return e[i].Time.Before(e[j].Time) }
+
 // Full topology.
 func handleTopology(ctx context.Context, renderer render.Renderer, decorator render.Decorator, report report.Report, w http.ResponseWriter, r *http.Request) {
 	respondWith(w, http.StatusOK, APITopology{
@@ -51,6 +71,47 @@ func handleNode(ctx context.Context, renderer render.Renderer, _ render.Decorato
 	respondWith(w, http.StatusOK, APINode{Node: detailed.MakeNode(topologyID, report, rendered, node)})
 }
 
+// Lifecycle events of the containers of an individual node, oldest first.
+// Reports only hold the most recent events of each container, so those kept
+// by the Reporter are served too, if it keeps any.
+func makeNodeEventsHandler(rep Reporter) rendererHandler {
+	history, _ := rep.(ContainerEventHistory)
+	return func(ctx context.Context, renderer render.Renderer, _ render.Decorator, rpt report.Report, w http.ResponseWriter, r *http.Request) {
+		var (
+			nodeID   = mux.Vars(r)["id"]
+			rendered = renderer.Render(rpt, nil)
+			node, ok = rendered[nodeID]
+		)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		events := []APIEvent{}
+		addEvents := func(n report.Node) {
+			if n.Topology != report.Container {
+				return
+			}
+			label := n.ID
+			if summary, ok := detailed.MakeNodeSummary(rpt, n); ok {
+				label = summary.Label
+			}
+			containerEvents := docker.ParseContainerEvents(n)
+			if history != nil {
+				containerEvents = mergeContainerEvents(history.ContainerEvents(ctx, n.ID), containerEvents)
+			}
+			for _, event := range containerEvents {
+				events = append(events, APIEvent{ContainerEvent: event, NodeID: n.ID, Label: label})
+			}
+		}
+		addEvents(node)
+		node.Children.ForEach(addEvents)
+		sort.Sort(apiEventsByTime(events))
+
+		respondWith(w, http.StatusOK, APIEvents{Events: events})
+	}
+}
+
 // Websocket for the full topology.
 func handleWebsocket(
 	ctx context.Context,
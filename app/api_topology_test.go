@@ -2,13 +2,16 @@ package app_test
 
 import (
 	"fmt"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/ugorji/go/codec"
 
 	"$GITHUB_URI/app"
+	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/render/detailed"
 	"$GITHUB_URI/render/expected"
 	"$GITHUB_URI/test/fixture"
@@ -117,6 +120,47 @@ func TestAPITopologyHosts(t *testing.T) {
 	}
 }
 
+func TestAPITopologyNodeEvents(t *testing.T) {
+	router := mux.NewRouter().SkipClean(true)
+	app.RegisterTopologyRoutes(router, EventsReport{})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+	is404(t, ts, "/api/topology/containers/foobar/events")
+
+	getEvents := func(path string) app.APIEvents {
+		body := getRawJSON(t, ts, path)
+		var events app.APIEvents
+		decoder := codec.NewDecoderBytes(body, &codec.JsonHandle{})
+		if err := decoder.Decode(&events); err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	// Events of containers are merged into the timeline of the nodes they
+	// are grouped into.
+	for _, path := range []string{
+		"/api/topology/containers/" + url.QueryEscape(fixture.ServerContainerNodeID) + "/events",
+		"/api/topology/containers-by-image/" + url.QueryEscape(fixture.ServerContainerImageNodeID) + "/events",
+	} {
+		events := getEvents(path)
+		if len(events.Events) != 2 {
+			t.Fatalf("%s: expected 2 events, got %v", path, events.Events)
+		}
+		die, start := events.Events[0], events.Events[1]
+		equals(t, docker.DieEvent, die.Action)
+		equals(t, 137, *die.ExitCode)
+		equals(t, true, die.OOMKilled)
+		equals(t, fixture.ServerContainerNodeID, die.NodeID)
+		equals(t, "server", die.Label)
+		equals(t, docker.StartEvent, start.Action)
+	}
+
+	if events := getEvents("/api/topology/containers/" + url.QueryEscape(fixture.ClientContainerNodeID) + "/events"); len(events.Events) != 0 {
+		t.Errorf("Expected no events, got %v", events.Events)
+	}
+}
+
 // Basic websocket test
 func TestAPITopologyWebsocket(t *testing.T) {
 	ts := topologyServer()
@@ -7,6 +7,7 @@ import (
 	"golang.org/x/net/context"
 
 	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/report"
 )
 
@@ -39,6 +40,7 @@ type collector struct {
 	window     time.Duration
 	cached     *report.Report
 	merger     Merger
+	events     *eventHistory
 	waitableCondition
 }
 
@@ -79,6 +81,7 @@ func NewCollector(window time.Duration) Collector {
 			waiters: map[chan struct{}]struct{}{},
 		},
 		merger: NewSmartMerger(),
+		events: newEventHistory(),
 	}
 }
 
@@ -91,6 +94,7 @@ func (c *collector) Add(_ context.Context, rpt report.Report) error {
 
 	c.clean()
 	c.cached = nil
+	c.events.add(rpt)
 	if rpt.Shortcut {
 		c.Broadcast()
 	}
@@ -116,6 +120,13 @@ func (c *collector) Report(_ context.Context) (report.Report, error) {
 	return c.merger.Merge(c.reports), nil
 }
 
+// ContainerEvents returns the lifecycle events of a container, oldest first,
+// including those no longer in the reports collected. It implements
+// ContainerEventHistory.
+func (c *collector) ContainerEvents(_ context.Context, nodeID string) []docker.ContainerEvent {
+	return c.events.get(nodeID)
+}
+
 func (c *collector) clean() {
 	var (
 		cleanedReports    = make([]report.Report, 0, len(c.reports))
@@ -8,6 +8,7 @@ import (
 
 	"$GITHUB_URI/app"
 	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/report"
 	"$GITHUB_URI/test"
 	"$GITHUB_URI/test/reflect"
@@ -113,3 +114,43 @@ func TestCollectorWait(t *testing.T) {
 		t.Fatal("Didn't unblock")
 	}
 }
+
+func TestCollectorContainerEvents(t *testing.T) {
+	now := time.Date(2016, time.September, 14, 10, 0, 0, 0, time.UTC)
+	mtime.NowForce(now)
+	defer mtime.NowReset()
+
+	ctx := context.Background()
+	window := 10 * time.Second
+	c := app.NewCollector(window)
+	nodeID := report.MakeContainerNodeID("ping")
+	eventsReport := func(events ...docker.ContainerEvent) report.Report {
+		r := report.MakeReport()
+		r.Container.AddNode(docker.WithContainerEvents(report.MakeNode(nodeID), events))
+		return r
+	}
+
+	die := docker.ContainerEvent{Time: now.Add(-time.Minute), Action: docker.DieEvent}
+	start := docker.ContainerEvent{Time: now, Action: docker.StartEvent}
+	c.Add(ctx, eventsReport(die))
+	c.Add(ctx, eventsReport(die, start))
+
+	// Events are kept once, and after the reports they came in expire
+	mtime.NowForce(now.Add(time.Minute))
+	c.Add(ctx, eventsReport())
+	history := c.(app.ContainerEventHistory)
+	have := history.ContainerEvents(ctx, nodeID)
+	for i := range have {
+		have[i].Time = have[i].Time.UTC()
+	}
+	if want := []docker.ContainerEvent{die, start}; !reflect.DeepEqual(want, have) {
+		t.Error(test.Diff(want, have))
+	}
+
+	// ...but not for ever once the container is gone
+	mtime.NowForce(now.Add(2 * time.Hour))
+	c.Add(ctx, report.MakeReport())
+	if have := history.ContainerEvents(ctx, nodeID); len(have) != 0 {
+		t.Errorf("Expected the events of a container gone for long to be forgotten, got %v", have)
+	}
+}
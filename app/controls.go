@@ -30,9 +30,21 @@ func handleControl(cr ControlRouter) CtxHandlerFunc {
 			nodeID  = vars["nodeID"]
 			control = vars["control"]
 		)
+		if err := r.ParseForm(); err != nil {
+			respondWith(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var controlArgs map[string]string
+		for key := range r.Form {
+			if controlArgs == nil {
+				controlArgs = map[string]string{}
+			}
+			controlArgs[key] = r.Form.Get(key)
+		}
 		result, err := cr.Handle(ctx, probeID, xfer.Request{
-			NodeID:  nodeID,
-			Control: control,
+			NodeID:      nodeID,
+			Control:     control,
+			ControlArgs: controlArgs,
 		})
 		if err != nil {
 			respondWith(w, http.StatusBadRequest, err.Error())
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+)
+
+const (
+	// maxContainerEvents bounds the history kept per container. A
+	// crashlooping container has a start and a die per restart, so this is
+	// well above the events probes send in each report.
+	maxContainerEvents = 100
+
+	// The history of containers which haven't been reported for this long
+	// is forgotten.
+	containerEventRetention = time.Hour
+)
+
+// ContainerEventHistory is implemented by Reporters which keep the lifecycle
+// events of containers for longer than the reports they came in.
+type ContainerEventHistory interface {
+	ContainerEvents(ctx context.Context, nodeID string) []docker.ContainerEvent
+}
+
+type containerEvents struct {
+	events   []docker.ContainerEvent
+	lastSeen time.Time
+}
+
+// eventHistory accumulates the events of the containers of the reports
+// added to it, as probes only send the most recent events of each.
+type eventHistory struct {
+	sync.Mutex
+	containers map[string]*containerEvents
+}
+
+func newEventHistory() *eventHistory {
+	return &eventHistory{containers: map[string]*containerEvents{}}
+}
+
+func (h *eventHistory) add(rpt report.Report) {
+	h.Lock()
+	defer h.Unlock()
+	now := mtime.Now()
+	for id, node := range rpt.Container.Nodes {
+		events := docker.ParseContainerEvents(node)
+		history, ok := h.containers[id]
+		if !ok {
+			if len(events) == 0 {
+				continue
+			}
+			history = &containerEvents{}
+			h.containers[id] = history
+		}
+		history.lastSeen = now
+		history.events = mergeContainerEvents(history.events, events)
+	}
+	for id, history := range h.containers {
+		if now.Sub(history.lastSeen) > containerEventRetention {
+			delete(h.containers, id)
+		}
+	}
+}
+
+func (h *eventHistory) get(nodeID string) []docker.ContainerEvent {
+	h.Lock()
+	defer h.Unlock()
+	history, ok := h.containers[nodeID]
+	if !ok {
+		return nil
+	}
+	result := make([]docker.ContainerEvent, len(history.events))
+	copy(result, history.events)
+	return result
+}
+
+// mergeContainerEvents merges two histories, oldest first, keeping the most
+// recent maxContainerEvents. Events are keyed by their time, so those sent
+// in several reports are only kept once.
+func mergeContainerEvents(a, b []docker.ContainerEvent) []docker.ContainerEvent {
+	byTime := make(map[int64]docker.ContainerEvent, len(a)+len(b))
+	for _, events := range [][]docker.ContainerEvent{a, b} {
+		for _, event := range events {
+			byTime[event.Time.UnixNano()] = event
+		}
+	}
+	result := make([]docker.ContainerEvent, 0, len(byTime))
+	for _, event := range byTime {
+		result = append(result, event)
+	}
+	sort.Sort(containerEventsByTime(result))
+	if len(result) > maxContainerEvents {
+		result = result[len(result)-maxContainerEvents:]
+	}
+	return result
+}
+
+type containerEventsByTime []docker.ContainerEvent
+
+func (e containerEventsByTime) Len() int           { return len(e) }
+func (e containerEventsByTime) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e containerEventsByTime) Less(i, j int) bool { return e[i].Time.Before(e[j].Time) }
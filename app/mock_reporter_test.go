@@ -1,6 +1,7 @@
 package app_test
 
 import (
+	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/report"
 	"$GITHUB_URI/test/fixture"
 
@@ -14,3 +15,15 @@ func (s StaticReport) Report(context.Context) (report.Report, error) { return fi
 func (s StaticReport) Add(context.Context, report.Report) error      { return nil }
 func (s StaticReport) WaitOn(context.Context, chan struct{})         {}
 func (s StaticReport) UnWait(context.Context, chan struct{})         {}
+
+// EventsReport is StaticReport, with lifecycle events on the server container.
+type EventsReport struct{ StaticReport }
+
+func (s EventsReport) Report(context.Context) (report.Report, error) {
+	rpt := fixture.Report.Copy()
+	rpt.Container.Nodes[fixture.ServerContainerNodeID] = rpt.Container.Nodes[fixture.ServerContainerNodeID].WithLatests(map[string]string{
+		docker.EventPrefix + "2016-09-14T10:00:01.000000000Z": "start",
+		docker.EventPrefix + "2016-09-14T10:00:00.000000000Z": "die (exit code 137, OOM killed)",
+	})
+	return rpt, nil
+}
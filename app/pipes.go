@@ -1,6 +1,9 @@
 package app
 
 import (
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 
 	log "github.com/Sirupsen/logrus"
@@ -10,6 +13,10 @@ import (
 	"$GITHUB_URI/common/xfer"
 )
 
+// maxDownloadSize bounds the archives downloaded through pipes. It is
+// lowered in tests.
+var maxDownloadSize = xfer.MaxArchiveSize
+
 // RegisterPipeRoutes registers the pipe routes
 func RegisterPipeRoutes(router *mux.Router, pr PipeRouter) {
 	router.Methods("GET").
@@ -24,6 +31,14 @@ func RegisterPipeRoutes(router *mux.Router, pr PipeRouter) {
 		Path("/api/pipe/{pipeID}/probe").
 		HandlerFunc(requestContextDecorator(handlePipeWs(pr, ProbeEnd)))
 
+	router.Methods("GET").
+		Path("/api/pipe/{pipeID}/download").
+		HandlerFunc(requestContextDecorator(downloadFromPipe(pr)))
+
+	router.Methods("POST").
+		Path("/api/pipe/{pipeID}/upload").
+		HandlerFunc(requestContextDecorator(uploadToPipe(pr)))
+
 	router.Methods("DELETE", "POST").
 		Path("/api/pipe/{pipeID}").
 		HandlerFunc(requestContextDecorator(deletePipe(pr)))
@@ -68,6 +83,91 @@ func handlePipeWs(pr PipeRouter, end End) CtxHandlerFunc {
 	}
 }
 
+// downloadFromPipe serves the archive a probe streams down a pipe, until the
+// probe closes it.
+func downloadFromPipe(pr PipeRouter) CtxHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["pipeID"]
+		_, endIO, err := pr.Get(ctx, id, UIEnd)
+		if err != nil {
+			log.Errorf("Error getting pipe %s: %v", id, err)
+			http.NotFound(w, r)
+			return
+		}
+		defer pr.Release(ctx, id, UIEnd)
+
+		w.Header().Set("Content-Type", "application/x-tar")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".tar"))
+		// One byte more than allowed is read, to tell archives at the limit
+		// from those cut short by it.
+		n, err := io.Copy(w, io.LimitReader(endIO, maxDownloadSize+1))
+		if err == io.ErrClosedPipe {
+			err = nil
+		}
+		if err == nil && n > maxDownloadSize {
+			err = fmt.Errorf("archive larger than %d bytes", maxDownloadSize)
+		}
+		if err != nil {
+			log.Errorf("Error downloading from pipe %s for %s after %d bytes: %v", id, r.RemoteAddr, n, err)
+			abortResponse(w)
+			return
+		}
+		log.Infof("Downloaded %d bytes from pipe %s for %s", n, id, r.RemoteAddr)
+	}
+}
+
+// abortResponse closes the connection of a response already under way, so
+// that the client sees it fail rather than end.
+func abortResponse(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return
+	}
+	if conn, _, err := hijacker.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// uploadToPipe streams the archive in the request body up a pipe, and waits
+// for the probe to close it. Whatever the probe wrote back meanwhile is what
+// went wrong.
+func uploadToPipe(pr PipeRouter) CtxHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["pipeID"]
+		if r.ContentLength > xfer.MaxArchiveSize {
+			respondWith(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Archive larger than %d bytes", xfer.MaxArchiveSize))
+			return
+		}
+		_, endIO, err := pr.Get(ctx, id, UIEnd)
+		if err != nil {
+			log.Errorf("Error getting pipe %s: %v", id, err)
+			http.NotFound(w, r)
+			return
+		}
+		defer pr.Release(ctx, id, UIEnd)
+
+		body := http.MaxBytesReader(w, r.Body, xfer.MaxArchiveSize)
+		n, err := io.Copy(endIO, body)
+		if err != nil {
+			log.Errorf("Error uploading to pipe %s for %s after %d bytes: %v", id, r.RemoteAddr, n, err)
+			respondWith(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		failure, err := ioutil.ReadAll(io.LimitReader(endIO, 4096))
+		if err != nil && err != io.ErrClosedPipe {
+			respondWith(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if len(failure) > 0 {
+			log.Errorf("Error uploading to pipe %s for %s: %s", id, r.RemoteAddr, failure)
+			respondWith(w, http.StatusInternalServerError, string(failure))
+			return
+		}
+		log.Infof("Uploaded %d bytes to pipe %s for %s", n, id, r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 func deletePipe(pr PipeRouter) CtxHandlerFunc {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 		pipeID := mux.Vars(r)["pipeID"]
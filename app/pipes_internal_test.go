@@ -3,6 +3,8 @@ package app
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -139,3 +141,75 @@ func TestPipeClose(t *testing.T) {
 		return pipe.Closed()
 	})
 }
+
+func TestPipeFiles(t *testing.T) {
+	router := mux.NewRouter()
+	pr := NewLocalPipeRouter()
+	RegisterPipeRoutes(router, pr)
+	defer pr.Stop()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// the probe streams the archive, then closes the pipe
+	ctx := context.Background()
+	_, probe, err := pr.Get(ctx, "download", ProbeEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		probe.Write([]byte("archive"))
+		pr.Delete(ctx, "download")
+	}()
+	resp, err := http.Get(server.URL + "/api/pipe/download/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "archive" {
+		t.Errorf("Expected to download %q, got %d %q", "archive", resp.StatusCode, body)
+	}
+
+	// archives going past the limit fail, rather than end early
+	oldMaxDownloadSize := maxDownloadSize
+	defer func() { maxDownloadSize = oldMaxDownloadSize }()
+	maxDownloadSize = 4
+	_, probe, err = pr.Get(ctx, "toolarge", ProbeEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		probe.Write([]byte("archive"))
+		pr.Delete(ctx, "toolarge")
+	}()
+	resp, err = http.Get(server.URL + "/api/pipe/toolarge/download")
+	if err == nil {
+		_, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	if err == nil {
+		t.Errorf("Expected a download past the limit to fail")
+	}
+	maxDownloadSize = oldMaxDownloadSize
+
+	// the probe reads the archive, then says what went wrong
+	_, probe, err = pr.Get(ctx, "upload", ProbeEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.ReadFull(probe, make([]byte, len("archive")))
+		probe.Write([]byte("no space left on device"))
+		pr.Delete(ctx, "upload")
+	}()
+	resp, err = http.Post(server.URL+"/api/pipe/upload/upload", "application/x-tar", strings.NewReader("archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || !strings.Contains(string(body), "no space left on device") {
+		t.Errorf("Expected upload to fail, got %d %q", resp.StatusCode, body)
+	}
+}
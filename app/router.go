@@ -96,6 +96,8 @@ func RegisterTopologyRoutes(router *mux.Router, r Reporter) {
 		requestContextDecorator(captureReporter(r, handleWebsocket))) // NB not gzip!
 	get.MatcherFunc(URLMatcher("/api/topology/{topology}/{id}")).HandlerFunc(
 		gzipHandler(requestContextDecorator(topologyRegistry.captureRenderer(r, handleNode))))
+	get.MatcherFunc(URLMatcher("/api/topology/{topology}/{id}/events")).HandlerFunc(
+		gzipHandler(requestContextDecorator(topologyRegistry.captureRenderer(r, makeNodeEventsHandler(r)))))
 	get.HandleFunc("/api/report",
 		gzipHandler(requestContextDecorator(makeRawReportHandler(r))))
 	get.HandleFunc("/api/probes",
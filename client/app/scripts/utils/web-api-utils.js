@@ -204,6 +204,9 @@ export function doControlRequest(nodeId, control, dispatch) {
   reqwest({
     method: 'POST',
     url,
+    // e.g. the start time of a process, so that the probe can tell it apart
+    // from a later one reusing its PID
+    data: control.args,
     success: (res) => {
       dispatch(receiveControlSuccess(nodeId));
       if (res) {
@@ -15,6 +15,7 @@ type Interface interface {
 	Lstat(string, *syscall.Stat_t) error
 	Stat(string, *syscall.Stat_t) error
 	Open(string) (io.ReadWriteCloser, error)
+	Readlink(string) (string, error)
 }
 
 type realFS struct{}
@@ -51,6 +52,10 @@ func (realFS) Open(path string) (io.ReadWriteCloser, error) {
 	return os.Open(path)
 }
 
+func (realFS) Readlink(path string) (string, error) {
+	return os.Readlink(path)
+}
+
 // trampolines here to allow users to do fs.ReadDir etc
 
 // ReadDir see ioutil.ReadDir
@@ -83,6 +88,11 @@ func Open(path string) (io.ReadWriteCloser, error) {
 	return fs.Open(path)
 }
 
+// Readlink see os.Readlink
+func Readlink(path string) (string, error) {
+	return fs.Readlink(path)
+}
+
 // Mock is used to switch out the filesystem for a mock.
 func Mock(mock Interface) {
 	fs = mock
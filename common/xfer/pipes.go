@@ -7,6 +7,10 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// MaxArchiveSize bounds the size of the archives of files copied into and
+// out of containers through a pipe.
+const MaxArchiveSize int64 = 1 << 30
+
 // Pipe is a bi-directional channel from someone thing in the probe
 // to the UI.
 type Pipe interface {
@@ -7,7 +7,6 @@ import (
 	"mime"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gorilla/mux"
 
@@ -65,8 +64,9 @@ func (t *tracer) http(port int) {
 		containers := []Container{}
 		t.docker.WalkContainers(func(container docker.Container) {
 			children, _ := pidTree.GetChildren(container.PID())
+			name, _ := container.GetNode().Latest.Lookup(docker.ContainerName)
 			out := Container{
-				Name: strings.TrimPrefix(container.Container().Name, "/"),
+				Name: name,
 				ID:   container.ID(),
 				PIDs: children,
 			}
@@ -32,7 +32,7 @@ func (t *tracer) Stop() {
 }
 
 func main() {
-	dockerRegistry, err := docker.NewRegistry(pollInterval, nil, false, "")
+	dockerRegistry, err := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, pollInterval, nil, false, "", nil)
 	if err != nil {
 		log.Fatalf("Could start docker watcher: %v", err)
 	}
@@ -82,10 +82,22 @@ func TestAppClientPublish(t *testing.T) {
 	rpt.Process.Controls = nil
 	rpt.Container.Controls = nil
 	rpt.ContainerImage.Controls = nil
+	rpt.DockerNetwork.Controls = nil
+	rpt.DockerVolume.Controls = nil
+	rpt.SwarmService.Controls = nil
+	rpt.SwarmTask.Controls = nil
+	rpt.SwarmNode.Controls = nil
+	rpt.ComposeProject.Controls = nil
+	rpt.ComposeService.Controls = nil
+	rpt.SystemdUnit.Controls = nil
+	rpt.NetworkInterface.Controls = nil
 	rpt.Pod.Controls = nil
 	rpt.Service.Controls = nil
 	rpt.Deployment.Controls = nil
 	rpt.ReplicaSet.Controls = nil
+	rpt.PersistentVolume.Controls = nil
+	rpt.PersistentVolumeClaim.Controls = nil
+	rpt.StorageClass.Controls = nil
 	rpt.Host.Controls = nil
 	rpt.Overlay.Controls = nil
 
@@ -0,0 +1,199 @@
+// Package cloud finds out which cloud, if any, the probe's host is an instance
+// of, from the metadata service of the provider.
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	AWS       = "aws"
+	GCE       = "gce"
+	Azure     = "azure"
+	OpenStack = "openstack"
+)
+
+// Metadata of a cloud instance
+type Metadata struct {
+	Provider     string
+	InstanceID   string
+	InstanceType string
+	Region       string
+	Zone         string
+}
+
+// provider fetches the metadata of an instance from the metadata service at
+// an endpoint, by default its own.
+type provider struct {
+	name     string
+	endpoint string
+	fetch    func(c client) (Metadata, error)
+}
+
+// providers in order of precedence: OpenStack also serves the EC2 metadata
+// API, so it goes before AWS.
+var providers = []provider{
+	{name: GCE, endpoint: "http://metadata.google.internal", fetch: fetchGCE},
+	{name: Azure, endpoint: "http://169.254.169.254", fetch: fetchAzure},
+	{name: OpenStack, endpoint: "http://169.254.169.254", fetch: fetchOpenStack},
+	{name: AWS, endpoint: "http://169.254.169.254", fetch: fetchAWS},
+}
+
+// Detect queries the metadata services of all providers at once, at their
+// own endpoint or else at the one given, and returns the metadata of the
+// first provider, by precedence, to answer within the timeout.
+func Detect(endpoint string, timeout time.Duration) (Metadata, bool) {
+	httpClient := &http.Client{Timeout: timeout}
+	results := make([]chan *Metadata, len(providers))
+	for i, p := range providers {
+		results[i] = make(chan *Metadata, 1)
+		c := client{http: httpClient, endpoint: strings.TrimSuffix(endpoint, "/")}
+		if c.endpoint == "" {
+			c.endpoint = p.endpoint
+		}
+		go func(p provider, result chan<- *Metadata) {
+			metadata, err := p.fetch(c)
+			if err != nil || metadata.InstanceID == "" {
+				result <- nil
+				return
+			}
+			metadata.Provider = p.name
+			result <- &metadata
+		}(p, results[i])
+	}
+	for _, result := range results {
+		if metadata := <-result; metadata != nil {
+			return *metadata, true
+		}
+	}
+	return Metadata{}, false
+}
+
+type client struct {
+	http     *http.Client
+	endpoint string
+}
+
+// do makes a request to the metadata service, and returns the body of the
+// response, if successful.
+func (c client) do(method, path string, header map[string]string) (string, error) {
+	req, err := http.NewRequest(method, c.endpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func (c client) get(path string, header map[string]string) (string, error) {
+	return c.do("GET", path, header)
+}
+
+// fetchAWS uses the session tokens of IMDSv2 where enabled, and falls back
+// to IMDSv1 otherwise.
+func fetchAWS(c client) (Metadata, error) {
+	header := map[string]string{}
+	if token, err := c.do("PUT", "/latest/api/token", map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"}); err == nil {
+		header["X-aws-ec2-metadata-token"] = token
+	}
+	var (
+		metadata Metadata
+		err      error
+	)
+	if metadata.InstanceID, err = c.get("/latest/meta-data/instance-id", header); err != nil {
+		return Metadata{}, err
+	}
+	metadata.InstanceType, _ = c.get("/latest/meta-data/instance-type", header)
+	metadata.Zone, _ = c.get("/latest/meta-data/placement/availability-zone", header)
+	if metadata.Region, err = c.get("/latest/meta-data/placement/region", header); err != nil && metadata.Zone != "" {
+		// Older instances only know their zone, e.g. us-east-1a
+		metadata.Region = metadata.Zone[:len(metadata.Zone)-1]
+	}
+	return metadata, nil
+}
+
+// fetchGCE reads the machine type and zone, which are given as resource
+// paths, e.g. projects/123/zones/us-central1-a.
+func fetchGCE(c client) (Metadata, error) {
+	header := map[string]string{"Metadata-Flavor": "Google"}
+	var (
+		metadata Metadata
+		err      error
+	)
+	if metadata.InstanceID, err = c.get("/computeMetadata/v1/instance/id", header); err != nil {
+		return Metadata{}, err
+	}
+	if machineType, err := c.get("/computeMetadata/v1/instance/machine-type", header); err == nil {
+		metadata.InstanceType = path.Base(machineType)
+	}
+	if zone, err := c.get("/computeMetadata/v1/instance/zone", header); err == nil {
+		metadata.Zone = path.Base(zone)
+		if i := strings.LastIndex(metadata.Zone, "-"); i > 0 {
+			metadata.Region = metadata.Zone[:i]
+		}
+	}
+	return metadata, nil
+}
+
+// fetchAzure reads the compute metadata of the instance. Azure numbers the
+// zones of each region from 1, so zones are named after their region,
+// e.g. eastus-1.
+func fetchAzure(c client) (Metadata, error) {
+	body, err := c.get("/metadata/instance/compute?api-version=2021-02-01&format=json", map[string]string{"Metadata": "true"})
+	if err != nil {
+		return Metadata{}, err
+	}
+	var compute struct {
+		VMID     string `json:"vmId"`
+		VMSize   string `json:"vmSize"`
+		Location string `json:"location"`
+		Zone     string `json:"zone"`
+	}
+	if err := json.Unmarshal([]byte(body), &compute); err != nil {
+		return Metadata{}, err
+	}
+	metadata := Metadata{InstanceID: compute.VMID, InstanceType: compute.VMSize, Region: compute.Location}
+	if compute.Zone != "" {
+		metadata.Zone = compute.Location + "-" + compute.Zone
+	}
+	return metadata, nil
+}
+
+// fetchOpenStack reads the OpenStack metadata of the instance, which has no
+// region, and its flavor from the EC2 compatible metadata, where served.
+func fetchOpenStack(c client) (Metadata, error) {
+	body, err := c.get("/openstack/latest/meta_data.json", nil)
+	if err != nil {
+		return Metadata{}, err
+	}
+	var metadata struct {
+		UUID             string `json:"uuid"`
+		AvailabilityZone string `json:"availability_zone"`
+	}
+	if err := json.Unmarshal([]byte(body), &metadata); err != nil {
+		return Metadata{}, err
+	}
+	instanceType, _ := c.get("/latest/meta-data/instance-type", nil)
+	return Metadata{InstanceID: metadata.UUID, InstanceType: instanceType, Zone: metadata.AvailabilityZone}, nil
+}
@@ -0,0 +1,138 @@
+package cloud_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"$GITHUB_URI/probe/cloud"
+	"$GITHUB_URI/report"
+)
+
+// metadataService stands in for the metadata service of a provider, serving
+// paths only to requests with the headers given.
+func metadataService(header map[string]string, paths map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, value := range header {
+			if r.Header.Get(key) != value {
+				http.Error(w, "missing header "+key, http.StatusForbidden)
+				return
+			}
+		}
+		body, ok := paths[r.Method+" "+r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		header map[string]string
+		paths  map[string]string
+		want   cloud.Metadata
+	}{
+		{
+			name: "aws",
+			paths: map[string]string{
+				"PUT /latest/api/token":                             "token",
+				"GET /latest/meta-data/instance-id":                 "i-0123456789abcdef0",
+				"GET /latest/meta-data/instance-type":               "m5.large",
+				"GET /latest/meta-data/placement/availability-zone": "eu-west-1b",
+			},
+			want: cloud.Metadata{Provider: cloud.AWS, InstanceID: "i-0123456789abcdef0", InstanceType: "m5.large", Region: "eu-west-1", Zone: "eu-west-1b"},
+		},
+		{
+			name:   "gce",
+			header: map[string]string{"Metadata-Flavor": "Google"},
+			paths: map[string]string{
+				"GET /computeMetadata/v1/instance/id":           "4520031799277581759",
+				"GET /computeMetadata/v1/instance/machine-type": "projects/123/machineTypes/n1-standard-2",
+				"GET /computeMetadata/v1/instance/zone":         "projects/123/zones/us-central1-a",
+			},
+			want: cloud.Metadata{Provider: cloud.GCE, InstanceID: "4520031799277581759", InstanceType: "n1-standard-2", Region: "us-central1", Zone: "us-central1-a"},
+		},
+		{
+			name:   "azure",
+			header: map[string]string{"Metadata": "true"},
+			paths: map[string]string{
+				"GET /metadata/instance/compute?api-version=2021-02-01&format=json": `{"vmId":"02aab8a4-74ef-476e-8182-f6d2ba4166a6","vmSize":"Standard_D2s_v3","location":"eastus","zone":"2"}`,
+			},
+			want: cloud.Metadata{Provider: cloud.Azure, InstanceID: "02aab8a4-74ef-476e-8182-f6d2ba4166a6", InstanceType: "Standard_D2s_v3", Region: "eastus", Zone: "eastus-2"},
+		},
+		{
+			name: "openstack",
+			paths: map[string]string{
+				"GET /openstack/latest/meta_data.json": `{"uuid":"d8e02d56-2648-49a3-bf97-6be8f1204f38","availability_zone":"nova"}`,
+				// The EC2 compatible metadata, which must not be taken for AWS
+				"GET /latest/meta-data/instance-id":   "i-00000001",
+				"GET /latest/meta-data/instance-type": "m1.small",
+			},
+			want: cloud.Metadata{Provider: cloud.OpenStack, InstanceID: "d8e02d56-2648-49a3-bf97-6be8f1204f38", InstanceType: "m1.small", Zone: "nova"},
+		},
+	} {
+		server := metadataService(tc.header, tc.paths)
+		have, ok := cloud.Detect(server.URL, time.Second)
+		server.Close()
+		if !ok {
+			t.Errorf("%s: expected to be detected", tc.name)
+		} else if !reflect.DeepEqual(tc.want, have) {
+			t.Errorf("%s: expected %+v, got %+v", tc.name, tc.want, have)
+		}
+	}
+
+	server := metadataService(nil, nil)
+	defer server.Close()
+	if have, ok := cloud.Detect(server.URL, time.Second); ok {
+		t.Errorf("Expected no cloud, got %+v", have)
+	}
+}
+
+func TestTagger(t *testing.T) {
+	server := metadataService(nil, map[string]string{
+		"GET /latest/meta-data/instance-id":                 "i-0123456789abcdef0",
+		"GET /latest/meta-data/placement/availability-zone": "eu-west-1b",
+		"GET /latest/meta-data/placement/region":            "eu-west-1",
+	})
+	defer server.Close()
+
+	tagger := cloud.NewTagger("host1", server.URL)
+	defer tagger.Stop()
+
+	hostNodeID := report.MakeHostNodeID("host1")
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		rpt, err := tagger.Tag(report.MakeReport())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node, ok := rpt.Host.Nodes[hostNodeID]; ok {
+			want := map[string]string{
+				cloud.Provider:   cloud.AWS,
+				cloud.InstanceID: "i-0123456789abcdef0",
+				cloud.Region:     "eu-west-1",
+				cloud.Zone:       "eu-west-1b",
+			}
+			for key, value := range want {
+				if have, _ := node.Latest.Lookup(key); have != value {
+					t.Errorf("Expected %s %q, got %q", key, value, have)
+				}
+			}
+			if _, ok := node.Latest.Lookup(cloud.InstanceType); ok {
+				t.Errorf("Expected no instance type, as none was served")
+			}
+			if _, ok := rpt.Host.MetadataTemplates[cloud.Region]; !ok {
+				t.Errorf("Expected the cloud metadata templates")
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the host to be tagged")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
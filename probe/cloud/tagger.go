@@ -0,0 +1,116 @@
+package cloud
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/report"
+)
+
+// Keys of the cloud metadata of hosts
+const (
+	Provider     = "cloud_provider"
+	InstanceID   = "cloud_instance_id"
+	InstanceType = "cloud_instance_type"
+	Region       = "cloud_region"
+	Zone         = "cloud_zone"
+)
+
+const (
+	// How long the metadata services have to answer
+	timeout = time.Second
+	// How often the metadata services are asked again, while none answered
+	retryInterval = 5 * time.Minute
+)
+
+// MetadataTemplates are the host metadata the tagger adds.
+var MetadataTemplates = report.MetadataTemplates{
+	Provider:     {ID: Provider, Label: "Cloud", From: report.FromLatest, Priority: 15},
+	InstanceID:   {ID: InstanceID, Label: "Instance ID", From: report.FromLatest, Priority: 16},
+	InstanceType: {ID: InstanceType, Label: "Instance type", From: report.FromLatest, Priority: 17},
+	Region:       {ID: Region, Label: "Region", From: report.FromLatest, Priority: 18},
+	Zone:         {ID: Zone, Label: "Zone", From: report.FromLatest, Priority: 19},
+}
+
+// Tagger adds the cloud metadata of the probe's host to its host node. The
+// metadata services are only queried until one answers, as the metadata of
+// an instance does not change while it runs.
+type Tagger struct {
+	hostNodeID string
+	endpoint   string
+	quit       chan struct{}
+	done       chan struct{}
+
+	mtx      sync.Mutex
+	metadata map[string]string
+}
+
+// NewTagger makes a new Tagger, querying the metadata services at their own
+// endpoints, or at endpoint if not empty.
+func NewTagger(hostID, endpoint string) *Tagger {
+	t := &Tagger{
+		hostNodeID: report.MakeHostNodeID(hostID),
+		endpoint:   endpoint,
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go t.loop()
+	return t
+}
+
+// Name of this tagger, for metrics gathering
+func (*Tagger) Name() string { return "Cloud" }
+
+// Stop stops querying the metadata services.
+func (t *Tagger) Stop() {
+	close(t.quit)
+	<-t.done
+}
+
+func (t *Tagger) loop() {
+	defer close(t.done)
+	for {
+		if metadata, ok := Detect(t.endpoint, timeout); ok {
+			log.Infof("Cloud: %s instance %s in %s", metadata.Provider, metadata.InstanceID, metadata.Zone)
+			t.mtx.Lock()
+			t.metadata = map[string]string{
+				Provider:     metadata.Provider,
+				InstanceID:   metadata.InstanceID,
+				InstanceType: metadata.InstanceType,
+				Region:       metadata.Region,
+				Zone:         metadata.Zone,
+			}
+			for key, value := range t.metadata {
+				if value == "" {
+					delete(t.metadata, key)
+				}
+			}
+			t.mtx.Unlock()
+			return
+		}
+		select {
+		case <-t.quit:
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// Tag implements Tagger.
+func (t *Tagger) Tag(rpt report.Report) (report.Report, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.metadata == nil {
+		return rpt, nil
+	}
+	node := report.MakeNode(t.hostNodeID).WithTopology(report.Host)
+	now := mtime.Now()
+	for key, value := range t.metadata {
+		node = node.WithLatest(key, now, value)
+	}
+	rpt.Host = rpt.Host.AddNode(node).WithMetadataTemplates(MetadataTemplates)
+	return rpt, nil
+}
@@ -0,0 +1,58 @@
+package controls
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"$GITHUB_URI/common/mtime"
+)
+
+// Control arguments understood by the log controls.
+const (
+	// LogsFollowArg keeps streaming new lines once the existing ones have
+	// been sent; "true" or "false", defaulting to true.
+	LogsFollowArg = "follow"
+	// LogsTailArg limits the output to the last n lines; defaults to all.
+	LogsTailArg = "tail"
+	// LogsSinceArg only shows lines since a time, given either as an RFC3339
+	// timestamp or as a duration before now, e.g. "10m".
+	LogsSinceArg = "since"
+)
+
+// LogOptions are the options of a log control.
+type LogOptions struct {
+	Follow bool
+	Tail   int       // negative for all lines
+	Since  time.Time // zero for all lines
+}
+
+// ParseLogOptions parses the log options out of the arguments of a control
+// request.
+func ParseLogOptions(args map[string]string) (LogOptions, error) {
+	opts := LogOptions{Follow: true, Tail: -1}
+	if follow, ok := args[LogsFollowArg]; ok && follow != "" {
+		b, err := strconv.ParseBool(follow)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid %s: %q", LogsFollowArg, follow)
+		}
+		opts.Follow = b
+	}
+	if tail, ok := args[LogsTailArg]; ok && tail != "" && tail != "all" {
+		n, err := strconv.Atoi(tail)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("Invalid %s: %q", LogsTailArg, tail)
+		}
+		opts.Tail = n
+	}
+	if since, ok := args[LogsSinceArg]; ok && since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			opts.Since = t
+		} else if d, err := time.ParseDuration(since); err == nil && d >= 0 {
+			opts.Since = mtime.Now().Add(-d)
+		} else {
+			return opts, fmt.Errorf("Invalid %s: %q", LogsSinceArg, since)
+		}
+	}
+	return opts, nil
+}
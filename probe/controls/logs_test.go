@@ -0,0 +1,61 @@
+package controls_test
+
+import (
+	"testing"
+	"time"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/test"
+	"$GITHUB_URI/test/reflect"
+)
+
+func TestParseLogOptions(t *testing.T) {
+	now := time.Date(2016, time.September, 14, 10, 0, 0, 0, time.UTC)
+	mtime.NowForce(now)
+	defer mtime.NowReset()
+
+	for _, tc := range []struct {
+		args    map[string]string
+		want    controls.LogOptions
+		wantErr string
+	}{
+		{
+			args: nil,
+			want: controls.LogOptions{Follow: true, Tail: -1},
+		},
+		{
+			args: map[string]string{"follow": "false", "tail": "100", "since": "10m"},
+			want: controls.LogOptions{Follow: false, Tail: 100, Since: now.Add(-10 * time.Minute)},
+		},
+		{
+			args: map[string]string{"tail": "all", "since": "2016-09-13T08:00:00Z"},
+			want: controls.LogOptions{Follow: true, Tail: -1, Since: time.Date(2016, time.September, 13, 8, 0, 0, 0, time.UTC)},
+		},
+		{
+			args:    map[string]string{"tail": "-1"},
+			wantErr: `Invalid tail: "-1"`,
+		},
+		{
+			args:    map[string]string{"since": "yesterday"},
+			wantErr: `Invalid since: "yesterday"`,
+		},
+		{
+			args:    map[string]string{"follow": "maybe"},
+			wantErr: `Invalid follow: "maybe"`,
+		},
+	} {
+		have, err := controls.ParseLogOptions(tc.args)
+		if tc.wantErr != "" {
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("%v: expected error %q, got %v", tc.args, tc.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", tc.args, err)
+		} else if !reflect.DeepEqual(tc.want, have) {
+			t.Errorf("%v: %s", tc.args, test.Diff(tc.want, have))
+		}
+	}
+}
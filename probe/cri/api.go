@@ -0,0 +1,372 @@
+package cri
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The messages below are the subset of the CRI runtime API (runtime.v1, see
+// k8s.io/cri-api/pkg/apis/runtime/v1/api.proto) the probe uses. They are
+// declared here rather than vendored: the generated v1alpha1 package of the
+// vendored kubernetes is for a different service, without container stats
+// or pids, and the generated runtime.v1 package needs grpc and a newer Go.
+// Field numbers must match the upstream definitions; fields we don't use are
+// left out and skipped when decoding.
+
+// ContainerState is the state of a container, as reported by the runtime.
+type ContainerState int32
+
+// Container states.
+const (
+	ContainerCreated ContainerState = 0
+	ContainerRunning ContainerState = 1
+	ContainerExited  ContainerState = 2
+	ContainerUnknown ContainerState = 3
+)
+
+// NamespaceMode says which namespace a pod's containers share.
+type NamespaceMode int32
+
+// Namespace modes.
+const (
+	NamespacePod       NamespaceMode = 0
+	NamespaceContainer NamespaceMode = 1
+	NamespaceNode      NamespaceMode = 2
+	NamespaceTarget    NamespaceMode = 3
+)
+
+// VersionRequest is the request for RuntimeService.Version
+type VersionRequest struct {
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (m *VersionRequest) Reset()         { *m = VersionRequest{} }
+func (m *VersionRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*VersionRequest) ProtoMessage() {}
+
+// VersionResponse is the response for RuntimeService.Version
+type VersionResponse struct {
+	Version           string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	RuntimeName       string `protobuf:"bytes,2,opt,name=runtime_name,proto3" json:"runtime_name,omitempty"`
+	RuntimeVersion    string `protobuf:"bytes,3,opt,name=runtime_version,proto3" json:"runtime_version,omitempty"`
+	RuntimeAPIVersion string `protobuf:"bytes,4,opt,name=runtime_api_version,proto3" json:"runtime_api_version,omitempty"`
+}
+
+func (m *VersionResponse) Reset()         { *m = VersionResponse{} }
+func (m *VersionResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*VersionResponse) ProtoMessage() {}
+
+// ContainerMetadata holds the name of a container within its pod.
+type ContainerMetadata struct {
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Attempt uint32 `protobuf:"varint,2,opt,name=attempt,proto3" json:"attempt,omitempty"`
+}
+
+func (m *ContainerMetadata) Reset()         { *m = ContainerMetadata{} }
+func (m *ContainerMetadata) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ContainerMetadata) ProtoMessage() {}
+
+// ImageSpec names an image.
+type ImageSpec struct {
+	Image string `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+}
+
+func (m *ImageSpec) Reset()         { *m = ImageSpec{} }
+func (m *ImageSpec) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ImageSpec) ProtoMessage() {}
+
+// ListContainersRequest is the request for RuntimeService.ListContainers
+type ListContainersRequest struct{}
+
+func (m *ListContainersRequest) Reset()         { *m = ListContainersRequest{} }
+func (m *ListContainersRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ListContainersRequest) ProtoMessage() {}
+
+// ListContainersResponse is the response for RuntimeService.ListContainers
+type ListContainersResponse struct {
+	Containers []*APIContainer `protobuf:"bytes,1,rep,name=containers" json:"containers,omitempty"`
+}
+
+func (m *ListContainersResponse) Reset()         { *m = ListContainersResponse{} }
+func (m *ListContainersResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ListContainersResponse) ProtoMessage() {}
+
+// APIContainer is a container, as listed by the runtime.
+type APIContainer struct {
+	ID           string             `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PodSandboxID string             `protobuf:"bytes,2,opt,name=pod_sandbox_id,proto3" json:"pod_sandbox_id,omitempty"`
+	Metadata     *ContainerMetadata `protobuf:"bytes,3,opt,name=metadata" json:"metadata,omitempty"`
+	Image        *ImageSpec         `protobuf:"bytes,4,opt,name=image" json:"image,omitempty"`
+	ImageRef     string             `protobuf:"bytes,5,opt,name=image_ref,proto3" json:"image_ref,omitempty"`
+	State        ContainerState     `protobuf:"varint,6,opt,name=state,proto3" json:"state,omitempty"`
+	CreatedAt    int64              `protobuf:"varint,7,opt,name=created_at,proto3" json:"created_at,omitempty"`
+	Labels       map[string]string  `protobuf:"bytes,8,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *APIContainer) Reset()         { *m = APIContainer{} }
+func (m *APIContainer) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*APIContainer) ProtoMessage() {}
+
+// ContainerStatusRequest is the request for RuntimeService.ContainerStatus
+type ContainerStatusRequest struct {
+	ContainerID string `protobuf:"bytes,1,opt,name=container_id,proto3" json:"container_id,omitempty"`
+	Verbose     bool   `protobuf:"varint,2,opt,name=verbose,proto3" json:"verbose,omitempty"`
+}
+
+func (m *ContainerStatusRequest) Reset()         { *m = ContainerStatusRequest{} }
+func (m *ContainerStatusRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ContainerStatusRequest) ProtoMessage() {}
+
+// ContainerStatusResponse is the response for RuntimeService.ContainerStatus.
+// With Verbose set, Info["info"] holds runtime specific JSON, which for both
+// containerd and CRI-O includes the container's pid.
+type ContainerStatusResponse struct {
+	Status *ContainerStatus  `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Info   map[string]string `protobuf:"bytes,2,rep,name=info" json:"info,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *ContainerStatusResponse) Reset()         { *m = ContainerStatusResponse{} }
+func (m *ContainerStatusResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ContainerStatusResponse) ProtoMessage() {}
+
+// ContainerStatus is the detailed status of a container.
+type ContainerStatus struct {
+	ID         string             `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Metadata   *ContainerMetadata `protobuf:"bytes,2,opt,name=metadata" json:"metadata,omitempty"`
+	State      ContainerState     `protobuf:"varint,3,opt,name=state,proto3" json:"state,omitempty"`
+	CreatedAt  int64              `protobuf:"varint,4,opt,name=created_at,proto3" json:"created_at,omitempty"`
+	StartedAt  int64              `protobuf:"varint,5,opt,name=started_at,proto3" json:"started_at,omitempty"`
+	FinishedAt int64              `protobuf:"varint,6,opt,name=finished_at,proto3" json:"finished_at,omitempty"`
+	ExitCode   int32              `protobuf:"varint,7,opt,name=exit_code,proto3" json:"exit_code,omitempty"`
+	Image      *ImageSpec         `protobuf:"bytes,8,opt,name=image" json:"image,omitempty"`
+	ImageRef   string             `protobuf:"bytes,9,opt,name=image_ref,proto3" json:"image_ref,omitempty"`
+	Reason     string             `protobuf:"bytes,10,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message    string             `protobuf:"bytes,11,opt,name=message,proto3" json:"message,omitempty"`
+	Labels     map[string]string  `protobuf:"bytes,12,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *ContainerStatus) Reset()         { *m = ContainerStatus{} }
+func (m *ContainerStatus) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ContainerStatus) ProtoMessage() {}
+
+// PodSandboxStatusRequest is the request for RuntimeService.PodSandboxStatus
+type PodSandboxStatusRequest struct {
+	PodSandboxID string `protobuf:"bytes,1,opt,name=pod_sandbox_id,proto3" json:"pod_sandbox_id,omitempty"`
+}
+
+func (m *PodSandboxStatusRequest) Reset()         { *m = PodSandboxStatusRequest{} }
+func (m *PodSandboxStatusRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*PodSandboxStatusRequest) ProtoMessage() {}
+
+// PodSandboxStatusResponse is the response for RuntimeService.PodSandboxStatus
+type PodSandboxStatusResponse struct {
+	Status *PodSandboxStatus `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *PodSandboxStatusResponse) Reset()         { *m = PodSandboxStatusResponse{} }
+func (m *PodSandboxStatusResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*PodSandboxStatusResponse) ProtoMessage() {}
+
+// PodSandboxStatus is the status of a pod sandbox, the environment (network
+// namespace etc) shared by the containers of a pod.
+type PodSandboxStatus struct {
+	ID      string                   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Network *PodSandboxNetworkStatus `protobuf:"bytes,5,opt,name=network" json:"network,omitempty"`
+	Linux   *LinuxPodSandboxStatus   `protobuf:"bytes,6,opt,name=linux" json:"linux,omitempty"`
+}
+
+func (m *PodSandboxStatus) Reset()         { *m = PodSandboxStatus{} }
+func (m *PodSandboxStatus) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*PodSandboxStatus) ProtoMessage() {}
+
+// PodSandboxNetworkStatus holds the IPs of a pod sandbox.
+type PodSandboxNetworkStatus struct {
+	IP            string   `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	AdditionalIPs []*PodIP `protobuf:"bytes,2,rep,name=additional_ips" json:"additional_ips,omitempty"`
+}
+
+func (m *PodSandboxNetworkStatus) Reset()         { *m = PodSandboxNetworkStatus{} }
+func (m *PodSandboxNetworkStatus) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*PodSandboxNetworkStatus) ProtoMessage() {}
+
+// PodIP is an additional IP of a pod sandbox.
+type PodIP struct {
+	IP string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+}
+
+func (m *PodIP) Reset()         { *m = PodIP{} }
+func (m *PodIP) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*PodIP) ProtoMessage() {}
+
+// LinuxPodSandboxStatus holds the namespaces of a pod sandbox.
+type LinuxPodSandboxStatus struct {
+	Namespaces *Namespace `protobuf:"bytes,1,opt,name=namespaces" json:"namespaces,omitempty"`
+}
+
+func (m *LinuxPodSandboxStatus) Reset()         { *m = LinuxPodSandboxStatus{} }
+func (m *LinuxPodSandboxStatus) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*LinuxPodSandboxStatus) ProtoMessage() {}
+
+// Namespace holds the namespace options of a pod sandbox.
+type Namespace struct {
+	Options *NamespaceOption `protobuf:"bytes,2,opt,name=options" json:"options,omitempty"`
+}
+
+func (m *Namespace) Reset()         { *m = Namespace{} }
+func (m *Namespace) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Namespace) ProtoMessage() {}
+
+// NamespaceOption says which namespaces a pod sandbox shares.
+type NamespaceOption struct {
+	Network NamespaceMode `protobuf:"varint,1,opt,name=network,proto3" json:"network,omitempty"`
+}
+
+func (m *NamespaceOption) Reset()         { *m = NamespaceOption{} }
+func (m *NamespaceOption) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*NamespaceOption) ProtoMessage() {}
+
+// StopContainerRequest is the request for RuntimeService.StopContainer
+type StopContainerRequest struct {
+	ContainerID string `protobuf:"bytes,1,opt,name=container_id,proto3" json:"container_id,omitempty"`
+	Timeout     int64  `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (m *StopContainerRequest) Reset()         { *m = StopContainerRequest{} }
+func (m *StopContainerRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*StopContainerRequest) ProtoMessage() {}
+
+// RemoveContainerRequest is the request for RuntimeService.RemoveContainer
+type RemoveContainerRequest struct {
+	ContainerID string `protobuf:"bytes,1,opt,name=container_id,proto3" json:"container_id,omitempty"`
+}
+
+func (m *RemoveContainerRequest) Reset()         { *m = RemoveContainerRequest{} }
+func (m *RemoveContainerRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*RemoveContainerRequest) ProtoMessage() {}
+
+// Empty is the response of the calls which return nothing.
+type Empty struct{}
+
+func (m *Empty) Reset()         { *m = Empty{} }
+func (m *Empty) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Empty) ProtoMessage() {}
+
+// ListContainerStatsRequest is the request for RuntimeService.ListContainerStats
+type ListContainerStatsRequest struct{}
+
+func (m *ListContainerStatsRequest) Reset()         { *m = ListContainerStatsRequest{} }
+func (m *ListContainerStatsRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ListContainerStatsRequest) ProtoMessage() {}
+
+// ListContainerStatsResponse is the response for RuntimeService.ListContainerStats
+type ListContainerStatsResponse struct {
+	Stats []*ContainerStats `protobuf:"bytes,1,rep,name=stats" json:"stats,omitempty"`
+}
+
+func (m *ListContainerStatsResponse) Reset()         { *m = ListContainerStatsResponse{} }
+func (m *ListContainerStatsResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ListContainerStatsResponse) ProtoMessage() {}
+
+// ContainerStats holds the resource usage of a container.
+type ContainerStats struct {
+	Attributes *ContainerAttributes `protobuf:"bytes,1,opt,name=attributes" json:"attributes,omitempty"`
+	CPU        *CPUUsage            `protobuf:"bytes,2,opt,name=cpu" json:"cpu,omitempty"`
+	Memory     *MemoryUsage         `protobuf:"bytes,3,opt,name=memory" json:"memory,omitempty"`
+}
+
+func (m *ContainerStats) Reset()         { *m = ContainerStats{} }
+func (m *ContainerStats) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ContainerStats) ProtoMessage() {}
+
+// ContainerAttributes identifies the container stats are for.
+type ContainerAttributes struct {
+	ID string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *ContainerAttributes) Reset()         { *m = ContainerAttributes{} }
+func (m *ContainerAttributes) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ContainerAttributes) ProtoMessage() {}
+
+// CPUUsage is the cumulative CPU time used by a container.
+type CPUUsage struct {
+	Timestamp            int64        `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	UsageCoreNanoSeconds *UInt64Value `protobuf:"bytes,2,opt,name=usage_core_nano_seconds" json:"usage_core_nano_seconds,omitempty"`
+}
+
+func (m *CPUUsage) Reset()         { *m = CPUUsage{} }
+func (m *CPUUsage) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*CPUUsage) ProtoMessage() {}
+
+// MemoryUsage is the memory used by a container.
+type MemoryUsage struct {
+	Timestamp       int64        `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	WorkingSetBytes *UInt64Value `protobuf:"bytes,2,opt,name=working_set_bytes" json:"working_set_bytes,omitempty"`
+}
+
+func (m *MemoryUsage) Reset()         { *m = MemoryUsage{} }
+func (m *MemoryUsage) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*MemoryUsage) ProtoMessage() {}
+
+// UInt64Value wraps a uint64, so it can be told apart from unset.
+type UInt64Value struct {
+	Value uint64 `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *UInt64Value) Reset()         { *m = UInt64Value{} }
+func (m *UInt64Value) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*UInt64Value) ProtoMessage() {}
@@ -0,0 +1,224 @@
+package cri
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"golang.org/x/net/http2"
+)
+
+const (
+	runtimeService = "runtime.v1.RuntimeService"
+	requestTimeout = 10 * time.Second
+	maxMessageSize = 16 * 1024 * 1024
+)
+
+// Client is the subset of the CRI runtime service used by the registry,
+// and is an interface for mocking.
+type Client interface {
+	Version() (*VersionResponse, error)
+	ListContainers() ([]*APIContainer, error)
+	ContainerStatus(id string) (*ContainerStatusResponse, error)
+	PodSandboxStatus(id string) (*PodSandboxStatus, error)
+	ListContainerStats() ([]*ContainerStats, error)
+	StopContainer(id string, timeout int64) error
+	RemoveContainer(id string) error
+}
+
+// Error is a non-OK status returned by the runtime.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("cri: %s (code %d)", e.Message, e.Code)
+}
+
+// gRPC status codes we look out for.
+const (
+	codeNotFound = 5
+)
+
+// IsNotFound returns true if err says the container or sandbox asked for
+// doesn't exist.
+func IsNotFound(err error) bool {
+	e, ok := err.(Error)
+	return ok && e.Code == codeNotFound
+}
+
+// client makes unary gRPC calls to the runtime. gRPC is spoken directly over
+// a cleartext HTTP/2 connection to the runtime's unix socket, as the grpc
+// package isn't vendored; this is all the probe needs of it.
+type client struct {
+	http *http.Client
+}
+
+// NewClient makes a new Client talking to the runtime at endpoint, which
+// must be a unix:// URL, eg. unix:///run/containerd/containerd.sock
+func NewClient(endpoint string) (Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "unix" {
+		return nil, fmt.Errorf("cri: unsupported endpoint %q, only unix sockets are supported", endpoint)
+	}
+	socket := u.Path
+
+	return &client{
+		http: &http.Client{
+			// The transport only dials "TLS" connections, so the socket is
+			// handed to it as one; AllowHTTP lets it speak cleartext over it.
+			Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(_, _ string, _ *tls.Config) (net.Conn, error) {
+					return net.DialTimeout("unix", socket, requestTimeout)
+				},
+			},
+		},
+	}, nil
+}
+
+func (c *client) call(method string, req, resp proto.Message) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	// Each message is prefixed with a compressed flag and its length
+	frame := make([]byte, 5, 5+len(body))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(body)))
+	frame = append(frame, body...)
+
+	httpReq, err := http.NewRequest("POST", "http://localhost/"+runtimeService+"/"+method, bytes.NewReader(frame))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc+proto")
+	httpReq.Header.Set("TE", "trailers")
+	httpReq.Header.Set("User-Agent", "weavescope")
+	// The http2 transport can't be given a client timeout, so the request
+	// is cancelled instead; that also covers reading the response.
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	httpReq.Cancel = ctx.Done()
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cri: %s: unexpected HTTP status %s", method, httpResp.Status)
+	}
+
+	var (
+		header [5]byte
+		msg    []byte
+	)
+	if _, err := io.ReadFull(httpResp.Body, header[:]); err == nil {
+		if header[0] != 0 {
+			return fmt.Errorf("cri: %s: compressed responses are not supported", method)
+		}
+		length := binary.BigEndian.Uint32(header[1:])
+		if length > maxMessageSize {
+			return fmt.Errorf("cri: %s: response too large (%d bytes)", method, length)
+		}
+		msg = make([]byte, length)
+		if _, err := io.ReadFull(httpResp.Body, msg); err != nil {
+			return err
+		}
+	} else if err != io.EOF {
+		return err
+	}
+	// The status is in the trailers, which are only there once the body has
+	// been read; responses without a body may have it in the headers instead.
+	if _, err := io.Copy(ioutil.Discard, httpResp.Body); err != nil {
+		return err
+	}
+	if err := status(httpResp.Trailer, httpResp.Header); err != nil {
+		return err
+	}
+	return proto.Unmarshal(msg, resp)
+}
+
+func status(headers ...http.Header) error {
+	for _, h := range headers {
+		raw := h.Get("Grpc-Status")
+		if raw == "" {
+			continue
+		}
+		code, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("cri: invalid status %q", raw)
+		}
+		if code == 0 {
+			return nil
+		}
+		// The message is percent-encoded, but unlike a query, '+' is
+		// left as it is.
+		message, _ := url.QueryUnescape(strings.Replace(h.Get("Grpc-Message"), "+", "%2B", -1))
+		return Error{Code: code, Message: message}
+	}
+	return fmt.Errorf("cri: response has no status")
+}
+
+func (c *client) Version() (*VersionResponse, error) {
+	resp := &VersionResponse{}
+	if err := c.call("Version", &VersionRequest{Version: "v1"}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *client) ListContainers() ([]*APIContainer, error) {
+	resp := &ListContainersResponse{}
+	if err := c.call("ListContainers", &ListContainersRequest{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Containers, nil
+}
+
+func (c *client) ContainerStatus(id string) (*ContainerStatusResponse, error) {
+	resp := &ContainerStatusResponse{}
+	if err := c.call("ContainerStatus", &ContainerStatusRequest{ContainerID: id, Verbose: true}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *client) PodSandboxStatus(id string) (*PodSandboxStatus, error) {
+	resp := &PodSandboxStatusResponse{}
+	if err := c.call("PodSandboxStatus", &PodSandboxStatusRequest{PodSandboxID: id}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
+
+func (c *client) ListContainerStats() ([]*ContainerStats, error) {
+	resp := &ListContainerStatsResponse{}
+	if err := c.call("ListContainerStats", &ListContainerStatsRequest{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Stats, nil
+}
+
+func (c *client) StopContainer(id string, timeout int64) error {
+	return c.call("StopContainer", &StopContainerRequest{ContainerID: id, Timeout: timeout}, &Empty{})
+}
+
+func (c *client) RemoveContainer(id string) error {
+	return c.call("RemoveContainer", &RemoveContainerRequest{ContainerID: id}, &Empty{})
+}
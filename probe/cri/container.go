@@ -0,0 +1,273 @@
+package cri
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+)
+
+// Container is a container run by a CRI runtime. It implements
+// docker.Container, so the docker reporter and tagger can report it.
+type Container interface {
+	docker.Container
+	ImageName() string
+	UpdateState(*APIContainer, *ContainerStatusResponse, *PodSandboxStatus)
+	AddStats(*ContainerStats)
+}
+
+type container struct {
+	sync.RWMutex
+	container    *APIContainer
+	status       *ContainerStatus
+	sandbox      *PodSandboxStatus
+	pid          int
+	pendingStats [20]*ContainerStats
+	numPending   int
+	hostID       string
+}
+
+// NewContainer creates a new Container
+func NewContainer(c *APIContainer, status *ContainerStatusResponse, sandbox *PodSandboxStatus, hostID string) Container {
+	result := &container{
+		hostID: hostID,
+	}
+	result.UpdateState(c, status, sandbox)
+	return result
+}
+
+// verboseInfo is the part of the runtime specific verbose container status
+// we use; containerd and CRI-O both report the pid there.
+type verboseInfo struct {
+	Pid int `json:"pid"`
+}
+
+func (c *container) UpdateState(apiContainer *APIContainer, status *ContainerStatusResponse, sandbox *PodSandboxStatus) {
+	c.Lock()
+	defer c.Unlock()
+	c.container = apiContainer
+	c.status = status.Status
+	if c.status == nil {
+		c.status = &ContainerStatus{ID: apiContainer.ID, State: apiContainer.State}
+	}
+	c.sandbox = sandbox
+	c.pid = 0
+	var info verboseInfo
+	if raw, ok := status.Info["info"]; ok && json.Unmarshal([]byte(raw), &info) == nil {
+		c.pid = info.Pid
+	}
+}
+
+// AddStats adds a sample of the container's resource usage, to be reported
+// as metrics in the next report.
+func (c *container) AddStats(stats *ContainerStats) {
+	c.Lock()
+	defer c.Unlock()
+	if c.numPending >= len(c.pendingStats) {
+		return
+	}
+	c.pendingStats[c.numPending] = stats
+	c.numPending++
+}
+
+func (c *container) ID() string {
+	return c.container.ID
+}
+
+func (c *container) Image() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.image()
+}
+
+func (c *container) image() string {
+	if c.container.ImageRef != "" {
+		return strings.TrimPrefix(c.container.ImageRef, "sha256:")
+	}
+	return strings.TrimPrefix(c.status.ImageRef, "sha256:")
+}
+
+// ImageName returns the name of the image the container was created from.
+func (c *container) ImageName() string {
+	c.RLock()
+	defer c.RUnlock()
+	if c.container.Image == nil {
+		return ""
+	}
+	return c.container.Image.Image
+}
+
+func (c *container) PID() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.pid
+}
+
+// Hostname isn't known to the runtime.
+func (c *container) Hostname() string {
+	return ""
+}
+
+// HasTTY is always false, as attaching isn't supported for CRI containers.
+func (c *container) HasTTY() bool {
+	return false
+}
+
+func (c *container) State() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.state()
+}
+
+func (c *container) state() string {
+	switch c.status.State {
+	case ContainerCreated:
+		return "Created"
+	case ContainerRunning:
+		return "Up " + c.uptime().String()
+	case ContainerExited:
+		return "Exited (" + strconv.Itoa(int(c.status.ExitCode)) + ")"
+	}
+	return "Unknown"
+}
+
+func (c *container) StateString() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.stateString()
+}
+
+func (c *container) stateString() string {
+	switch c.status.State {
+	case ContainerCreated:
+		return docker.StateCreated
+	case ContainerRunning:
+		return docker.StateRunning
+	case ContainerExited:
+		return docker.StateExited
+	}
+	return docker.StateDead
+}
+
+func (c *container) uptime() time.Duration {
+	return (mtime.Now().Sub(time.Unix(0, c.status.StartedAt)) / time.Second) * time.Second
+}
+
+// NetworkMode returns "host" for containers in pods sharing the host's
+// network namespace; the containers of other pods have their own IPs.
+func (c *container) NetworkMode() (string, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.sandbox == nil || c.sandbox.Linux == nil || c.sandbox.Linux.Namespaces == nil ||
+		c.sandbox.Linux.Namespaces.Options == nil {
+		return "", false
+	}
+	if c.sandbox.Linux.Namespaces.Options.Network == NamespaceNode {
+		return docker.NetworkModeHost, true
+	}
+	return "", true
+}
+
+// NetworkInfo returns the IPs of the container's pod sandbox. CRI doesn't
+// expose port mappings.
+func (c *container) NetworkInfo([]net.IP) report.Sets {
+	c.RLock()
+	defer c.RUnlock()
+	ips := []string{}
+	if c.sandbox != nil && c.sandbox.Network != nil {
+		if c.sandbox.Network.IP != "" {
+			ips = append(ips, c.sandbox.Network.IP)
+		}
+		for _, ip := range c.sandbox.Network.AdditionalIPs {
+			ips = append(ips, ip.IP)
+		}
+	}
+	// Treat all pod IPs as local scoped, as docker does.
+	ipsWithScopes := []string{}
+	for _, ip := range ips {
+		ipsWithScopes = append(ipsWithScopes, report.MakeAddressNodeID(c.hostID, ip))
+	}
+	return report.EmptySets.
+		Add(docker.ContainerIPs, report.MakeStringSet(ips...)).
+		Add(docker.ContainerIPsWithScopes, report.MakeStringSet(ipsWithScopes...))
+}
+
+func (c *container) metrics() report.Metrics {
+	if c.numPending == 0 {
+		return report.Metrics{}
+	}
+	memory, cpu := report.MakeMetric(), report.MakeMetric()
+	pendingStats := c.pendingStats[:c.numPending]
+	for i, s := range pendingStats {
+		if s.Memory != nil && s.Memory.WorkingSetBytes != nil {
+			memory = memory.Add(time.Unix(0, s.Memory.Timestamp), float64(s.Memory.WorkingSetBytes.Value))
+		}
+		if i == 0 {
+			continue
+		}
+		// CPU usage is cumulative, so work out the percentage from the
+		// difference between consecutive samples.
+		previous := pendingStats[i-1]
+		if s.CPU == nil || s.CPU.UsageCoreNanoSeconds == nil ||
+			previous.CPU == nil || previous.CPU.UsageCoreNanoSeconds == nil {
+			continue
+		}
+		elapsed := float64(s.CPU.Timestamp - previous.CPU.Timestamp)
+		used := float64(s.CPU.UsageCoreNanoSeconds.Value) - float64(previous.CPU.UsageCoreNanoSeconds.Value)
+		if elapsed <= 0 || used < 0 {
+			continue
+		}
+		cpu = cpu.Add(time.Unix(0, s.CPU.Timestamp), used/elapsed*100.0)
+	}
+
+	// leave one stat to help with relative metrics
+	c.pendingStats[0] = c.pendingStats[c.numPending-1]
+	c.numPending = 1
+
+	result := report.Metrics{}
+	if memory.Len() > 0 {
+		result[docker.MemoryUsage] = memory
+	}
+	if cpu.Len() > 0 {
+		result[docker.CPUTotalUsage] = cpu
+	}
+	return result
+}
+
+func (c *container) GetNode() report.Node {
+	c.Lock()
+	defer c.Unlock()
+	image := c.image()
+	latest := map[string]string{
+		docker.ContainerID:         c.container.ID,
+		docker.ContainerCreated:    time.Unix(0, c.container.CreatedAt).Format(time.RFC822),
+		docker.ImageID:             image,
+		docker.ContainerState:      c.stateString(),
+		docker.ContainerStateHuman: c.state(),
+	}
+	if c.container.Metadata != nil {
+		latest[docker.ContainerName] = c.container.Metadata.Name
+		latest[docker.ContainerRestartCount] = strconv.Itoa(int(c.container.Metadata.Attempt))
+	}
+	controls := []string{}
+	if c.status.State == ContainerRunning {
+		latest[docker.ContainerUptime] = c.uptime().String()
+		controls = append(controls, docker.StopContainer)
+	} else {
+		controls = append(controls, docker.RemoveContainer)
+	}
+
+	return report.MakeNodeWith(report.MakeContainerNodeID(c.container.ID), latest).
+		WithParents(report.EmptySets.
+			Add(report.ContainerImage, report.MakeStringSet(report.MakeContainerImageNodeID(image))),
+		).
+		AddTable(docker.LabelPrefix, c.container.Labels).
+		WithControls(controls...).
+		WithMetrics(c.metrics())
+}
@@ -0,0 +1,52 @@
+package cri
+
+import (
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+)
+
+// stopTimeout is how long, in seconds, containers are given to stop before
+// they are killed.
+const stopTimeout = 10
+
+// The runtime service can only stop and remove containers, so only those of
+// the docker controls are registered; containers only offer those controls.
+
+func (r *registry) stopContainer(containerID string, _ xfer.Request) xfer.Response {
+	log.Infof("Stopping container %s", containerID)
+	return xfer.ResponseError(r.client.StopContainer(containerID, stopTimeout))
+}
+
+func (r *registry) removeContainer(containerID string, req xfer.Request) xfer.Response {
+	log.Infof("Removing container %s", containerID)
+	if err := r.client.RemoveContainer(containerID); err != nil {
+		return xfer.ResponseError(err)
+	}
+	return xfer.Response{
+		RemovedNode: req.NodeID,
+	}
+}
+
+func captureContainerID(f func(string, xfer.Request) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		containerID, ok := report.ParseContainerNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		return f(containerID, req)
+	}
+}
+
+func (r *registry) registerControls() {
+	controls.Register(docker.StopContainer, captureContainerID(r.stopContainer))
+	controls.Register(docker.RemoveContainer, captureContainerID(r.removeContainer))
+}
+
+func (r *registry) deregisterControls() {
+	controls.Rm(docker.StopContainer)
+	controls.Rm(docker.RemoveContainer)
+}
@@ -0,0 +1,306 @@
+package cri
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/armon/go-radix"
+	docker_client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+)
+
+// DefaultEndpoint is where containerd serves the CRI runtime service.
+const DefaultEndpoint = "unix:///run/containerd/containerd.sock"
+
+// Vars exported for testing.
+var (
+	NewClientStub    = NewClient
+	NewContainerStub = NewContainer
+)
+
+// registry keeps track of the containers of a CRI runtime. The runtime
+// service has no events we can rely on, so it is polled every interval.
+type registry struct {
+	sync.RWMutex
+	quit         chan chan struct{}
+	interval     time.Duration
+	collectStats bool
+	client       Client
+	hostID       string
+
+	watchers        []docker.ContainerUpdateWatcher
+	containers      *radix.Tree
+	containersByPID map[int]docker.Container
+}
+
+// NewRegistry returns a docker.Registry for the CRI runtime at endpoint.
+// Don't forget to Stop it.
+func NewRegistry(endpoint string, interval time.Duration, collectStats bool, hostID string) (docker.Registry, error) {
+	client, err := NewClientStub(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	version, err := client.Version()
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("cri registry: using %s %s (CRI %s)", version.RuntimeName, version.RuntimeVersion, version.RuntimeAPIVersion)
+
+	r := &registry{
+		containers:      radix.New(),
+		containersByPID: map[int]docker.Container{},
+
+		client:       client,
+		interval:     interval,
+		collectStats: collectStats,
+		hostID:       hostID,
+		quit:         make(chan chan struct{}),
+	}
+
+	if err := r.update(); err != nil {
+		log.Errorf("cri registry: %s", err)
+	}
+	r.registerControls()
+	go r.loop()
+	return r, nil
+}
+
+// Stop stops polling the runtime.
+func (r *registry) Stop() {
+	r.deregisterControls()
+	ch := make(chan struct{})
+	r.quit <- ch
+	<-ch
+}
+
+// WatchContainerUpdates registers a callback to be called
+// whenever a container is updated.
+func (r *registry) WatchContainerUpdates(f docker.ContainerUpdateWatcher) {
+	r.Lock()
+	defer r.Unlock()
+	r.watchers = append(r.watchers, f)
+}
+
+func (r *registry) loop() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.update(); err != nil {
+				log.Errorf("cri registry: %s", err)
+			}
+
+		case ch := <-r.quit:
+			close(ch)
+			return
+		}
+	}
+}
+
+// update brings the registry in line with the containers the runtime has.
+func (r *registry) update() error {
+	apiContainers, err := r.client.ListContainers()
+	if err != nil {
+		return err
+	}
+
+	var (
+		seen      = map[string]struct{}{}
+		sandboxes = map[string]*PodSandboxStatus{}
+		updated   = []report.Node{}
+	)
+	for _, apiContainer := range apiContainers {
+		status, err := r.client.ContainerStatus(apiContainer.ID)
+		if IsNotFound(err) {
+			continue // it went away since we listed it
+		} else if err != nil {
+			log.Errorf("cri registry: status of container %s: %v", apiContainer.ID, err)
+			continue
+		}
+		sandbox, ok := sandboxes[apiContainer.PodSandboxID]
+		if !ok && apiContainer.PodSandboxID != "" {
+			if sandbox, err = r.client.PodSandboxStatus(apiContainer.PodSandboxID); err != nil && !IsNotFound(err) {
+				log.Errorf("cri registry: status of sandbox %s: %v", apiContainer.PodSandboxID, err)
+			}
+			sandboxes[apiContainer.PodSandboxID] = sandbox
+		}
+		seen[apiContainer.ID] = struct{}{}
+		if node, changed := r.updateContainer(apiContainer, status, sandbox); changed {
+			updated = append(updated, node)
+		}
+	}
+	updated = append(updated, r.removeContainers(seen)...)
+
+	if r.collectStats {
+		if err := r.updateStats(); err != nil {
+			log.Errorf("cri registry: %s", err)
+		}
+	}
+
+	// Trigger anyone watching for updates
+	r.RLock()
+	watchers := r.watchers
+	r.RUnlock()
+	for _, node := range updated {
+		for _, f := range watchers {
+			f(node)
+		}
+	}
+	return nil
+}
+
+// updateContainer adds or updates a container, returning a node with its
+// new state if that changed.
+func (r *registry) updateContainer(apiContainer *APIContainer, status *ContainerStatusResponse, sandbox *PodSandboxStatus) (report.Node, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	var (
+		c        Container
+		oldState string
+	)
+	if o, ok := r.containers.Get(apiContainer.ID); ok {
+		c = o.(Container)
+		oldState = c.StateString()
+		// potentially remove existing pid mapping.
+		delete(r.containersByPID, c.PID())
+		c.UpdateState(apiContainer, status, sandbox)
+	} else {
+		c = NewContainerStub(apiContainer, status, sandbox, r.hostID)
+		r.containers.Insert(apiContainer.ID, c)
+	}
+
+	// Update PID index
+	if c.PID() > 1 {
+		r.containersByPID[c.PID()] = c
+	}
+
+	newState := c.StateString()
+	return report.MakeNodeWith(report.MakeContainerNodeID(apiContainer.ID), map[string]string{
+		docker.ContainerID:    apiContainer.ID,
+		docker.ContainerState: newState,
+	}), oldState != "" && oldState != newState
+}
+
+// removeContainers forgets the containers the runtime no longer has,
+// returning deleted nodes for them.
+func (r *registry) removeContainers(seen map[string]struct{}) []report.Node {
+	r.Lock()
+	defer r.Unlock()
+
+	removed := []report.Node{}
+	r.containers.Walk(func(id string, c interface{}) bool {
+		if _, ok := seen[id]; ok {
+			return false
+		}
+		removed = append(removed, report.MakeNodeWith(report.MakeContainerNodeID(id), map[string]string{
+			docker.ContainerID:    id,
+			docker.ContainerState: docker.StateDeleted,
+		}))
+		if pid := c.(Container).PID(); r.containersByPID[pid] == c {
+			delete(r.containersByPID, pid)
+		}
+		return false
+	})
+	for _, node := range removed {
+		id, _ := node.Latest.Lookup(docker.ContainerID)
+		r.containers.Delete(id)
+	}
+	return removed
+}
+
+func (r *registry) updateStats() error {
+	stats, err := r.client.ListContainerStats()
+	if err != nil {
+		return err
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+	for _, s := range stats {
+		if s.Attributes == nil {
+			continue
+		}
+		if c, ok := r.containers.Get(s.Attributes.ID); ok {
+			c.(Container).AddStats(s)
+		}
+	}
+	return nil
+}
+
+// LockedPIDLookup runs f under a read lock, and gives f a function for
+// use doing pid->container lookups.
+func (r *registry) LockedPIDLookup(f func(func(int) docker.Container)) {
+	r.RLock()
+	defer r.RUnlock()
+
+	lookup := func(pid int) docker.Container {
+		return r.containersByPID[pid]
+	}
+
+	f(lookup)
+}
+
+// WalkContainers runs f on every container the registry knows of.
+func (r *registry) WalkContainers(f func(docker.Container)) {
+	r.RLock()
+	defer r.RUnlock()
+
+	r.containers.Walk(func(_ string, c interface{}) bool {
+		f(c.(docker.Container))
+		return false
+	})
+}
+
+func (r *registry) GetContainer(id string) (docker.Container, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	c, ok := r.containers.Get(id)
+	if ok {
+		return c.(docker.Container), true
+	}
+	return nil, false
+}
+
+func (r *registry) GetContainerByPrefix(prefix string) (docker.Container, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	out := []interface{}{}
+	r.containers.WalkPrefix(prefix, func(_ string, v interface{}) bool {
+		out = append(out, v)
+		return false
+	})
+	if len(out) == 1 {
+		return out[0].(docker.Container), true
+	}
+	return nil, false
+}
+
+// WalkImages runs f on the image of every container the registry knows
+// of. The runtime service only tells us the image's ID and the name the
+// container was created with, so that is all we report.
+func (r *registry) WalkImages(f func(*docker_client.APIImages)) {
+	r.RLock()
+	defer r.RUnlock()
+
+	r.containers.Walk(func(_ string, c interface{}) bool {
+		container := c.(Container)
+		image := &docker_client.APIImages{ID: container.Image()}
+		if name := container.ImageName(); name != "" {
+			image.RepoTags = []string{name}
+		}
+		f(image)
+		return false
+	})
+}
+
+// WalkNetworks does nothing, as the runtime service has no networks; pods
+// get their network from the kubelet's CNI plugins.
+func (r *registry) WalkNetworks(f func(docker_client.Network)) {}
+
+// WalkVolumes does nothing, as the runtime service has no volumes.
+func (r *registry) WalkVolumes(f func(docker_client.Volume)) {}
@@ -0,0 +1,299 @@
+package cri_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	docker_client "github.com/fsouza/go-dockerclient"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/http2"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/cri"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+	"$GITHUB_URI/test"
+)
+
+const (
+	containerID = "c0ffee"
+	sandboxID   = "5a4db0c5"
+	containerIP = "10.32.0.5"
+	imageID     = "sha256:1234abcd"
+	imageName   = "docker.io/library/nginx:1.11"
+	pid         = 4321
+)
+
+// fakeRuntime is a CRI runtime service, speaking gRPC on a unix socket.
+type fakeRuntime struct {
+	sync.Mutex
+	containers map[string]*cri.APIContainer
+	stopped    []string
+	listener   net.Listener
+	conns      []net.Conn
+	dir        string
+}
+
+func newFakeRuntime(t *testing.T) (*fakeRuntime, string) {
+	dir, err := ioutil.TempDir("", "cri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "cri.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeRuntime{
+		containers: map[string]*cri.APIContainer{
+			containerID: {
+				ID:           containerID,
+				PodSandboxID: sandboxID,
+				Metadata:     &cri.ContainerMetadata{Name: "nginx", Attempt: 2},
+				Image:        &cri.ImageSpec{Image: imageName},
+				ImageRef:     imageID,
+				State:        cri.ContainerRunning,
+				Labels:       map[string]string{"io.kubernetes.pod.name": "nginx-1"},
+			},
+		},
+		dir: dir,
+	}
+	f.listener = listener
+	go f.serve()
+	return f, "unix://" + socket
+}
+
+// serve speaks cleartext HTTP/2 on every connection, as gRPC does.
+func (f *fakeRuntime) serve() {
+	server := &http2.Server{}
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.Lock()
+		f.conns = append(f.conns, conn)
+		f.Unlock()
+		go server.ServeConn(conn, &http2.ServeConnOpts{Handler: f})
+	}
+}
+
+func (f *fakeRuntime) Close() {
+	f.listener.Close()
+	f.Lock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.Unlock()
+	os.RemoveAll(f.dir)
+}
+
+func (f *fakeRuntime) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var header [5]byte
+	if _, err := io.ReadFull(r.Body, header[:]); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(r.Body, body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	resp, err := f.call(r.URL.Path, body)
+	if err != nil {
+		w.Header().Set("Grpc-Status", "5")
+		w.Header().Set("Grpc-Message", err.Error())
+		return
+	}
+	msg, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	binary.BigEndian.PutUint32(header[1:], uint32(len(msg)))
+	w.Write(header[:])
+	w.Write(msg)
+	w.Header().Set("Grpc-Status", "0")
+}
+
+func (f *fakeRuntime) call(method string, body []byte) (proto.Message, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	switch method {
+	case "/runtime.v1.RuntimeService/Version":
+		return &cri.VersionResponse{RuntimeName: "fake", RuntimeVersion: "0.1", RuntimeAPIVersion: "v1"}, nil
+
+	case "/runtime.v1.RuntimeService/ListContainers":
+		resp := &cri.ListContainersResponse{}
+		for _, c := range f.containers {
+			resp.Containers = append(resp.Containers, c)
+		}
+		return resp, nil
+
+	case "/runtime.v1.RuntimeService/ContainerStatus":
+		req := &cri.ContainerStatusRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			return nil, err
+		}
+		c, ok := f.containers[req.ContainerID]
+		if !ok {
+			return nil, fmt.Errorf("container %q not found", req.ContainerID)
+		}
+		return &cri.ContainerStatusResponse{
+			Status: &cri.ContainerStatus{ID: c.ID, State: c.State, ImageRef: c.ImageRef},
+			Info:   map[string]string{"info": fmt.Sprintf(`{"pid": %d}`, pid)},
+		}, nil
+
+	case "/runtime.v1.RuntimeService/PodSandboxStatus":
+		return &cri.PodSandboxStatusResponse{Status: &cri.PodSandboxStatus{
+			ID:      sandboxID,
+			Network: &cri.PodSandboxNetworkStatus{IP: containerIP},
+		}}, nil
+
+	case "/runtime.v1.RuntimeService/ListContainerStats":
+		return &cri.ListContainerStatsResponse{}, nil
+
+	case "/runtime.v1.RuntimeService/StopContainer":
+		req := &cri.StopContainerRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			return nil, err
+		}
+		c, ok := f.containers[req.ContainerID]
+		if !ok {
+			return nil, fmt.Errorf("container %q not found", req.ContainerID)
+		}
+		f.stopped = append(f.stopped, req.ContainerID)
+		c.State = cri.ContainerExited
+		return &cri.Empty{}, nil
+	}
+	return nil, fmt.Errorf("unknown method %s", method)
+}
+
+func (f *fakeRuntime) remove(id string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.containers, id)
+}
+
+func TestRegistry(t *testing.T) {
+	runtime, endpoint := newFakeRuntime(t)
+	defer runtime.Close()
+
+	registry, err := cri.NewRegistry(endpoint, 10*time.Millisecond, true, "host1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer registry.Stop()
+
+	var c docker.Container
+	registry.LockedPIDLookup(func(lookup func(int) docker.Container) {
+		c = lookup(pid)
+	})
+	if c == nil || c.ID() != containerID {
+		t.Fatalf("Expected container %q for pid %d, got %v", containerID, pid, c)
+	}
+
+	node := c.GetNode()
+	for key, want := range map[string]string{
+		docker.ContainerID:           containerID,
+		docker.ContainerName:         "nginx",
+		docker.ContainerState:        docker.StateRunning,
+		docker.ContainerRestartCount: "2",
+		docker.ImageID:               "1234abcd",
+	} {
+		if have, ok := node.Latest.Lookup(key); !ok || have != want {
+			t.Errorf("Expected %s %q, got %q", key, want, have)
+		}
+	}
+	if have, ok := node.Latest.Lookup(docker.LabelPrefix + "io.kubernetes.pod.name"); !ok || have != "nginx-1" {
+		t.Errorf("Expected pod name label, got %q", have)
+	}
+	if ips, ok := c.NetworkInfo(nil).Lookup(docker.ContainerIPs); !ok || !ips.Contains(containerIP) {
+		t.Errorf("Expected container IPs to include %q, got %v", containerIP, ips)
+	}
+
+	images := []*docker_client.APIImages{}
+	registry.WalkImages(func(image *docker_client.APIImages) {
+		images = append(images, image)
+	})
+	if len(images) != 1 || images[0].ID != "1234abcd" || len(images[0].RepoTags) != 1 || images[0].RepoTags[0] != imageName {
+		t.Errorf("Unexpected images: %v", images)
+	}
+}
+
+func TestRegistryUpdates(t *testing.T) {
+	runtime, endpoint := newFakeRuntime(t)
+	defer runtime.Close()
+
+	registry, err := cri.NewRegistry(endpoint, 10*time.Millisecond, false, "host1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer registry.Stop()
+
+	updates := make(chan report.Node, 10)
+	registry.WatchContainerUpdates(func(n report.Node) { updates <- n })
+
+	// Stopping the container should be noticed at the next poll
+	result := controls.HandleControlRequest(xfer.Request{
+		Control: docker.StopContainer,
+		NodeID:  report.MakeContainerNodeID(containerID),
+	})
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+	runtime.Lock()
+	stopped := runtime.stopped
+	runtime.Unlock()
+	if want, have := []string{containerID}, stopped; len(have) != 1 || have[0] != want[0] {
+		t.Errorf("Expected %v to be stopped, got %v", want, have)
+	}
+	test.Poll(t, 100*time.Millisecond, docker.StateExited, func() interface{} {
+		c, ok := registry.GetContainer(containerID)
+		if !ok {
+			return ""
+		}
+		return c.StateString()
+	})
+	if state, _ := (<-updates).Latest.Lookup(docker.ContainerState); state != docker.StateExited {
+		t.Errorf("Expected an update with state %q, got %q", docker.StateExited, state)
+	}
+
+	// And removing it too
+	runtime.remove(containerID)
+	test.Poll(t, 100*time.Millisecond, false, func() interface{} {
+		_, ok := registry.GetContainer(containerID)
+		return ok
+	})
+	if state, _ := (<-updates).Latest.Lookup(docker.ContainerState); state != docker.StateDeleted {
+		t.Errorf("Expected an update with state %q, got %q", docker.StateDeleted, state)
+	}
+}
+
+func TestClientError(t *testing.T) {
+	runtime, endpoint := newFakeRuntime(t)
+	defer runtime.Close()
+
+	client, err := cri.NewClient(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ContainerStatus("nonexistent"); !cri.IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,411 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/common/fs"
+	"$GITHUB_URI/common/mtime"
+)
+
+const (
+	// DefaultCgroupRoot is where the cgroup filesystem is usually mounted.
+	DefaultCgroupRoot = "/sys/fs/cgroup"
+
+	cgroupSampleInterval = time.Second
+
+	// Linux reports CPU time in /proc/stat in USER_HZ, which is 100
+	// everywhere that matters.
+	clockTicksPerSecond = 100
+
+	// Memory limits at or above this are the kernel's way of saying
+	// "unlimited" on cgroup v1.
+	unlimitedMemory = 1 << 62
+)
+
+// Errors returned by CgroupCollector.Sample
+var (
+	// ErrNoProcess means the process has gone, so its container stopped.
+	ErrNoProcess = errors.New("process not found")
+
+	// ErrCgroupNotFound means the process's cgroups aren't under the cgroup
+	// root. This is the case when the probe is in a different cgroup
+	// namespace to the process, as its cgroups are then given relative to
+	// the probe's namespace, eg /../docker-<id>.scope.
+	ErrCgroupNotFound = errors.New("cgroup not found")
+)
+
+// ContainerStats is a sample of a container's resource usage, taken from
+// either the docker stats stream or the container's cgroups.
+type ContainerStats struct {
+	docker.Stats
+
+	// Pids is only known when reading from cgroups, as the docker stats
+	// we decode predate the pids controller.
+	Pids *PidsStats
+}
+
+// PidsStats is the accounting of the pids controller.
+type PidsStats struct {
+	Current uint64
+	Limit   uint64 // zero if unlimited
+}
+
+// CgroupCollector reads a container's resource accounting straight from the
+// cgroup filesystem, rather than opening a docker stats stream per
+// container. Both cgroup v1 and the unified v2 hierarchy are understood.
+type CgroupCollector struct {
+	root     string
+	procRoot string
+}
+
+// NewCgroupCollector makes a new CgroupCollector for the cgroup filesystem
+// mounted at root, finding the cgroups of processes in procRoot.
+func NewCgroupCollector(root, procRoot string) *CgroupCollector {
+	return &CgroupCollector{root: root, procRoot: procRoot}
+}
+
+// Sample reads the accounting of the cgroups the process pid is in.
+func (c *CgroupCollector) Sample(pid int) (ContainerStats, error) {
+	var result ContainerStats
+	result.Read = mtime.Now()
+
+	controllers, unified, err := c.cgroupPaths(pid)
+	if err != nil {
+		return result, err
+	}
+	if err := c.readSystemCPU(&result.Stats); err != nil {
+		return result, err
+	}
+	if len(controllers) == 0 {
+		err = c.sampleV2(path.Join(c.root, unified), &result)
+	} else {
+		err = c.sampleV1(controllers, &result)
+	}
+	// The cgroups go away with the container.
+	if err != nil && !c.exists(pid) {
+		err = ErrNoProcess
+	}
+	return result, err
+}
+
+// Check returns ErrNoProcess or ErrCgroupNotFound if the cgroups of the
+// process pid can't be sampled.
+func (c *CgroupCollector) Check(pid int) error {
+	_, _, err := c.cgroupPaths(pid)
+	return err
+}
+
+func (c *CgroupCollector) exists(pid int) bool {
+	_, err := fs.ReadFile(path.Join(c.procRoot, strconv.Itoa(pid), "cgroup"))
+	return err == nil
+}
+
+// cgroupPaths returns the cgroup of the process for each v1 controller, and
+// its cgroup in the unified hierarchy, making sure they can be found under
+// the cgroup root.
+func (c *CgroupCollector) cgroupPaths(pid int) (map[string]string, string, error) {
+	controllers, unified, err := c.readCgroupPaths(pid)
+	if err != nil {
+		return nil, "", err
+	}
+	// Only the controllers we can't do without are checked.
+	dirs := []string{}
+	if len(controllers) > 0 {
+		for _, controller := range []string{"cpuacct", "memory"} {
+			cgroup, ok := controllers[controller]
+			if !ok || isRelative(cgroup) {
+				return nil, "", ErrCgroupNotFound
+			}
+			dirs = append(dirs, path.Join(controller, cgroup))
+		}
+	} else {
+		if unified == "" || isRelative(unified) {
+			return nil, "", ErrCgroupNotFound
+		}
+		dirs = append(dirs, unified)
+	}
+	var stat syscall.Stat_t
+	for _, dir := range dirs {
+		if err := fs.Stat(path.Join(c.root, dir), &stat); err != nil {
+			if !c.exists(pid) {
+				return nil, "", ErrNoProcess
+			}
+			return nil, "", ErrCgroupNotFound
+		}
+	}
+	return controllers, unified, nil
+}
+
+// isRelative returns true for cgroups given relative to the root of our
+// cgroup namespace, which are outside it.
+func isRelative(cgroup string) bool {
+	return cgroup == "/.." || strings.HasPrefix(cgroup, "/../")
+}
+
+// readCgroupPaths reads /proc/<pid>/cgroup.
+func (c *CgroupCollector) readCgroupPaths(pid int) (map[string]string, string, error) {
+	buf, err := fs.ReadFile(path.Join(c.procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil, "", ErrNoProcess
+	}
+	controllers := map[string]string{}
+	unified := ""
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			unified = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			if controller != "" {
+				controllers[controller] = fields[2]
+			}
+		}
+	}
+	return controllers, unified, nil
+}
+
+func (c *CgroupCollector) sampleV1(controllers map[string]string, s *ContainerStats) error {
+	file := func(controller, name string) string {
+		return path.Join(c.root, controller, controllers[controller], name)
+	}
+
+	usage, err := readUint(file("cpuacct", "cpuacct.usage"))
+	if err != nil {
+		return err
+	}
+	s.CPUStats.CPUUsage.TotalUsage = usage
+
+	if s.MemoryStats.Usage, err = readUint(file("memory", "memory.usage_in_bytes")); err != nil {
+		return err
+	}
+	limit, err := readUint(file("memory", "memory.limit_in_bytes"))
+	if err != nil {
+		return err
+	}
+	if limit >= unlimitedMemory {
+		limit = 0
+	}
+	if s.MemoryStats.Limit, err = c.memoryLimit(limit); err != nil {
+		return err
+	}
+
+	// blkio and pids aren't enabled everywhere, so are optional
+	if _, ok := controllers["blkio"]; ok {
+		if entries, err := readBlkioV1(file("blkio", "blkio.throttle.io_service_bytes")); err == nil {
+			s.BlkioStats.IOServiceBytesRecursive = entries
+		}
+	}
+	if _, ok := controllers["pids"]; ok {
+		s.Pids = readPids(file("pids", "pids.current"), file("pids", "pids.max"))
+	}
+	return nil
+}
+
+func (c *CgroupCollector) sampleV2(dir string, s *ContainerStats) error {
+	cpuStat, err := readKeyValues(path.Join(dir, "cpu.stat"))
+	if err != nil {
+		return err
+	}
+	s.CPUStats.CPUUsage.TotalUsage = cpuStat["usage_usec"] * uint64(time.Microsecond)
+
+	if s.MemoryStats.Usage, err = readUint(path.Join(dir, "memory.current")); err != nil {
+		return err
+	}
+	limit, err := readUint(path.Join(dir, "memory.max"))
+	if err != nil {
+		return err
+	}
+	if s.MemoryStats.Limit, err = c.memoryLimit(limit); err != nil {
+		return err
+	}
+
+	if entries, err := readIOStatV2(path.Join(dir, "io.stat")); err == nil {
+		s.BlkioStats.IOServiceBytesRecursive = entries
+	}
+	s.Pids = readPids(path.Join(dir, "pids.current"), path.Join(dir, "pids.max"))
+	return nil
+}
+
+// memoryLimit returns the memory limit of a container, which is the host's
+// memory if the container is unlimited, as the docker daemon does.
+func (c *CgroupCollector) memoryLimit(limit uint64) (uint64, error) {
+	if limit != 0 {
+		return limit, nil
+	}
+	meminfo, err := readKeyValues(path.Join(c.procRoot, "meminfo"))
+	if err != nil {
+		return 0, err
+	}
+	return meminfo["MemTotal:"] * 1024, nil
+}
+
+// readSystemCPU fills in the system CPU usage and number of CPUs from
+// /proc/stat, which the CPU percentage is relative to.
+func (c *CgroupCollector) readSystemCPU(s *docker.Stats) error {
+	buf, err := fs.ReadFile(path.Join(c.procRoot, "stat"))
+	if err != nil {
+		return err
+	}
+	cpus := 0
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			cpus++
+			continue
+		}
+		// user nice system idle iowait irq softirq, as counted by the daemon
+		if len(fields) < 8 {
+			return fmt.Errorf("invalid cpu line in %s", path.Join(c.procRoot, "stat"))
+		}
+		var total uint64
+		for _, field := range fields[1:8] {
+			n, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return err
+			}
+			total += n
+		}
+		s.CPUStats.SystemCPUUsage = total * uint64(time.Second) / clockTicksPerSecond
+	}
+	s.CPUStats.CPUUsage.PercpuUsage = make([]uint64, cpus)
+	return nil
+}
+
+// readUint reads a file holding a single number, or "max" for no limit,
+// which is returned as zero.
+func readUint(filename string) (uint64, error) {
+	buf, err := fs.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(buf))
+	if value == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readKeyValues reads a file of "key value" lines, such as cpu.stat.
+func readKeyValues(filename string) (map[string]uint64, error) {
+	buf, err := fs.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]uint64{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if n, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			result[fields[0]] = n
+		}
+	}
+	return result, nil
+}
+
+func readPids(current, max string) *PidsStats {
+	n, err := readUint(current)
+	if err != nil {
+		return nil
+	}
+	limit, _ := readUint(max)
+	return &PidsStats{Current: n, Limit: limit}
+}
+
+// readBlkioV1 reads blkio.throttle.io_service_bytes, made of
+// "major:minor op bytes" lines.
+func readBlkioV1(filename string) ([]docker.BlkioStatsEntry, error) {
+	buf, err := fs.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	result := []docker.BlkioStatsEntry{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue // the "Total" line
+		}
+		major, minor, ok := parseDevice(fields[0])
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		result = append(result, docker.BlkioStatsEntry{Major: major, Minor: minor, Op: fields[1], Value: value})
+	}
+	return result, nil
+}
+
+// readIOStatV2 reads io.stat, made of "major:minor rbytes=n wbytes=n ..."
+// lines, into the same form as v1.
+func readIOStatV2(filename string) ([]docker.BlkioStatsEntry, error) {
+	buf, err := fs.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	ops := map[string]string{"rbytes": "Read", "wbytes": "Write"}
+	result := []docker.BlkioStatsEntry{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		major, minor, ok := parseDevice(fields[0])
+		if !ok {
+			continue
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			op, ok := ops[kv[0]]
+			if len(kv) != 2 || !ok {
+				continue
+			}
+			if value, err := strconv.ParseUint(kv[1], 10, 64); err == nil {
+				result = append(result, docker.BlkioStatsEntry{Major: major, Minor: minor, Op: op, Value: value})
+			}
+		}
+	}
+	return result, nil
+}
+
+func parseDevice(device string) (uint64, uint64, bool) {
+	parts := strings.SplitN(device, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
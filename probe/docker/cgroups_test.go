@@ -0,0 +1,205 @@
+package docker_test
+
+import (
+	"testing"
+	"time"
+
+	client "github.com/fsouza/go-dockerclient"
+
+	fs_hook "$GITHUB_URI/common/fs"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/test"
+	"$GITHUB_URI/test/fs"
+	"$GITHUB_URI/test/reflect"
+)
+
+const (
+	procStat = `cpu  100 0 100 700 50 25 25 0 0 0
+cpu0 50 0 50 350 25 12 13 0 0 0
+cpu1 50 0 50 350 25 13 12 0 0 0
+intr 12345
+`
+	meminfo = `MemTotal:        2048 kB
+MemFree:         1024 kB
+`
+)
+
+// dirs makes nested directories for path, holding entries.
+func dirs(path []string, entries ...fs.Entry) fs.Entry {
+	if len(path) == 1 {
+		return fs.Dir(path[0], entries...)
+	}
+	return fs.Dir(path[0], dirs(path[1:], entries...))
+}
+
+func file(name, contents string) fs.Entry {
+	return fs.File{FName: name, FContents: contents}
+}
+
+func mockProc(cgroup string) fs.Entry {
+	return fs.Dir("proc",
+		file("stat", procStat),
+		file("meminfo", meminfo),
+		fs.Dir("42", file("cgroup", cgroup)),
+	)
+}
+
+var (
+	mockCgroupV1 = fs.Dir("",
+		mockProc(`11:pids:/docker/ping
+5:blkio:/docker/ping
+4:memory:/docker/ping
+3:cpu,cpuacct:/docker/ping
+1:name=systemd:/docker/ping
+`),
+		fs.Dir("cgroup",
+			dirs([]string{"cpuacct", "docker", "ping"},
+				file("cpuacct.usage", "123456789\n"),
+			),
+			dirs([]string{"memory", "docker", "ping"},
+				file("memory.usage_in_bytes", "1048576\n"),
+				file("memory.limit_in_bytes", "9223372036854771712\n"),
+			),
+			dirs([]string{"blkio", "docker", "ping"},
+				file("blkio.throttle.io_service_bytes", `8:0 Read 4096
+8:0 Write 8192
+8:0 Sync 0
+8:0 Async 12288
+8:0 Total 12288
+Total 12288
+`),
+			),
+			dirs([]string{"pids", "docker", "ping"},
+				file("pids.current", "7\n"),
+				file("pids.max", "max\n"),
+			),
+		),
+	)
+
+	mockCgroupV2 = fs.Dir("",
+		mockProc("0::/system.slice/docker-ping.scope\n"),
+		dirs([]string{"cgroup", "system.slice", "docker-ping.scope"},
+			file("cpu.stat", "usage_usec 123456\nuser_usec 100000\nsystem_usec 23456\n"),
+			file("memory.current", "1048576\n"),
+			file("memory.max", "4194304\n"),
+			file("io.stat", "8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n"),
+			file("pids.current", "7\n"),
+			file("pids.max", "100\n"),
+		),
+	)
+)
+
+func TestCgroupCollector(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		fs          fs.Entry
+		cpuUsage    uint64
+		memoryLimit uint64
+		pids        docker.PidsStats
+	}{
+		{"v1", mockCgroupV1, 123456789, 2048 * 1024, docker.PidsStats{Current: 7}},
+		{"v2", mockCgroupV2, 123456000, 4194304, docker.PidsStats{Current: 7, Limit: 100}},
+	} {
+		fs_hook.Mock(tc.fs)
+		stats, err := docker.NewCgroupCollector("/cgroup", "/proc").Sample(42)
+		fs_hook.Restore()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+
+		if want, have := tc.cpuUsage, stats.CPUStats.CPUUsage.TotalUsage; want != have {
+			t.Errorf("%s: expected cpu usage %d, got %d", tc.name, want, have)
+		}
+		// 1000 jiffies of 10ms each, over two CPUs
+		if want, have := uint64(10*time.Second), stats.CPUStats.SystemCPUUsage; want != have {
+			t.Errorf("%s: expected system cpu usage %d, got %d", tc.name, want, have)
+		}
+		if want, have := 2, len(stats.CPUStats.CPUUsage.PercpuUsage); want != have {
+			t.Errorf("%s: expected %d CPUs, got %d", tc.name, want, have)
+		}
+		if want, have := uint64(1048576), stats.MemoryStats.Usage; want != have {
+			t.Errorf("%s: expected memory usage %d, got %d", tc.name, want, have)
+		}
+		// Unlimited containers are limited by the host's memory
+		if want, have := tc.memoryLimit, stats.MemoryStats.Limit; want != have {
+			t.Errorf("%s: expected memory limit %d, got %d", tc.name, want, have)
+		}
+		wantBlkio := []client.BlkioStatsEntry{
+			{Major: 8, Minor: 0, Op: "Read", Value: 4096},
+			{Major: 8, Minor: 0, Op: "Write", Value: 8192},
+		}
+		if have := stats.BlkioStats.IOServiceBytesRecursive; !reflect.DeepEqual(wantBlkio, have[:2]) {
+			t.Errorf("%s: %s", tc.name, test.Diff(wantBlkio, have))
+		}
+		if stats.Pids == nil || *stats.Pids != tc.pids {
+			t.Errorf("%s: expected pids %v, got %v", tc.name, tc.pids, stats.Pids)
+		}
+	}
+
+	fs_hook.Mock(mockCgroupV1)
+	defer fs_hook.Restore()
+	if _, err := docker.NewCgroupCollector("/cgroup", "/proc").Sample(43); err != docker.ErrNoProcess {
+		t.Errorf("Expected %v for a process which doesn't exist, got %v", docker.ErrNoProcess, err)
+	}
+}
+
+func TestCgroupCollectorNotFound(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cgroup string
+	}{
+		// A probe in its own cgroup namespace sees paths relative to it
+		{"namespaced", "0::/../system.slice/docker-ping.scope\n"},
+		{"missing", "0::/system.slice/docker-pong.scope\n"},
+	} {
+		fs_hook.Mock(fs.Dir("",
+			mockProc(tc.cgroup),
+			dirs([]string{"cgroup", "system.slice", "docker-ping.scope"}, file("memory.current", "1048576\n")),
+		))
+		collector := docker.NewCgroupCollector("/cgroup", "/proc")
+		if err := collector.Check(42); err != docker.ErrCgroupNotFound {
+			t.Errorf("%s: expected %v, got %v", tc.name, docker.ErrCgroupNotFound, err)
+		}
+		if _, err := collector.Sample(42); err != docker.ErrCgroupNotFound {
+			t.Errorf("%s: expected %v, got %v", tc.name, docker.ErrCgroupNotFound, err)
+		}
+		fs_hook.Restore()
+	}
+}
+
+func TestContainerCgroupStats(t *testing.T) {
+	fs_hook.Mock(mockCgroupV2)
+	defer fs_hook.Restore()
+
+	c := docker.NewContainer(&client.Container{
+		ID:     "ping",
+		Image:  "baz",
+		State:  client.State{Pid: 42, Running: true},
+		Config: &client.Config{},
+	}, "host1")
+	if err := c.SampleCgroups(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.GetNode().Metrics[docker.PidsCurrent]; ok {
+		t.Errorf("Expected no stats before gathering them")
+	}
+
+	if err := c.StartGatheringCgroupStats(docker.NewCgroupCollector("/cgroup", "/proc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StartGatheringCgroupStats(docker.NewCgroupCollector("/cgroup", "/proc")); err == nil {
+		t.Errorf("Expected an error gathering stats twice")
+	}
+	if err := c.SampleCgroups(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.GetNode().Metrics[docker.PidsCurrent]; !ok {
+		t.Errorf("Expected pids metric")
+	}
+
+	c.StopGatheringStats()
+	if err := c.StartGatheringCgroupStats(docker.NewCgroupCollector("/cgroup", "/proc")); err != nil {
+		t.Errorf("Expected to gather stats again once stopped, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package docker
+
+import (
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/report"
+)
+
+// Control IDs used by the compose integration. They run the matching
+// container control on every container of a project.
+const (
+	StopComposeProject    = "docker_compose_stop"
+	StartComposeProject   = "docker_compose_start"
+	RestartComposeProject = "docker_compose_restart"
+)
+
+// These constants are keys used in node metadata
+const (
+	ComposeProject = "docker_compose_project"
+	ComposeService = "docker_compose_service"
+)
+
+// Labels docker-compose puts on the containers it creates.
+const (
+	ComposeProjectLabel = "com.docker.compose.project"
+	ComposeServiceLabel = "com.docker.compose.service"
+)
+
+// Exposed for testing
+var (
+	ComposeProjectMetadataTemplates = report.MetadataTemplates{
+		ComposeProject:   {ID: ComposeProject, Label: "Project", From: report.FromLatest, Priority: 1},
+		report.Container: {ID: report.Container, Label: "# Containers", From: report.FromCounters, Datatype: "number", Priority: 2},
+	}
+
+	ComposeServiceMetadataTemplates = report.MetadataTemplates{
+		ComposeService:   {ID: ComposeService, Label: "Service", From: report.FromLatest, Priority: 1},
+		ComposeProject:   {ID: ComposeProject, Label: "Project", From: report.FromLatest, Priority: 2},
+		report.Container: {ID: report.Container, Label: "# Containers", From: report.FromCounters, Datatype: "number", Priority: 3},
+	}
+
+	ComposeProjectControls = []report.Control{
+		{
+			ID:    StartComposeProject,
+			Human: "Start all",
+			Icon:  "fa-play",
+			Rank:  1,
+		},
+		{
+			ID:    RestartComposeProject,
+			Human: "Restart all",
+			Icon:  "fa-repeat",
+			Rank:  2,
+		},
+		{
+			ID:    StopComposeProject,
+			Human: "Stop all",
+			Icon:  "fa-stop",
+			Rank:  3,
+		},
+	}
+)
+
+// MakeComposeProjectNodeID makes the ID of a compose project node. Projects
+// are only unique per host, as each is run against one daemon.
+func MakeComposeProjectNodeID(hostID, project string) string {
+	return report.MakeComposeProjectNodeID(project + "@" + hostID)
+}
+
+// ParseComposeProjectNodeID returns the project and host ID of a compose
+// project node ID.
+func ParseComposeProjectNodeID(nodeID string) (project, hostID string, ok bool) {
+	id, ok := report.ParseComposeProjectNodeID(nodeID)
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(id, "@")
+	if i < 0 {
+		return "", "", false
+	}
+	return id[:i], id[i+1:], true
+}
+
+// MakeComposeServiceNodeID makes the ID of a compose service node.
+func MakeComposeServiceNodeID(hostID, project, service string) string {
+	return report.MakeComposeServiceNodeID(project + "/" + service + "@" + hostID)
+}
+
+// composeParents links a container created by docker-compose to its
+// project and service.
+func composeParents(hostID string, labels map[string]string) report.Sets {
+	parents := report.EmptySets
+	project, ok := labels[ComposeProjectLabel]
+	if !ok {
+		return parents
+	}
+	parents = parents.Add(report.ComposeProject, report.MakeStringSet(MakeComposeProjectNodeID(hostID, project)))
+	if service, ok := labels[ComposeServiceLabel]; ok {
+		parents = parents.Add(report.ComposeService, report.MakeStringSet(MakeComposeServiceNodeID(hostID, project, service)))
+	}
+	return parents
+}
+
+// composeTopologies makes the compose project and service topologies from
+// the labels of the containers in the container topology.
+func (r *Reporter) composeTopologies(containers report.Topology) (report.Topology, report.Topology) {
+	projects := report.MakeTopology().
+		WithMetadataTemplates(ComposeProjectMetadataTemplates)
+	projects.Controls.AddControls(ComposeProjectControls)
+	services := report.MakeTopology().
+		WithMetadataTemplates(ComposeServiceMetadataTemplates)
+
+	hostParent := report.EmptySets.
+		Add(report.Host, report.MakeStringSet(report.MakeHostNodeID(r.hostID)))
+	for _, container := range containers.Nodes {
+		project, ok := container.Latest.Lookup(LabelPrefix + ComposeProjectLabel)
+		if !ok {
+			continue
+		}
+		projectID := MakeComposeProjectNodeID(r.hostID, project)
+		projects.AddNode(report.MakeNodeWith(projectID, map[string]string{
+			ComposeProject:        project,
+			report.ControlProbeID: r.probeID,
+		}).WithParents(hostParent).WithControls(StopComposeProject, StartComposeProject, RestartComposeProject))
+
+		service, ok := container.Latest.Lookup(LabelPrefix + ComposeServiceLabel)
+		if !ok {
+			continue
+		}
+		services.AddNode(report.MakeNodeWith(MakeComposeServiceNodeID(r.hostID, project, service), map[string]string{
+			ComposeProject: project,
+			ComposeService: service,
+		}).WithParents(hostParent.
+			Add(report.ComposeProject, report.MakeStringSet(projectID)),
+		))
+	}
+	return projects, services
+}
+
+// captureComposeProject makes a handler for a compose project control,
+// which runs f on the containers of the project that want returns true for.
+// A project's containers may be spread over several daemons of its host.
+func captureComposeProject(verb string, want func(Container) bool, f func(*registry, string, xfer.Request) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		project, hostID, ok := ParseComposeProjectNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+
+		owners := map[string]*registry{}
+		ids := []string{}
+		registries.RLock()
+		for _, r := range registries.all {
+			if r.hostID != hostID {
+				continue
+			}
+			r.WalkContainers(func(c Container) {
+				config := c.(DockerContainer).Container().Config
+				if config != nil && config.Labels[ComposeProjectLabel] == project && want(c) {
+					owners[c.ID()] = r
+					ids = append(ids, c.ID())
+				}
+			})
+		}
+		registries.RUnlock()
+		if len(ids) == 0 {
+			return xfer.ResponseErrorf("No containers of compose project %s to %s", project, verb)
+		}
+
+		errors := []string{}
+		for _, id := range ids {
+			if response := f(owners[id], id, req); response.Error != "" {
+				log.Errorf("Compose project %s: container %s: %s", project, id, response.Error)
+				errors = append(errors, response.Error)
+			}
+		}
+		if len(errors) > 0 {
+			return xfer.ResponseErrorf("%s", strings.Join(errors, "; "))
+		}
+		return xfer.Response{}
+	}
+}
+
+func containerIsRunning(c Container) bool {
+	return !ContainerIsStopped(c)
+}
+
+func registerComposeControls() {
+	controls.Register(StopComposeProject, captureComposeProject("stop", containerIsRunning, (*registry).stopContainer))
+	controls.Register(StartComposeProject, captureComposeProject("start", ContainerIsStopped, (*registry).startContainer))
+	controls.Register(RestartComposeProject, captureComposeProject("restart", containerIsRunning, (*registry).restartContainer))
+}
+
+func deregisterComposeControls() {
+	controls.Rm(StopComposeProject)
+	controls.Rm(StartComposeProject)
+	controls.Rm(RestartComposeProject)
+}
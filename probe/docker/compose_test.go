@@ -0,0 +1,101 @@
+package docker_test
+
+import (
+	"testing"
+	"time"
+
+	client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+	"$GITHUB_URI/test"
+)
+
+var composeContainer = &client.Container{
+	ID:    "web1",
+	Name:  "myapp_web_1",
+	Image: "baz",
+	State: client.State{Pid: 3, Running: true},
+	Config: &client.Config{
+		Labels: map[string]string{
+			docker.ComposeProjectLabel: "myapp",
+			docker.ComposeServiceLabel: "web",
+		},
+	},
+}
+
+func TestComposeReporter(t *testing.T) {
+	registry := &mockRegistry{
+		containersByPID: map[int]docker.Container{
+			2: docker.NewContainer(container1, "host1"),
+			3: docker.NewContainer(composeContainer, "host1"),
+		},
+	}
+	rpt, err := docker.NewReporter(registry, "host1", "probe1", nil).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projectID := docker.MakeComposeProjectNodeID("host1", "myapp")
+	serviceID := docker.MakeComposeServiceNodeID("host1", "myapp", "web")
+	if want, have := 1, len(rpt.ComposeProject.Nodes); want != have {
+		t.Fatalf("Expected %d project, got %d", want, have)
+	}
+	project, ok := rpt.ComposeProject.Nodes[projectID]
+	if !ok {
+		t.Fatalf("Expected report to have project %q", projectID)
+	}
+	if have, ok := project.Latest.Lookup(report.ControlProbeID); !ok || have != "probe1" {
+		t.Errorf("Expected project control probe ID %q, got %q", "probe1", have)
+	}
+	if want, have := 3, len(project.Controls.Controls); want != have {
+		t.Errorf("Expected %d project controls, got %v", want, project.Controls.Controls)
+	}
+	service, ok := rpt.ComposeService.Nodes[serviceID]
+	if !ok {
+		t.Fatalf("Expected report to have service %q", serviceID)
+	}
+	if projects, ok := service.Parents.Lookup(report.ComposeProject); !ok || !projects.Contains(projectID) {
+		t.Errorf("Expected service to have project parent, got %v", projects)
+	}
+
+	container := rpt.Container.Nodes[report.MakeContainerNodeID("web1")]
+	if projects, ok := container.Parents.Lookup(report.ComposeProject); !ok || !projects.Contains(projectID) {
+		t.Errorf("Expected container to have project parent, got %v", projects)
+	}
+	if services, ok := container.Parents.Lookup(report.ComposeService); !ok || !services.Contains(serviceID) {
+		t.Errorf("Expected container to have service parent, got %v", services)
+	}
+}
+
+func TestComposeControls(t *testing.T) {
+	mdc := newMockClient()
+	mdc.apiContainers = []client.APIContainers{apiContainer1, {ID: "web1"}}
+	mdc.containers["web1"] = composeContainer
+	setupStubs(mdc, func() {
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "host1", nil)
+		defer registry.Stop()
+
+		test.Poll(t, 100*time.Millisecond, true, func() interface{} {
+			_, ok := registry.GetContainer("web1")
+			return ok
+		})
+
+		// The mock client fails every control, with what it would have done
+		for _, tc := range []struct{ command, result string }{
+			{docker.StopComposeProject, "stopped"},
+			{docker.RestartComposeProject, "restarted"},
+			{docker.StartComposeProject, "No containers of compose project myapp to start"},
+		} {
+			result := controls.HandleControlRequest(xfer.Request{
+				Control: tc.command,
+				NodeID:  docker.MakeComposeProjectNodeID("host1", "myapp"),
+			})
+			if result.Error != tc.result {
+				t.Errorf("%s: expected %q, got %q", tc.command, tc.result, result.Error)
+			}
+		}
+	})
+}
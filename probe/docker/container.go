@@ -7,7 +7,6 @@ import (
 	"net"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -56,6 +55,10 @@ const (
 	CPUUsageInKernelmode = "docker_cpu_usage_in_kernelmode"
 	CPUSystemCPUUsage    = "docker_cpu_system_cpu_usage"
 
+	BlockIOReadRate  = "docker_block_io_read_rate"
+	BlockIOWriteRate = "docker_block_io_write_rate"
+	PidsCurrent      = "docker_pids_current"
+
 	NetworkModeHost = "host"
 
 	LabelPrefix = "docker_label_"
@@ -92,10 +95,8 @@ type ClientConn interface {
 	Close() error
 }
 
-// Container represents a Docker container
+// Container represents a container, whichever runtime is running it
 type Container interface {
-	UpdateState(*docker.Container)
-
 	ID() string
 	Image() string
 	PID() int
@@ -104,26 +105,37 @@ type Container interface {
 	State() string
 	StateString() string
 	HasTTY() bool
-	Container() *docker.Container
-	StartGatheringStats() error
-	StopGatheringStats()
 	NetworkMode() (string, bool)
 	NetworkInfo([]net.IP) report.Sets
 }
 
+// DockerContainer represents a Docker container
+type DockerContainer interface {
+	Container
+	UpdateState(*docker.Container)
+	Container() *docker.Container
+	StartGatheringStats(Endpoint) error
+	StartGatheringCgroupStats(*CgroupCollector) error
+	SampleCgroups() error
+	StopGatheringStats()
+	AddEvent(ContainerEvent)
+}
+
 type container struct {
 	sync.RWMutex
 	container    *docker.Container
 	statsConn    ClientConn
-	latestStats  docker.Stats
-	pendingStats [20]docker.Stats
+	cgroups      *CgroupCollector
+	latestStats  ContainerStats
+	pendingStats [20]ContainerStats
 	numPending   int
 	hostID       string
 	baseNode     report.Node
+	events       []ContainerEvent
 }
 
-// NewContainer creates a new Container
-func NewContainer(c *docker.Container, hostID string) Container {
+// NewContainer creates a new DockerContainer
+func NewContainer(c *docker.Container, hostID string) DockerContainer {
 	result := &container{
 		container: c,
 		hostID:    hostID,
@@ -138,6 +150,12 @@ func (c *container) UpdateState(container *docker.Container) {
 	c.container = container
 }
 
+func (c *container) AddEvent(event ContainerEvent) {
+	c.Lock()
+	defer c.Unlock()
+	c.events = addEvent(c.events, event)
+}
+
 func (c *container) ID() string {
 	return c.container.ID
 }
@@ -175,11 +193,13 @@ func (c *container) Container() *docker.Container {
 	return c.container
 }
 
-func (c *container) StartGatheringStats() error {
+// StartGatheringStats streams the container's stats from the docker daemon
+// at endpoint, until it stops or StopGatheringStats is called.
+func (c *container) StartGatheringStats(endpoint Endpoint) error {
 	c.Lock()
 	defer c.Unlock()
 
-	if c.statsConn != nil {
+	if c.statsConn != nil || c.cgroups != nil {
 		return fmt.Errorf("already gather stats for container %s", c.container.ID)
 	}
 
@@ -192,13 +212,7 @@ func (c *container) StartGatheringStats() error {
 		}
 		req.Header.Set("User-Agent", "weavescope")
 
-		url, err := url.Parse(endpoint)
-		if err != nil {
-			log.Errorf("docker container: %v", err)
-			return
-		}
-
-		dial, err := DialStub(url.Scheme, url.Path)
+		dial, err := endpoint.dial()
 		if err != nil {
 			log.Errorf("docker container: %v", err)
 			return
@@ -234,16 +248,7 @@ func (c *container) StartGatheringStats() error {
 				return
 			}
 
-			c.Lock()
-			if c.numPending >= len(c.pendingStats) {
-				log.Warnf("docker container: dropping stats.")
-			} else {
-				c.latestStats = stats
-				c.pendingStats[c.numPending] = stats
-				c.numPending++
-			}
-			c.Unlock()
-
+			c.addStats(ContainerStats{Stats: stats})
 			stats = docker.Stats{}
 		}
 	}()
@@ -251,10 +256,61 @@ func (c *container) StartGatheringStats() error {
 	return nil
 }
 
+// StartGatheringCgroupStats has the container's stats read from its
+// cgroups by cgroups, each time SampleCgroups is called, until
+// StopGatheringStats is.
+func (c *container) StartGatheringCgroupStats(cgroups *CgroupCollector) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.statsConn != nil || c.cgroups != nil {
+		return fmt.Errorf("already gather stats for container %s", c.container.ID)
+	}
+	log.Infof("docker container: collecting cgroup stats for %s", c.container.ID)
+	c.cgroups = cgroups
+	return nil
+}
+
+// SampleCgroups takes a sample of the container's cgroups, if its stats
+// are gathered from them.
+func (c *container) SampleCgroups() error {
+	c.RLock()
+	cgroups, pid := c.cgroups, c.container.State.Pid
+	c.RUnlock()
+	if cgroups == nil {
+		return nil
+	}
+
+	stats, err := cgroups.Sample(pid)
+	if err != nil {
+		return err
+	}
+	c.addStats(stats)
+	return nil
+}
+
+func (c *container) addStats(stats ContainerStats) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.numPending >= len(c.pendingStats) {
+		log.Warnf("docker container: dropping stats.")
+		return
+	}
+	c.latestStats = stats
+	c.pendingStats[c.numPending] = stats
+	c.numPending++
+}
+
 func (c *container) StopGatheringStats() {
 	c.Lock()
 	defer c.Unlock()
 
+	if c.cgroups != nil {
+		log.Infof("docker container: stopped collecting cgroup stats for %s", c.container.ID)
+		c.cgroups = nil
+	}
+
 	if c.statsConn == nil {
 		return
 	}
@@ -322,7 +378,7 @@ func (c *container) NetworkInfo(localAddrs []net.IP) report.Sets {
 
 }
 
-func (c *container) memoryUsageMetric(stats []docker.Stats) report.Metric {
+func (c *container) memoryUsageMetric(stats []ContainerStats) report.Metric {
 	result := report.MakeMetric()
 	for _, s := range stats {
 		result = result.Add(s.Read, float64(s.MemoryStats.Usage)).WithMax(float64(s.MemoryStats.Limit))
@@ -330,7 +386,7 @@ func (c *container) memoryUsageMetric(stats []docker.Stats) report.Metric {
 	return result
 }
 
-func (c *container) cpuPercentMetric(stats []docker.Stats) report.Metric {
+func (c *container) cpuPercentMetric(stats []ContainerStats) report.Metric {
 	result := report.MakeMetric()
 	if len(stats) < 2 {
 		return result
@@ -355,14 +411,66 @@ func (c *container) cpuPercentMetric(stats []docker.Stats) report.Metric {
 	return result
 }
 
+// blockIOBytes sums the bytes read or written over all devices.
+func blockIOBytes(s ContainerStats, op string) uint64 {
+	var total uint64
+	for _, entry := range s.BlkioStats.IOServiceBytesRecursive {
+		if strings.EqualFold(entry.Op, op) {
+			total += entry.Value
+		}
+	}
+	return total
+}
+
+// blockIORateMetric is the rate of bytes read or written per second.
+func (c *container) blockIORateMetric(stats []ContainerStats, op string) report.Metric {
+	result := report.MakeMetric()
+	if len(stats) < 2 {
+		return result
+	}
+
+	previous := stats[0]
+	for _, s := range stats[1:] {
+		seconds := s.Read.Sub(previous.Read).Seconds()
+		current, last := blockIOBytes(s, op), blockIOBytes(previous, op)
+		rate := 0.0
+		// Counters go backwards when the container restarts
+		if seconds > 0 && current >= last {
+			rate = float64(current-last) / seconds
+		}
+		result = result.Add(s.Read, rate)
+		previous = s
+	}
+	return result
+}
+
+func (c *container) pidsMetric(stats []ContainerStats) report.Metric {
+	result := report.MakeMetric()
+	for _, s := range stats {
+		if s.Pids == nil {
+			continue
+		}
+		result = result.Add(s.Read, float64(s.Pids.Current))
+		if s.Pids.Limit > 0 {
+			result = result.WithMax(float64(s.Pids.Limit))
+		}
+	}
+	return result
+}
+
 func (c *container) metrics() report.Metrics {
 	if c.numPending == 0 {
 		return report.Metrics{}
 	}
 	pendingStats := c.pendingStats[:c.numPending]
 	result := report.Metrics{
-		MemoryUsage:   c.memoryUsageMetric(pendingStats),
-		CPUTotalUsage: c.cpuPercentMetric(pendingStats),
+		MemoryUsage:      c.memoryUsageMetric(pendingStats),
+		CPUTotalUsage:    c.cpuPercentMetric(pendingStats),
+		BlockIOReadRate:  c.blockIORateMetric(pendingStats, "read"),
+		BlockIOWriteRate: c.blockIORateMetric(pendingStats, "write"),
+	}
+	if pids := c.pidsMetric(pendingStats); pids.Len() > 0 {
+		result[PidsCurrent] = pids
 	}
 
 	// leave one stat to help with relative metrics
@@ -391,8 +499,13 @@ func (c *container) getBaseNode() report.Node {
 		ImageID:           c.Image(),
 		ContainerHostname: c.Hostname(),
 	}).WithParents(report.EmptySets.
-		Add(report.ContainerImage, report.MakeStringSet(report.MakeContainerImageNodeID(c.Image()))),
+		Add(report.ContainerImage, report.MakeStringSet(report.MakeContainerImageNodeID(c.Image()))).
+		Merge(swarmParents(c.container.Config.Labels)).
+		Merge(composeParents(c.hostID, c.container.Config.Labels)),
 	)
+	if digest := pinnedDigest(c.container.Config.Image); digest != "" {
+		result = result.WithLatests(map[string]string{ContainerImageDigest: digest})
+	}
 	result = result.AddTable(LabelPrefix, c.container.Config.Labels)
 	result = result.AddTable(EnvPrefix, c.env())
 	return result
@@ -405,7 +518,8 @@ func (c *container) GetNode() report.Node {
 		ContainerState:      c.StateString(),
 		ContainerStateHuman: c.State(),
 	}
-	controls := []string{}
+	// Logs and files are kept after the container stops
+	controls := []string{ContainerLogs, DownloadFiles, UploadFiles}
 
 	if c.container.State.Paused {
 		controls = append(controls, UnpauseContainer)
@@ -427,9 +541,46 @@ func (c *container) GetNode() report.Node {
 	result := c.baseNode.WithLatests(latest)
 	result = result.WithControls(controls...)
 	result = result.WithMetrics(c.metrics())
+	result = WithContainerEvents(result, c.events)
+	result = c.withNetworksAndVolumes(result)
 	return result
 }
 
+// withNetworksAndVolumes adds the user-defined networks the container is
+// attached to, with its IP on each, and the named volumes it mounts.
+func (c *container) withNetworksAndVolumes(node report.Node) report.Node {
+	var (
+		networks = report.MakeStringSet()
+		volumes  = report.MakeStringSet()
+		ips      = map[string]string{}
+	)
+	if c.container.NetworkSettings != nil {
+		for name, network := range c.container.NetworkSettings.Networks {
+			if IsPredefinedNetwork(name) || network.NetworkID == "" {
+				continue
+			}
+			networks = networks.Add(report.MakeDockerNetworkNodeID(network.NetworkID))
+			if network.IPAddress != "" {
+				ips[name] = network.IPAddress
+			}
+		}
+	}
+	for _, mount := range c.container.Mounts {
+		// Bind mounts have no name
+		if mount.Name != "" {
+			volumes = volumes.Add(MakeVolumeNodeID(c.hostID, mount.Name))
+		}
+	}
+	parents := report.EmptySets
+	if len(networks) > 0 {
+		parents = parents.Add(report.DockerNetwork, networks)
+	}
+	if len(volumes) > 0 {
+		parents = parents.Add(report.DockerVolume, volumes)
+	}
+	return node.AddTable(NetworkIPPrefix, ips).WithParents(parents)
+}
+
 // ExtractContainerIPs returns the list of container IPs given a Node from the Container topology.
 func ExtractContainerIPs(nmd report.Node) []string {
 	v, _ := nmd.Sets.Lookup(ContainerIPs)
@@ -57,7 +57,7 @@ func TestContainer(t *testing.T) {
 
 	const hostID = "scope"
 	c := docker.NewContainer(container1, hostID)
-	err := c.StartGatheringStats()
+	err := c.StartGatheringStats(docker.Endpoint{Address: docker.DefaultEndpoint})
 	if err != nil {
 		t.Errorf("%v", err)
 	}
@@ -89,11 +89,13 @@ func TestContainer(t *testing.T) {
 			"docker_container_uptime":      uptime.String(),
 		}).
 			WithControls(
-				docker.RestartContainer, docker.StopContainer, docker.PauseContainer,
-				docker.AttachContainer, docker.ExecContainer,
+				docker.ContainerLogs, docker.DownloadFiles, docker.UploadFiles, docker.RestartContainer,
+				docker.StopContainer, docker.PauseContainer, docker.AttachContainer, docker.ExecContainer,
 			).WithMetrics(report.Metrics{
-			"docker_cpu_total_usage": report.MakeMetric(),
-			"docker_memory_usage":    report.MakeMetric().Add(now, 12345).WithMax(45678),
+			"docker_cpu_total_usage":     report.MakeMetric(),
+			"docker_memory_usage":        report.MakeMetric().Add(now, 12345).WithMax(45678),
+			"docker_block_io_read_rate":  report.MakeMetric(),
+			"docker_block_io_write_rate": report.MakeMetric(),
 		}).WithParents(report.EmptySets.
 			Add(report.ContainerImage, report.MakeStringSet(report.MakeContainerImageNodeID("baz"))),
 		)
@@ -131,3 +133,36 @@ func TestContainer(t *testing.T) {
 		t.Errorf("%v != %v", have, []string{"1.2.3.4"})
 	}
 }
+
+func TestContainerNetworksAndVolumes(t *testing.T) {
+	c := docker.NewContainer(&client.Container{
+		ID:    "ping",
+		Image: "baz",
+		State: client.State{Pid: 2, Running: true},
+		NetworkSettings: &client.NetworkSettings{
+			Networks: map[string]client.ContainerNetwork{
+				"frontend": {NetworkID: "net1", IPAddress: "10.0.1.2"},
+				"bridge":   {NetworkID: "bridge1", IPAddress: "172.17.0.2"},
+			},
+		},
+		Mounts: []client.Mount{
+			{Name: "data", Destination: "/data"},
+			{Source: "/etc/hosts", Destination: "/etc/hosts"},
+		},
+		Config: &client.Config{},
+	}, "host1")
+
+	node := c.GetNode()
+	if have, ok := node.Parents.Lookup(report.DockerNetwork); !ok || !reflect.DeepEqual(have, report.MakeStringSet(report.MakeDockerNetworkNodeID("net1"))) {
+		t.Errorf("Expected only network net1 as a parent, got %v", have)
+	}
+	if have, ok := node.Parents.Lookup(report.DockerVolume); !ok || !reflect.DeepEqual(have, report.MakeStringSet(docker.MakeVolumeNodeID("host1", "data"))) {
+		t.Errorf("Expected only volume data as a parent, got %v", have)
+	}
+	if have, ok := node.Latest.Lookup(docker.NetworkIPPrefix + "frontend"); !ok || have != "10.0.1.2" {
+		t.Errorf("Expected IP on network frontend to be %q, got %q", "10.0.1.2", have)
+	}
+	if _, ok := node.Latest.Lookup(docker.NetworkIPPrefix + "bridge"); ok {
+		t.Errorf("Expected no IP for the bridge network")
+	}
+}
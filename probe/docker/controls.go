@@ -1,6 +1,15 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"sync"
+
 	docker_client "github.com/fsouza/go-dockerclient"
 
 	log "github.com/Sirupsen/logrus"
@@ -20,10 +29,19 @@ const (
 	RemoveContainer  = "docker_remove_container"
 	AttachContainer  = "docker_attach_container"
 	ExecContainer    = "docker_exec_container"
+	ContainerLogs    = "docker_container_logs"
+	DownloadFiles    = "docker_download_files"
+	UploadFiles      = "docker_upload_files"
 
 	waitTime = 10
+
+	// ArchivePathArg is the path in the container of the files to download,
+	// or of the directory to extract an upload into.
+	ArchivePathArg = "path"
 )
 
+var errArchiveTooLarge = errors.New("Archive too large")
+
 func (r *registry) stopContainer(containerID string, _ xfer.Request) xfer.Response {
 	log.Infof("Stopping container %s", containerID)
 	return xfer.ResponseError(r.client.StopContainer(containerID, waitTime))
@@ -153,6 +171,227 @@ func (r *registry) execContainer(containerID string, req xfer.Request) xfer.Resp
 	}
 }
 
+// labelledWriter prefixes each line written to it with a label, so that
+// stdout and stderr can share a pipe and still be told apart.
+type labelledWriter struct {
+	w       io.Writer
+	label   []byte
+	midLine bool
+}
+
+func (l *labelledWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		if !l.midLine {
+			if _, err := l.w.Write(l.label); err != nil {
+				return n, err
+			}
+			l.midLine = true
+		}
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+			l.midLine = false
+		}
+		m, err := l.w.Write(line)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[len(line):]
+	}
+	return n, nil
+}
+
+func (r *registry) containerLogs(containerID string, req xfer.Request) xfer.Response {
+	c, ok := r.GetContainer(containerID)
+	if !ok {
+		return xfer.ResponseErrorf("Not found: %s", containerID)
+	}
+	opts, err := controls.ParseLogOptions(req.ControlArgs)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+
+	id, pipe, err := controls.NewPipe(r.pipes, req.AppID)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	local, _ := pipe.Ends()
+	logsOpts := docker_client.LogsOptions{
+		Container:  containerID,
+		Follow:     opts.Follow,
+		Stdout:     true,
+		Stderr:     true,
+		Timestamps: true,
+	}
+	if opts.Tail >= 0 {
+		logsOpts.Tail = strconv.Itoa(opts.Tail)
+	}
+	if !opts.Since.IsZero() {
+		logsOpts.Since = opts.Since.Unix()
+	}
+	if c.HasTTY() {
+		// Both streams go to the TTY, so the daemon can't tell them apart
+		logsOpts.RawTerminal = true
+		logsOpts.OutputStream = local
+		logsOpts.ErrorStream = local
+	} else {
+		logsOpts.OutputStream = &labelledWriter{w: local, label: []byte("stdout: ")}
+		logsOpts.ErrorStream = &labelledWriter{w: local, label: []byte("stderr: ")}
+	}
+	// The client can't cancel a logs request; closing the pipe makes the
+	// next write fail, which ends it.
+	go func() {
+		if err := r.client.Logs(logsOpts); err != nil && !pipe.Closed() {
+			log.Errorf("Error getting logs of container %s: %v", containerID, err)
+		}
+		log.Infof("Logs of container %s closed.", containerID)
+		pipe.Close()
+	}()
+	return xfer.Response{
+		Pipe: id,
+	}
+}
+
+func archivePath(args map[string]string) (string, error) {
+	p := args[ArchivePathArg]
+	if !path.IsAbs(p) {
+		return "", fmt.Errorf("Invalid %s: %q", ArchivePathArg, p)
+	}
+	return path.Clean(p), nil
+}
+
+// limitedWriter fails writes beyond n bytes, so a download can't fill up the
+// app. The bytes up to n are written, so that the app sees the archive go
+// past its own limit rather than end early.
+type limitedWriter struct {
+	w       io.Writer
+	n       int64
+	written int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if remaining := l.n - l.written; int64(len(p)) > remaining {
+		n, err := l.w.Write(p[:remaining])
+		l.written += int64(n)
+		if err != nil {
+			return n, err
+		}
+		return n, errArchiveTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.written += int64(n)
+	return n, err
+}
+
+// downloadFiles streams a tar archive of a path in the container down the
+// pipe, closing it at the end.
+func (r *registry) downloadFiles(containerID string, req xfer.Request) xfer.Response {
+	src, err := archivePath(req.ControlArgs)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+
+	id, pipe, err := controls.NewPipe(r.pipes, req.AppID)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	local, _ := pipe.Ends()
+	log.Infof("Downloading %s from container %s through pipe %s for app %s", src, containerID, id, req.AppID)
+	go func() {
+		// The app fails downloads going past its limit
+		w := &limitedWriter{w: local, n: xfer.MaxArchiveSize + 1}
+		err := r.client.DownloadFromContainer(containerID, docker_client.DownloadFromContainerOptions{
+			Path:         src,
+			OutputStream: w,
+		})
+		switch {
+		case err == nil:
+			log.Infof("Downloaded %s from container %s: %d bytes", src, containerID, w.written)
+		case pipe.Closed():
+			log.Warnf("Download of %s from container %s cancelled after %d bytes", src, containerID, w.written)
+		default:
+			log.Errorf("Error downloading %s from container %s after %d bytes: %v", src, containerID, w.written, err)
+		}
+		pipe.Close()
+	}()
+	return xfer.Response{
+		Pipe: id,
+	}
+}
+
+// uploadFiles extracts a tar archive read from the pipe into a directory of
+// the container. What went wrong, if anything, is written back down the pipe
+// before it is closed.
+func (r *registry) uploadFiles(containerID string, req xfer.Request) xfer.Response {
+	dst, err := archivePath(req.ControlArgs)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+
+	id, pipe, err := controls.NewPipe(r.pipes, req.AppID)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	local, _ := pipe.Ends()
+	log.Infof("Uploading to %s in container %s through pipe %s for app %s", dst, containerID, id, req.AppID)
+	go func() {
+		if err := r.upload(containerID, dst, local); err != nil {
+			log.Errorf("Error uploading to %s in container %s: %v", dst, containerID, err)
+			if !pipe.Closed() {
+				io.WriteString(local, err.Error())
+			}
+		} else {
+			log.Infof("Uploaded to %s in container %s", dst, containerID)
+		}
+		pipe.Close()
+	}()
+	return xfer.Response{
+		Pipe: id,
+	}
+}
+
+// upload copies the archive over entry by entry rather than as a stream, as
+// the pipe never reaches EOF: the end of the archive ends the upload.
+func (r *registry) upload(containerID, dst string, rd io.Reader) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(copyArchive(tar.NewWriter(pw), tar.NewReader(rd), func(hdr *tar.Header) {
+			log.Infof("Uploading %s (%d bytes) to %s in container %s", hdr.Name, hdr.Size, dst, containerID)
+		}))
+	}()
+	err := r.client.UploadToContainer(containerID, docker_client.UploadToContainerOptions{
+		Path:        dst,
+		InputStream: pr,
+	})
+	// Stops the copy if the daemon gave up on the archive early
+	pr.CloseWithError(err)
+	return err
+}
+
+func copyArchive(tw *tar.Writer, tr *tar.Reader, onEntry func(*tar.Header)) error {
+	var size int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return tw.Close()
+		} else if err != nil {
+			return err
+		}
+		if size += hdr.Size; size > xfer.MaxArchiveSize {
+			return errArchiveTooLarge
+		}
+		onEntry(hdr)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
 func captureContainerID(f func(string, xfer.Request) xfer.Response) func(xfer.Request) xfer.Response {
 	return func(req xfer.Request) xfer.Response {
 		containerID, ok := report.ParseContainerNodeID(req.NodeID)
@@ -163,18 +402,76 @@ func captureContainerID(f func(string, xfer.Request) xfer.Response) func(xfer.Re
 	}
 }
 
+// registries are the registries of every docker endpoint of the probe. The
+// controls are registered once for all of them, and each request is routed
+// to the registry of the daemon owning the container.
+var registries = struct {
+	sync.RWMutex
+	all []*registry
+}{}
+
+// registryFor returns the registry of the daemon owning a container. When
+// there is only one daemon it is assumed to own it, so that it can report
+// the container missing itself.
+func registryFor(containerID string) (*registry, bool) {
+	registries.RLock()
+	defer registries.RUnlock()
+	for _, r := range registries.all {
+		if _, ok := r.GetContainer(containerID); ok {
+			return r, true
+		}
+	}
+	if len(registries.all) == 1 {
+		return registries.all[0], true
+	}
+	return nil, false
+}
+
+// routeToContainer makes a control handler running f on the registry of
+// the daemon owning the container of the request.
+func routeToContainer(f func(*registry, string, xfer.Request) xfer.Response) func(xfer.Request) xfer.Response {
+	return captureContainerID(func(containerID string, req xfer.Request) xfer.Response {
+		r, ok := registryFor(containerID)
+		if !ok {
+			return xfer.ResponseErrorf("Not found: %s", containerID)
+		}
+		return f(r, containerID, req)
+	})
+}
+
 func (r *registry) registerControls() {
-	controls.Register(StopContainer, captureContainerID(r.stopContainer))
-	controls.Register(StartContainer, captureContainerID(r.startContainer))
-	controls.Register(RestartContainer, captureContainerID(r.restartContainer))
-	controls.Register(PauseContainer, captureContainerID(r.pauseContainer))
-	controls.Register(UnpauseContainer, captureContainerID(r.unpauseContainer))
-	controls.Register(RemoveContainer, captureContainerID(r.removeContainer))
-	controls.Register(AttachContainer, captureContainerID(r.attachContainer))
-	controls.Register(ExecContainer, captureContainerID(r.execContainer))
+	registries.Lock()
+	defer registries.Unlock()
+	registries.all = append(registries.all, r)
+	if len(registries.all) > 1 {
+		return
+	}
+	controls.Register(StopContainer, routeToContainer((*registry).stopContainer))
+	controls.Register(StartContainer, routeToContainer((*registry).startContainer))
+	controls.Register(RestartContainer, routeToContainer((*registry).restartContainer))
+	controls.Register(PauseContainer, routeToContainer((*registry).pauseContainer))
+	controls.Register(UnpauseContainer, routeToContainer((*registry).unpauseContainer))
+	controls.Register(RemoveContainer, routeToContainer((*registry).removeContainer))
+	controls.Register(AttachContainer, routeToContainer((*registry).attachContainer))
+	controls.Register(ExecContainer, routeToContainer((*registry).execContainer))
+	controls.Register(ContainerLogs, routeToContainer((*registry).containerLogs))
+	controls.Register(DownloadFiles, routeToContainer((*registry).downloadFiles))
+	controls.Register(UploadFiles, routeToContainer((*registry).uploadFiles))
+	registerComposeControls()
 }
 
 func (r *registry) deregisterControls() {
+	registries.Lock()
+	defer registries.Unlock()
+	for i, other := range registries.all {
+		if other == r {
+			registries.all = append(registries.all[:i], registries.all[i+1:]...)
+			break
+		}
+	}
+	if len(registries.all) > 0 {
+		return
+	}
 	controls.Rm(StopContainer)
 	controls.Rm(StartContainer)
 	controls.Rm(RestartContainer)
@@ -183,4 +480,8 @@ func (r *registry) deregisterControls() {
 	controls.Rm(RemoveContainer)
 	controls.Rm(AttachContainer)
 	controls.Rm(ExecContainer)
+	controls.Rm(ContainerLogs)
+	controls.Rm(DownloadFiles)
+	controls.Rm(UploadFiles)
+	deregisterComposeControls()
 }
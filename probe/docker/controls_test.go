@@ -1,7 +1,10 @@
 package docker_test
 
 import (
+	"archive/tar"
+	"bytes"
 	"io"
+	"io/ioutil"
 	"reflect"
 	"testing"
 	"time"
@@ -16,7 +19,7 @@ import (
 func TestControls(t *testing.T) {
 	mdc := newMockClient()
 	setupStubs(mdc, func() {
-		registry, _ := docker.NewRegistry(10*time.Second, nil, false, "")
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "", nil)
 		defer registry.Stop()
 
 		for _, tc := range []struct{ command, result string }{
@@ -56,7 +59,7 @@ func TestPipes(t *testing.T) {
 
 	mdc := newMockClient()
 	setupStubs(mdc, func() {
-		registry, _ := docker.NewRegistry(10*time.Second, nil, false, "")
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "", nil)
 		defer registry.Stop()
 
 		test.Poll(t, 100*time.Millisecond, true, func() interface{} {
@@ -82,3 +85,147 @@ func TestPipes(t *testing.T) {
 		}
 	})
 }
+
+func TestContainerLogs(t *testing.T) {
+	oldNewPipe := controls.NewPipe
+	defer func() { controls.NewPipe = oldNewPipe }()
+	pipes := map[string]xfer.Pipe{}
+	controls.NewPipe = func(_ controls.PipeClient, _ string) (string, xfer.Pipe, error) {
+		pipes["pipeid"] = xfer.NewPipe()
+		return "pipeid", pipes["pipeid"], nil
+	}
+
+	mdc := newMockClient()
+	setupStubs(mdc, func() {
+		docker.NewContainerStub = docker.NewContainer
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "", nil)
+		defer registry.Stop()
+
+		test.Poll(t, 100*time.Millisecond, true, func() interface{} {
+			_, ok := registry.GetContainer("ping")
+			return ok
+		})
+
+		result := controls.HandleControlRequest(xfer.Request{
+			Control: docker.ContainerLogs,
+			NodeID:  report.MakeContainerNodeID("ping"),
+			ControlArgs: map[string]string{
+				controls.LogsFollowArg: "false",
+				controls.LogsTailArg:   "10",
+				controls.LogsSinceArg:  "2016-09-14T10:00:00Z",
+			},
+		})
+		if want := (xfer.Response{Pipe: "pipeid"}); !reflect.DeepEqual(result, want) {
+			t.Fatalf("diff: %s", test.Diff(want, result))
+		}
+
+		// The streams are told apart by their labels
+		_, remote := pipes["pipeid"].Ends()
+		want := "stdout: hello\nstdout: world\nstderr: oops\n"
+		contents := make([]byte, len(want))
+		if _, err := io.ReadFull(remote, contents); err != nil {
+			t.Fatal(err)
+		}
+		if string(contents) != want {
+			t.Errorf("Expected pipe to contain %q, got %q", want, string(contents))
+		}
+
+		mdc.RLock()
+		defer mdc.RUnlock()
+		if len(mdc.logsOptions) != 1 {
+			t.Fatalf("Expected one logs request, got %d", len(mdc.logsOptions))
+		}
+		opts := mdc.logsOptions[0]
+		if opts.Container != "ping" || opts.Follow || opts.Tail != "10" || opts.Since != 1473847200 || !opts.Stdout || !opts.Stderr {
+			t.Errorf("Unexpected logs options: %+v", opts)
+		}
+
+		// Bad options are rejected before a pipe is made
+		result = controls.HandleControlRequest(xfer.Request{
+			Control:     docker.ContainerLogs,
+			NodeID:      report.MakeContainerNodeID("ping"),
+			ControlArgs: map[string]string{controls.LogsTailArg: "lots"},
+		})
+		if want := `Invalid tail: "lots"`; result.Error != want {
+			t.Errorf("Expected error %q, got %q", want, result.Error)
+		}
+	})
+}
+
+func TestFileControls(t *testing.T) {
+	oldNewPipe := controls.NewPipe
+	defer func() { controls.NewPipe = oldNewPipe }()
+	var pipe xfer.Pipe
+	controls.NewPipe = func(_ controls.PipeClient, _ string) (string, xfer.Pipe, error) {
+		pipe = xfer.NewPipe()
+		return "pipeid", pipe, nil
+	}
+
+	mdc := newMockClient()
+	setupStubs(mdc, func() {
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "", nil)
+		defer registry.Stop()
+
+		test.Poll(t, 100*time.Millisecond, true, func() interface{} {
+			_, ok := registry.GetContainer("ping")
+			return ok
+		})
+
+		// Downloads are streamed down the pipe as a tar archive
+		result := controls.HandleControlRequest(xfer.Request{
+			Control:     docker.DownloadFiles,
+			NodeID:      report.MakeContainerNodeID("ping"),
+			ControlArgs: map[string]string{docker.ArchivePathArg: "/etc/hosts"},
+		})
+		if want := (xfer.Response{Pipe: "pipeid"}); !reflect.DeepEqual(result, want) {
+			t.Fatalf("diff: %s", test.Diff(want, result))
+		}
+		_, remote := pipe.Ends()
+		tr := tar.NewReader(remote)
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name != "hosts" {
+			t.Errorf("Expected to download hosts, got %s", hdr.Name)
+		}
+		if _, err := tr.Next(); err != io.EOF {
+			t.Errorf("Expected the archive to end, got %v", err)
+		}
+
+		// Uploads end with the archive, trailing padding notwithstanding
+		result = controls.HandleControlRequest(xfer.Request{
+			Control:     docker.UploadFiles,
+			NodeID:      report.MakeContainerNodeID("ping"),
+			ControlArgs: map[string]string{docker.ArchivePathArg: "/tmp"},
+		})
+		if want := (xfer.Response{Pipe: "pipeid"}); !reflect.DeepEqual(result, want) {
+			t.Fatalf("diff: %s", test.Diff(want, result))
+		}
+		_, remote = pipe.Ends()
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		tw.WriteHeader(&tar.Header{Name: "patch", Mode: 0644, Size: 5})
+		io.WriteString(tw, "fixed")
+		tw.Close()
+		go remote.Write(append(buf.Bytes(), make([]byte, 1024)...))
+		if errors, _ := ioutil.ReadAll(remote); len(errors) != 0 {
+			t.Errorf("Expected upload to succeed, got %q", errors)
+		}
+		mdc.RLock()
+		if want := map[string]string{"/tmp/patch": "fixed"}; !reflect.DeepEqual(mdc.uploads, want) {
+			t.Errorf("diff: %s", test.Diff(want, mdc.uploads))
+		}
+		mdc.RUnlock()
+
+		// Paths have to be absolute
+		result = controls.HandleControlRequest(xfer.Request{
+			Control:     docker.UploadFiles,
+			NodeID:      report.MakeContainerNodeID("ping"),
+			ControlArgs: map[string]string{docker.ArchivePathArg: "tmp"},
+		})
+		if want := `Invalid path: "tmp"`; result.Error != want {
+			t.Errorf("Expected error %q, got %q", want, result.Error)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"strings"
+)
+
+// These constants are keys used in node metadata
+const (
+	// ImageRepoDigests are the "repo@digest" references of an image, as
+	// recorded by the registries it was pulled from.
+	ImageRepoDigests = "docker_image_repo_digests"
+	// ImageDigest is the digest an image's name resolved to when pulled.
+	ImageDigest = "docker_image_digest"
+	// ContainerImageDigest is the digest of a container's image, either as
+	// pinned by the container's configuration or as resolved on its host.
+	ContainerImageDigest = "docker_container_image_digest"
+
+	// ImageTagDigests is set by the app on containers and images whose tag
+	// resolves to more than one digest in the fleet, to how many it does.
+	ImageTagDigests = "docker_image_tag_digests"
+	// ImageDriftPrefix is the prefix of the node table listing the digests a
+	// drifting tag resolves to.
+	ImageDriftPrefix = "docker_image_drift_"
+)
+
+// pinnedDigest returns the digest of an image reference of the form
+// repo@digest, or "" if the reference is not pinned.
+func pinnedDigest(reference string) string {
+	if i := strings.LastIndex(reference, "@"); i >= 0 {
+		return reference[i+1:]
+	}
+	return ""
+}
+
+// imageRepo strips the tag or digest off an image reference, leaving the
+// repository, including any registry host and port.
+func imageRepo(reference string) string {
+	if i := strings.LastIndex(reference, "@"); i >= 0 {
+		reference = reference[:i]
+	}
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		reference = reference[:i]
+	}
+	return reference
+}
+
+// repoDigest returns the digest from repoDigests for the repository of the
+// image reference name. If name is not from any of them, but the image only
+// has one digest, that is it.
+func repoDigest(repoDigests []string, name string) string {
+	repo := imageRepo(name)
+	for _, repoDigest := range repoDigests {
+		if imageRepo(repoDigest) == repo {
+			return pinnedDigest(repoDigest)
+		}
+	}
+	if len(repoDigests) == 1 {
+		return pinnedDigest(repoDigests[0])
+	}
+	return ""
+}
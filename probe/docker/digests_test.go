@@ -0,0 +1,59 @@
+package docker_test
+
+import (
+	"testing"
+
+	client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+)
+
+func TestReporterImageDigests(t *testing.T) {
+	image := apiImage1
+	image.RepoDigests = []string{
+		"registry.example.com:5000/bang@sha256:aaa",
+		"bang@sha256:bbb",
+	}
+	pinned := *container1
+	pinned.ID = "pinned"
+	pinned.Config = &client.Config{Image: "registry.example.com:5000/bang@sha256:aaa"}
+
+	registry := &mockRegistry{
+		containersByPID: map[int]docker.Container{
+			2: &mockContainer{container1},
+			3: docker.NewContainer(&pinned, "host1"),
+		},
+		images: map[string]*client.APIImages{
+			"baz": &image,
+		},
+	}
+	rpt, err := docker.NewReporter(registry, "host1", "", nil).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imageNode, ok := rpt.ContainerImage.Nodes[report.MakeContainerImageNodeID("baz")]
+	if !ok {
+		t.Fatal("Expected report to have the container image")
+	}
+	if have, ok := imageNode.Latest.Lookup(docker.ImageDigest); !ok || have != "sha256:bbb" {
+		t.Errorf("Expected image digest %q, got %q", "sha256:bbb", have)
+	}
+	if have, ok := imageNode.Sets.Lookup(docker.ImageRepoDigests); !ok || len(have) != 2 {
+		t.Errorf("Expected image to have 2 repo digests, got %v", have)
+	}
+
+	for id, want := range map[string]string{
+		"ping":   "sha256:bbb", // resolved by the image's name
+		"pinned": "sha256:aaa", // pinned in the container's config
+	} {
+		node, ok := rpt.Container.Nodes[report.MakeContainerNodeID(id)]
+		if !ok {
+			t.Fatalf("Expected report to have container %q", id)
+		}
+		if have, ok := node.Latest.Lookup(docker.ContainerImageDigest); !ok || have != want {
+			t.Errorf("Expected container %s image digest %q, got %q", id, want, have)
+		}
+	}
+}
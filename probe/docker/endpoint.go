@@ -0,0 +1,161 @@
+package docker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+
+	docker_client "github.com/fsouza/go-dockerclient"
+)
+
+// DefaultEndpoint is the socket of the local docker daemon.
+const DefaultEndpoint = "unix:///var/run/docker.sock"
+
+// Endpoint is a docker daemon the probe reports on.
+type Endpoint struct {
+	// Address of the daemon, eg unix:///run/user/1000/docker.sock or
+	// tcp://10.0.0.5:2376.
+	Address string
+
+	// TLS client certificate, key and CA certificate files. All three are
+	// needed to use TLS.
+	TLSCert string
+	TLSKey  string
+	TLSCA   string
+
+	// HostID, if set, is the host the daemon runs on, when that isn't the
+	// host of the probe. Its containers are reported as on that host.
+	HostID string
+}
+
+// ParseEndpoint parses an endpoint of the form
+// address[,host=id][,cert=file,key=file,ca=file].
+func ParseEndpoint(s string) (Endpoint, error) {
+	parts := strings.Split(s, ",")
+	e := Endpoint{Address: parts[0]}
+	if e.Address == "" {
+		return e, fmt.Errorf("docker endpoint %q has no address", s)
+	}
+	for _, option := range parts[1:] {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return e, fmt.Errorf("docker endpoint %q: invalid option %q", s, option)
+		}
+		switch kv[0] {
+		case "host":
+			e.HostID = kv[1]
+		case "cert":
+			e.TLSCert = kv[1]
+		case "key":
+			e.TLSKey = kv[1]
+		case "ca":
+			e.TLSCA = kv[1]
+		default:
+			return e, fmt.Errorf("docker endpoint %q: unknown option %q", s, kv[0])
+		}
+	}
+	if e.TLS() && (e.TLSCert == "" || e.TLSKey == "" || e.TLSCA == "") {
+		return e, fmt.Errorf("docker endpoint %q: TLS needs cert, key and ca", s)
+	}
+	return e, nil
+}
+
+// TLS returns true if the endpoint is to be connected to over TLS.
+func (e Endpoint) TLS() bool {
+	return e.TLSCert != "" || e.TLSKey != "" || e.TLSCA != ""
+}
+
+// Remote returns true if the daemon is on another host than the probe, so
+// its containers' processes and cgroups can't be seen by the probe.
+func (e Endpoint) Remote() bool {
+	return e.HostID != ""
+}
+
+func (e Endpoint) String() string {
+	if e.HostID == "" {
+		return e.Address
+	}
+	return fmt.Sprintf("%s (host %s)", e.Address, e.HostID)
+}
+
+// Endpoints is a list of endpoints, usable as a repeated command line flag.
+type Endpoints []Endpoint
+
+func (es *Endpoints) String() string {
+	addresses := make([]string, 0, len(*es))
+	for _, e := range *es {
+		addresses = append(addresses, e.String())
+	}
+	return strings.Join(addresses, ", ")
+}
+
+// Set implements flag.Value, adding an endpoint to the list.
+func (es *Endpoints) Set(s string) error {
+	e, err := ParseEndpoint(s)
+	if err != nil {
+		return err
+	}
+	*es = append(*es, e)
+	return nil
+}
+
+// dial connects to the daemon, for the API calls the client can't make.
+func (e Endpoint) dial() (net.Conn, error) {
+	u, err := url.Parse(e.Address)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "unix":
+		return DialStub(u.Scheme, u.Path)
+	case "tcp":
+		if !e.TLS() {
+			return DialStub(u.Scheme, u.Host)
+		}
+		config, err := e.tlsConfig(u.Host)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := DialStub(u.Scheme, u.Host)
+		if err != nil {
+			return nil, err
+		}
+		return tls.Client(conn, config), nil
+	}
+	return nil, fmt.Errorf("unsupported docker endpoint scheme %q", u.Scheme)
+}
+
+func (e Endpoint) tlsConfig(address string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(e.TLSCert, e.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ioutil.ReadFile(e.TLSCA)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", e.TLSCA)
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   host,
+	}, nil
+}
+
+func newDockerClient(e Endpoint) (Client, error) {
+	if e.TLS() {
+		return docker_client.NewTLSClient(e.Address, e.TLSCert, e.TLSKey, e.TLSCA)
+	}
+	return docker_client.NewClient(e.Address)
+}
@@ -0,0 +1,140 @@
+package docker_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/host"
+	"$GITHUB_URI/report"
+	"$GITHUB_URI/test"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want docker.Endpoint
+		err  bool
+	}{
+		{in: "unix:///run/user/1000/docker.sock", want: docker.Endpoint{Address: "unix:///run/user/1000/docker.sock"}},
+		{in: "tcp://10.0.0.5:2375,host=appliance1", want: docker.Endpoint{Address: "tcp://10.0.0.5:2375", HostID: "appliance1"}},
+		{
+			in: "tcp://10.0.0.5:2376,cert=cert.pem,key=key.pem,ca=ca.pem,host=appliance1",
+			want: docker.Endpoint{
+				Address: "tcp://10.0.0.5:2376",
+				TLSCert: "cert.pem",
+				TLSKey:  "key.pem",
+				TLSCA:   "ca.pem",
+				HostID:  "appliance1",
+			},
+		},
+		{in: "", err: true},
+		{in: "tcp://10.0.0.5:2376,cert=cert.pem", err: true},
+		{in: "tcp://10.0.0.5:2376,colour=blue", err: true},
+		{in: "tcp://10.0.0.5:2376,host=", err: true},
+	} {
+		have, err := docker.ParseEndpoint(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+		} else if !reflect.DeepEqual(tc.want, have) {
+			t.Errorf("%q: expected %v, got %v", tc.in, tc.want, have)
+		}
+	}
+}
+
+// namedDockerClient tells which daemon stopped a container.
+type namedDockerClient struct {
+	*mockDockerClient
+	name string
+}
+
+func (c namedDockerClient) StopContainer(id string, _ uint) error {
+	return fmt.Errorf("%s stopped %s", c.name, id)
+}
+
+func TestControlsRouting(t *testing.T) {
+	local := newMockClient()
+	remote := newMockClient()
+	remote.apiContainers = []client.APIContainers{{ID: "wiff"}}
+	remote.containers = map[string]*client.Container{"wiff": container2}
+
+	setupStubs(local, func() {
+		clients := map[string]docker.Client{
+			docker.DefaultEndpoint: namedDockerClient{local, "local"},
+			"tcp://10.0.0.5:2375":  namedDockerClient{remote, "remote"},
+		}
+		docker.NewDockerClientStub = func(e docker.Endpoint) (docker.Client, error) {
+			return clients[e.Address], nil
+		}
+
+		localRegistry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "host1", nil)
+		defer localRegistry.Stop()
+		remoteRegistry, _ := docker.NewRegistry(docker.Endpoint{Address: "tcp://10.0.0.5:2375", HostID: "appliance1"}, 10*time.Second, nil, false, "host1", nil)
+		defer remoteRegistry.Stop()
+		for _, r := range []docker.Registry{localRegistry, remoteRegistry} {
+			r := r
+			test.Poll(t, 100*time.Millisecond, 1, func() interface{} {
+				return len(allContainers(r))
+			})
+		}
+
+		for id, want := range map[string]string{
+			"ping": "local stopped ping",
+			"wiff": "remote stopped wiff",
+			"nope": "Not found: nope",
+		} {
+			result := controls.HandleControlRequest(xfer.Request{
+				Control: docker.StopContainer,
+				NodeID:  report.MakeContainerNodeID(id),
+			})
+			if result.Error != want {
+				t.Errorf("Expected %q, got %q", want, result.Error)
+			}
+		}
+
+		// The controls stay registered until the last registry stops
+		remoteRegistry.Stop()
+		result := controls.HandleControlRequest(xfer.Request{
+			Control: docker.StopContainer,
+			NodeID:  report.MakeContainerNodeID("ping"),
+		})
+		if want := "local stopped ping"; result.Error != want {
+			t.Errorf("Expected %q, got %q", want, result.Error)
+		}
+	})
+}
+
+func TestRemoteReporter(t *testing.T) {
+	rpt, err := docker.NewRemoteReporter(mockRegistryInstance, "appliance1", "", nil).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpt, _ = host.NewTagger("host1").Tag(rpt)
+
+	hostNodeID := report.MakeHostNodeID("appliance1")
+	if _, ok := rpt.Host.Nodes[hostNodeID]; !ok {
+		t.Errorf("Expected report to have the remote host %q", hostNodeID)
+	}
+	node, ok := rpt.Container.Nodes[report.MakeContainerNodeID("ping")]
+	if !ok {
+		t.Fatal("Expected report to have the container")
+	}
+	if have, ok := node.Latest.Lookup(report.HostNodeID); !ok || have != hostNodeID {
+		t.Errorf("Expected container to be on host %q, got %q", hostNodeID, have)
+	}
+	if have, ok := node.Parents.Lookup(report.Host); !ok || !reflect.DeepEqual(have, report.MakeStringSet(hostNodeID)) {
+		t.Errorf("Expected container to have host parent %q, got %v", hostNodeID, have)
+	}
+}
@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	docker_client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/report"
+)
+
+// EventPrefix is the prefix of the node table holding a container's
+// lifecycle events.
+const EventPrefix = "docker_event_"
+
+// ContainerEvent is a lifecycle event of a container, as seen by the probe,
+// or an event the kernel logged about one of its processes.
+type ContainerEvent struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	OOMKilled bool      `json:"oom_killed,omitempty"`
+	Details   string    `json:"details,omitempty"`
+}
+
+// String gives the human readable form of the event, used as the value in
+// the events table, eg "die (exit code 137, OOM killed)".
+func (e ContainerEvent) String() string {
+	details := []string{}
+	if e.ExitCode != nil {
+		details = append(details, fmt.Sprintf("exit code %d", *e.ExitCode))
+	}
+	if e.OOMKilled {
+		details = append(details, "OOM killed")
+	}
+	if e.Details != "" {
+		details = append(details, e.Details)
+	}
+	if len(details) == 0 {
+		return e.Action
+	}
+	return fmt.Sprintf("%s (%s)", e.Action, strings.Join(details, ", "))
+}
+
+// makeContainerEvent makes the event for an API event, taking the exit code
+// and OOM-killed flag from the inspected container when it died.
+func makeContainerEvent(event *docker_client.APIEvents, c *docker_client.Container) ContainerEvent {
+	result := ContainerEvent{
+		Time:   time.Unix(event.Time, 0),
+		Action: event.Status,
+	}
+	if event.TimeNano != 0 {
+		result.Time = time.Unix(0, event.TimeNano)
+	}
+	if event.Status == DieEvent {
+		exitCode := c.State.ExitCode
+		result.ExitCode = &exitCode
+		result.OOMKilled = c.State.OOMKilled
+	}
+	return result
+}
+
+// addEvent appends an event to a history, dropping the oldest events beyond
+// those reported. Events are kept in time order, and never share a
+// timestamp, as that is used as their key.
+func addEvent(events []ContainerEvent, event ContainerEvent) []ContainerEvent {
+	if n := len(events); n > 0 && !event.Time.After(events[n-1].Time) {
+		event.Time = events[n-1].Time.Add(time.Nanosecond)
+	}
+	events = append(events, event)
+	if len(events) > report.MaxTableRows {
+		events = events[len(events)-report.MaxTableRows:]
+	}
+	return events
+}
+
+// WithContainerEvents adds events to the events table of a container node.
+// Only the most recent are reported; the app keeps the longer history.
+func WithContainerEvents(node report.Node, events []ContainerEvent) report.Node {
+	rows := make([]report.TimelineRow, 0, len(events))
+	for _, event := range events {
+		rows = append(rows, report.TimelineRow{Time: event.Time, Value: event.String()})
+	}
+	return node.AddTimeline(EventPrefix, rows)
+}
+
+// ParseContainerEvents returns the events in the events table of a node,
+// oldest first.
+func ParseContainerEvents(node report.Node) []ContainerEvent {
+	rows := node.ExtractTimeline(EventPrefix)
+	result := make([]ContainerEvent, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, parseContainerEvent(row.Time, row.Value))
+	}
+	return result
+}
+
+// parseContainerEvent is the inverse of ContainerEvent.String.
+func parseContainerEvent(t time.Time, value string) ContainerEvent {
+	result := ContainerEvent{Time: t, Action: value}
+	i := strings.Index(value, " (")
+	if i < 0 || !strings.HasSuffix(value, ")") {
+		return result
+	}
+	result.Action = value[:i]
+	others := []string{}
+	for _, detail := range strings.Split(value[i+2:len(value)-1], ", ") {
+		var exitCode int
+		if detail == "OOM killed" {
+			result.OOMKilled = true
+		} else if _, err := fmt.Sscanf(detail, "exit code %d", &exitCode); err == nil {
+			result.ExitCode = &exitCode
+		} else {
+			others = append(others, detail)
+		}
+	}
+	result.Details = strings.Join(others, ", ")
+	return result
+}
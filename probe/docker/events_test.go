@@ -0,0 +1,114 @@
+package docker_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+	"$GITHUB_URI/test"
+	"$GITHUB_URI/test/reflect"
+)
+
+func TestRegistryRecordsEvents(t *testing.T) {
+	mdc := newMockClient()
+	setupStubs(mdc, func() {
+		docker.NewContainerStub = docker.NewContainer
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "", nil)
+		defer registry.Stop()
+		runtime.Gosched()
+
+		died := *container1
+		died.State = client.State{ExitCode: 137, OOMKilled: true}
+		mdc.Lock()
+		mdc.containers["ping"] = &died
+		mdc.Unlock()
+		dieTime := time.Date(2016, time.September, 14, 10, 0, 0, 42, time.UTC)
+		mdc.send(&client.APIEvents{Status: docker.DieEvent, ID: "ping", Time: dieTime.Unix(), TimeNano: dieTime.UnixNano()})
+		runtime.Gosched()
+
+		exitCode := 137
+		want := []docker.ContainerEvent{{Time: dieTime, Action: docker.DieEvent, ExitCode: &exitCode, OOMKilled: true}}
+		test.Poll(t, 100*time.Millisecond, want, func() interface{} {
+			events := []docker.ContainerEvent{}
+			registry.WalkContainers(func(c docker.Container) {
+				events = append(events, docker.ParseContainerEvents(c.GetNode())...)
+			})
+			for i := range events {
+				events[i].Time = events[i].Time.UTC()
+			}
+			return events
+		})
+	})
+}
+
+func TestContainerEvents(t *testing.T) {
+	c := docker.NewContainer(container1, "host1")
+	now := time.Date(2016, time.September, 14, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < report.MaxTableRows+5; i++ {
+		c.AddEvent(docker.ContainerEvent{Time: now, Action: docker.StartEvent})
+	}
+	exitCode := 0
+	c.AddEvent(docker.ContainerEvent{Time: now.Add(time.Second), Action: docker.DieEvent, ExitCode: &exitCode})
+
+	// Only the most recent events are kept, and events at the same time are
+	// kept apart.
+	events := docker.ParseContainerEvents(c.GetNode())
+	if want, have := report.MaxTableRows, len(events); want != have {
+		t.Fatalf("Expected %d events, got %d", want, have)
+	}
+	for i := 1; i < len(events); i++ {
+		if !events[i-1].Time.Before(events[i].Time) {
+			t.Errorf("Expected events in time order, got %v then %v", events[i-1].Time, events[i].Time)
+		}
+	}
+	last := events[len(events)-1]
+	last.Time = last.Time.UTC()
+	if want := (docker.ContainerEvent{Time: now.Add(time.Second), Action: docker.DieEvent, ExitCode: &exitCode}); !reflect.DeepEqual(want, last) {
+		t.Errorf("Expected last event %v, got %v", want, last)
+	}
+	if want, have := "die (exit code 0)", last.String(); want != have {
+		t.Errorf("Expected %q, got %q", want, have)
+	}
+}
+
+func TestRegistryForgetsDestroyedContainerEvents(t *testing.T) {
+	mdc := newMockClient()
+	setupStubs(mdc, func() {
+		docker.NewContainerStub = docker.NewContainer
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, false, "", nil)
+		defer registry.Stop()
+		runtime.Gosched()
+
+		events := func() interface{} {
+			result := []string{}
+			registry.WalkContainers(func(c docker.Container) {
+				for _, event := range docker.ParseContainerEvents(c.GetNode()) {
+					result = append(result, c.ID()+" "+event.Action)
+				}
+			})
+			return result
+		}
+
+		mdc.send(&client.APIEvents{Status: docker.DieEvent, ID: "ping"})
+		test.Poll(t, 100*time.Millisecond, []string{"ping die"}, events)
+
+		mdc.Lock()
+		mdc.apiContainers = []client.APIContainers{}
+		delete(mdc.containers, "ping")
+		mdc.Unlock()
+		mdc.send(&client.APIEvents{Status: docker.DestroyEvent, ID: "ping"})
+		test.Poll(t, 100*time.Millisecond, []string{}, events)
+
+		// A container coming back with the same ID starts a new history.
+		mdc.Lock()
+		mdc.apiContainers = []client.APIContainers{apiContainer1}
+		mdc.containers["ping"] = container1
+		mdc.Unlock()
+		mdc.send(&client.APIEvents{Status: docker.StartEvent, ID: "ping"})
+		test.Poll(t, 100*time.Millisecond, []string{"ping start"}, events)
+	})
+}
@@ -0,0 +1,93 @@
+package docker
+
+import (
+	docker_client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/report"
+)
+
+// These constants are keys used in node metadata
+const (
+	NetworkName     = "docker_network_name"
+	NetworkDriver   = "docker_network_driver"
+	NetworkScope    = "docker_network_scope"
+	NetworkInternal = "docker_network_internal"
+
+	VolumeName       = "docker_volume_name"
+	VolumeDriver     = "docker_volume_driver"
+	VolumeMountpoint = "docker_volume_mountpoint"
+
+	// NetworkIPPrefix prefixes the container's IP on each network it is
+	// attached to, keyed by network name.
+	NetworkIPPrefix = "docker_network_ip_"
+
+	localScope = "local"
+)
+
+// The networks every daemon has; they're not user-defined, so aren't
+// reported. Containers on them are already grouped by network mode.
+var predefinedNetworks = map[string]struct{}{
+	"bridge": {},
+	"host":   {},
+	"none":   {},
+}
+
+// IsPredefinedNetwork returns true for the networks docker creates itself.
+func IsPredefinedNetwork(name string) bool {
+	_, ok := predefinedNetworks[name]
+	return ok
+}
+
+// MakeVolumeNodeID makes the ID of a volume node. Volumes are named per
+// host, so the same name on two hosts is two volumes.
+func MakeVolumeNodeID(hostID, name string) string {
+	return report.MakeDockerVolumeNodeID(name + "@" + hostID)
+}
+
+func (r *Reporter) networkTopology() report.Topology {
+	result := report.MakeTopology().
+		WithMetadataTemplates(NetworkMetadataTemplates)
+
+	r.registry.WalkNetworks(func(network docker_client.Network) {
+		if IsPredefinedNetwork(network.Name) {
+			return
+		}
+		internal := "false"
+		if network.Internal {
+			internal = "true"
+		}
+		node := report.MakeNodeWith(report.MakeDockerNetworkNodeID(network.ID), map[string]string{
+			NetworkName:     network.Name,
+			NetworkDriver:   network.Driver,
+			NetworkScope:    network.Scope,
+			NetworkInternal: internal,
+		})
+		// Networks spanning hosts (eg. overlay networks) are the same
+		// network on each of them.
+		if network.Scope == localScope {
+			node = node.WithParents(report.EmptySets.
+				Add(report.Host, report.MakeStringSet(report.MakeHostNodeID(r.hostID))),
+			)
+		}
+		result.AddNode(node)
+	})
+
+	return result
+}
+
+func (r *Reporter) volumeTopology() report.Topology {
+	result := report.MakeTopology().
+		WithMetadataTemplates(VolumeMetadataTemplates)
+
+	r.registry.WalkVolumes(func(volume docker_client.Volume) {
+		result.AddNode(report.MakeNodeWith(MakeVolumeNodeID(r.hostID, volume.Name), map[string]string{
+			VolumeName:       volume.Name,
+			VolumeDriver:     volume.Driver,
+			VolumeMountpoint: volume.Mountpoint,
+		}).WithParents(report.EmptySets.
+			Add(report.Host, report.MakeStringSet(report.MakeHostNodeID(r.hostID))),
+		))
+	})
+
+	return result
+}
@@ -23,7 +23,7 @@ const (
 	DieEvent     = "die"
 	PauseEvent   = "pause"
 	UnpauseEvent = "unpause"
-	endpoint     = "unix:///var/run/docker.sock"
+	OOMEvent     = "oom"
 )
 
 // Vars exported for testing.
@@ -32,12 +32,15 @@ var (
 	NewContainerStub    = NewContainer
 )
 
-// Registry keeps track of running docker containers and their images
+// Registry keeps track of running containers and their images. It is
+// implemented for Docker here, and for other runtimes elsewhere.
 type Registry interface {
 	Stop()
 	LockedPIDLookup(f func(func(int) Container))
 	WalkContainers(f func(Container))
 	WalkImages(f func(*docker_client.APIImages))
+	WalkNetworks(f func(docker_client.Network))
+	WalkVolumes(f func(docker_client.Volume))
 	WatchContainerUpdates(ContainerUpdateWatcher)
 	GetContainer(string) (Container, bool)
 	GetContainerByPrefix(string) (Container, bool)
@@ -49,8 +52,12 @@ type ContainerUpdateWatcher func(report.Node)
 type registry struct {
 	sync.RWMutex
 	quit         chan chan struct{}
+	sampleQuit   chan struct{}
+	stopOnce     sync.Once
 	interval     time.Duration
 	collectStats bool
+	cgroups      *CgroupCollector
+	endpoint     Endpoint
 	client       Client
 	pipes        controls.PipeClient
 	hostID       string
@@ -59,6 +66,8 @@ type registry struct {
 	containers      *radix.Tree
 	containersByPID map[int]Container
 	images          map[string]*docker_client.APIImages
+	networks        []docker_client.Network
+	volumes         []docker_client.Volume
 }
 
 // Client interface for mocking.
@@ -66,6 +75,8 @@ type Client interface {
 	ListContainers(docker_client.ListContainersOptions) ([]docker_client.APIContainers, error)
 	InspectContainer(string) (*docker_client.Container, error)
 	ListImages(docker_client.ListImagesOptions) ([]docker_client.APIImages, error)
+	ListNetworks() ([]docker_client.Network, error)
+	ListVolumes(docker_client.ListVolumesOptions) ([]docker_client.Volume, error)
 	AddEventListener(chan<- *docker_client.APIEvents) error
 	RemoveEventListener(chan *docker_client.APIEvents) error
 
@@ -78,43 +89,59 @@ type Client interface {
 	AttachToContainerNonBlocking(docker_client.AttachToContainerOptions) (docker_client.CloseWaiter, error)
 	CreateExec(docker_client.CreateExecOptions) (*docker_client.Exec, error)
 	StartExecNonBlocking(string, docker_client.StartExecOptions) (docker_client.CloseWaiter, error)
+	Logs(docker_client.LogsOptions) error
+	DownloadFromContainer(string, docker_client.DownloadFromContainerOptions) error
+	UploadToContainer(string, docker_client.UploadToContainerOptions) error
 }
 
-func newDockerClient(endpoint string) (Client, error) {
-	return docker_client.NewClient(endpoint)
-}
-
-// NewRegistry returns a usable Registry. Don't forget to Stop it.
-func NewRegistry(interval time.Duration, pipes controls.PipeClient, collectStats bool, hostID string) (Registry, error) {
+// NewRegistry returns a usable Registry for the daemon at endpoint. Don't
+// forget to Stop it. If cgroups is given, container stats are read with it
+// rather than from the docker stats stream. The containers are on host hostID,
+// unless the endpoint says otherwise.
+func NewRegistry(endpoint Endpoint, interval time.Duration, pipes controls.PipeClient, collectStats bool, hostID string, cgroups *CgroupCollector) (Registry, error) {
 	client, err := NewDockerClientStub(endpoint)
 	if err != nil {
 		return nil, err
 	}
+	if endpoint.Remote() {
+		// The cgroups of a daemon on another host can't be read from here
+		hostID, cgroups = endpoint.HostID, nil
+	}
 
 	r := &registry{
 		containers:      radix.New(),
 		containersByPID: map[int]Container{},
 		images:          map[string]*docker_client.APIImages{},
 
+		endpoint:     endpoint,
 		client:       client,
 		pipes:        pipes,
 		interval:     interval,
 		collectStats: collectStats,
+		cgroups:      cgroups,
 		hostID:       hostID,
 		quit:         make(chan chan struct{}),
+		sampleQuit:   make(chan struct{}),
 	}
 
 	r.registerControls()
 	go r.loop()
+	if r.collectStats && r.cgroups != nil {
+		go r.sampleCgroups()
+	}
 	return r, nil
 }
 
 // Stop stops the Docker registry's event subscriber.
+// It is safe to call more than once.
 func (r *registry) Stop() {
-	r.deregisterControls()
-	ch := make(chan struct{})
-	r.quit <- ch
-	<-ch
+	r.stopOnce.Do(func() {
+		r.deregisterControls()
+		close(r.sampleQuit)
+		ch := make(chan struct{})
+		r.quit <- ch
+		<-ch
+	})
 }
 
 // WatchContainerUpdates registers a callback to be called
@@ -135,7 +162,13 @@ func (r *registry) loop() {
 
 		// Sleep here so we don't hammer the
 		// logs if docker is down
-		time.Sleep(r.interval)
+		select {
+		case <-time.After(r.interval):
+		case ch := <-r.quit:
+			r.stopGatheringStats()
+			close(ch)
+			return
+		}
 	}
 }
 
@@ -150,71 +183,112 @@ func (r *registry) listenForEvents() bool {
 	// after listing but before listening for events.
 	events := make(chan *docker_client.APIEvents)
 	if err := r.client.AddEventListener(events); err != nil {
-		log.Errorf("docker registry: %s", err)
+		log.Errorf("docker registry %s: %s", r.endpoint, err)
 		return true
 	}
 	defer func() {
 		if err := r.client.RemoveEventListener(events); err != nil {
-			log.Errorf("docker registry: %s", err)
+			log.Errorf("docker registry %s: %s", r.endpoint, err)
 		}
 	}()
 
 	if err := r.updateContainers(); err != nil {
-		log.Errorf("docker registry: %s", err)
+		log.Errorf("docker registry %s: %s", r.endpoint, err)
 		return true
 	}
 
 	if err := r.updateImages(); err != nil {
-		log.Errorf("docker registry: %s", err)
+		log.Errorf("docker registry %s: %s", r.endpoint, err)
 		return true
 	}
+	r.updateNetworksAndVolumes()
 
 	otherUpdates := time.Tick(r.interval)
 	for {
 		select {
 		case event, ok := <-events:
 			if !ok {
-				log.Errorf("docker registry: event listener unexpectedly disconnected")
+				log.Errorf("docker registry %s: event listener unexpectedly disconnected", r.endpoint)
 				return true
 			}
 			r.handleEvent(event)
 
 		case <-otherUpdates:
 			if err := r.updateImages(); err != nil {
-				log.Errorf("docker registry: %s", err)
+				log.Errorf("docker registry %s: %s", r.endpoint, err)
 				return true
 			}
+			r.updateNetworksAndVolumes()
 
 		case ch := <-r.quit:
-			r.Lock()
-			defer r.Unlock()
-
-			if r.collectStats {
-				r.containers.Walk(func(_ string, c interface{}) bool {
-					c.(Container).StopGatheringStats()
-					return false
-				})
-			}
+			r.stopGatheringStats()
 			close(ch)
 			return false
 		}
 	}
 }
 
-func (r *registry) reset() {
+// sampleCgroups samples the cgroups of the containers whose stats are read
+// from them every cgroupSampleInterval, until the registry is stopped.
+func (r *registry) sampleCgroups() {
+	ticker := time.NewTicker(cgroupSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.sampleQuit:
+			return
+		}
+
+		r.RLock()
+		containers := make([]DockerContainer, 0, r.containers.Len())
+		r.containers.Walk(func(_ string, c interface{}) bool {
+			containers = append(containers, c.(DockerContainer))
+			return false
+		})
+		r.RUnlock()
+
+		for _, c := range containers {
+			switch err := c.SampleCgroups(); err {
+			case nil:
+			case ErrNoProcess, ErrCgroupNotFound:
+				// The container stopped; its die event will follow.
+				log.Debugf("docker registry %s: stopped sampling cgroups of %s: %v", r.endpoint, c.ID(), err)
+				c.StopGatheringStats()
+			default:
+				log.Warnf("docker registry %s: sampling cgroups of %s: %v", r.endpoint, c.ID(), err)
+			}
+		}
+	}
+}
+
+func (r *registry) stopGatheringStats() {
 	r.Lock()
 	defer r.Unlock()
+	r.stopGatheringStatsLocked()
+}
 
+// stopGatheringStatsLocked must be called with the registry locked.
+func (r *registry) stopGatheringStatsLocked() {
 	if r.collectStats {
 		r.containers.Walk(func(_ string, c interface{}) bool {
-			c.(Container).StopGatheringStats()
+			c.(DockerContainer).StopGatheringStats()
 			return false
 		})
 	}
+}
+
+func (r *registry) reset() {
+	r.Lock()
+	defer r.Unlock()
+
+	r.stopGatheringStatsLocked()
 
 	r.containers = radix.New()
 	r.containersByPID = map[int]Container{}
 	r.images = map[string]*docker_client.APIImages{}
+	r.networks = nil
+	r.volumes = nil
 }
 
 func (r *registry) updateContainers() error {
@@ -224,7 +298,7 @@ func (r *registry) updateContainers() error {
 	}
 
 	for _, apiContainer := range apiContainers {
-		r.updateContainerState(apiContainer.ID, nil)
+		r.updateContainerState(apiContainer.ID, nil, nil)
 	}
 
 	return nil
@@ -247,10 +321,28 @@ func (r *registry) updateImages() error {
 	return nil
 }
 
+// updateNetworksAndVolumes refreshes the networks and volumes. Failures are
+// only logged, as older daemons don't have the APIs.
+func (r *registry) updateNetworksAndVolumes() {
+	networks, err := r.client.ListNetworks()
+	if err != nil {
+		log.Errorf("docker registry %s: listing networks: %s", r.endpoint, err)
+	}
+	volumes, err := r.client.ListVolumes(docker_client.ListVolumesOptions{})
+	if err != nil {
+		log.Errorf("docker registry %s: listing volumes: %s", r.endpoint, err)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.networks = networks
+	r.volumes = volumes
+}
+
 func (r *registry) handleEvent(event *docker_client.APIEvents) {
 	switch event.Status {
-	case CreateEvent, RenameEvent, StartEvent, DieEvent, DestroyEvent, PauseEvent, UnpauseEvent:
-		r.updateContainerState(event.ID, stateAfterEvent(event.Status))
+	case CreateEvent, RenameEvent, StartEvent, DieEvent, DestroyEvent, PauseEvent, UnpauseEvent, OOMEvent:
+		r.updateContainerState(event.ID, stateAfterEvent(event.Status), event)
 	}
 }
 
@@ -263,7 +355,10 @@ func stateAfterEvent(event string) *string {
 	}
 }
 
-func (r *registry) updateContainerState(containerID string, intendedState *string) {
+// updateContainerState inspects a container and updates our view of it. If
+// the update is due to an event, the event is recorded in the container's
+// history.
+func (r *registry) updateContainerState(containerID string, intendedState *string, event *docker_client.APIEvents) {
 	r.Lock()
 	defer r.Unlock()
 
@@ -280,7 +375,7 @@ func (r *registry) updateContainerState(containerID string, intendedState *strin
 		if !ok {
 			return
 		}
-		container := c.(Container)
+		container := c.(DockerContainer)
 
 		r.containers.Delete(containerID)
 		delete(r.containersByPID, container.PID())
@@ -303,16 +398,19 @@ func (r *registry) updateContainerState(containerID string, intendedState *strin
 
 	// Container exists, ensure we have it
 	o, ok := r.containers.Get(containerID)
-	var c Container
+	var c DockerContainer
 	if !ok {
 		c = NewContainerStub(dockerContainer, r.hostID)
 		r.containers.Insert(containerID, c)
 	} else {
-		c = o.(Container)
+		c = o.(DockerContainer)
 		// potentially remove existing pid mapping.
 		delete(r.containersByPID, c.PID())
 		c.UpdateState(dockerContainer)
 	}
+	if event != nil {
+		c.AddEvent(makeContainerEvent(event, dockerContainer))
+	}
 
 	// Update PID index
 	if c.PID() > 1 {
@@ -330,7 +428,7 @@ func (r *registry) updateContainerState(containerID string, intendedState *strin
 	// And finally, ensure we gather stats for it
 	if r.collectStats {
 		if dockerContainer.State.Running {
-			if err := c.StartGatheringStats(); err != nil {
+			if err := r.startGatheringStats(c); err != nil {
 				log.Errorf("Error gather stats for container: %s", containerID)
 				return
 			}
@@ -340,6 +438,24 @@ func (r *registry) updateContainerState(containerID string, intendedState *strin
 	}
 }
 
+// startGatheringStats reads the stats of a running container from its
+// cgroups if we can find them, and from the stats API otherwise.
+func (r *registry) startGatheringStats(c DockerContainer) error {
+	if r.cgroups == nil {
+		return c.StartGatheringStats(r.endpoint)
+	}
+	switch err := r.cgroups.Check(c.PID()); err {
+	case nil:
+		return c.StartGatheringCgroupStats(r.cgroups)
+	case ErrCgroupNotFound:
+		log.Infof("docker registry %s: cgroups of %s not found, using the stats API", r.endpoint, c.ID())
+		return c.StartGatheringStats(r.endpoint)
+	default:
+		log.Debugf("docker registry %s: not gathering stats for %s: %v", r.endpoint, c.ID(), err)
+		return nil
+	}
+}
+
 // LockedPIDLookup runs f under a read lock, and gives f a function for
 // use doing pid->container lookups.
 func (r *registry) LockedPIDLookup(f func(func(int) Container)) {
@@ -404,6 +520,26 @@ func (r *registry) WalkImages(f func(*docker_client.APIImages)) {
 	})
 }
 
+// WalkNetworks runs f on every network the registry knows of.
+func (r *registry) WalkNetworks(f func(docker_client.Network)) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, network := range r.networks {
+		f(network)
+	}
+}
+
+// WalkVolumes runs f on every volume the registry knows of.
+func (r *registry) WalkVolumes(f func(docker_client.Volume)) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, volume := range r.volumes {
+		f(volume)
+	}
+}
+
 // ImageNameWithoutVersion splits the image name apart, returning the name
 // without the version, if possible
 func ImageNameWithoutVersion(name string) string {
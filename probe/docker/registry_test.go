@@ -1,7 +1,10 @@
 package docker_test
 
 import (
+	"archive/tar"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
 	"runtime"
 	"sort"
@@ -48,12 +51,22 @@ func (c *mockContainer) StateString() string {
 	return docker.StateRunning
 }
 
-func (c *mockContainer) StartGatheringStats() error {
+func (c *mockContainer) StartGatheringStats(docker.Endpoint) error {
+	return nil
+}
+
+func (c *mockContainer) StartGatheringCgroupStats(*docker.CgroupCollector) error {
+	return nil
+}
+
+func (c *mockContainer) SampleCgroups() error {
 	return nil
 }
 
 func (c *mockContainer) StopGatheringStats() {}
 
+func (c *mockContainer) AddEvent(docker.ContainerEvent) {}
+
 func (c *mockContainer) GetNode() report.Node {
 	return report.MakeNodeWith(report.MakeContainerNodeID(c.c.ID), map[string]string{
 		docker.ContainerID:   c.c.ID,
@@ -83,6 +96,8 @@ type mockDockerClient struct {
 	containers    map[string]*client.Container
 	apiImages     []client.APIImages
 	events        []chan<- *client.APIEvents
+	logsOptions   []client.LogsOptions
+	uploads       map[string]string
 }
 
 func (m *mockDockerClient) ListContainers(client.ListContainersOptions) ([]client.APIContainers, error) {
@@ -107,6 +122,14 @@ func (m *mockDockerClient) ListImages(client.ListImagesOptions) ([]client.APIIma
 	return m.apiImages, nil
 }
 
+func (m *mockDockerClient) ListNetworks() ([]client.Network, error) {
+	return []client.Network{apiNetwork1}, nil
+}
+
+func (m *mockDockerClient) ListVolumes(client.ListVolumesOptions) ([]client.Volume, error) {
+	return []client.Volume{apiVolume1}, nil
+}
+
 func (m *mockDockerClient) AddEventListener(events chan<- *client.APIEvents) error {
 	m.Lock()
 	defer m.Unlock()
@@ -166,6 +189,51 @@ func (m *mockDockerClient) StartExecNonBlocking(string, client.StartExecOptions)
 	return mockCloseWaiter{}, nil
 }
 
+func (m *mockDockerClient) Logs(opts client.LogsOptions) error {
+	m.Lock()
+	m.logsOptions = append(m.logsOptions, opts)
+	m.Unlock()
+	if _, err := io.WriteString(opts.OutputStream, "hello\nworld\n"); err != nil {
+		return err
+	}
+	_, err := io.WriteString(opts.ErrorStream, "oops\n")
+	return err
+}
+
+func (m *mockDockerClient) DownloadFromContainer(_ string, opts client.DownloadFromContainerOptions) error {
+	tw := tar.NewWriter(opts.OutputStream)
+	hosts := "127.0.0.1 localhost\n"
+	if err := tw.WriteHeader(&tar.Header{Name: "hosts", Mode: 0644, Size: int64(len(hosts))}); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(tw, hosts); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func (m *mockDockerClient) UploadToContainer(_ string, opts client.UploadToContainerOptions) error {
+	tr := tar.NewReader(opts.InputStream)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		contents, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		m.Lock()
+		if m.uploads == nil {
+			m.uploads = map[string]string{}
+		}
+		m.uploads[opts.Path+"/"+hdr.Name] = string(contents)
+		m.Unlock()
+	}
+}
+
 func (m *mockDockerClient) send(event *client.APIEvents) {
 	m.RLock()
 	defer m.RUnlock()
@@ -231,6 +299,8 @@ var (
 	apiContainer1       = client.APIContainers{ID: "ping"}
 	apiContainer2       = client.APIContainers{ID: "wiff"}
 	renamedAPIContainer = client.APIContainers{ID: "renamed"}
+	apiNetwork1         = client.Network{ID: "net1", Name: "frontend", Driver: "overlay", Scope: "swarm"}
+	apiVolume1          = client.Volume{Name: "data", Driver: "local", Mountpoint: "/var/lib/docker/volumes/data/_data"}
 	apiImage1           = client.APIImages{
 		ID:       "baz",
 		RepoTags: []string{"bang", "not-chosen"},
@@ -253,11 +323,11 @@ func setupStubs(mdc *mockDockerClient, f func()) {
 	oldDockerClient, oldNewContainer := docker.NewDockerClientStub, docker.NewContainerStub
 	defer func() { docker.NewDockerClientStub, docker.NewContainerStub = oldDockerClient, oldNewContainer }()
 
-	docker.NewDockerClientStub = func(endpoint string) (docker.Client, error) {
+	docker.NewDockerClientStub = func(docker.Endpoint) (docker.Client, error) {
 		return mdc, nil
 	}
 
-	docker.NewContainerStub = func(c *client.Container, _ string) docker.Container {
+	docker.NewContainerStub = func(c *client.Container, _ string) docker.DockerContainer {
 		return &mockContainer{c}
 	}
 
@@ -290,7 +360,7 @@ func allImages(r docker.Registry) []*client.APIImages {
 func TestRegistry(t *testing.T) {
 	mdc := newMockClient()
 	setupStubs(mdc, func() {
-		registry, _ := docker.NewRegistry(10*time.Second, nil, true, "")
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, true, "", nil)
 		defer registry.Stop()
 		runtime.Gosched()
 
@@ -313,7 +383,7 @@ func TestRegistry(t *testing.T) {
 func TestLookupByPID(t *testing.T) {
 	mdc := newMockClient()
 	setupStubs(mdc, func() {
-		registry, _ := docker.NewRegistry(10*time.Second, nil, true, "")
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, true, "", nil)
 		defer registry.Stop()
 
 		want := docker.Container(&mockContainer{container1})
@@ -330,7 +400,7 @@ func TestLookupByPID(t *testing.T) {
 func TestRegistryEvents(t *testing.T) {
 	mdc := newMockClient()
 	setupStubs(mdc, func() {
-		registry, _ := docker.NewRegistry(10*time.Second, nil, true, "")
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, true, "", nil)
 		defer registry.Stop()
 		runtime.Gosched()
 
@@ -404,7 +474,7 @@ func TestRegistryDelete(t *testing.T) {
 
 	mdc := newMockClient()
 	setupStubs(mdc, func() {
-		registry, _ := docker.NewRegistry(10*time.Second, nil, true, "")
+		registry, _ := docker.NewRegistry(docker.Endpoint{Address: docker.DefaultEndpoint}, 10*time.Second, nil, true, "", nil)
 		defer registry.Stop()
 		runtime.Gosched()
 
@@ -7,6 +7,7 @@ import (
 	docker_client "github.com/fsouza/go-dockerclient"
 
 	"$GITHUB_URI/probe"
+	"$GITHUB_URI/probe/host"
 	"$GITHUB_URI/report"
 )
 
@@ -29,28 +30,59 @@ var (
 		ContainerIPs:          {ID: ContainerIPs, Label: "IPs", From: report.FromSets, Priority: 14},
 		ContainerPorts:        {ID: ContainerPorts, Label: "Ports", From: report.FromSets, Priority: 15},
 		ContainerCreated:      {ID: ContainerCreated, Label: "Created", From: report.FromLatest, Priority: 16},
+		ContainerImageDigest:  {ID: ContainerImageDigest, Label: "Image digest", From: report.FromLatest, Truncate: 19, Priority: 17},
 	}
 
 	ContainerMetricTemplates = report.MetricTemplates{
-		CPUTotalUsage: {ID: CPUTotalUsage, Label: "CPU", Format: report.PercentFormat, Priority: 1},
-		MemoryUsage:   {ID: MemoryUsage, Label: "Memory", Format: report.FilesizeFormat, Priority: 2},
+		CPUTotalUsage:    {ID: CPUTotalUsage, Label: "CPU", Format: report.PercentFormat, Priority: 1},
+		MemoryUsage:      {ID: MemoryUsage, Label: "Memory", Format: report.FilesizeFormat, Priority: 2},
+		BlockIOReadRate:  {ID: BlockIOReadRate, Label: "Disk read/s", Format: report.FilesizeFormat, Priority: 3},
+		BlockIOWriteRate: {ID: BlockIOWriteRate, Label: "Disk write/s", Format: report.FilesizeFormat, Priority: 4},
+		PidsCurrent:      {ID: PidsCurrent, Label: "PIDs", Format: report.IntegerFormat, Priority: 5},
 	}
 
 	ContainerImageMetadataTemplates = report.MetadataTemplates{
 		ImageID:          {ID: ImageID, Label: "Image ID", From: report.FromLatest, Truncate: 12, Priority: 1},
 		report.Container: {ID: report.Container, Label: "# Containers", From: report.FromCounters, Datatype: "number", Priority: 2},
+		ImageDigest:      {ID: ImageDigest, Label: "Digest", From: report.FromLatest, Truncate: 19, Priority: 3},
+		ImageTagDigests:  {ID: ImageTagDigests, Label: "Digests of tag", From: report.FromLatest, Datatype: "number", Priority: 4},
+		ImageRepoDigests: {ID: ImageRepoDigests, Label: "Repo digests", From: report.FromSets, Priority: 5},
+	}
+
+	NetworkMetadataTemplates = report.MetadataTemplates{
+		NetworkName:      {ID: NetworkName, Label: "Name", From: report.FromLatest, Priority: 1},
+		NetworkDriver:    {ID: NetworkDriver, Label: "Driver", From: report.FromLatest, Priority: 2},
+		NetworkScope:     {ID: NetworkScope, Label: "Scope", From: report.FromLatest, Priority: 3},
+		NetworkInternal:  {ID: NetworkInternal, Label: "Internal", From: report.FromLatest, Priority: 4},
+		report.Container: {ID: report.Container, Label: "# Containers", From: report.FromCounters, Datatype: "number", Priority: 5},
+	}
+
+	VolumeMetadataTemplates = report.MetadataTemplates{
+		VolumeName:       {ID: VolumeName, Label: "Name", From: report.FromLatest, Priority: 1},
+		VolumeDriver:     {ID: VolumeDriver, Label: "Driver", From: report.FromLatest, Priority: 2},
+		VolumeMountpoint: {ID: VolumeMountpoint, Label: "Mount point", From: report.FromLatest, Priority: 3},
+		report.Container: {ID: report.Container, Label: "# Containers", From: report.FromCounters, Datatype: "number", Priority: 4},
 	}
 
 	ContainerTableTemplates = report.TableTemplates{
-		LabelPrefix: {ID: LabelPrefix, Label: "Docker Labels", Prefix: LabelPrefix},
-		EnvPrefix:   {ID: EnvPrefix, Label: "Environment Variables", Prefix: EnvPrefix},
+		LabelPrefix:     {ID: LabelPrefix, Label: "Docker Labels", Prefix: LabelPrefix},
+		EnvPrefix:       {ID: EnvPrefix, Label: "Environment Variables", Prefix: EnvPrefix},
+		NetworkIPPrefix: {ID: NetworkIPPrefix, Label: "Networks", Prefix: NetworkIPPrefix},
+		EventPrefix:     {ID: EventPrefix, Label: "Events", Prefix: EventPrefix},
 	}
 
 	ContainerImageTableTemplates = report.TableTemplates{
 		ImageLabelPrefix: {ID: ImageLabelPrefix, Label: "Docker Labels", Prefix: ImageLabelPrefix},
+		ImageDriftPrefix: {ID: ImageDriftPrefix, Label: "Tag Drift", Prefix: ImageDriftPrefix},
 	}
 
 	ContainerControls = []report.Control{
+		{
+			ID:    ContainerLogs,
+			Human: "Get logs",
+			Icon:  "fa-desktop",
+			Rank:  0,
+		},
 		{
 			ID:    AttachContainer,
 			Human: "Attach",
@@ -99,15 +131,31 @@ var (
 			Icon:  "fa-trash-o",
 			Rank:  8,
 		},
+		{
+			ID:    DownloadFiles,
+			Human: "Download files",
+			Icon:  "fa-download",
+			Rank:  9,
+		},
+		{
+			ID:    UploadFiles,
+			Human: "Upload files",
+			Icon:  "fa-upload",
+			Rank:  10,
+		},
 	}
 )
 
-// Reporter generate Reports containing Container and ContainerImage topologies
+// Reporter generate Reports containing Container, ContainerImage,
+// DockerNetwork, DockerVolume, ComposeProject and ComposeService topologies
 type Reporter struct {
 	registry Registry
 	hostID   string
 	probeID  string
 	probe    *probe.Probe
+
+	// remote is set if the daemon isn't on the probe's host
+	remote bool
 }
 
 // NewReporter makes a new Reporter
@@ -122,6 +170,15 @@ func NewReporter(registry Registry, hostID string, probeID string, probe *probe.
 	return reporter
 }
 
+// NewRemoteReporter makes a new Reporter for the registry of a daemon on
+// host hostID, which isn't the probe's host. Its containers are parented to
+// that host, which is reported too, as no probe runs on it.
+func NewRemoteReporter(registry Registry, hostID string, probeID string, probe *probe.Probe) *Reporter {
+	reporter := NewReporter(registry, hostID, probeID, probe)
+	reporter.remote = true
+	return reporter
+}
+
 // Name of this reporter, for metrics gathering
 func (Reporter) Name() string { return "Docker" }
 
@@ -130,11 +187,12 @@ func (r *Reporter) ContainerUpdated(n report.Node) {
 	// Publish a 'short cut' report container just this container
 	rpt := report.MakeReport()
 	rpt.Shortcut = true
-	rpt.Container.AddNode(n)
+	rpt.Container.AddNode(r.withHost(n))
 	r.probe.Publish(rpt)
 }
 
-// Report generates a Report containing Container and ContainerImage topologies
+// Report generates a Report containing Container, ContainerImage,
+// DockerNetwork, DockerVolume, ComposeProject and ComposeService topologies
 func (r *Reporter) Report() (report.Report, error) {
 	localAddrs, err := report.LocalAddresses()
 	if err != nil {
@@ -142,11 +200,38 @@ func (r *Reporter) Report() (report.Report, error) {
 	}
 
 	result := report.MakeReport()
-	result.Container = result.Container.Merge(r.containerTopology(localAddrs))
+	containers := r.containerTopology(localAddrs)
+	projects, services := r.composeTopologies(containers)
+	result.Container = result.Container.Merge(containers)
+	result.ComposeProject = result.ComposeProject.Merge(projects)
+	result.ComposeService = result.ComposeService.Merge(services)
 	result.ContainerImage = result.ContainerImage.Merge(r.containerImageTopology())
+	result.DockerNetwork = result.DockerNetwork.Merge(r.networkTopology())
+	result.DockerVolume = result.DockerVolume.Merge(r.volumeTopology())
+	if r.remote {
+		for _, topology := range []report.Topology{result.Container, result.ContainerImage} {
+			for _, node := range topology.Nodes {
+				topology.AddNode(r.withHost(node))
+			}
+		}
+		result.Host.AddNode(r.withHost(report.MakeNodeWith(report.MakeHostNodeID(r.hostID), map[string]string{
+			host.HostName: r.hostID,
+		})))
+	}
 	return result, nil
 }
 
+// withHost tags a node of a remote daemon with its host, which the host
+// tagger leaves be.
+func (r *Reporter) withHost(n report.Node) report.Node {
+	if !r.remote {
+		return n
+	}
+	hostNodeID := report.MakeHostNodeID(r.hostID)
+	return n.WithLatests(map[string]string{report.HostNodeID: hostNodeID}).
+		WithParents(report.EmptySets.Add(report.Host, report.MakeStringSet(hostNodeID)))
+}
+
 func getLocalIPs() ([]string, error) {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
@@ -169,10 +254,25 @@ func (r *Reporter) containerTopology(localAddrs []net.IP) report.Topology {
 		WithTableTemplates(ContainerTableTemplates)
 	result.Controls.AddControls(ContainerControls)
 
+	// Containers not pinned to a digest run whatever their image's name
+	// resolved to on this host.
+	imageDigests := map[string]string{}
+	r.registry.WalkImages(func(image *docker_client.APIImages) {
+		if digest := imageDigest(image); digest != "" {
+			imageDigests[trimImageID(image.ID)] = digest
+		}
+	})
+
 	metadata := map[string]string{report.ControlProbeID: r.probeID}
 	nodes := []report.Node{}
 	r.registry.WalkContainers(func(c Container) {
-		nodes = append(nodes, c.GetNode().WithLatests(metadata))
+		node := c.GetNode().WithLatests(metadata)
+		if _, ok := node.Latest.Lookup(ContainerImageDigest); !ok {
+			if digest, ok := imageDigests[c.Image()]; ok {
+				node = node.WithLatests(map[string]string{ContainerImageDigest: digest})
+			}
+		}
+		nodes = append(nodes, node)
 	})
 
 	// Copy the IP addresses from other containers where they share network
@@ -233,6 +333,12 @@ func (r *Reporter) containerImageTopology() report.Topology {
 		if len(image.RepoTags) > 0 {
 			node = node.WithLatests(map[string]string{ImageName: image.RepoTags[0]})
 		}
+		if len(image.RepoDigests) > 0 {
+			node = node.WithSets(report.EmptySets.Add(ImageRepoDigests, report.MakeStringSet(image.RepoDigests...)))
+		}
+		if digest := imageDigest(image); digest != "" {
+			node = node.WithLatests(map[string]string{ImageDigest: digest})
+		}
 
 		result.AddNode(node)
 	})
@@ -240,6 +346,14 @@ func (r *Reporter) containerImageTopology() report.Topology {
 	return result
 }
 
+// imageDigest returns the digest the name of an image resolved to.
+func imageDigest(image *docker_client.APIImages) string {
+	if len(image.RepoTags) == 0 {
+		return repoDigest(image.RepoDigests, "")
+	}
+	return repoDigest(image.RepoDigests, image.RepoTags[0])
+}
+
 // Docker sometimes prefixes ids with a "type" annotation, but it renders a bit
 // ugly and isn't necessary, so we should strip it off
 func trimImageID(id string) string {
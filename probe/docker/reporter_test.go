@@ -12,6 +12,8 @@ import (
 type mockRegistry struct {
 	containersByPID map[int]docker.Container
 	images          map[string]*client.APIImages
+	networks        []client.Network
+	volumes         []client.Volume
 }
 
 func (r *mockRegistry) Stop() {}
@@ -34,6 +36,18 @@ func (r *mockRegistry) WalkImages(f func(*client.APIImages)) {
 	}
 }
 
+func (r *mockRegistry) WalkNetworks(f func(client.Network)) {
+	for _, n := range r.networks {
+		f(n)
+	}
+}
+
+func (r *mockRegistry) WalkVolumes(f func(client.Volume)) {
+	for _, v := range r.volumes {
+		f(v)
+	}
+}
+
 func (r *mockRegistry) WatchContainerUpdates(_ docker.ContainerUpdateWatcher) {}
 
 func (r *mockRegistry) GetContainer(_ string) (docker.Container, bool) { return nil, false }
@@ -48,6 +62,11 @@ var (
 		images: map[string]*client.APIImages{
 			"baz": &apiImage1,
 		},
+		networks: []client.Network{
+			apiNetwork1,
+			{ID: "bridge1", Name: "bridge", Driver: "bridge", Scope: "local"},
+		},
+		volumes: []client.Volume{apiVolume1},
 	}
 )
 
@@ -114,3 +133,45 @@ func TestReporter(t *testing.T) {
 		}
 	}
 }
+
+func TestReporterNetworksAndVolumes(t *testing.T) {
+	rpt, err := docker.NewReporter(mockRegistryInstance, "host1", "", nil).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Predefined networks shouldn't be reported
+	if want, have := 1, len(rpt.DockerNetwork.Nodes); want != have {
+		t.Fatalf("Expected %d network, got %d", want, have)
+	}
+	networkNodeID := report.MakeDockerNetworkNodeID("net1")
+	network, ok := rpt.DockerNetwork.Nodes[networkNodeID]
+	if !ok {
+		t.Fatalf("Expected report to have network %q", networkNodeID)
+	}
+	for k, want := range map[string]string{
+		docker.NetworkName:   "frontend",
+		docker.NetworkDriver: "overlay",
+		docker.NetworkScope:  "swarm",
+	} {
+		if have, ok := network.Latest.Lookup(k); !ok || have != want {
+			t.Errorf("Expected network latest %q: %q, got %q", k, want, have)
+		}
+	}
+	// Swarm networks span hosts
+	if hosts, ok := network.Parents.Lookup(report.Host); ok {
+		t.Errorf("Expected network to have no host parents, got %v", hosts)
+	}
+
+	volumeNodeID := docker.MakeVolumeNodeID("host1", "data")
+	volume, ok := rpt.DockerVolume.Nodes[volumeNodeID]
+	if !ok {
+		t.Fatalf("Expected report to have volume %q", volumeNodeID)
+	}
+	if have, ok := volume.Latest.Lookup(docker.VolumeMountpoint); !ok || have != apiVolume1.Mountpoint {
+		t.Errorf("Expected volume mount point %q, got %q", apiVolume1.Mountpoint, have)
+	}
+	if hosts, ok := volume.Parents.Lookup(report.Host); !ok || !hosts.Contains(report.MakeHostNodeID("host1")) {
+		t.Errorf("Expected volume to have host parent, got %v", hosts)
+	}
+}
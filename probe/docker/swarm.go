@@ -0,0 +1,360 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/report"
+)
+
+// Control IDs used by the swarm integration.
+const (
+	ScaleUpService   = "docker_swarm_scale_up"
+	ScaleDownService = "docker_swarm_scale_down"
+)
+
+// These constants are keys used in node metadata
+const (
+	SwarmServiceID   = "docker_swarm_service_id"
+	SwarmServiceName = "docker_swarm_service_name"
+	SwarmServiceMode = "docker_swarm_service_mode"
+	SwarmImage       = "docker_swarm_image"
+	SwarmCreated     = "docker_swarm_created"
+	DesiredReplicas  = "docker_swarm_desired_replicas"
+
+	SwarmTaskID           = "docker_swarm_task_id"
+	SwarmTaskName         = "docker_swarm_task_name"
+	SwarmTaskState        = "docker_swarm_task_state"
+	SwarmTaskDesiredState = "docker_swarm_task_desired_state"
+	SwarmTaskMessage      = "docker_swarm_task_message"
+
+	SwarmNodeID           = "docker_swarm_node_id"
+	SwarmNodeHostname     = "docker_swarm_node_hostname"
+	SwarmNodeRole         = "docker_swarm_node_role"
+	SwarmNodeAvailability = "docker_swarm_node_availability"
+	SwarmNodeState        = "docker_swarm_node_state"
+	SwarmNodeAddr         = "docker_swarm_node_addr"
+	SwarmNodeManager      = "docker_swarm_node_manager"
+	SwarmNodeEngine       = "docker_swarm_node_engine_version"
+)
+
+// Labels the swarm puts on the containers of its tasks.
+const (
+	swarmServiceIDLabel = "com.docker.swarm.service.id"
+	swarmTaskIDLabel    = "com.docker.swarm.task.id"
+)
+
+const (
+	swarmModeReplicated = "replicated"
+	swarmModeGlobal     = "global"
+	swarmStateActive    = "active"
+	swarmStateRunning   = "running"
+)
+
+// Exposed for testing
+var (
+	SwarmServiceMetadataTemplates = report.MetadataTemplates{
+		SwarmServiceID:   {ID: SwarmServiceID, Label: "ID", From: report.FromLatest, Truncate: 12, Priority: 1},
+		SwarmServiceMode: {ID: SwarmServiceMode, Label: "Mode", From: report.FromLatest, Priority: 2},
+		SwarmImage:       {ID: SwarmImage, Label: "Image", From: report.FromLatest, Priority: 3},
+		SwarmCreated:     {ID: SwarmCreated, Label: "Created", From: report.FromLatest, Priority: 4},
+		DesiredReplicas:  {ID: DesiredReplicas, Label: "Desired Replicas", From: report.FromLatest, Datatype: "number", Priority: 5},
+		report.Container: {ID: report.Container, Label: "# Containers", From: report.FromCounters, Datatype: "number", Priority: 6},
+	}
+
+	SwarmTaskMetadataTemplates = report.MetadataTemplates{
+		SwarmTaskID:           {ID: SwarmTaskID, Label: "ID", From: report.FromLatest, Truncate: 12, Priority: 1},
+		SwarmTaskState:        {ID: SwarmTaskState, Label: "State", From: report.FromLatest, Priority: 2},
+		SwarmTaskDesiredState: {ID: SwarmTaskDesiredState, Label: "Desired State", From: report.FromLatest, Priority: 3},
+		SwarmTaskMessage:      {ID: SwarmTaskMessage, Label: "Message", From: report.FromLatest, Priority: 4},
+		SwarmImage:            {ID: SwarmImage, Label: "Image", From: report.FromLatest, Priority: 5},
+	}
+
+	SwarmNodeMetadataTemplates = report.MetadataTemplates{
+		SwarmNodeID:           {ID: SwarmNodeID, Label: "ID", From: report.FromLatest, Truncate: 12, Priority: 1},
+		SwarmNodeRole:         {ID: SwarmNodeRole, Label: "Role", From: report.FromLatest, Priority: 2},
+		SwarmNodeManager:      {ID: SwarmNodeManager, Label: "Manager Status", From: report.FromLatest, Priority: 3},
+		SwarmNodeAvailability: {ID: SwarmNodeAvailability, Label: "Availability", From: report.FromLatest, Priority: 4},
+		SwarmNodeState:        {ID: SwarmNodeState, Label: "State", From: report.FromLatest, Priority: 5},
+		SwarmNodeAddr:         {ID: SwarmNodeAddr, Label: "Address", From: report.FromLatest, Priority: 6},
+		SwarmNodeEngine:       {ID: SwarmNodeEngine, Label: "Engine Version", From: report.FromLatest, Priority: 7},
+		report.SwarmTask:      {ID: report.SwarmTask, Label: "# Tasks", From: report.FromCounters, Datatype: "number", Priority: 8},
+	}
+
+	SwarmScalingControls = []report.Control{
+		{
+			ID:    ScaleDownService,
+			Human: "Scale Down",
+			Icon:  "fa-minus",
+			Rank:  0,
+		},
+		{
+			ID:    ScaleUpService,
+			Human: "Scale Up",
+			Icon:  "fa-plus",
+			Rank:  1,
+		},
+	}
+)
+
+// SwarmReporter generates Reports containing the SwarmService, SwarmTask
+// and SwarmNode topologies of a daemon. Only managers can list these, so the
+// reports of daemons which are workers, aren't in a swarm or predate swarm
+// mode are empty.
+type SwarmReporter struct {
+	client  SwarmClient
+	probeID string
+
+	sync.RWMutex
+	services map[string]struct{} // IDs of the services last reported
+}
+
+// NewSwarmReporter makes a new SwarmReporter. Don't forget to Stop it.
+func NewSwarmReporter(client SwarmClient, probeID string) *SwarmReporter {
+	reporter := &SwarmReporter{
+		client:   client,
+		probeID:  probeID,
+		services: map[string]struct{}{},
+	}
+	reporter.registerControls()
+	return reporter
+}
+
+// Stop deregisters the reporter's controls.
+func (r *SwarmReporter) Stop() {
+	r.deregisterControls()
+}
+
+// Name of this reporter, for metrics gathering
+func (*SwarmReporter) Name() string { return "Swarm" }
+
+// Report generates a Report containing the SwarmService, SwarmTask and
+// SwarmNode topologies, if the daemon is a swarm manager.
+func (r *SwarmReporter) Report() (report.Report, error) {
+	result := report.MakeReport()
+	result.SwarmService = result.SwarmService.WithMetadataTemplates(SwarmServiceMetadataTemplates)
+	result.SwarmService.Controls.AddControls(SwarmScalingControls)
+	result.SwarmTask = result.SwarmTask.WithMetadataTemplates(SwarmTaskMetadataTemplates)
+	result.SwarmNode = result.SwarmNode.WithMetadataTemplates(SwarmNodeMetadataTemplates)
+
+	// Daemons without swarm mode reject the request, and those whose
+	// socket is gone fail it; neither has anything to report.
+	info, err := r.client.Info()
+	if err != nil {
+		log.Debugf("Swarm: not reporting: %v", err)
+		r.setServices(nil)
+		return result, nil
+	}
+	if info.LocalNodeState != swarmStateActive || !info.ControlAvailable {
+		r.setServices(nil)
+		return result, nil
+	}
+
+	services, err := r.client.ListServices()
+	if err != nil {
+		return result, err
+	}
+	tasks, err := r.client.ListTasks()
+	if err != nil {
+		return result, err
+	}
+	nodes, err := r.client.ListNodes()
+	if err != nil {
+		return result, err
+	}
+
+	serviceNames := map[string]string{}
+	for _, service := range services {
+		serviceNames[service.ID] = service.Spec.Name
+		result.SwarmService.AddNode(r.serviceNode(service))
+	}
+	for _, task := range tasks {
+		// The swarm keeps the tasks it has replaced around for a while;
+		// those aren't worth showing.
+		if task.DesiredState != swarmStateRunning {
+			continue
+		}
+		result.SwarmTask.AddNode(taskNode(task, serviceNames[task.ServiceID]))
+	}
+	for _, node := range nodes {
+		result.SwarmNode.AddNode(swarmNodeNode(node))
+	}
+	r.setServices(services)
+	return result, nil
+}
+
+func (r *SwarmReporter) setServices(services []SwarmService) {
+	ids := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		ids[service.ID] = struct{}{}
+	}
+	r.Lock()
+	r.services = ids
+	r.Unlock()
+}
+
+func (r *SwarmReporter) hasService(serviceID string) bool {
+	r.RLock()
+	defer r.RUnlock()
+	_, ok := r.services[serviceID]
+	return ok
+}
+
+func (r *SwarmReporter) serviceNode(service SwarmService) report.Node {
+	latest := map[string]string{
+		SwarmServiceID:        service.ID,
+		SwarmServiceName:      service.Spec.Name,
+		SwarmImage:            service.Spec.TaskTemplate.ContainerSpec.Image,
+		SwarmCreated:          service.CreatedAt.Format(time.RFC822),
+		report.ControlProbeID: r.probeID,
+	}
+	node := report.MakeNode(report.MakeSwarmServiceNodeID(service.ID))
+	if replicated := service.Spec.Mode.Replicated; replicated != nil {
+		latest[SwarmServiceMode] = swarmModeReplicated
+		if replicated.Replicas != nil {
+			latest[DesiredReplicas] = strconv.FormatUint(*replicated.Replicas, 10)
+		}
+		node = node.WithControls(ScaleUpService, ScaleDownService)
+	} else if service.Spec.Mode.Global != nil {
+		latest[SwarmServiceMode] = swarmModeGlobal
+	}
+	return node.WithLatests(latest).AddTable(LabelPrefix, service.Spec.Labels)
+}
+
+func taskNode(task SwarmTask, serviceName string) report.Node {
+	// Tasks are named as the docker CLI names them: by their slot for
+	// replicated services and by their node for global ones.
+	name := fmt.Sprintf("%s.%d", serviceName, task.Slot)
+	if task.Slot == 0 {
+		name = serviceName + "." + task.NodeID
+	}
+	message := task.Status.Err
+	if message == "" {
+		message = task.Status.Message
+	}
+	latest := map[string]string{
+		SwarmTaskID:           task.ID,
+		SwarmTaskName:         name,
+		SwarmTaskState:        task.Status.State,
+		SwarmTaskDesiredState: task.DesiredState,
+		SwarmTaskMessage:      message,
+	}
+	if id := task.Status.ContainerStatus.ContainerID; id != "" {
+		latest[ContainerID] = id
+	}
+	parents := report.EmptySets.
+		Add(report.SwarmService, report.MakeStringSet(report.MakeSwarmServiceNodeID(task.ServiceID)))
+	if task.NodeID != "" {
+		parents = parents.Add(report.SwarmNode, report.MakeStringSet(report.MakeSwarmNodeNodeID(task.NodeID)))
+	}
+	return report.MakeNodeWith(report.MakeSwarmTaskNodeID(task.ID), latest).WithParents(parents)
+}
+
+func swarmNodeNode(node SwarmNode) report.Node {
+	manager := ""
+	if node.ManagerStatus != nil {
+		manager = node.ManagerStatus.Reachability
+		if node.ManagerStatus.Leader {
+			manager = "leader"
+		}
+	}
+	// Probes use the hostname as their host ID, unless told otherwise.
+	return report.MakeNodeWith(report.MakeSwarmNodeNodeID(node.ID), map[string]string{
+		SwarmNodeID:           node.ID,
+		SwarmNodeHostname:     node.Description.Hostname,
+		SwarmNodeRole:         node.Spec.Role,
+		SwarmNodeAvailability: node.Spec.Availability,
+		SwarmNodeState:        node.Status.State,
+		SwarmNodeAddr:         node.Status.Addr,
+		SwarmNodeManager:      manager,
+		SwarmNodeEngine:       node.Description.Engine.EngineVersion,
+	}).WithParents(report.EmptySets.
+		Add(report.Host, report.MakeStringSet(report.MakeHostNodeID(node.Description.Hostname))),
+	)
+}
+
+// swarmParents links the container of a swarm task to its task and service.
+func swarmParents(labels map[string]string) report.Sets {
+	parents := report.EmptySets
+	if id, ok := labels[swarmTaskIDLabel]; ok {
+		parents = parents.Add(report.SwarmTask, report.MakeStringSet(report.MakeSwarmTaskNodeID(id)))
+	}
+	if id, ok := labels[swarmServiceIDLabel]; ok {
+		parents = parents.Add(report.SwarmService, report.MakeStringSet(report.MakeSwarmServiceNodeID(id)))
+	}
+	return parents
+}
+
+// swarmReporters are the swarm reporters of every docker endpoint of the
+// probe. As with the registries, the controls are registered once for all of
+// them, and each request is routed to a daemon managing the service.
+var swarmReporters = struct {
+	sync.RWMutex
+	all []*SwarmReporter
+}{}
+
+// swarmReporterFor returns the reporter of a daemon managing a service. When
+// there is only one it is assumed to, so that it can report the service
+// missing itself.
+func swarmReporterFor(serviceID string) (*SwarmReporter, bool) {
+	swarmReporters.RLock()
+	defer swarmReporters.RUnlock()
+	for _, r := range swarmReporters.all {
+		if r.hasService(serviceID) {
+			return r, true
+		}
+	}
+	if len(swarmReporters.all) == 1 {
+		return swarmReporters.all[0], true
+	}
+	return nil, false
+}
+
+// routeToService makes a control handler running f with the client of the
+// daemon managing the service of the request.
+func routeToService(f func(SwarmClient, string) error) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		serviceID, ok := report.ParseSwarmServiceNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		r, ok := swarmReporterFor(serviceID)
+		if !ok {
+			return xfer.ResponseErrorf("Not found: %s", serviceID)
+		}
+		return xfer.ResponseError(f(r.client, serviceID))
+	}
+}
+
+func (r *SwarmReporter) registerControls() {
+	swarmReporters.Lock()
+	defer swarmReporters.Unlock()
+	swarmReporters.all = append(swarmReporters.all, r)
+	if len(swarmReporters.all) > 1 {
+		return
+	}
+	controls.Register(ScaleUpService, routeToService(SwarmClient.ScaleUp))
+	controls.Register(ScaleDownService, routeToService(SwarmClient.ScaleDown))
+}
+
+func (r *SwarmReporter) deregisterControls() {
+	swarmReporters.Lock()
+	defer swarmReporters.Unlock()
+	for i, other := range swarmReporters.all {
+		if other == r {
+			swarmReporters.all = append(swarmReporters.all[:i], swarmReporters.all[i+1:]...)
+			break
+		}
+	}
+	if len(swarmReporters.all) > 0 {
+		return
+	}
+	controls.Rm(ScaleUpService)
+	controls.Rm(ScaleDownService)
+}
@@ -0,0 +1,256 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// Swarm mode arrived with API version 1.24 (Docker 1.12)
+	swarmAPIVersion     = "v1.24"
+	swarmRequestTimeout = 10 * time.Second
+)
+
+// SwarmClient is the subset of the Docker swarm mode API used by the swarm
+// reporter, and is an interface for mocking.
+type SwarmClient interface {
+	Info() (SwarmInfo, error)
+	ListServices() ([]SwarmService, error)
+	ListTasks() ([]SwarmTask, error)
+	ListNodes() ([]SwarmNode, error)
+	ScaleUp(serviceID string) error
+	ScaleDown(serviceID string) error
+}
+
+// SwarmInfo is the swarm part of the daemon's info.
+type SwarmInfo struct {
+	NodeID           string
+	LocalNodeState   string
+	ControlAvailable bool
+}
+
+// SwarmVersion is the version of a swarm object, needed to update it.
+type SwarmVersion struct {
+	Index uint64
+}
+
+// SwarmService is a service, as returned by the API.
+type SwarmService struct {
+	ID        string
+	Version   SwarmVersion
+	CreatedAt time.Time
+	Spec      SwarmServiceSpec
+}
+
+// SwarmServiceSpec is the part of a service's spec we report.
+type SwarmServiceSpec struct {
+	Name         string
+	Labels       map[string]string
+	TaskTemplate struct {
+		ContainerSpec struct {
+			Image string
+		}
+	}
+	Mode struct {
+		Replicated *struct {
+			Replicas *uint64
+		}
+		Global *struct{}
+	}
+}
+
+// SwarmTask is a task, as returned by the API.
+type SwarmTask struct {
+	ID           string
+	ServiceID    string
+	NodeID       string
+	Slot         int
+	DesiredState string
+	Status       struct {
+		Timestamp       time.Time
+		State           string
+		Message         string
+		Err             string
+		ContainerStatus struct {
+			ContainerID string
+		}
+	}
+}
+
+// SwarmNode is a node, as returned by the API.
+type SwarmNode struct {
+	ID   string
+	Spec struct {
+		Role         string
+		Availability string
+	}
+	Description struct {
+		Hostname string
+		Engine   struct {
+			EngineVersion string
+		}
+	}
+	Status struct {
+		State string
+		Addr  string
+	}
+	ManagerStatus *struct {
+		Leader       bool
+		Reachability string
+	}
+}
+
+// swarmClient makes plain HTTP calls to the daemon, as the vendored
+// go-dockerclient predates swarm mode.
+type swarmClient struct {
+	http *http.Client
+}
+
+// NewSwarmClient makes a new SwarmClient talking to the daemon at endpoint.
+func NewSwarmClient(endpoint Endpoint) (SwarmClient, error) {
+	if _, err := url.Parse(endpoint.Address); err != nil {
+		return nil, err
+	}
+
+	return &swarmClient{
+		http: &http.Client{
+			Transport: &http.Transport{
+				Dial: func(_, _ string) (net.Conn, error) {
+					return endpoint.dial()
+				},
+			},
+			Timeout: swarmRequestTimeout,
+		},
+	}, nil
+}
+
+// do makes a request to the daemon, decoding the response into out.
+func (c *swarmClient) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequest(method, "http://docker/"+swarmAPIVersion+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Message == "" {
+			return fmt.Errorf("swarm: %s %s: %s", method, path, resp.Status)
+		}
+		return fmt.Errorf("swarm: %s", apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	return decoder.Decode(out)
+}
+
+func (c *swarmClient) Info() (SwarmInfo, error) {
+	var info struct {
+		Swarm SwarmInfo
+	}
+	err := c.do("GET", "/info", nil, &info)
+	return info.Swarm, err
+}
+
+func (c *swarmClient) ListServices() ([]SwarmService, error) {
+	var services []SwarmService
+	err := c.do("GET", "/services", nil, &services)
+	return services, err
+}
+
+func (c *swarmClient) ListTasks() ([]SwarmTask, error) {
+	var tasks []SwarmTask
+	err := c.do("GET", "/tasks", nil, &tasks)
+	return tasks, err
+}
+
+func (c *swarmClient) ListNodes() ([]SwarmNode, error) {
+	var nodes []SwarmNode
+	err := c.do("GET", "/nodes", nil, &nodes)
+	return nodes, err
+}
+
+func (c *swarmClient) ScaleUp(serviceID string) error {
+	return c.modifyReplicas(serviceID, func(replicas uint64) (uint64, error) {
+		return replicas + 1, nil
+	})
+}
+
+func (c *swarmClient) ScaleDown(serviceID string) error {
+	return c.modifyReplicas(serviceID, func(replicas uint64) (uint64, error) {
+		if replicas == 0 {
+			return 0, fmt.Errorf("swarm: service %s has no replicas", serviceID)
+		}
+		return replicas - 1, nil
+	})
+}
+
+// swarmID matches the IDs swarm gives objects, which are lowercase base 36.
+// Service IDs are checked against it as they are put in request paths.
+var swarmID = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// modifyReplicas updates the number of replicas of a replicated service.
+// The spec is sent back as it was read, bar the replicas, so that fields we
+// don't know about aren't lost.
+func (c *swarmClient) modifyReplicas(serviceID string, f func(uint64) (uint64, error)) error {
+	if !swarmID.MatchString(serviceID) {
+		return fmt.Errorf("swarm: invalid service ID %q", serviceID)
+	}
+	var service struct {
+		Version SwarmVersion
+		Spec    map[string]interface{}
+	}
+	if err := c.do("GET", "/services/"+serviceID, nil, &service); err != nil {
+		return err
+	}
+
+	mode, _ := service.Spec["Mode"].(map[string]interface{})
+	replicated, ok := mode["Replicated"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("swarm: service %s isn't replicated, so can't be scaled", serviceID)
+	}
+	var replicas uint64
+	if n, ok := replicated["Replicas"].(json.Number); ok {
+		r, err := strconv.ParseUint(n.String(), 10, 64)
+		if err != nil {
+			return err
+		}
+		replicas = r
+	}
+	replicas, err := f(replicas)
+	if err != nil {
+		return err
+	}
+	replicated["Replicas"] = replicas
+
+	path := fmt.Sprintf("/services/%s/update?version=%d", serviceID, service.Version.Index)
+	return c.do("POST", path, service.Spec, nil)
+}
@@ -0,0 +1,268 @@
+package docker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "github.com/fsouza/go-dockerclient"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+	"$GITHUB_URI/test/reflect"
+)
+
+type mockSwarmClient struct {
+	info     docker.SwarmInfo
+	infoErr  error
+	services []docker.SwarmService
+	tasks    []docker.SwarmTask
+	nodes    []docker.SwarmNode
+	scaled   map[string]int
+}
+
+func (c *mockSwarmClient) Info() (docker.SwarmInfo, error)              { return c.info, c.infoErr }
+func (c *mockSwarmClient) ListServices() ([]docker.SwarmService, error) { return c.services, nil }
+func (c *mockSwarmClient) ListTasks() ([]docker.SwarmTask, error)       { return c.tasks, nil }
+func (c *mockSwarmClient) ListNodes() ([]docker.SwarmNode, error)       { return c.nodes, nil }
+
+func (c *mockSwarmClient) ScaleUp(id string) error {
+	c.scaled[id]++
+	return nil
+}
+
+func (c *mockSwarmClient) ScaleDown(id string) error {
+	c.scaled[id]--
+	return nil
+}
+
+func newMockSwarmClient() *mockSwarmClient {
+	replicas := uint64(2)
+	c := &mockSwarmClient{
+		info:   docker.SwarmInfo{NodeID: "node1", LocalNodeState: "active", ControlAvailable: true},
+		scaled: map[string]int{},
+	}
+	web := docker.SwarmService{ID: "service1"}
+	web.Spec.Name = "web"
+	web.Spec.TaskTemplate.ContainerSpec.Image = "nginx:1.11"
+	web.Spec.Mode.Replicated = &struct{ Replicas *uint64 }{&replicas}
+	agent := docker.SwarmService{ID: "service2"}
+	agent.Spec.Name = "agent"
+	agent.Spec.Mode.Global = &struct{}{}
+	c.services = []docker.SwarmService{web, agent}
+
+	running := docker.SwarmTask{ID: "task1", ServiceID: "service1", NodeID: "node1", Slot: 1, DesiredState: "running"}
+	running.Status.State = "running"
+	running.Status.ContainerStatus.ContainerID = "ping"
+	replaced := docker.SwarmTask{ID: "task0", ServiceID: "service1", NodeID: "node1", Slot: 1, DesiredState: "shutdown"}
+	replaced.Status.State = "failed"
+	c.tasks = []docker.SwarmTask{running, replaced}
+
+	node := docker.SwarmNode{ID: "node1"}
+	node.Description.Hostname = "host1"
+	node.Spec.Role = "manager"
+	c.nodes = []docker.SwarmNode{node}
+	return c
+}
+
+func TestSwarmReporter(t *testing.T) {
+	swarm := newMockSwarmClient()
+	reporter := docker.NewSwarmReporter(swarm, "probe1")
+	defer reporter.Stop()
+
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	web, ok := rpt.SwarmService.Nodes[report.MakeSwarmServiceNodeID("service1")]
+	if !ok {
+		t.Fatalf("Expected report to have service %q", "service1")
+	}
+	for k, want := range map[string]string{
+		docker.SwarmServiceName: "web",
+		docker.SwarmServiceMode: "replicated",
+		docker.DesiredReplicas:  "2",
+		docker.SwarmImage:       "nginx:1.11",
+	} {
+		if have, ok := web.Latest.Lookup(k); !ok || have != want {
+			t.Errorf("Expected service latest %q: %q, got %q", k, want, have)
+		}
+	}
+	if want, have := report.MakeStringSet(docker.ScaleUpService, docker.ScaleDownService), report.MakeStringSet(web.Controls.Controls...); !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected replicated service controls %v, got %v", want, have)
+	}
+	// Global services can't be scaled
+	if agent := rpt.SwarmService.Nodes[report.MakeSwarmServiceNodeID("service2")]; len(agent.Controls.Controls) != 0 {
+		t.Errorf("Expected global service to have no controls, got %v", agent.Controls.Controls)
+	}
+
+	// Tasks which have been replaced aren't reported
+	if want, have := 1, len(rpt.SwarmTask.Nodes); want != have {
+		t.Fatalf("Expected %d task, got %d", want, have)
+	}
+	task := rpt.SwarmTask.Nodes[report.MakeSwarmTaskNodeID("task1")]
+	if have, ok := task.Latest.Lookup(docker.SwarmTaskName); !ok || have != "web.1" {
+		t.Errorf("Expected task name %q, got %q", "web.1", have)
+	}
+	if services, ok := task.Parents.Lookup(report.SwarmService); !ok || !services.Contains(report.MakeSwarmServiceNodeID("service1")) {
+		t.Errorf("Expected task to have service parent, got %v", services)
+	}
+	if nodes, ok := task.Parents.Lookup(report.SwarmNode); !ok || !nodes.Contains(report.MakeSwarmNodeNodeID("node1")) {
+		t.Errorf("Expected task to have swarm node parent, got %v", nodes)
+	}
+
+	node := rpt.SwarmNode.Nodes[report.MakeSwarmNodeNodeID("node1")]
+	if hosts, ok := node.Parents.Lookup(report.Host); !ok || !hosts.Contains(report.MakeHostNodeID("host1")) {
+		t.Errorf("Expected swarm node to have host parent, got %v", hosts)
+	}
+
+	// Scaling controls are passed on to the client
+	for _, control := range []string{docker.ScaleUpService, docker.ScaleUpService, docker.ScaleDownService} {
+		result := controls.HandleControlRequest(xfer.Request{
+			Control: control,
+			NodeID:  report.MakeSwarmServiceNodeID("service1"),
+		})
+		if result.Error != "" {
+			t.Fatal(result.Error)
+		}
+	}
+	if want, have := 1, swarm.scaled["service1"]; want != have {
+		t.Errorf("Expected service to be scaled by %d, got %d", want, have)
+	}
+}
+
+func TestSwarmReporterWorker(t *testing.T) {
+	swarm := newMockSwarmClient()
+	swarm.info.ControlAvailable = false
+	reporter := docker.NewSwarmReporter(swarm, "probe1")
+	defer reporter.Stop()
+
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rpt.SwarmService.Nodes) + len(rpt.SwarmTask.Nodes) + len(rpt.SwarmNode.Nodes); n != 0 {
+		t.Errorf("Expected workers to report nothing, got %d nodes", n)
+	}
+}
+
+func TestSwarmReporterUnsupported(t *testing.T) {
+	swarm := newMockSwarmClient()
+	swarm.infoErr = fmt.Errorf("client is newer than server (client API version: 1.24, server API version: 1.23)")
+	reporter := docker.NewSwarmReporter(swarm, "probe1")
+	defer reporter.Stop()
+
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Errorf("Expected daemons without swarm mode not to fail the report, got %v", err)
+	}
+	if n := len(rpt.SwarmService.Nodes) + len(rpt.SwarmTask.Nodes) + len(rpt.SwarmNode.Nodes); n != 0 {
+		t.Errorf("Expected daemons without swarm mode to report nothing, got %d nodes", n)
+	}
+}
+
+// With several daemons, scaling goes to the one managing the service.
+func TestSwarmReporterRouting(t *testing.T) {
+	manager, other := newMockSwarmClient(), newMockSwarmClient()
+	other.services = nil
+	for _, swarm := range []*mockSwarmClient{other, manager} {
+		reporter := docker.NewSwarmReporter(swarm, "probe1")
+		defer reporter.Stop()
+		if _, err := reporter.Report(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := controls.HandleControlRequest(xfer.Request{
+		Control: docker.ScaleUpService,
+		NodeID:  report.MakeSwarmServiceNodeID("service1"),
+	})
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+	if manager.scaled["service1"] != 1 || len(other.scaled) != 0 {
+		t.Errorf("Expected the managing daemon to scale the service, got %v and %v", manager.scaled, other.scaled)
+	}
+	result = controls.HandleControlRequest(xfer.Request{
+		Control: docker.ScaleUpService,
+		NodeID:  report.MakeSwarmServiceNodeID("service3"),
+	})
+	if result.Error == "" {
+		t.Errorf("Expected scaling an unknown service to fail with several daemons")
+	}
+}
+
+func TestContainerSwarmParents(t *testing.T) {
+	c := docker.NewContainer(&client.Container{
+		ID:    "ping",
+		Image: "baz",
+		Config: &client.Config{Labels: map[string]string{
+			"com.docker.swarm.service.id": "service1",
+			"com.docker.swarm.task.id":    "task1",
+		}},
+	}, "host1")
+	node := c.GetNode()
+	if have, ok := node.Parents.Lookup(report.SwarmTask); !ok || !have.Contains(report.MakeSwarmTaskNodeID("task1")) {
+		t.Errorf("Expected container to have task parent, got %v", have)
+	}
+	if have, ok := node.Parents.Lookup(report.SwarmService); !ok || !have.Contains(report.MakeSwarmServiceNodeID("service1")) {
+		t.Errorf("Expected container to have service parent, got %v", have)
+	}
+}
+
+func TestSwarmClientScale(t *testing.T) {
+	var updated map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1.24/services/service1":
+			fmt.Fprint(w, `{"ID": "service1", "Version": {"Index": 42}, "Spec": {
+				"Name": "web",
+				"UpdateConfig": {"Parallelism": 1},
+				"Mode": {"Replicated": {"Replicas": 2}}}}`)
+		case r.Method == "POST" && r.URL.Path == "/v1.24/services/service1/update":
+			if version := r.URL.Query().Get("version"); version != "42" {
+				http.Error(w, `{"message": "update out of sequence"}`, http.StatusInternalServerError)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&updated)
+		default:
+			http.Error(w, `{"message": "not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	oldDialStub := docker.DialStub
+	defer func() { docker.DialStub = oldDialStub }()
+	docker.DialStub = func(string, string) (net.Conn, error) {
+		return net.Dial("tcp", server.Listener.Addr().String())
+	}
+
+	swarm, err := docker.NewSwarmClient(docker.Endpoint{Address: "tcp://docker:2375"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := swarm.ScaleUp("service1"); err != nil {
+		t.Fatal(err)
+	}
+	replicas := updated["Mode"].(map[string]interface{})["Replicated"].(map[string]interface{})["Replicas"]
+	if replicas != 3.0 {
+		t.Errorf("Expected 3 replicas, got %v", replicas)
+	}
+	// Fields we don't know of must be kept
+	if _, ok := updated["UpdateConfig"]; !ok {
+		t.Errorf("Expected update config to be kept, got %v", updated)
+	}
+
+	if err := swarm.ScaleUp("nonexistent"); err == nil || err.Error() != "swarm: not found" {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := swarm.ScaleUp("../nodes"); err == nil || err.Error() != `swarm: invalid service ID "../nodes"` {
+		t.Errorf("Expected invalid ID error, got %v", err)
+	}
+}
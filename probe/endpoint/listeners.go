@@ -0,0 +1,138 @@
+package endpoint
+
+import (
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"$GITHUB_URI/probe/endpoint/procspy"
+	"$GITHUB_URI/report"
+)
+
+// ListeningPortPrefix prefixes the rows of the table of the sockets a process
+// listens on. Rows are keyed by transport and bind address, e.g.
+// "tcp 0.0.0.0:5432", or "unix /run/docker.sock", and valued with the
+// protocol usually spoken there, if well-known.
+const ListeningPortPrefix = "listening_port_"
+
+// UnknownProtocol is the protocol of listening sockets not on a well-known
+// port.
+const UnknownProtocol = "-"
+
+// ProcessTableTemplates are the tables the endpoint reporter adds to
+// processes.
+var ProcessTableTemplates = report.TableTemplates{
+	ListeningPortPrefix: {ID: ListeningPortPrefix, Label: "Listening ports", Prefix: ListeningPortPrefix},
+}
+
+// wellKnownPorts are the protocols usually spoken on a port, whatever the
+// transport.
+var wellKnownPorts = map[uint16]string{
+	21:    "ftp",
+	22:    "ssh",
+	25:    "smtp",
+	53:    "dns",
+	80:    "http",
+	110:   "pop3",
+	123:   "ntp",
+	143:   "imap",
+	161:   "snmp",
+	389:   "ldap",
+	443:   "https",
+	445:   "smb",
+	465:   "smtps",
+	514:   "syslog",
+	587:   "submission",
+	636:   "ldaps",
+	993:   "imaps",
+	995:   "pop3s",
+	1433:  "mssql",
+	1521:  "oracle",
+	2049:  "nfs",
+	2181:  "zookeeper",
+	2379:  "etcd",
+	2380:  "etcd-peer",
+	3306:  "mysql",
+	4040:  "scope",
+	4369:  "epmd",
+	5432:  "postgres",
+	5672:  "amqp",
+	5984:  "couchdb",
+	6379:  "redis",
+	6443:  "kubernetes-api",
+	8080:  "http-alt",
+	8443:  "https-alt",
+	9042:  "cassandra",
+	9092:  "kafka",
+	9200:  "elasticsearch",
+	9300:  "elasticsearch-transport",
+	10250: "kubelet",
+	11211: "memcached",
+	15672: "rabbitmq-management",
+	27017: "mongodb",
+}
+
+// wellKnownSockets are the protocols usually spoken on a unix socket, by
+// file name.
+var wellKnownSockets = map[string]string{
+	"docker.sock":     "docker",
+	"containerd.sock": "containerd",
+	"crio.sock":       "cri-o",
+	"mysqld.sock":     "mysql",
+	"mysql.sock":      "mysql",
+	"redis.sock":      "redis",
+	"php-fpm.sock":    "fastcgi",
+	"supervisor.sock": "supervisor",
+}
+
+// ListenerProtocol returns the protocol usually spoken on a listening
+// socket, or UnknownProtocol.
+func ListenerProtocol(l procspy.Listener) string {
+	if l.Transport == "unix" {
+		name := path.Base(l.Path)
+		if strings.HasPrefix(name, ".s.PGSQL.") {
+			return "postgres"
+		}
+		if protocol, ok := wellKnownSockets[name]; ok {
+			return protocol
+		}
+		return UnknownProtocol
+	}
+	if protocol, ok := wellKnownPorts[l.Port]; ok {
+		return protocol
+	}
+	return UnknownProtocol
+}
+
+// listenerKey is the row key of a listening socket.
+func listenerKey(l procspy.Listener) string {
+	if l.Transport == "unix" {
+		return l.Transport + " " + l.Path
+	}
+	return l.Transport + " " + net.JoinHostPort(l.Address.String(), strconv.Itoa(int(l.Port)))
+}
+
+// addListeners adds the sockets processes listen on to the process topology.
+// Of processes listening on more than report.MaxTableRows, the same are
+// reported each time, along with how many were left out.
+func (r *Reporter) addListeners(rpt *report.Report, listeners []procspy.Listener) {
+	byPID := map[uint]map[string]string{}
+	for _, l := range listeners {
+		if l.Proc.PID == 0 {
+			continue
+		}
+		rows, ok := byPID[l.Proc.PID]
+		if !ok {
+			rows = map[string]string{}
+			byPID[l.Proc.PID] = rows
+		}
+		rows[listenerKey(l)] = ListenerProtocol(l)
+	}
+	for pid, rows := range byPID {
+		nodeID := report.MakeProcessNodeID(r.hostID, strconv.FormatUint(uint64(pid), 10))
+		node := report.MakeNode(nodeID).WithTopology(report.Process).AddTable(ListeningPortPrefix, rows)
+		rpt.Process = rpt.Process.AddNode(node)
+	}
+	rpt.Process = rpt.Process.WithTableTemplates(ProcessTableTemplates)
+}
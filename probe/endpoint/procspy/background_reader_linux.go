@@ -25,6 +25,8 @@ type backgroundReader struct {
 	mtx           sync.Mutex
 	latestBuf     *bytes.Buffer
 	latestSockets map[uint64]*Proc
+	// listening sockets, as of the last walk
+	latestListeners []Listener
 }
 
 // starts a rate-limited background goroutine to read the expensive files from
@@ -51,9 +53,16 @@ func (br *backgroundReader) getWalkedProcPid(buf *bytes.Buffer) (map[uint64]*Pro
 	return br.latestSockets, err
 }
 
+func (br *backgroundReader) getListeners() []Listener {
+	br.mtx.Lock()
+	defer br.mtx.Unlock()
+	return br.latestListeners
+}
+
 type walkResult struct {
-	buf     *bytes.Buffer
-	sockets map[uint64]*Proc
+	buf       *bytes.Buffer
+	sockets   map[uint64]*Proc
+	listeners []Listener
 }
 
 func performWalk(w pidWalker, c chan<- walkResult) {
@@ -64,11 +73,12 @@ func performWalk(w pidWalker, c chan<- walkResult) {
 		}
 	)
 
-	result.sockets, err = w.walk(result.buf)
+	result.sockets, result.listeners, err = w.walk(result.buf)
 	if err != nil {
 		log.Errorf("background /proc reader: error walking /proc: %s", err)
 		result.buf.Reset()
 		result.sockets = nil
+		result.listeners = nil
 	}
 	c <- result
 }
@@ -97,6 +107,7 @@ func (br *backgroundReader) loop(walker process.Walker) {
 			br.mtx.Lock()
 			br.latestBuf = result.buf
 			br.latestSockets = result.sockets
+			br.latestListeners = result.listeners
 			br.mtx.Unlock()
 
 			// Schedule next walk and adjust its rate limit
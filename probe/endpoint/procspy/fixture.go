@@ -23,5 +23,10 @@ func (s FixedScanner) Connections(_ bool) (ConnIter, error) {
 	return &iter, nil
 }
 
+// Listeners implements ConnectionsScanner.Listeners
+func (s FixedScanner) Listeners() []Listener {
+	return nil
+}
+
 // Stop implements ConnectionsScanner.Stop (dummy since there is no background work)
 func (s FixedScanner) Stop() {}
@@ -0,0 +1,62 @@
+package procspy
+
+import (
+	"bytes"
+	"net"
+)
+
+// unix socket flag of listening sockets, according to /include/linux/net.h
+const unixAcceptCon = 0x10000
+
+// parseIPListeners parses the sockets in the wanted state out of
+// /proc/net/{tcp,udp}{,6} files.
+func parseIPListeners(transport string, b []byte, wantedState uint) []Listener {
+	listeners := []Listener{}
+	pn := NewProcNet(b, wantedState)
+	for c := pn.Next(); c != nil; c = pn.Next() {
+		if c.LocalPort == 0 {
+			continue
+		}
+		listeners = append(listeners, Listener{
+			Transport: transport,
+			Address:   append(net.IP(nil), c.LocalAddress...),
+			Port:      c.LocalPort,
+			inode:     c.inode,
+		})
+	}
+	return listeners
+}
+
+// parseUnixListeners parses the listening sockets bound to a path out of a
+// /proc/net/unix file. Abstract and unnamed sockets are skipped.
+func parseUnixListeners(b []byte) []Listener {
+	listeners := []Listener{}
+	for ; len(b) > 0; b = nextLine(b) {
+		line := b
+		if i := bytes.IndexByte(line, '\n'); i != -1 {
+			line = line[:i]
+		}
+		// Num: RefCount Protocol Flags Type St Inode Path
+		fields := bytes.Fields(line)
+		if len(fields) < 8 || bytes.Equal(fields[0], []byte("Num")) {
+			continue
+		}
+		if parseHex(fields[3])&unixAcceptCon == 0 {
+			continue
+		}
+		path := line
+		for i := 0; i < 7; i++ {
+			_, path = nextField(path)
+		}
+		path = bytes.TrimSpace(path)
+		if len(path) == 0 || path[0] == '@' {
+			continue
+		}
+		listeners = append(listeners, Listener{
+			Transport: "unix",
+			Path:      string(path),
+			inode:     parseDec(fields[6]),
+		})
+	}
+	return listeners
+}
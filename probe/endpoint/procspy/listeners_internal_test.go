@@ -0,0 +1,59 @@
+package procspy
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseIPListeners(t *testing.T) {
+	testString := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  0: 00000000000000000000000000000000:0035 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 10221 2 ffff880035c4d800 0
+  1: 00000000000000000000000000000000:D6F1 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 10222 2 ffff880035c4d800 0
+  2: 00000000000000000000000001000000:C9A3 00000000000000000000000001000000:0035 01 00000000:00000000 00:00000000 00000000     0        0 10223 2 ffff880035c4d800 0
+`
+	have := parseIPListeners("udp", []byte(testString), tcpClose)
+	want := []Listener{
+		{
+			Transport: "udp",
+			Address:   net.ParseIP("::"),
+			Port:      53,
+			inode:     10221,
+		},
+		{
+			Transport: "udp",
+			Address:   net.ParseIP("::"),
+			Port:      55025,
+			inode:     10222,
+		},
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Fatalf("want %+v, have %+v", want, have)
+	}
+}
+
+func TestParseUnixListeners(t *testing.T) {
+	testString := `Num       RefCount Protocol Flags    Type St Inode Path
+0000000000000000: 00000002 00000000 00010000 0001 01 14351 /run/postgresql/.s.PGSQL.5432
+0000000000000000: 00000002 00000000 00010000 0001 01 14352 /var/run/my app.sock
+0000000000000000: 00000002 00000000 00010000 0001 01 14353 @/tmp/.X11-unix/X0
+0000000000000000: 00000003 00000000 00000000 0001 03 14354 /run/systemd/journal/stdout
+0000000000000000: 00000003 00000000 00000000 0001 03 14355
+`
+	have := parseUnixListeners([]byte(testString))
+	want := []Listener{
+		{
+			Transport: "unix",
+			Path:      "/run/postgresql/.s.PGSQL.5432",
+			inode:     14351,
+		},
+		{
+			Transport: "unix",
+			Path:      "/var/run/my app.sock",
+			inode:     14352,
+		},
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Fatalf("want %+v, have %+v", want, have)
+	}
+}
@@ -2,6 +2,7 @@ package procspy
 
 import (
 	"bytes"
+	"net"
 	"reflect"
 	"syscall"
 	"testing"
@@ -23,6 +24,13 @@ var mockFS = fs.Dir("",
 						Mode: syscall.S_IFSOCK,
 					},
 				},
+				fs.File{
+					FName: "17",
+					FStat: syscall.Stat_t{
+						Ino:  5108,
+						Mode: syscall.S_IFSOCK,
+					},
+				},
 			),
 			fs.File{
 				FName:     "cmdline",
@@ -39,6 +47,7 @@ var mockFS = fs.Dir("",
 					FName: "tcp",
 					FContents: `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
    0: 00000000:A6C0 00000000:0000 01 00000000:00000000 00:00000000 00000000   105        0 5107 1 ffff8800a6aaf040 100 0 0 10 2d
+   1: 00000000:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000   105        0 5108 1 ffff8800a6aaf740 100 0 0 10 0
 `,
 				},
 				fs.File{
@@ -66,7 +75,7 @@ func TestWalkProcPid(t *testing.T) {
 	ticker := time.NewTicker(time.Millisecond)
 	defer ticker.Stop()
 	pWalker := newPidWalker(walker, ticker.C, 1)
-	have, err := pWalker.walk(&buf)
+	have, haveListeners, err := pWalker.walk(&buf)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -75,8 +84,27 @@ func TestWalkProcPid(t *testing.T) {
 			PID:  1,
 			Name: "foo",
 		},
+		5108: {
+			PID:  1,
+			Name: "foo",
+		},
 	}
 	if !reflect.DeepEqual(want, have) {
 		t.Fatalf("%+v", have)
 	}
+	wantListeners := []Listener{
+		{
+			Transport: "tcp",
+			Address:   net.IP([]byte{0, 0, 0, 0}),
+			Port:      5432,
+			inode:     5108,
+			Proc: Proc{
+				PID:  1,
+				Name: "foo",
+			},
+		},
+	}
+	if !reflect.DeepEqual(wantListeners, haveListeners) {
+		t.Fatalf("%+v", haveListeners)
+	}
 }
@@ -125,11 +125,38 @@ func readProcessConnections(buf *bytes.Buffer, namespaceProcs []*process.Process
 
 }
 
-// walkNamespace does the work of walk for a single namespace
-func (w pidWalker) walkNamespace(buf *bytes.Buffer, sockets map[uint64]*Proc, namespaceProcs []*process.Process) error {
+// Read the listening sockets of a group of processes living in the same
+// namespace, from /proc/PID/net/{tcp,udp}{,6} and /proc/PID/net/unix for any
+// of the processes.
+func readProcessListeners(namespaceProcs []*process.Process) []Listener {
+	for _, p := range namespaceProcs {
+		var (
+			dirName        = filepath.Join(procRoot, strconv.Itoa(p.PID), "net")
+			tcp, udp, unix bytes.Buffer
+		)
+		if _, err := readFile(filepath.Join(dirName, "tcp"), &tcp); err != nil {
+			// try next process
+			continue
+		}
+		readFile(filepath.Join(dirName, "tcp6"), &tcp)
+		readFile(filepath.Join(dirName, "udp"), &udp)
+		readFile(filepath.Join(dirName, "udp6"), &udp)
+		readFile(filepath.Join(dirName, "unix"), &unix)
+
+		listeners := parseIPListeners("tcp", tcp.Bytes(), tcpListen)
+		listeners = append(listeners, parseIPListeners("udp", udp.Bytes(), tcpClose)...)
+		return append(listeners, parseUnixListeners(unix.Bytes())...)
+	}
+	return nil
+}
+
+// walkNamespace does the work of walk for a single namespace, returning its
+// listening sockets.
+func (w pidWalker) walkNamespace(buf *bytes.Buffer, sockets map[uint64]*Proc, namespaceProcs []*process.Process) ([]Listener, error) {
 
-	if found, err := readProcessConnections(buf, namespaceProcs); err != nil || !found {
-		return err
+	listeners := readProcessListeners(namespaceProcs)
+	if found, err := readProcessConnections(buf, namespaceProcs); err != nil || (!found && len(listeners) == 0) {
+		return listeners, err
 	}
 
 	var statT syscall.Stat_t
@@ -145,14 +172,14 @@ func (w pidWalker) walkNamespace(buf *bytes.Buffer, sockets map[uint64]*Proc, na
 			select {
 			case <-w.tickc:
 			case <-w.stopc:
-				return nil // abort
+				return listeners, nil // abort
 			}
 
 			fdBlockCount = 0
 			// read the connections again to
 			// avoid the race between between /net/tcp{,6} and /proc/PID/fd/*
-			if found, err := readProcessConnections(buf, namespaceProcs[i:]); err != nil || !found {
-				return err
+			if found, err := readProcessConnections(buf, namespaceProcs[i:]); err != nil || (!found && len(listeners) == 0) {
+				return listeners, err
 			}
 		}
 
@@ -191,17 +218,18 @@ func (w pidWalker) walkNamespace(buf *bytes.Buffer, sockets map[uint64]*Proc, na
 
 	}
 
-	return nil
+	return listeners, nil
 }
 
 // walk walks over all numerical (PID) /proc entries. It reads
 // /proc/PID/net/tcp{,6} for each namespace and sees if the ./fd/* files of each
 // process in that namespace are symlinks to sockets. Returns a map from socket
-// ID (inode) to PID.
-func (w pidWalker) walk(buf *bytes.Buffer) (map[uint64]*Proc, error) {
+// ID (inode) to PID, and the listening sockets of all namespaces.
+func (w pidWalker) walk(buf *bytes.Buffer) (map[uint64]*Proc, []Listener, error) {
 	var (
 		sockets    = map[uint64]*Proc{}              // map socket inode -> process
 		namespaces = map[uint64][]*process.Process{} // map network namespace id -> processes
+		listeners  = []Listener{}                    // listening sockets of all namespaces
 		statT      syscall.Stat_t
 	)
 
@@ -228,14 +256,21 @@ func (w pidWalker) walk(buf *bytes.Buffer) (map[uint64]*Proc, error) {
 	for _, procs := range namespaces {
 		select {
 		case <-w.tickc:
-			w.walkNamespace(buf, sockets, procs)
+			namespaceListeners, _ := w.walkNamespace(buf, sockets, procs)
+			listeners = append(listeners, namespaceListeners...)
 		case <-w.stopc:
 			break // abort
 		}
 	}
 
+	for i, l := range listeners {
+		if proc, ok := sockets[l.inode]; ok {
+			listeners[i].Proc = *proc
+		}
+	}
+
 	metrics.SetGauge(namespaceKey, float32(len(namespaces)))
-	return sockets, nil
+	return sockets, listeners, nil
 }
 
 func (w pidWalker) stop() {
@@ -8,7 +8,9 @@ import (
 )
 
 const (
-	tcpEstablished = 1 // according to /include/net/tcp_states.h
+	tcpEstablished = 1  // according to /include/net/tcp_states.h
+	tcpClose       = 7  // unconnected UDP sockets are in this state
+	tcpListen      = 10 // according to /include/net/tcp_states.h
 )
 
 // Connection is a (TCP) connection. The Proc struct might not be filled in.
@@ -22,6 +24,18 @@ type Connection struct {
 	Proc
 }
 
+// Listener is a socket waiting for connections (TCP and unix) or datagrams
+// (UDP). Unix sockets have a Path rather than an address and port. The Proc
+// struct might not be filled in.
+type Listener struct {
+	Transport string // "tcp", "udp" or "unix"
+	Address   net.IP
+	Port      uint16
+	Path      string
+	inode     uint64
+	Proc
+}
+
 // Proc is a single process with PID and process name.
 type Proc struct {
 	PID  uint
@@ -41,6 +55,9 @@ type ConnectionScanner interface {
 	// connection, filling in the Proc field. You will need to run this as root to
 	// find all processes.
 	Connections(processes bool) (ConnIter, error)
+	// Listeners returns the listening sockets, with their owning processes
+	// when found, as of the last time the scanner looked for them.
+	Listeners() []Listener
 	// Stops the scanning
 	Stop()
 }
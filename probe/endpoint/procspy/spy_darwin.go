@@ -70,5 +70,10 @@ func (s *darwinScanner) Connections(processes bool) (ConnIter, error) {
 	return &f, nil
 }
 
+// Listeners are not looked for on Darwin.
+func (s *darwinScanner) Listeners() []Listener {
+	return nil
+}
+
 // Nothing to stop since there's nothing running in the background
 func (s *darwinScanner) Stop() {}
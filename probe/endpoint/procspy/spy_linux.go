@@ -70,3 +70,7 @@ func (s *linuxScanner) Connections(processes bool) (ConnIter, error) {
 func (s *linuxScanner) Stop() {
 	s.br.stop()
 }
+
+func (s *linuxScanner) Listeners() []Listener {
+	return s.br.getListeners()
+}
@@ -169,6 +169,10 @@ func (r *Reporter) Report() (report.Report, error) {
 		}
 	}
 
+	if r.includeProcesses {
+		r.addListeners(&rpt, r.scanner.Listeners())
+	}
+
 	r.natMapper.applyNAT(rpt, r.hostID)
 	return rpt, nil
 }
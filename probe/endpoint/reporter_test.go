@@ -113,3 +113,71 @@ func TestSpyWithProcesses(t *testing.T) {
 		}
 	}
 }
+
+type listeningScanner struct {
+	procspy.FixedScanner
+	listeners []procspy.Listener
+}
+
+func (s listeningScanner) Listeners() []procspy.Listener {
+	return s.listeners
+}
+
+func TestSpyListeners(t *testing.T) {
+	const (
+		hostID   = "nikon"
+		hostName = "fishermans-friend"
+	)
+
+	proc := procspy.Proc{PID: fixProcessPID, Name: fixProcessName}
+	scanner := listeningScanner{listeners: []procspy.Listener{
+		{Transport: "tcp", Address: net.ParseIP("0.0.0.0"), Port: 5432, Proc: proc},
+		{Transport: "udp", Address: net.ParseIP("::"), Port: 8125, Proc: proc},
+		{Transport: "unix", Path: "/var/run/docker.sock", Proc: proc},
+		{Transport: "tcp", Address: net.ParseIP("127.0.0.1"), Port: 22},
+	}}
+	reporter := endpoint.NewReporter(hostID, hostName, true, false, scanner)
+	r, _ := reporter.Report()
+
+	if want, have := 1, len(r.Process.Nodes); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	nodeID := report.MakeProcessNodeID(hostID, strconv.FormatUint(uint64(fixProcessPID), 10))
+	for key, want := range map[string]string{
+		endpoint.ListeningPortPrefix + "tcp 0.0.0.0:5432":          "postgres",
+		endpoint.ListeningPortPrefix + "udp [::]:8125":             endpoint.UnknownProtocol,
+		endpoint.ListeningPortPrefix + "unix /var/run/docker.sock": "docker",
+	} {
+		if have, _ := r.Process.Nodes[nodeID].Latest.Lookup(key); want != have {
+			t.Errorf("Process.Nodes[%q][%q]: want %q, have %q", nodeID, key, want, have)
+		}
+	}
+}
+
+func TestSpyManyListeners(t *testing.T) {
+	const hostID = "nikon"
+	proc := procspy.Proc{PID: fixProcessPID, Name: fixProcessName}
+	listeners := []procspy.Listener{}
+	for port := 1024 + report.MaxTableRows + 4; port >= 1024; port-- {
+		listeners = append(listeners, procspy.Listener{Transport: "tcp", Address: net.ParseIP("0.0.0.0"), Port: uint16(port), Proc: proc})
+	}
+	reporter := endpoint.NewReporter(hostID, "fishermans-friend", true, false, listeningScanner{listeners: listeners})
+	nodeID := report.MakeProcessNodeID(hostID, strconv.FormatUint(uint64(fixProcessPID), 10))
+
+	// The same listeners are reported each time
+	for i := 0; i < 5; i++ {
+		r, _ := reporter.Report()
+		rows, truncationCount := r.Process.Nodes[nodeID].ExtractTable(endpoint.ListeningPortPrefix)
+		if want, have := report.MaxTableRows, len(rows); want != have {
+			t.Fatalf("want %d listeners, have %d", want, have)
+		}
+		if want, have := 5, truncationCount; want != have {
+			t.Errorf("want %d left out, have %d", want, have)
+		}
+		for port := 1024; port < 1024+report.MaxTableRows; port++ {
+			if _, ok := rows["tcp 0.0.0.0:"+strconv.Itoa(port)]; !ok {
+				t.Errorf("want port %d, have %v", port, rows)
+			}
+		}
+	}
+}
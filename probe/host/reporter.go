@@ -15,6 +15,7 @@ const (
 	Timestamp     = "ts"
 	HostName      = "host_name"
 	LocalNetworks = "local_networks"
+	Mounts        = "host_mounts"
 	OS            = "os"
 	KernelVersion = "kernel_version"
 	Uptime        = "uptime"
@@ -22,6 +23,19 @@ const (
 	CPUUsage      = "host_cpu_usage_percent"
 	MemoryUsage   = "host_mem_usage_bytes"
 	ScopeVersion  = "host_scope_version"
+
+	CPUStealUsage    = "host_cpu_steal_percent"
+	SwapUsage        = "host_swap_usage_bytes"
+	MemoryPressure   = "host_mem_pressure_percent"
+	FilesystemUsage  = "host_filesystem_usage_bytes"
+	DiskReadBytes    = "host_disk_read_bytes_per_second"
+	DiskWriteBytes   = "host_disk_write_bytes_per_second"
+	NetworkRxBytes   = "host_network_receive_bytes_per_second"
+	NetworkTxBytes   = "host_network_transmit_bytes_per_second"
+	FilesystemPrefix = "host_filesystem_"
+	DiskPrefix       = "host_disk_"
+	InterfacePrefix  = "host_interface_"
+	CPUPrefix        = "host_cpu_"
 )
 
 // Exposed for testing.
@@ -30,6 +44,7 @@ const (
 	ProcLoad    = "/proc/loadavg"
 	ProcStat    = "/proc/stat"
 	ProcMemInfo = "/proc/meminfo"
+	ProcMounts  = "/proc/mounts"
 )
 
 // Exposed for testing.
@@ -47,9 +62,33 @@ var (
 		CPUUsage:    {ID: CPUUsage, Label: "CPU", Format: report.PercentFormat, Priority: 1},
 		MemoryUsage: {ID: MemoryUsage, Label: "Memory", Format: report.FilesizeFormat, Priority: 2},
 		Load1:       {ID: Load1, Label: "Load (1m)", Format: report.DefaultFormat, Group: "load", Priority: 11},
+
+		CPUStealUsage:   {ID: CPUStealUsage, Label: "CPU steal", Format: report.PercentFormat, Priority: 3},
+		SwapUsage:       {ID: SwapUsage, Label: "Swap", Format: report.FilesizeFormat, Priority: 4},
+		MemoryPressure:  {ID: MemoryPressure, Label: "Memory pressure", Format: report.PercentFormat, Priority: 5},
+		FilesystemUsage: {ID: FilesystemUsage, Label: "Filesystems", Format: report.FilesizeFormat, Priority: 6},
+		DiskReadBytes:   {ID: DiskReadBytes, Label: "Disk reads /s", Format: report.FilesizeFormat, Group: "disk", Priority: 7},
+		DiskWriteBytes:  {ID: DiskWriteBytes, Label: "Disk writes /s", Format: report.FilesizeFormat, Group: "disk", Priority: 8},
+		NetworkRxBytes:  {ID: NetworkRxBytes, Label: "Received /s", Format: report.FilesizeFormat, Group: "network", Priority: 9},
+		NetworkTxBytes:  {ID: NetworkTxBytes, Label: "Transmitted /s", Format: report.FilesizeFormat, Group: "network", Priority: 10},
+	}
+
+	TableTemplates = report.TableTemplates{
+		FilesystemPrefix: {ID: FilesystemPrefix, Label: "Filesystems", Prefix: FilesystemPrefix},
+		DiskPrefix:       {ID: DiskPrefix, Label: "Disks", Prefix: DiskPrefix},
+		InterfacePrefix:  {ID: InterfacePrefix, Label: "Network Interfaces", Prefix: InterfacePrefix},
+		CPUPrefix:        {ID: CPUPrefix, Label: "CPUs", Prefix: CPUPrefix},
 	}
 )
 
+// Stats are the usage of a host's resources beyond its CPU and memory: its
+// filesystems, disks, network interfaces and individual CPUs. Tables holds
+// the rows of the host's tables, by prefix.
+type Stats struct {
+	Metrics report.Metrics
+	Tables  map[string]map[string]string
+}
+
 // Reporter generates Reports containing the host topology.
 type Reporter struct {
 	hostID       string
@@ -121,6 +160,7 @@ func (r *Reporter) Report() (report.Report, error) {
 
 	rep.Host = rep.Host.WithMetadataTemplates(MetadataTemplates)
 	rep.Host = rep.Host.WithMetricTemplates(MetricTemplates)
+	rep.Host = rep.Host.WithTableTemplates(TableTemplates)
 
 	now := mtime.Now()
 	metrics := GetLoad(now)
@@ -128,23 +168,30 @@ func (r *Reporter) Report() (report.Report, error) {
 	metrics[CPUUsage] = report.MakeMetric().Add(now, cpuUsage).WithMax(max)
 	memoryUsage, max := GetMemoryUsageBytes()
 	metrics[MemoryUsage] = report.MakeMetric().Add(now, memoryUsage).WithMax(max)
+	stats := GetStats(now)
+	for key, metric := range stats.Metrics {
+		metrics[key] = metric
+	}
 
-	rep.Host.AddNode(
-		report.MakeNodeWith(report.MakeHostNodeID(r.hostID), map[string]string{
-			report.ControlProbeID: r.probeID,
-			Timestamp:             mtime.Now().UTC().Format(time.RFC3339Nano),
-			HostName:              r.hostName,
-			OS:                    runtime.GOOS,
-			KernelVersion:         kernel,
-			Uptime:                uptime.String(),
-			ScopeVersion:          r.version,
-		}).
-			WithSets(report.EmptySets.
-				Add(LocalNetworks, report.MakeStringSet(localCIDRs...)),
-			).
-			WithMetrics(metrics).
-			WithControls(ExecHost),
-	)
+	node := report.MakeNodeWith(report.MakeHostNodeID(r.hostID), map[string]string{
+		report.ControlProbeID: r.probeID,
+		Timestamp:             mtime.Now().UTC().Format(time.RFC3339Nano),
+		HostName:              r.hostName,
+		OS:                    runtime.GOOS,
+		KernelVersion:         kernel,
+		Uptime:                uptime.String(),
+		ScopeVersion:          r.version,
+	}).
+		WithSets(report.EmptySets.
+			Add(LocalNetworks, report.MakeStringSet(localCIDRs...)).
+			Add(Mounts, report.MakeStringSet(GetMounts()...)),
+		).
+		WithMetrics(metrics).
+		WithControls(ExecHost)
+	for prefix, rows := range stats.Tables {
+		node = node.AddTable(prefix, rows)
+	}
+	rep.Host.AddNode(node)
 
 	rep.Host.Controls.AddControl(report.Control{
 		ID:    ExecHost,
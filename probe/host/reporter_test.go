@@ -24,7 +24,10 @@ func TestReporter(t *testing.T) {
 			host.CPUUsage:    report.MakeMetric().Add(timestamp, 30.0).WithMax(100.0),
 			host.MemoryUsage: report.MakeMetric().Add(timestamp, 60.0).WithMax(100.0),
 		}
+		swap        = report.MakeMetric().Add(timestamp, 10.0).WithMax(100.0)
+		iface       = "rx 2.0 kB/s, tx 1.0 kB/s, 0 errors, 0 drops"
 		uptime      = "278h55m43s"
+		mount       = "/var/lib/kubelet/pods/1234/volumes/kubernetes.io~nfs/pv1"
 		kernel      = "release version"
 		_, ipnet, _ = net.ParseCIDR(network)
 	)
@@ -39,6 +42,8 @@ func TestReporter(t *testing.T) {
 		oldGetCPUUsagePercent  = host.GetCPUUsagePercent
 		oldGetMemoryUsageBytes = host.GetMemoryUsageBytes
 		oldGetLocalNetworks    = host.GetLocalNetworks
+		oldGetMounts           = host.GetMounts
+		oldGetStats            = host.GetStats
 	)
 	defer func() {
 		host.GetKernelVersion = oldGetKernelVersion
@@ -47,6 +52,8 @@ func TestReporter(t *testing.T) {
 		host.GetCPUUsagePercent = oldGetCPUUsagePercent
 		host.GetMemoryUsageBytes = oldGetMemoryUsageBytes
 		host.GetLocalNetworks = oldGetLocalNetworks
+		host.GetMounts = oldGetMounts
+		host.GetStats = oldGetStats
 	}()
 	host.GetKernelVersion = func() (string, error) { return release + " " + version, nil }
 	host.GetLoad = func(time.Time) report.Metrics { return metrics }
@@ -54,6 +61,13 @@ func TestReporter(t *testing.T) {
 	host.GetCPUUsagePercent = func() (float64, float64) { return 30.0, 100.0 }
 	host.GetMemoryUsageBytes = func() (float64, float64) { return 60.0, 100.0 }
 	host.GetLocalNetworks = func() ([]*net.IPNet, error) { return []*net.IPNet{ipnet}, nil }
+	host.GetMounts = func() []string { return []string{mount} }
+	host.GetStats = func(time.Time) host.Stats {
+		return host.Stats{
+			Metrics: report.Metrics{host.SwapUsage: swap},
+			Tables:  map[string]map[string]string{host.InterfacePrefix: {"eth0": iface}},
+		}
+	}
 
 	rpt, err := host.NewReporter(hostID, hostname, "", "", nil).Report()
 	if err != nil {
@@ -86,6 +100,17 @@ func TestReporter(t *testing.T) {
 		t.Errorf("Expected host.LocalNetworks to include %q, got %q", network, have)
 	}
 
+	// Should have the mounts
+	if have, ok := node.Sets.Lookup(host.Mounts); !ok || !have.Contains(mount) {
+		t.Errorf("Expected host.Mounts to include %q, got %q", mount, have)
+	}
+
+	// Should have the stats
+	if have, ok := node.Latest.Lookup(host.InterfacePrefix + "eth0"); !ok || have != iface {
+		t.Errorf("Expected interface eth0 %q, got %q", iface, have)
+	}
+	metrics[host.SwapUsage] = swap
+
 	// Should have metrics
 	for key, want := range metrics {
 		wantSample := want.LastSample()
@@ -0,0 +1,326 @@
+package host
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	linuxproc "github.com/c9s/goprocinfo/linux"
+
+	"$GITHUB_URI/report"
+)
+
+// Statfs is swappable for mocking in tests.
+var Statfs = syscall.Statfs
+
+// GetStats returns the usage of the host's filesystems, disks, network
+// interfaces and CPUs. Rates are over the time since the previous call.
+var GetStats = NewStatsReader("/proc").Read
+
+// Devices which are no disks, as named in /proc/diskstats
+var virtualDisks = []string{"loop", "ram", "zram"}
+
+// Devices stacked on other disks, whose I/O would be counted twice in the
+// host's totals.
+var stackedDisks = []string{"dm-", "md"}
+
+// StatsReader reads the usage of the host's resources from a proc
+// filesystem. It keeps the counters it read last, to compute rates.
+type StatsReader struct {
+	procRoot   string
+	last       time.Time
+	disks      map[string]linuxproc.DiskStat
+	interfaces map[string]linuxproc.NetworkStat
+	cpus       map[string]linuxproc.CPUStat
+}
+
+// NewStatsReader makes a new StatsReader reading from procRoot.
+func NewStatsReader(procRoot string) *StatsReader {
+	return &StatsReader{procRoot: procRoot}
+}
+
+// Read returns the usage of the host's resources. Rates are only known from
+// the second read on.
+func (r *StatsReader) Read(now time.Time) Stats {
+	stats := Stats{
+		Metrics: report.Metrics{},
+		Tables:  map[string]map[string]string{},
+	}
+	var interval float64
+	if !r.last.IsZero() {
+		interval = now.Sub(r.last).Seconds()
+	}
+	r.last = now
+
+	r.readFilesystems(now, stats)
+	r.readMemory(now, stats)
+	r.readDisks(now, interval, stats)
+	r.readInterfaces(now, interval, stats)
+	r.readCPUs(now, stats)
+	return stats
+}
+
+// readFilesystems reads the usage of the filesystems mounted. Filesystems
+// mounted more than once, e.g. bind mounts, are only counted at their first
+// mount point.
+func (r *StatsReader) readFilesystems(now time.Time, stats Stats) {
+	buf, err := ioutil.ReadFile(filepath.Join(r.procRoot, "mounts"))
+	if err != nil {
+		return
+	}
+	var (
+		used, total float64
+		rows        = map[string]string{}
+		seen        = map[syscall.Fsid]struct{}{}
+	)
+	for _, mountPoint := range parseMounts(buf) {
+		var statfs syscall.Statfs_t
+		if err := Statfs(mountPoint, &statfs); err != nil || statfs.Blocks == 0 {
+			continue
+		}
+		if statfs.Fsid != (syscall.Fsid{}) {
+			if _, ok := seen[statfs.Fsid]; ok {
+				continue
+			}
+			seen[statfs.Fsid] = struct{}{}
+		}
+		var (
+			size      = float64(statfs.Blocks) * float64(statfs.Bsize)
+			free      = float64(statfs.Bfree) * float64(statfs.Bsize)
+			available = float64(statfs.Bavail) * float64(statfs.Bsize)
+		)
+		// As df does, the space reserved to root counts as neither used nor
+		// available.
+		rows[mountPoint] = fmt.Sprintf("%s of %s used (%.0f%%)",
+			formatBytes(size-free), formatBytes(size), percent(size-free, size-free+available))
+		used += size - free
+		total += size
+	}
+	if len(rows) == 0 {
+		return
+	}
+	stats.Metrics[FilesystemUsage] = report.MakeMetric().Add(now, used).WithMax(total)
+	stats.Tables[FilesystemPrefix] = rows
+}
+
+// readMemory reads the usage of swap and, where the kernel supports pressure
+// stall information, the share of time tasks were stalled on memory.
+func (r *StatsReader) readMemory(now time.Time, stats Stats) {
+	if meminfo, err := linuxproc.ReadMemInfo(filepath.Join(r.procRoot, "meminfo")); err == nil && meminfo.SwapTotal > 0 {
+		used := float64((meminfo.SwapTotal - meminfo.SwapFree) * kb)
+		stats.Metrics[SwapUsage] = report.MakeMetric().Add(now, used).WithMax(float64(meminfo.SwapTotal * kb))
+	}
+	buf, err := ioutil.ReadFile(filepath.Join(r.procRoot, "pressure", "memory"))
+	if err != nil {
+		return
+	}
+	// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "some" || !strings.HasPrefix(fields[1], "avg10=") {
+			continue
+		}
+		if pressure, err := strconv.ParseFloat(strings.TrimPrefix(fields[1], "avg10="), 64); err == nil {
+			stats.Metrics[MemoryPressure] = report.MakeMetric().Add(now, pressure).WithMax(100)
+		}
+	}
+}
+
+// readDisks reads the throughput and latency of the disks. Partitions are
+// left out, and so are stacked devices (LVM, RAID) from the host's totals.
+func (r *StatsReader) readDisks(now time.Time, interval float64, stats Stats) {
+	diskStats, err := linuxproc.ReadDiskStats(filepath.Join(r.procRoot, "diskstats"))
+	if err != nil {
+		return
+	}
+	disks := map[string]linuxproc.DiskStat{}
+	for _, disk := range diskStats {
+		if !hasAnyPrefix(disk.Name, virtualDisks) {
+			disks[disk.Name] = disk
+		}
+	}
+	previous := r.disks
+	r.disks = disks
+	if interval <= 0 {
+		return
+	}
+
+	var (
+		read, written float64
+		rows          = map[string]string{}
+	)
+	for name, disk := range disks {
+		last, ok := previous[name]
+		if !ok || isPartition(name, disks) {
+			continue
+		}
+		var (
+			readRate  = delta(disk.ReadSectors, last.ReadSectors) * 512 / interval
+			writeRate = delta(disk.WriteSectors, last.WriteSectors) * 512 / interval
+			ios       = delta(disk.ReadIOs, last.ReadIOs) + delta(disk.WriteIOs, last.WriteIOs)
+			ticks     = delta(disk.ReadTicks, last.ReadTicks) + delta(disk.WriteTicks, last.WriteTicks)
+			latency   float64
+		)
+		if ios > 0 {
+			latency = ticks / ios
+		}
+		rows[name] = fmt.Sprintf("read %s/s, write %s/s, %.1f ms per I/O",
+			formatBytes(readRate), formatBytes(writeRate), latency)
+		if !hasAnyPrefix(name, stackedDisks) {
+			read += readRate
+			written += writeRate
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	stats.Metrics[DiskReadBytes] = report.MakeMetric().Add(now, read)
+	stats.Metrics[DiskWriteBytes] = report.MakeMetric().Add(now, written)
+	stats.Tables[DiskPrefix] = rows
+}
+
+// readInterfaces reads the throughput, errors and drops of the network
+// interfaces, except for loopback and the veths of containers.
+func (r *StatsReader) readInterfaces(now time.Time, interval float64, stats Stats) {
+	networkStats, err := linuxproc.ReadNetworkStat(filepath.Join(r.procRoot, "net", "dev"))
+	if err != nil {
+		return
+	}
+	interfaces := map[string]linuxproc.NetworkStat{}
+	for _, iface := range networkStats {
+		if iface.Iface != "" && iface.Iface != "lo" && !strings.HasPrefix(iface.Iface, "veth") {
+			interfaces[iface.Iface] = iface
+		}
+	}
+	previous := r.interfaces
+	r.interfaces = interfaces
+	if interval <= 0 {
+		return
+	}
+
+	var (
+		received, transmitted float64
+		rows                  = map[string]string{}
+	)
+	for name, iface := range interfaces {
+		last, ok := previous[name]
+		if !ok {
+			continue
+		}
+		var (
+			rxRate = delta(iface.RxBytes, last.RxBytes) / interval
+			txRate = delta(iface.TxBytes, last.TxBytes) / interval
+		)
+		rows[name] = fmt.Sprintf("rx %s/s, tx %s/s, %d errors, %d drops",
+			formatBytes(rxRate), formatBytes(txRate), iface.RxErrs+iface.TxErrs, iface.RxDrop+iface.TxDrop)
+		received += rxRate
+		transmitted += txRate
+	}
+	if len(rows) == 0 {
+		return
+	}
+	stats.Metrics[NetworkRxBytes] = report.MakeMetric().Add(now, received)
+	stats.Metrics[NetworkTxBytes] = report.MakeMetric().Add(now, transmitted)
+	stats.Tables[InterfacePrefix] = rows
+}
+
+// readCPUs reads the usage of each CPU, and the share of time the hypervisor
+// gave the host's CPUs to other guests.
+func (r *StatsReader) readCPUs(now time.Time, stats Stats) {
+	stat, err := linuxproc.ReadStat(filepath.Join(r.procRoot, "stat"))
+	if err != nil {
+		return
+	}
+	cpus := map[string]linuxproc.CPUStat{stat.CPUStatAll.Id: stat.CPUStatAll}
+	for _, cpu := range stat.CPUStats {
+		cpus[cpu.Id] = cpu
+	}
+	previous := r.cpus
+	r.cpus = cpus
+
+	rows := map[string]string{}
+	for id, cpu := range cpus {
+		last, ok := previous[id]
+		if !ok {
+			continue
+		}
+		var (
+			idle  = delta(cpu.Idle+cpu.IOWait, last.Idle+last.IOWait)
+			steal = delta(cpu.Steal, last.Steal)
+			total = delta(cpuTotal(cpu), cpuTotal(last))
+		)
+		if total == 0 {
+			continue
+		}
+		if id == stat.CPUStatAll.Id {
+			stats.Metrics[CPUStealUsage] = report.MakeMetric().Add(now, percent(steal, total)).WithMax(100)
+			continue
+		}
+		rows[id] = fmt.Sprintf("%.1f%% used, %.1f%% steal", percent(total-idle, total), percent(steal, total))
+	}
+	if len(rows) > 0 {
+		stats.Tables[CPUPrefix] = rows
+	}
+}
+
+func cpuTotal(cpu linuxproc.CPUStat) uint64 {
+	return cpu.User + cpu.Nice + cpu.System + cpu.Idle + cpu.IOWait + cpu.IRQ + cpu.SoftIRQ + cpu.Steal
+}
+
+// isPartition tells partitions, e.g. sda1 or nvme0n1p1, from the disks they
+// are on.
+func isPartition(name string, disks map[string]linuxproc.DiskStat) bool {
+	disk := strings.TrimRight(name, "0123456789")
+	if disk == name {
+		return false
+	}
+	if _, ok := disks[disk]; ok {
+		return true
+	}
+	if strings.HasSuffix(disk, "p") {
+		_, ok := disks[strings.TrimSuffix(disk, "p")]
+		return ok
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// delta is the increase of a counter, which is none if it was reset.
+func delta(current, previous uint64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current - previous)
+}
+
+func percent(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part * 100 / whole
+}
+
+// formatBytes formats a number of bytes with SI units, e.g. "1.2 MB".
+func formatBytes(bytes float64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB", "PB"}
+	i := 0
+	for ; bytes >= 1000 && i < len(units)-1; i++ {
+		bytes /= 1000
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", bytes, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
@@ -0,0 +1,153 @@
+package host_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"$GITHUB_URI/probe/host"
+)
+
+// Two snapshots of a proc filesystem, ten seconds apart.
+var statsFixtures = []map[string]string{
+	{
+		"mounts": `/dev/sda1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/sda1 /var/lib/docker ext4 rw,relatime 0 0
+/dev/sdb /data xfs rw,relatime 0 0
+`,
+		"meminfo": `MemTotal:        8000000 kB
+MemFree:         4000000 kB
+SwapTotal:       2000000 kB
+SwapFree:        1500000 kB
+`,
+		"pressure/memory": `some avg10=1.50 avg60=0.80 avg300=0.20 total=123456
+full avg10=0.50 avg60=0.20 avg300=0.10 total=23456
+`,
+		"diskstats": `   8       0 sda 1000 0 20000 500 2000 0 40000 1500 0 2000 2000
+   8       1 sda1 1000 0 20000 500 2000 0 40000 1500 0 2000 2000
+ 253       0 dm-0 100 0 2000 50 200 0 4000 150 0 200 200
+   7       0 loop0 10 0 20 0 0 0 0 0 0 0 0
+`,
+		"net/dev": `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 5000 50 0 0 0 0 0 0 5000 50 0 0 0 0 0 0
+  eth0: 100000 1000 1 2 0 0 0 0 50000 500 0 0 0 0 0 0
+vethabc: 7000 70 0 0 0 0 0 0 7000 70 0 0 0 0 0 0
+`,
+		"stat": `cpu  400 0 100 1400 0 0 0 100 0 0
+cpu0 200 0 50 700 0 0 0 50 0 0
+cpu1 200 0 50 700 0 0 0 50 0 0
+`,
+	},
+	{
+		"diskstats": `   8       0 sda 1100 0 40480 600 2100 0 60480 1700 0 2100 2100
+   8       1 sda1 1100 0 40480 600 2100 0 60480 1700 0 2100 2100
+ 253       0 dm-0 200 0 22480 150 200 0 4000 150 0 300 300
+   7       0 loop0 10 0 20 0 0 0 0 0 0 0 0
+`,
+		"net/dev": `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 9000 90 0 0 0 0 0 0 9000 90 0 0 0 0 0 0
+  eth0: 120000 1200 1 3 0 0 0 0 60000 600 1 0 0 0 0 0
+vethabc: 9000 90 0 0 0 0 0 0 9000 90 0 0 0 0 0 0
+`,
+		"stat": `cpu  500 0 100 1480 0 0 0 120 0 0
+cpu0 300 0 50 700 0 0 0 50 0 0
+cpu1 200 0 50 780 0 0 0 70 0 0
+`,
+	},
+}
+
+func TestStatsReader(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(procRoot)
+
+	oldStatfs := host.Statfs
+	defer func() { host.Statfs = oldStatfs }()
+	host.Statfs = func(path string, statfs *syscall.Statfs_t) error {
+		switch path {
+		case "/", "/var/lib/docker":
+			*statfs = syscall.Statfs_t{Bsize: 1000, Blocks: 1000000, Bfree: 600000, Bavail: 500000, Fsid: syscall.Fsid{X__val: [2]int32{1, 0}}}
+		case "/data":
+			*statfs = syscall.Statfs_t{Bsize: 1000, Blocks: 2000000, Bfree: 2000000, Bavail: 2000000, Fsid: syscall.Fsid{X__val: [2]int32{2, 0}}}
+		default:
+			return syscall.ENOENT
+		}
+		return nil
+	}
+
+	var (
+		reader = host.NewStatsReader(procRoot)
+		start  = time.Now()
+		stats  host.Stats
+	)
+	for i, fixture := range statsFixtures {
+		for name, contents := range fixture {
+			filename := filepath.Join(procRoot, name)
+			if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stats = reader.Read(start.Add(time.Duration(i) * 10 * time.Second))
+		if i == 0 {
+			if _, ok := stats.Metrics[host.DiskReadBytes]; ok {
+				t.Errorf("Expected no rates on the first read, got %v", stats.Metrics)
+			}
+		}
+	}
+
+	for key, want := range map[string]struct{ value, max float64 }{
+		host.FilesystemUsage: {400000000, 3000000000},
+		host.SwapUsage:       {500000 * 1024, 2000000 * 1024},
+		host.MemoryPressure:  {1.5, 100},
+		host.DiskReadBytes:   {20480 * 512 / 10, 20480 * 512 / 10},
+		host.DiskWriteBytes:  {20480 * 512 / 10, 20480 * 512 / 10},
+		host.NetworkRxBytes:  {2000, 2000},
+		host.NetworkTxBytes:  {1000, 1000},
+		host.CPUStealUsage:   {10, 100},
+	} {
+		metric, ok := stats.Metrics[key]
+		if !ok {
+			t.Errorf("Expected %s metric, but not found", key)
+			continue
+		}
+		if have := metric.LastSample().Value; have != want.value || metric.Max != want.max {
+			t.Errorf("Expected %s metric %f (max %f), got %f (max %f)", key, want.value, want.max, have, metric.Max)
+		}
+	}
+
+	want := map[string]map[string]string{
+		host.FilesystemPrefix: {
+			"/":     "400.0 MB of 1.0 GB used (44%)",
+			"/data": "0 B of 2.0 GB used (0%)",
+		},
+		host.DiskPrefix: {
+			"sda":  "read 1.0 MB/s, write 1.0 MB/s, 1.5 ms per I/O",
+			"dm-0": "read 1.0 MB/s, write 0 B/s, 1.0 ms per I/O",
+		},
+		host.InterfacePrefix: {
+			"eth0": "rx 2.0 kB/s, tx 1.0 kB/s, 2 errors, 3 drops",
+		},
+		host.CPUPrefix: {
+			"cpu0": "100.0% used, 0.0% steal",
+			"cpu1": "20.0% used, 20.0% steal",
+		},
+	}
+	if !reflect.DeepEqual(want, stats.Tables) {
+		t.Errorf("Expected tables %v, got %v", want, stats.Tables)
+	}
+	if _, ok := stats.Metrics[host.CPUUsage]; ok {
+		t.Errorf("Expected the CPU usage to be left to GetCPUUsagePercent")
+	}
+}
@@ -83,3 +83,15 @@ var GetCPUUsagePercent = func() (float64, float64) {
 var GetMemoryUsageBytes = func() (float64, float64) {
 	return 0.0, 0.0
 }
+
+// GetMounts returns the mount points of the host's filesystems. It is not
+// implemented on darwin.
+var GetMounts = func() []string {
+	return nil
+}
+
+// GetStats returns the usage of the host's filesystems, disks, network
+// interfaces and CPUs. It is not implemented on darwin.
+var GetStats = func(time.Time) Stats {
+	return Stats{}
+}
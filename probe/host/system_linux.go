@@ -107,3 +107,43 @@ var GetMemoryUsageBytes = func() (float64, float64) {
 	used := meminfo.MemTotal - meminfo.MemFree - meminfo.Buffers - meminfo.Cached
 	return float64(used * kb), float64(meminfo.MemTotal * kb)
 }
+
+// Filesystems which never hold data worth tracking, so are left out of the
+// host's mounts.
+var virtualFilesystems = map[string]struct{}{
+	"autofs": {}, "binfmt_misc": {}, "bpf": {}, "cgroup": {}, "cgroup2": {},
+	"configfs": {}, "debugfs": {}, "devpts": {}, "devtmpfs": {}, "fusectl": {},
+	"hugetlbfs": {}, "mqueue": {}, "nsfs": {}, "proc": {}, "pstore": {},
+	"rpc_pipefs": {}, "securityfs": {}, "sysfs": {}, "tmpfs": {}, "tracefs": {},
+	"aufs": {}, "overlay": {}, "shm": {},
+}
+
+// GetMounts returns the mount points of the real (disk or network)
+// filesystems on the host.
+var GetMounts = func() []string {
+	buf, err := ioutil.ReadFile(ProcMounts)
+	if err != nil {
+		return nil
+	}
+	return parseMounts(buf)
+}
+
+func parseMounts(buf []byte) []string {
+	result := []string{}
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		if _, ok := virtualFilesystems[fields[2]]; ok {
+			continue
+		}
+		// Spaces and the like are octal-escaped
+		mountPoint, err := strconv.Unquote(`"` + strings.Replace(fields[1], `"`, `\"`, -1) + `"`)
+		if err != nil {
+			mountPoint = fields[1]
+		}
+		result = append(result, mountPoint)
+	}
+	return result
+}
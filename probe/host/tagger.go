@@ -6,7 +6,8 @@ import (
 
 // Tagger tags each node in each topology of a report with the origin host
 // node ID of this (probe) host. Effectively, a foreign key linking every node
-// in every topology to an origin host node in the host topology.
+// in every topology to an origin host node in the host topology. Nodes
+// already tagged with another host, as reported on behalf of it, keep it.
 type Tagger struct {
 	hostNodeID string
 }
@@ -33,6 +34,9 @@ func (t Tagger) Tag(r report.Report) (report.Report, error) {
 	// and as such do their own host tagging
 	for _, topology := range []report.Topology{r.Process, r.Container, r.ContainerImage, r.Host, r.Overlay, r.Pod} {
 		for _, node := range topology.Nodes {
+			if hostNodeID, ok := node.Latest.Lookup(report.HostNodeID); ok && hostNodeID != t.hostNodeID {
+				continue
+			}
 			topology.AddNode(node.WithLatests(metadata).WithParents(parents))
 		}
 	}
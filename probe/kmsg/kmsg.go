@@ -0,0 +1,180 @@
+// Package kmsg follows the kernel log for the events which matter to the
+// processes and containers of a host: OOM kills, hung tasks and segfaults.
+package kmsg
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of kernel events
+const (
+	OOMKill  = "oom-kill"
+	HungTask = "hung-task"
+	Segfault = "segfault"
+)
+
+// Event is a kernel event concerning a process.
+type Event struct {
+	Time    time.Time
+	Kind    string
+	PID     int
+	Command string
+	Cgroup  string // of the process, when known
+	Details string
+}
+
+// String gives the human readable form of the event, used as the value in
+// the events table, eg "oom-kill: java (1234), anon-rss:1048576kB".
+func (e Event) String() string {
+	s := fmt.Sprintf("%s: %s (%d)", e.Kind, e.Command, e.PID)
+	if e.Details != "" {
+		s += ", " + e.Details
+	}
+	return s
+}
+
+var (
+	// Out of memory: Killed process 1234 (java) total-vm:2000000kB, anon-rss:1048576kB, ...
+	// Memory cgroup out of memory: Killed process 1234 (java) total-vm:...
+	oomKilledRe = regexp.MustCompile(`Killed process (\d+) \((.*?)\)(?:.* (anon-rss:\d+kB))?`)
+	// INFO: task java:1234 blocked for more than 120 seconds.
+	hungTaskRe = regexp.MustCompile(`task (.+):(\d+) blocked for (more than \d+ seconds)`)
+	// java[1234]: segfault at 0 ip 00007f2c8a0b1234 sp 00007ffd2c3b4f18 error 4 in libc.so.6[7f2c8a000000+1c5000]
+	segfaultRe = regexp.MustCompile(`^(.+)\[(\d+)\]: segfault (at .*)$`)
+	// [12345.678901] at the start of dmesg and syslog lines
+	timestampRe = regexp.MustCompile(`^\[\s*\d+\.\d+\]\s*`)
+)
+
+// parser turns kernel messages into events. The kernel logs the cgroup of
+// the victim of the OOM killer in a line of its own, before killing it.
+type parser struct {
+	oomCgroups map[int]string
+}
+
+func newParser() *parser {
+	return &parser{oomCgroups: map[int]string{}}
+}
+
+// parse returns the event a kernel message is about, if any.
+func (p *parser) parse(t time.Time, msg string) (Event, bool) {
+	msg = strings.TrimSpace(msg)
+	if strings.HasPrefix(msg, "oom-kill:") {
+		// oom-kill:constraint=CONSTRAINT_MEMCG,...,task_memcg=/docker/abc,task=java,pid=1234,uid=0
+		var (
+			cgroup string
+			pid    int
+		)
+		for _, kv := range strings.Split(strings.TrimPrefix(msg, "oom-kill:"), ",") {
+			if i := strings.Index(kv, "="); i >= 0 {
+				switch kv[:i] {
+				case "task_memcg":
+					cgroup = kv[i+1:]
+				case "pid":
+					pid, _ = strconv.Atoi(kv[i+1:])
+				}
+			}
+		}
+		if pid > 0 && cgroup != "" {
+			p.oomCgroups[pid] = cgroup
+		}
+		return Event{}, false
+	}
+	if m := oomKilledRe.FindStringSubmatch(msg); m != nil {
+		pid, _ := strconv.Atoi(m[1])
+		cgroup := p.oomCgroups[pid]
+		delete(p.oomCgroups, pid)
+		return Event{Time: t, Kind: OOMKill, PID: pid, Command: m[2], Cgroup: cgroup, Details: m[3]}, true
+	}
+	if m := hungTaskRe.FindStringSubmatch(msg); m != nil {
+		pid, _ := strconv.Atoi(m[2])
+		return Event{Time: t, Kind: HungTask, PID: pid, Command: m[1], Details: "blocked for " + m[3]}, true
+	}
+	if m := segfaultRe.FindStringSubmatch(msg); m != nil {
+		pid, _ := strconv.Atoi(m[2])
+		return Event{Time: t, Kind: Segfault, PID: pid, Command: m[1], Details: m[3]}, true
+	}
+	return Event{}, false
+}
+
+// parseRecord parses a /dev/kmsg record, e.g.
+// "6,1234,5678901,-;message\n KEY=value", into the time of the message,
+// given that of boot, and the message.
+func parseRecord(record string, bootTime time.Time) (time.Time, string, bool) {
+	i := strings.Index(record, ";")
+	if i < 0 {
+		return time.Time{}, "", false
+	}
+	fields := strings.Split(record[:i], ",")
+	if len(fields) < 3 {
+		return time.Time{}, "", false
+	}
+	usecs, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	msg := record[i+1:]
+	if j := strings.Index(msg, "\n"); j >= 0 {
+		msg = msg[:j]
+	}
+	return bootTime.Add(time.Duration(usecs) * time.Microsecond), msg, true
+}
+
+// parseLogLine extracts the kernel message out of a line of a log file: of
+// syslog, e.g. "Oct 19 18:24:38 host kernel: [12345.678901] message", or
+// of dmesg, e.g. "[12345.678901] message".
+func parseLogLine(line string) (string, bool) {
+	if i := strings.Index(line, "kernel: "); i >= 0 {
+		line = line[i+len("kernel: "):]
+	} else if !timestampRe.MatchString(line) {
+		return "", false
+	}
+	return timestampRe.ReplaceAllString(line, ""), true
+}
+
+// ContainerCgroupPrefixes are those of the cgroups runtimes name after their
+// containers, either plainly, e.g. /docker/<id>, or as systemd scopes, e.g.
+// docker-<id>.scope.
+var ContainerCgroupPrefixes = []string{"docker-", "cri-containerd-", "crio-", "libpod-"}
+
+var containerIDRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ContainerIDFromCgroup returns the ID of the container a cgroup path
+// belongs to, if any.
+func ContainerIDFromCgroup(cgroup string) (string, bool) {
+	for cgroup != "/" && cgroup != "." && cgroup != "" {
+		name := strings.TrimSuffix(path.Base(cgroup), ".scope")
+		for _, prefix := range ContainerCgroupPrefixes {
+			name = strings.TrimPrefix(name, prefix)
+		}
+		if containerIDRe.MatchString(name) {
+			return name, true
+		}
+		cgroup = path.Dir(cgroup)
+	}
+	return "", false
+}
+
+var podUIDRe = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// PodUIDFromCgroup returns the UID of the kubernetes pod a cgroup path
+// belongs to, if any. The kubelet names pod cgroups pod<uid>, or
+// kubepods-<qos>-pod<uid>.slice with underscores for dashes under systemd.
+func PodUIDFromCgroup(cgroup string) (string, bool) {
+	for _, name := range strings.Split(cgroup, "/") {
+		name = strings.TrimSuffix(name, ".slice")
+		i := strings.LastIndex(name, "pod")
+		if i < 0 || (i > 0 && name[i-1] != '-') {
+			continue
+		}
+		uid := strings.Replace(name[i+len("pod"):], "_", "-", -1)
+		if podUIDRe.MatchString(uid) {
+			return uid, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,98 @@
+package kmsg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	var (
+		ts     = time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+		cgroup = "/kubepods/burstable/pod0b6c5f28-3c5e-11e7-b8a3-0800274a6b6e/4d1b9b1e0c6b1c8d0e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d"
+	)
+	p := newParser()
+	for _, input := range []struct {
+		msg  string
+		want *Event
+	}{
+		{"java invoked oom-killer: gfp_mask=0x24000c0, order=0, oom_score_adj=-998", nil},
+		{"oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=4d1b9b,mems_allowed=0,oom_memcg=" + cgroup + ",task_memcg=" + cgroup + ",task=java,pid=1234,uid=0", nil},
+		{
+			"Memory cgroup out of memory: Killed process 1234 (java) total-vm:2000000kB, anon-rss:1048576kB, file-rss:0kB, shmem-rss:0kB",
+			&Event{Time: ts, Kind: OOMKill, PID: 1234, Command: "java", Cgroup: cgroup, Details: "anon-rss:1048576kB"},
+		},
+		{
+			"Killed process 1234 (java)",
+			&Event{Time: ts, Kind: OOMKill, PID: 1234, Command: "java"},
+		},
+		{"Out of memory: Kill process 4321 (mysqld) score 800 or sacrifice child", nil},
+		{
+			"INFO: task jbd2/sda1-8:321 blocked for more than 120 seconds.",
+			&Event{Time: ts, Kind: HungTask, PID: 321, Command: "jbd2/sda1-8", Details: "blocked for more than 120 seconds"},
+		},
+		{
+			"python3[5678]: segfault at 0 ip 00007f2c8a0b1234 sp 00007ffd2c3b4f18 error 4 in libc.so.6[7f2c8a000000+1c5000]",
+			&Event{Time: ts, Kind: Segfault, PID: 5678, Command: "python3", Details: "at 0 ip 00007f2c8a0b1234 sp 00007ffd2c3b4f18 error 4 in libc.so.6[7f2c8a000000+1c5000]"},
+		},
+		{"eth0: link up", nil},
+	} {
+		have, ok := p.parse(ts, input.msg)
+		if input.want == nil {
+			if ok {
+				t.Errorf("%q: expected no event, got %v", input.msg, have)
+			}
+			continue
+		}
+		if !ok || !reflect.DeepEqual(*input.want, have) {
+			t.Errorf("%q: expected %v, got %v", input.msg, *input.want, have)
+		}
+	}
+}
+
+func TestParseRecord(t *testing.T) {
+	bootTime := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts, msg, ok := parseRecord("3,1234,5678901,-;python3[5678]: segfault at 0\n SUBSYSTEM=foo\n", bootTime)
+	if !ok || msg != "python3[5678]: segfault at 0" || !ts.Equal(bootTime.Add(5678901*time.Microsecond)) {
+		t.Errorf("Unexpected %v %q %v", ts, msg, ok)
+	}
+	if _, _, ok := parseRecord("garbage", bootTime); ok {
+		t.Errorf("Expected garbage not to parse")
+	}
+
+	for line, want := range map[string]string{
+		"Oct 19 18:24:38 host kernel: [12345.678901] Killed process 1234 (java)": "Killed process 1234 (java)",
+		"Oct 19 18:24:38 host kernel: Killed process 1234 (java)":                "Killed process 1234 (java)",
+		"[    5.123456] Killed process 1234 (java)":                              "Killed process 1234 (java)",
+		"Oct 19 18:24:38 host sshd[42]: Accepted publickey":                      "",
+	} {
+		if have, _ := parseLogLine(line); have != want {
+			t.Errorf("%q: expected %q, got %q", line, want, have)
+		}
+	}
+}
+
+func TestFromCgroup(t *testing.T) {
+	const (
+		containerID = "4d1b9b1e0c6b1c8d0e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d"
+		podUID      = "0b6c5f28-3c5e-11e7-b8a3-0800274a6b6e"
+	)
+	for _, input := range []struct {
+		cgroup, containerID, podUID string
+	}{
+		{"/docker/" + containerID, containerID, ""},
+		{"/system.slice/docker-" + containerID + ".scope", containerID, ""},
+		{"/kubepods/burstable/pod" + podUID + "/" + containerID, containerID, podUID},
+		{"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0b6c5f28_3c5e_11e7_b8a3_0800274a6b6e.slice/cri-containerd-" + containerID + ".scope", containerID, podUID},
+		{"/kubepods.slice/kubepods-pod0b6c5f28_3c5e_11e7_b8a3_0800274a6b6e.slice/crio-conmon-" + containerID + ".scope", "", podUID},
+		{"/system.slice/sshd.service", "", ""},
+		{"", "", ""},
+	} {
+		if have, _ := ContainerIDFromCgroup(input.cgroup); have != input.containerID {
+			t.Errorf("%q: expected container %q, got %q", input.cgroup, input.containerID, have)
+		}
+		if have, _ := PodUIDFromCgroup(input.cgroup); have != input.podUID {
+			t.Errorf("%q: expected pod %q, got %q", input.cgroup, input.podUID, have)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package kmsg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/host"
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/report"
+)
+
+// DefaultPath is where the kernel log is read from.
+const DefaultPath = "/dev/kmsg"
+
+// EventPrefix is the prefix of the node table holding the kernel events of
+// a process or pod. Those of containers go in their events table, with their
+// lifecycle events.
+const EventPrefix = "kernel_event_"
+
+// Keys of the counts of the events of a node, over the eventRetention.
+const (
+	OOMKills  = "kernel_oom_kills"
+	HungTasks = "kernel_hung_tasks"
+	Segfaults = "kernel_segfaults"
+)
+
+const (
+	// How many events, and for how long, are kept
+	maxEvents      = 1000
+	eventRetention = 24 * time.Hour
+
+	// How often a log file is checked for more lines at its end
+	pollInterval = time.Second
+)
+
+var counterKeys = map[string]string{
+	OOMKill:  OOMKills,
+	HungTask: HungTasks,
+	Segfault: Segfaults,
+}
+
+// Exposed for testing
+var (
+	MetadataTemplates = report.MetadataTemplates{
+		OOMKills:  {ID: OOMKills, Label: "OOM kills (24h)", From: report.FromLatest, Datatype: "number", Priority: 20},
+		HungTasks: {ID: HungTasks, Label: "Hung tasks (24h)", From: report.FromLatest, Datatype: "number", Priority: 21},
+		Segfaults: {ID: Segfaults, Label: "Segfaults (24h)", From: report.FromLatest, Datatype: "number", Priority: 22},
+	}
+
+	TableTemplates = report.TableTemplates{
+		EventPrefix: {ID: EventPrefix, Label: "Kernel Events", Prefix: EventPrefix},
+	}
+)
+
+// Tagger follows the kernel log, and adds the OOM kills, hung tasks and
+// segfaults it finds to the processes, containers and pods they concern.
+type Tagger struct {
+	hostID string
+	kmsg   *os.File // closed to interrupt reading
+	parser *parser
+	quit   chan struct{}
+	done   chan struct{}
+
+	mtx    sync.Mutex
+	events []Event
+	// The cgroups of the processes last reported, by PID, for those which
+	// die of the next event.
+	cgroups map[int]string
+}
+
+// NewTagger makes a new Tagger following the kernel log at path: either
+// /dev/kmsg, which is read from its oldest message, or a log file, e.g.
+// /var/log/kern.log, which is read from its end.
+func NewTagger(hostID, path string) (*Tagger, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	t := newTagger(hostID)
+	if info.Mode()&os.ModeCharDevice != 0 {
+		t.kmsg = file
+		go t.readKmsg()
+	} else {
+		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+			file.Close()
+			return nil, err
+		}
+		go t.readLog(path, file)
+	}
+	return t, nil
+}
+
+func newTagger(hostID string) *Tagger {
+	return &Tagger{
+		hostID:  hostID,
+		parser:  newParser(),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		cgroups: map[int]string{},
+	}
+}
+
+// Name of this tagger, for metrics gathering
+func (*Tagger) Name() string { return "Kmsg" }
+
+// Stop stops following the kernel log.
+func (t *Tagger) Stop() {
+	close(t.quit)
+	if t.kmsg != nil {
+		t.kmsg.Close()
+	}
+	<-t.done
+}
+
+// readKmsg reads /dev/kmsg, which returns a record per read.
+func (t *Tagger) readKmsg() {
+	defer close(t.done)
+	uptime, err := host.GetUptime()
+	if err != nil {
+		log.Warnf("Kmsg: cannot tell the time of kernel messages: %v", err)
+	}
+	bootTime := mtime.Now().Add(-uptime)
+	buf := make([]byte, 8192)
+	for {
+		n, err := t.kmsg.Read(buf)
+		select {
+		case <-t.quit:
+			return
+		default:
+		}
+		if pathErr, ok := err.(*os.PathError); ok && pathErr.Err == syscall.EPIPE {
+			// The oldest messages were overwritten before being read
+			continue
+		} else if err != nil {
+			log.Errorf("Kmsg: error reading the kernel log: %v", err)
+			return
+		}
+		if ts, msg, ok := parseRecord(string(buf[:n]), bootTime); ok {
+			t.handle(ts, msg)
+		}
+	}
+}
+
+// readLog follows a log file, reopening it once rotated.
+func (t *Tagger) readLog(path string, file *os.File) {
+	defer close(t.done)
+	defer func() { file.Close() }()
+	var (
+		reader  = bufio.NewReader(file)
+		partial string
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		partial += line
+		if err == nil {
+			if msg, ok := parseLogLine(strings.TrimSuffix(partial, "\n")); ok {
+				t.handle(mtime.Now(), msg)
+			}
+			partial = ""
+			continue
+		} else if err != io.EOF {
+			log.Errorf("Kmsg: error reading %s: %v", path, err)
+			return
+		}
+
+		select {
+		case <-t.quit:
+			return
+		case <-time.After(pollInterval):
+		}
+		if rotated(path, file) {
+			reopened, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			file.Close()
+			file, reader, partial = reopened, bufio.NewReader(reopened), ""
+		}
+	}
+}
+
+// rotated tells whether the file at path is no longer the one being read,
+// or was truncated.
+func rotated(path string, file *os.File) bool {
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil || !os.SameFile(info, current) {
+		return true
+	}
+	offset, err := file.Seek(0, io.SeekCurrent)
+	return err == nil && current.Size() < offset
+}
+
+// handle records the event a kernel message is about, if any.
+func (t *Tagger) handle(ts time.Time, msg string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	event, ok := t.parser.parse(ts, msg)
+	if !ok {
+		return
+	}
+	if event.Cgroup == "" {
+		event.Cgroup = t.cgroups[event.PID]
+	}
+	log.Infof("Kmsg: %s", event)
+	t.events = append(t.events, event)
+	if len(t.events) > maxEvents {
+		t.events = t.events[len(t.events)-maxEvents:]
+	}
+}
+
+// Tag implements Tagger. It adds the events of the last eventRetention to
+// the processes, containers and pods they concern, when reported.
+func (t *Tagger) Tag(rpt report.Report) (report.Report, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	cgroups := map[int]string{}
+	for _, node := range rpt.Process.Nodes {
+		pid, ok := node.Latest.Lookup(process.PID)
+		if !ok {
+			continue
+		}
+		if cgroup, ok := node.Latest.Lookup(process.Cgroup); ok {
+			if pid, err := strconv.Atoi(pid); err == nil {
+				cgroups[pid] = cgroup
+			}
+		}
+	}
+	t.cgroups = cgroups
+
+	now := mtime.Now()
+	events := t.events[:0]
+	for _, event := range t.events {
+		if now.Sub(event.Time) <= eventRetention {
+			events = append(events, event)
+		}
+	}
+	t.events = events
+
+	// The pods with containers on this host
+	pods := map[string]struct{}{}
+	for _, node := range rpt.Container.Nodes {
+		if uid, ok := node.Latest.Lookup(docker.LabelPrefix + "io.kubernetes.pod.uid"); ok {
+			pods[uid] = struct{}{}
+		}
+	}
+
+	var (
+		processEvents   = map[string][]Event{}
+		containerEvents = map[string][]Event{}
+		podEvents       = map[string][]Event{}
+	)
+	for _, event := range t.events {
+		processID := report.MakeProcessNodeID(t.hostID, strconv.Itoa(event.PID))
+		if node, ok := rpt.Process.Nodes[processID]; ok && !startedAfter(node, event.Time) {
+			processEvents[processID] = append(processEvents[processID], event)
+		}
+		containerID, ok := ContainerIDFromCgroup(event.Cgroup)
+		if !ok {
+			continue
+		}
+		containerNodeID := report.MakeContainerNodeID(containerID)
+		container, ok := rpt.Container.Nodes[containerNodeID]
+		if !ok {
+			continue
+		}
+		containerEvents[containerNodeID] = append(containerEvents[containerNodeID], event)
+		uid, ok := PodUIDFromCgroup(event.Cgroup)
+		if !ok {
+			uid, ok = container.Latest.Lookup(docker.LabelPrefix + "io.kubernetes.pod.uid")
+		}
+		if _, found := pods[uid]; ok && found {
+			podNodeID := report.MakePodNodeID(uid)
+			podEvents[podNodeID] = append(podEvents[podNodeID], event)
+		}
+	}
+
+	for id, events := range processEvents {
+		rpt.Process = rpt.Process.AddNode(withEvents(report.MakeNode(id).WithTopology(report.Process), events))
+	}
+	for id, events := range containerEvents {
+		node := docker.WithContainerEvents(report.MakeNode(id).WithTopology(report.Container), makeContainerEvents(events))
+		rpt.Container = rpt.Container.AddNode(withCounts(node, events))
+	}
+	for id, events := range podEvents {
+		rpt.Pod = rpt.Pod.AddNode(withEvents(report.MakeNode(id).WithTopology(report.Pod), events))
+	}
+	for _, topology := range []*report.Topology{&rpt.Process, &rpt.Pod} {
+		*topology = topology.WithTableTemplates(TableTemplates)
+	}
+	for _, topology := range []*report.Topology{&rpt.Process, &rpt.Container, &rpt.Pod} {
+		*topology = topology.WithMetadataTemplates(MetadataTemplates)
+	}
+	return rpt, nil
+}
+
+// startedAfter tells whether a process was started after time t, which
+// makes it a later one with the same PID.
+func startedAfter(node report.Node, t time.Time) bool {
+	started, ok := node.Latest.Lookup(process.StartTime)
+	if !ok {
+		return false
+	}
+	startTime, err := time.Parse(time.RFC3339, started)
+	// Start times are reported to the second
+	return err == nil && startTime.After(t.Truncate(time.Second))
+}
+
+// withEvents adds the events table and the counts of events to a node.
+func withEvents(node report.Node, events []Event) report.Node {
+	rows := make([]report.TimelineRow, 0, len(events))
+	for _, event := range events {
+		rows = append(rows, report.TimelineRow{Time: event.Time, Value: event.String()})
+	}
+	return withCounts(node.AddTimeline(EventPrefix, rows), events)
+}
+
+// withCounts adds the counts of events of each kind to a node.
+func withCounts(node report.Node, events []Event) report.Node {
+	var (
+		now    = mtime.Now()
+		counts = map[string]int{}
+	)
+	for _, event := range events {
+		counts[event.Kind]++
+	}
+	for kind, key := range counterKeys {
+		node = node.WithLatest(key, now, strconv.Itoa(counts[kind]))
+	}
+	return node
+}
+
+// makeContainerEvents makes the container events of kernel events, so they
+// show on the timeline of containers with their lifecycle events.
+func makeContainerEvents(events []Event) []docker.ContainerEvent {
+	result := make([]docker.ContainerEvent, 0, len(events))
+	for _, event := range events {
+		details := fmt.Sprintf("%s (%d)", event.Command, event.PID)
+		if event.Details != "" {
+			details += ", " + event.Details
+		}
+		result = append(result, docker.ContainerEvent{
+			Time:    event.Time,
+			Action:  event.Kind,
+			Details: details,
+		})
+	}
+	return result
+}
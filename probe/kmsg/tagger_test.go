@@ -0,0 +1,148 @@
+package kmsg
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/report"
+)
+
+const (
+	testContainerID = "4d1b9b1e0c6b1c8d0e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d"
+	testPodUID      = "0b6c5f28-3c5e-11e7-b8a3-0800274a6b6e"
+	testCgroup      = "/kubepods/burstable/pod" + testPodUID + "/" + testContainerID
+)
+
+func TestTag(t *testing.T) {
+	now := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	mtime.NowForce(now)
+	defer mtime.NowReset()
+
+	var (
+		processID   = report.MakeProcessNodeID("host1", "1234")
+		reusedID    = report.MakeProcessNodeID("host1", "4321")
+		containerID = report.MakeContainerNodeID(testContainerID)
+		podID       = report.MakePodNodeID(testPodUID)
+		rpt         = report.MakeReport()
+	)
+	rpt.Process.AddNode(report.MakeNodeWith(processID, map[string]string{
+		process.PID:    "1234",
+		process.Cgroup: testCgroup,
+	}).WithTopology(report.Process))
+	rpt.Container.AddNode(report.MakeNodeWith(containerID, map[string]string{
+		docker.LabelPrefix + "io.kubernetes.pod.uid": testPodUID,
+	}).WithTopology(report.Container))
+
+	tagger := newTagger("host1")
+	// The cgroup of the process is remembered from the report
+	tagger.Tag(rpt)
+	tagger.handle(now.Add(-time.Minute), "INFO: task java:1234 blocked for more than 120 seconds.")
+	tagger.handle(now.Add(-time.Minute), "python3[4321]: segfault at 0 ip 00007f2c8a0b1234 sp 00007ffd2c3b4f18 error 4 in libc.so.6")
+	tagger.handle(now.Add(-25*time.Hour), "Killed process 1234 (java)")
+
+	// The process which segfaulted is gone, and another got its PID since.
+	rpt.Process.AddNode(report.MakeNodeWith(reusedID, map[string]string{
+		process.PID:       "4321",
+		process.StartTime: now.Format(time.RFC3339),
+	}).WithTopology(report.Process))
+	rpt, _ = tagger.Tag(rpt)
+
+	for _, id := range []string{processID, containerID, podID} {
+		var node report.Node
+		for _, topology := range []report.Topology{rpt.Process, rpt.Container, rpt.Pod} {
+			if n, ok := topology.Nodes[id]; ok {
+				node = n
+			}
+		}
+		if id == containerID {
+			// Containers' kernel events are on their timeline
+			events := docker.ParseContainerEvents(node)
+			if len(events) != 1 || events[0].Action != HungTask || events[0].Details != "java (1234), blocked for more than 120 seconds" {
+				t.Errorf("%s: expected the hung task event, got %v", id, events)
+			}
+		} else if rows := node.ExtractTimeline(EventPrefix); len(rows) != 1 {
+			t.Errorf("%s: expected one event, got %v", id, rows)
+		}
+		for key, want := range map[string]string{HungTasks: "1", OOMKills: "0", Segfaults: "0"} {
+			if have, _ := node.Latest.Lookup(key); have != want {
+				t.Errorf("%s: expected %s %q, got %q", id, key, want, have)
+			}
+		}
+	}
+	if _, ok := rpt.Process.Nodes[reusedID].Latest.Lookup(Segfaults); ok {
+		t.Errorf("Expected the segfault not to be attributed to a later process")
+	}
+	if _, ok := rpt.Pod.Nodes[podID].Latest.Lookup(report.ControlProbeID); ok || rpt.Pod.Nodes[podID].Topology != report.Pod {
+		t.Errorf("Unexpected pod node %v", rpt.Pod.Nodes[podID])
+	}
+}
+
+func TestTagCapsEvents(t *testing.T) {
+	now := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	mtime.NowForce(now)
+	defer mtime.NowReset()
+
+	processID := report.MakeProcessNodeID("host1", "1234")
+	rpt := report.MakeReport()
+	rpt.Process.AddNode(report.MakeNodeWith(processID, map[string]string{
+		process.PID: "1234",
+	}).WithTopology(report.Process))
+
+	tagger := newTagger("host1")
+	for i := 0; i < report.MaxTableRows+10; i++ {
+		tagger.handle(now.Add(-time.Minute), "python3[1234]: segfault at 0 ip 00007f2c8a0b1234 sp 00007ffd2c3b4f18 error 4 in libc.so.6")
+	}
+	rpt, _ = tagger.Tag(rpt)
+
+	node := rpt.Process.Nodes[processID]
+	if rows := node.ExtractTimeline(EventPrefix); len(rows) != report.MaxTableRows {
+		t.Errorf("Expected the %d most recent events, got %d", report.MaxTableRows, len(rows))
+	}
+	if have, _ := node.Latest.Lookup(Segfaults); have != strconv.Itoa(report.MaxTableRows+10) {
+		t.Errorf("Expected all segfaults to be counted, got %q", have)
+	}
+}
+
+func TestFollowLog(t *testing.T) {
+	f, err := ioutil.TempFile("", "kern.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.WriteString("Oct 19 18:24:00 host kernel: Killed process 1 (old)\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	tagger, err := NewTagger("host1", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tagger.Stop()
+	if _, err := f.WriteString("Oct 19 18:24:38 host kernel: [12345.678901] Killed process 1234 (java)\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tagger.mtx.Lock()
+		events := append([]Event(nil), tagger.events...)
+		tagger.mtx.Unlock()
+		if len(events) > 0 {
+			if len(events) != 1 || events[0].PID != 1234 || events[0].Kind != OOMKill {
+				t.Fatalf("Unexpected events %v", events)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected an event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -2,7 +2,6 @@ package kubernetes
 
 import (
 	"io"
-	"strconv"
 	"sync"
 	"time"
 
@@ -15,6 +14,9 @@ import (
 	"k8s.io/kubernetes/pkg/fields"
 	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/util/wait"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/controls"
 )
 
 // Client keeps track of running kubernetes pods and services
@@ -26,18 +28,28 @@ type Client interface {
 	WalkReplicaSets(f func(ReplicaSet) error) error
 	WalkReplicationControllers(f func(ReplicationController) error) error
 	WalkNodes(f func(*api.Node) error) error
+	WalkEndpoints(f func(Endpoints) error) error
+	WalkPersistentVolumes(f func(PersistentVolume) error) error
+	WalkPersistentVolumeClaims(f func(PersistentVolumeClaim) error) error
 
 	WatchPods(f func(Event, Pod))
 
-	GetLogs(namespaceID, podID string) (io.ReadCloser, error)
+	GetLogs(namespaceID, podID, container string, opts controls.LogOptions) (io.ReadCloser, error)
+	Exec(namespaceID, podID, container string, cmd []string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error
+	Attach(namespaceID, podID, container string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error
 	DeletePod(namespaceID, podID string) error
 	ScaleUp(resource, namespaceID, id string) error
 	ScaleDown(resource, namespaceID, id string) error
+
+	GetEndpoints(namespaceID, name string) (*api.Endpoints, error)
+	CreateEndpoints(endpoints *api.Endpoints) (*api.Endpoints, error)
+	UpdateEndpoints(endpoints *api.Endpoints) (*api.Endpoints, error)
 }
 
 type client struct {
 	quit                       chan struct{}
 	resyncPeriod               time.Duration
+	config                     *restclient.Config
 	client                     *unversioned.Client
 	extensionsClient           *unversioned.ExtensionsClient
 	podStore                   *cache.StoreToPodLister
@@ -46,6 +58,9 @@ type client struct {
 	replicaSetStore            *cache.StoreToReplicaSetLister
 	replicationControllerStore *cache.StoreToReplicationControllerLister
 	nodeStore                  *cache.StoreToNodeLister
+	endpointsStore             *cache.StoreToEndpointsLister
+	persistentVolumeStore      cache.Store
+	persistentVolumeClaimStore cache.Store
 
 	podWatchesMutex sync.Mutex
 	podWatches      []func(Event, Pod)
@@ -91,6 +106,7 @@ func NewClient(addr string, resyncPeriod time.Duration) (Client, error) {
 	result := &client{
 		quit:             make(chan struct{}),
 		resyncPeriod:     resyncPeriod,
+		config:           config,
 		client:           c,
 		extensionsClient: ec,
 	}
@@ -99,6 +115,9 @@ func NewClient(addr string, resyncPeriod time.Duration) (Client, error) {
 	result.serviceStore = &cache.StoreToServiceLister{Store: result.setupStore(c, "services", &api.Service{})}
 	result.replicationControllerStore = &cache.StoreToReplicationControllerLister{Store: result.setupStore(c, "replicationcontrollers", &api.ReplicationController{})}
 	result.nodeStore = &cache.StoreToNodeLister{Store: result.setupStore(c, "nodes", &api.Node{})}
+	result.endpointsStore = &cache.StoreToEndpointsLister{Store: result.setupStore(c, "endpoints", &api.Endpoints{})}
+	result.persistentVolumeStore = result.setupStore(c, "persistentvolumes", &api.PersistentVolume{})
+	result.persistentVolumeClaimStore = result.setupStore(c, "persistentvolumeclaims", &api.PersistentVolumeClaim{})
 
 	// We list deployments here to check if this version of kubernetes is >= 1.2.
 	// We would use NegotiateVersion, but Kubernetes 1.1 "supports"
@@ -221,18 +240,103 @@ func (c *client) WalkNodes(f func(*api.Node) error) error {
 	return nil
 }
 
-func (c *client) GetLogs(namespaceID, podID string) (io.ReadCloser, error) {
+func (c *client) WalkEndpoints(f func(Endpoints) error) error {
+	list, err := c.endpointsStore.List()
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		if err := f(NewEndpoints(&(list.Items[i]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) WalkPersistentVolumes(f func(PersistentVolume) error) error {
+	for _, m := range c.persistentVolumeStore.List() {
+		if err := f(NewPersistentVolume(m.(*api.PersistentVolume))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) WalkPersistentVolumeClaims(f func(PersistentVolumeClaim) error) error {
+	for _, m := range c.persistentVolumeClaimStore.List() {
+		if err := f(NewPersistentVolumeClaim(m.(*api.PersistentVolumeClaim))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLogs streams the logs of the given container of a pod.
+func (c *client) GetLogs(namespaceID, podID, container string, opts controls.LogOptions) (io.ReadCloser, error) {
+	logOptions := &api.PodLogOptions{
+		Container:  container,
+		Follow:     opts.Follow,
+		Timestamps: true,
+	}
+	if opts.Tail >= 0 {
+		tail := int64(opts.Tail)
+		logOptions.TailLines = &tail
+	}
+	if !opts.Since.IsZero() {
+		// Round up, so we never show lines from before the requested time
+		since := int64((mtime.Now().Sub(opts.Since) + time.Second - 1) / time.Second)
+		if since < 1 {
+			since = 1
+		}
+		logOptions.SinceSeconds = &since
+	}
 	return c.client.RESTClient.Get().
 		Namespace(namespaceID).
 		Name(podID).
 		Resource("pods").
 		SubResource("log").
-		Param("follow", strconv.FormatBool(true)).
-		Param("previous", strconv.FormatBool(false)).
-		Param("timestamps", strconv.FormatBool(true)).
+		VersionedParams(logOptions, api.ParameterCodec).
 		Stream()
 }
 
+// Exec runs cmd in the given container of a pod, streaming stdin, stdout
+// and stderr over the API server's exec subresource. It blocks until the
+// command exits or the streams are closed.
+func (c *client) Exec(namespaceID, podID, container string, cmd []string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	req := c.client.RESTClient.Post().
+		Namespace(namespaceID).
+		Name(podID).
+		Resource("pods").
+		SubResource("exec").
+		VersionedParams(&api.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    stderr != nil,
+			TTY:       tty,
+		}, api.ParameterCodec)
+	return stream(c.config, req.URL(), stdin, stdout, stderr, tty)
+}
+
+// Attach attaches to the main process of the given container of a pod,
+// over the API server's attach subresource. It blocks like Exec.
+func (c *client) Attach(namespaceID, podID, container string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	req := c.client.RESTClient.Post().
+		Namespace(namespaceID).
+		Name(podID).
+		Resource("pods").
+		SubResource("attach").
+		VersionedParams(&api.PodAttachOptions{
+			Container: container,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    stderr != nil,
+			TTY:       tty,
+		}, api.ParameterCodec)
+	return stream(c.config, req.URL(), stdin, stdout, stderr, tty)
+}
+
 func (c *client) DeletePod(namespaceID, podID string) error {
 	return c.client.RESTClient.Delete().
 		Namespace(namespaceID).
@@ -263,6 +367,22 @@ func (c *client) modifyScale(resource, namespace, id string, f func(*extensions.
 	return err
 }
 
+// GetEndpoints fetches an endpoints object straight from the API server,
+// bypassing the caches, as it is used for leader election.
+func (c *client) GetEndpoints(namespaceID, name string) (*api.Endpoints, error) {
+	return c.client.Endpoints(namespaceID).Get(name)
+}
+
+func (c *client) CreateEndpoints(endpoints *api.Endpoints) (*api.Endpoints, error) {
+	return c.client.Endpoints(endpoints.Namespace).Create(endpoints)
+}
+
+// UpdateEndpoints fails with a conflict if endpoints has been modified since
+// it was fetched.
+func (c *client) UpdateEndpoints(endpoints *api.Endpoints) (*api.Endpoints, error) {
+	return c.client.Endpoints(endpoints.Namespace).Update(endpoints)
+}
+
 func (c *client) Stop() {
 	close(c.quit)
 }
@@ -4,6 +4,8 @@ import (
 	"io"
 	"io/ioutil"
 
+	log "github.com/Sirupsen/logrus"
+
 	"$GITHUB_URI/common/xfer"
 	"$GITHUB_URI/probe/controls"
 	"$GITHUB_URI/report"
@@ -15,11 +17,25 @@ const (
 	DeletePod = "kubernetes_delete_pod"
 	ScaleUp   = "kubernetes_scale_up"
 	ScaleDown = "kubernetes_scale_down"
+	ExecPod   = "kubernetes_exec_pod"
+	AttachPod = "kubernetes_attach_pod"
+
+	// ContainerArg is the control argument selecting which container of a
+	// multi-container pod to get the logs of, exec in or attach to. It
+	// defaults to the first container in the pod spec.
+	ContainerArg = "container"
 )
 
-// GetLogs is the control to get the logs for a kubernetes pod
-func (r *Reporter) GetLogs(req xfer.Request, namespaceID, podID string) xfer.Response {
-	readCloser, err := r.client.GetLogs(namespaceID, podID)
+// Same shell selection as the docker exec control.
+var execShellCmd = []string{"/bin/sh", "-c", "TERM=xterm exec $( (type getent > /dev/null 2>&1  && getent passwd root | cut -d: -f7 2>/dev/null) || echo /bin/sh)"}
+
+// GetLogs is the control to get the logs for a container of a kubernetes pod
+func (r *Reporter) GetLogs(req xfer.Request, pod Pod, container string) xfer.Response {
+	opts, err := controls.ParseLogOptions(req.ControlArgs)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	readCloser, err := r.client.GetLogs(pod.Namespace(), pod.Name(), container, opts)
 	if err != nil {
 		return xfer.ResponseError(err)
 	}
@@ -43,6 +59,47 @@ func (r *Reporter) GetLogs(req xfer.Request, namespaceID, podID string) xfer.Res
 	}
 }
 
+// ExecPod is the control to run a shell in a container of a kubernetes pod
+func (r *Reporter) ExecPod(req xfer.Request, pod Pod, container string) xfer.Response {
+	return r.streamPod(req, pod, container, true, func(local io.ReadWriter) error {
+		return r.client.Exec(pod.Namespace(), pod.Name(), container, execShellCmd, local, local, nil, true)
+	})
+}
+
+// AttachPod is the control to attach to a container of a kubernetes pod
+func (r *Reporter) AttachPod(req xfer.Request, pod Pod, container string) xfer.Response {
+	hasTTY := pod.HasTTY(container)
+	return r.streamPod(req, pod, container, hasTTY, func(local io.ReadWriter) error {
+		var stderr io.Writer
+		if !hasTTY {
+			// With a TTY, stderr is multiplexed onto stdout by the API server.
+			stderr = local
+		}
+		return r.client.Attach(pod.Namespace(), pod.Name(), container, local, local, stderr, hasTTY)
+	})
+}
+
+// streamPod bridges a blocking exec/attach stream into a new pipe, closing
+// the pipe when the stream ends.
+func (r *Reporter) streamPod(req xfer.Request, pod Pod, container string, rawTTY bool, stream func(io.ReadWriter) error) xfer.Response {
+	id, pipe, err := controls.NewPipe(r.pipes, req.AppID)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	local, _ := pipe.Ends()
+	go func() {
+		if err := stream(local); err != nil {
+			log.Errorf("Error streaming to container %s of pod %s: %v", container, pod.ID(), err)
+		}
+		log.Infof("Stream to container %s of pod %s closed.", container, pod.ID())
+		pipe.Close()
+	}()
+	return xfer.Response{
+		Pipe:   id,
+		RawTTY: rawTTY,
+	}
+}
+
 func (r *Reporter) deletePod(req xfer.Request, namespaceID, podID string) xfer.Response {
 	if err := r.client.DeletePod(namespaceID, podID); err != nil {
 		return xfer.ResponseError(err)
@@ -52,8 +109,7 @@ func (r *Reporter) deletePod(req xfer.Request, namespaceID, podID string) xfer.R
 	}
 }
 
-// CapturePod is exported for testing
-func (r *Reporter) CapturePod(f func(xfer.Request, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+func (r *Reporter) capturePod(f func(xfer.Request, Pod) xfer.Response) func(xfer.Request) xfer.Response {
 	return func(req xfer.Request) xfer.Response {
 		uid, ok := report.ParsePodNodeID(req.NodeID)
 		if !ok {
@@ -70,10 +126,37 @@ func (r *Reporter) CapturePod(f func(xfer.Request, string, string) xfer.Response
 		if pod == nil {
 			return xfer.ResponseErrorf("Pod not found: %s", uid)
 		}
-		return f(req, pod.Namespace(), pod.Name())
+		return f(req, pod)
 	}
 }
 
+// CapturePod is exported for testing
+func (r *Reporter) CapturePod(f func(xfer.Request, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return r.capturePod(func(req xfer.Request, pod Pod) xfer.Response {
+		return f(req, pod.Namespace(), pod.Name())
+	})
+}
+
+// CapturePodContainer is exported for testing
+func (r *Reporter) CapturePodContainer(f func(xfer.Request, Pod, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return r.capturePod(func(req xfer.Request, pod Pod) xfer.Response {
+		names := pod.ContainerNames()
+		container := req.ControlArgs[ContainerArg]
+		if container == "" {
+			if len(names) == 0 {
+				return xfer.ResponseErrorf("Pod has no containers: %s", pod.ID())
+			}
+			return f(req, pod, names[0])
+		}
+		for _, name := range names {
+			if name == container {
+				return f(req, pod, container)
+			}
+		}
+		return xfer.ResponseErrorf("Container %q not found in pod %s", container, pod.ID())
+	})
+}
+
 // CaptureResource is exported for testing
 func (r *Reporter) CaptureResource(f func(xfer.Request, string, string, string) xfer.Response) func(xfer.Request) xfer.Response {
 	return func(req xfer.Request) xfer.Response {
@@ -144,8 +227,10 @@ func (r *Reporter) ScaleDown(req xfer.Request, resource, namespace, id string) x
 }
 
 func (r *Reporter) registerControls() {
-	controls.Register(GetLogs, r.CapturePod(r.GetLogs))
+	controls.Register(GetLogs, r.CapturePodContainer(r.GetLogs))
 	controls.Register(DeletePod, r.CapturePod(r.deletePod))
+	controls.Register(ExecPod, r.CapturePodContainer(r.ExecPod))
+	controls.Register(AttachPod, r.CapturePodContainer(r.AttachPod))
 	controls.Register(ScaleUp, r.CaptureResource(r.ScaleUp))
 	controls.Register(ScaleDown, r.CaptureResource(r.ScaleDown))
 }
@@ -153,6 +238,8 @@ func (r *Reporter) registerControls() {
 func (r *Reporter) deregisterControls() {
 	controls.Rm(GetLogs)
 	controls.Rm(DeletePod)
+	controls.Rm(ExecPod)
+	controls.Rm(AttachPod)
 	controls.Rm(ScaleUp)
 	controls.Rm(ScaleDown)
 }
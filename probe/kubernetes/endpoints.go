@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"strconv"
+
+	"$GITHUB_URI/report"
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// These constants are keys used in node metadata
+const (
+	ReadyAddresses    = "kubernetes_ready_addresses"
+	NotReadyAddresses = "kubernetes_not_ready_addresses"
+	EndpointPorts     = "kubernetes_endpoint_ports"
+)
+
+// Endpoints represents the Kubernetes endpoints of a service, ie the
+// addresses of the pods (or anything else) actually backing it.
+type Endpoints interface {
+	Meta
+	ReadyAddresses() []string
+	NotReadyAddresses() []string
+	Ports() []string
+	PodUIDs() []string
+	Sets() report.Sets
+}
+
+type endpoints struct {
+	*api.Endpoints
+	Meta
+}
+
+// NewEndpoints creates a new Endpoints
+func NewEndpoints(e *api.Endpoints) Endpoints {
+	return &endpoints{Endpoints: e, Meta: meta{e.ObjectMeta}}
+}
+
+func (e *endpoints) ReadyAddresses() []string {
+	result := []string{}
+	for _, subset := range e.Subsets {
+		for _, address := range subset.Addresses {
+			result = append(result, address.IP)
+		}
+	}
+	return result
+}
+
+func (e *endpoints) NotReadyAddresses() []string {
+	result := []string{}
+	for _, subset := range e.Subsets {
+		for _, address := range subset.NotReadyAddresses {
+			result = append(result, address.IP)
+		}
+	}
+	return result
+}
+
+// Ports returns the ports on the backing addresses, which may differ from
+// those of the service.
+func (e *endpoints) Ports() []string {
+	result := []string{}
+	for _, subset := range e.Subsets {
+		for _, port := range subset.Ports {
+			result = append(result, strconv.Itoa(port.Port))
+		}
+	}
+	return result
+}
+
+// PodUIDs returns the UIDs of the pods backing the service, ready or not.
+func (e *endpoints) PodUIDs() []string {
+	result := []string{}
+	for _, subset := range e.Subsets {
+		for _, addresses := range [][]api.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+			for _, address := range addresses {
+				if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+					result = append(result, string(address.TargetRef.UID))
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Sets returns the addresses and ports, to be added to the service's node.
+func (e *endpoints) Sets() report.Sets {
+	return report.EmptySets.
+		Add(ReadyAddresses, report.MakeStringSet(e.ReadyAddresses()...)).
+		Add(NotReadyAddresses, report.MakeStringSet(e.NotReadyAddresses()...)).
+		Add(EndpointPorts, report.MakeStringSet(e.Ports()...))
+}
@@ -0,0 +1,21 @@
+package kubernetes
+
+import (
+	"io"
+	"net/url"
+
+	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/client/unversioned/remotecommand"
+	remotecommandserver "k8s.io/kubernetes/pkg/kubelet/server/remotecommand"
+)
+
+// stream connects to an exec or attach subresource of the API server, with
+// the same credentials as the rest of the client, and copies the streams to
+// and from it until the remote process exits.
+func stream(config *restclient.Config, u *url.URL, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	executor, err := remotecommand.NewExecutor(config, "POST", u)
+	if err != nil {
+		return err
+	}
+	return executor.Stream(remotecommandserver.SupportedStreamingProtocols, stdin, stdout, stderr, tty)
+}
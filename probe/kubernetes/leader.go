@@ -0,0 +1,229 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+
+	"$GITHUB_URI/common/mtime"
+)
+
+// LeaderAnnotation is the annotation on the endpoints object holding the
+// lease. It is the same one used by the kubernetes components, so the usual
+// tooling can show which probe is the leader.
+const LeaderAnnotation = "control-plane.alpha.kubernetes.io/leader"
+
+// Leader tells a Reporter whether it should report cluster-scoped objects.
+type Leader interface {
+	IsLeader() bool
+}
+
+type leaderRecord struct {
+	HolderIdentity       string    `json:"holderIdentity"`
+	LeaseDurationSeconds int       `json:"leaseDurationSeconds"`
+	AcquireTime          time.Time `json:"acquireTime"`
+	RenewTime            time.Time `json:"renewTime"`
+}
+
+// LeaderElector elects a single leader amongst the probes, using a lease
+// stored in an annotation of an endpoints object. The resource version of the
+// endpoints object guards against two probes acquiring the lease at once.
+type LeaderElector struct {
+	client        Client
+	namespace     string
+	name          string
+	identity      string
+	leaseDuration time.Duration
+	quit          chan struct{}
+	done          chan struct{}
+
+	mtx            sync.Mutex
+	leader         bool
+	observedRecord leaderRecord
+	observedTime   time.Time
+}
+
+// NewLeaderElector makes a new LeaderElector. Don't forget to Start it.
+func NewLeaderElector(client Client, namespace, name, identity string, leaseDuration time.Duration) *LeaderElector {
+	return &LeaderElector{
+		client:        client,
+		namespace:     namespace,
+		name:          name,
+		identity:      identity,
+		leaseDuration: leaseDuration,
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start tries to acquire, and then keep renewing, the lease in the
+// background.
+func (l *LeaderElector) Start() {
+	go l.loop()
+}
+
+// Stop stops renewing the lease, and gives it up if held, so another probe
+// can take over without waiting for it to expire.
+func (l *LeaderElector) Stop() {
+	close(l.quit)
+	<-l.done
+	l.release()
+}
+
+// IsLeader returns true if this probe holds the lease.
+func (l *LeaderElector) IsLeader() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.leader
+}
+
+func (l *LeaderElector) loop() {
+	defer close(l.done)
+	// Renew well within the lease, so a couple of failed attempts don't lose it.
+	ticker := time.NewTicker(l.leaseDuration / 4)
+	defer ticker.Stop()
+	for {
+		l.TryAcquireOrRenew()
+		select {
+		case <-ticker.C:
+		case <-l.quit:
+			return
+		}
+	}
+}
+
+// TryAcquireOrRenew makes a single attempt to acquire or renew the lease,
+// and returns whether this probe is now the leader. It is exported for
+// testing.
+func (l *LeaderElector) TryAcquireOrRenew() bool {
+	leader, err := l.tryAcquireOrRenew()
+	if err != nil {
+		log.Errorf("Kubernetes: leader election: %v", err)
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if leader != l.leader {
+		if leader {
+			log.Infof("Kubernetes: became leader, reporting cluster-wide objects")
+		} else {
+			log.Infof("Kubernetes: lost leadership, no longer reporting cluster-wide objects")
+		}
+	}
+	l.leader = leader
+	return leader
+}
+
+func (l *LeaderElector) tryAcquireOrRenew() (bool, error) {
+	now := mtime.Now()
+	record := leaderRecord{
+		HolderIdentity:       l.identity,
+		LeaseDurationSeconds: int(l.leaseDuration / time.Second),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	endpoints, err := l.client.GetEndpoints(l.namespace, l.name)
+	if errors.IsNotFound(err) {
+		endpoints = &api.Endpoints{
+			ObjectMeta: api.ObjectMeta{
+				Namespace: l.namespace,
+				Name:      l.name,
+			},
+		}
+		if err := l.setRecord(endpoints, record); err != nil {
+			return false, err
+		}
+		if _, err := l.client.CreateEndpoints(endpoints); err != nil {
+			return false, err
+		}
+		l.observe(record, now)
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if raw, ok := endpoints.Annotations[LeaderAnnotation]; ok {
+		var current leaderRecord
+		if err := json.Unmarshal([]byte(raw), &current); err != nil {
+			return false, err
+		}
+		// Lease expiry is judged against our own clock, from when we first
+		// saw the current record, so clock skew between hosts doesn't matter.
+		observedTime := l.observe(current, now)
+		if current.HolderIdentity != "" && current.HolderIdentity != l.identity &&
+			now.Before(observedTime.Add(l.leaseDuration)) {
+			return false, nil
+		}
+		if current.HolderIdentity == l.identity {
+			record.AcquireTime = current.AcquireTime
+		}
+	}
+
+	if err := l.setRecord(endpoints, record); err != nil {
+		return false, err
+	}
+	if _, err := l.client.UpdateEndpoints(endpoints); err != nil {
+		if errors.IsConflict(err) {
+			// Someone else got there first
+			return false, nil
+		}
+		return false, err
+	}
+	l.observe(record, now)
+	return true, nil
+}
+
+// observe records when we first saw record, returning that time.
+func (l *LeaderElector) observe(record leaderRecord, now time.Time) time.Time {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if record.HolderIdentity != l.observedRecord.HolderIdentity ||
+		!record.RenewTime.Equal(l.observedRecord.RenewTime) {
+		l.observedRecord = record
+		l.observedTime = now
+	}
+	return l.observedTime
+}
+
+func (l *LeaderElector) setRecord(endpoints *api.Endpoints, record leaderRecord) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if endpoints.Annotations == nil {
+		endpoints.Annotations = map[string]string{}
+	}
+	endpoints.Annotations[LeaderAnnotation] = string(raw)
+	return nil
+}
+
+func (l *LeaderElector) release() {
+	if !l.IsLeader() {
+		return
+	}
+	l.mtx.Lock()
+	l.leader = false
+	l.mtx.Unlock()
+
+	endpoints, err := l.client.GetEndpoints(l.namespace, l.name)
+	if err != nil {
+		log.Errorf("Kubernetes: leader election: failed to release lease: %v", err)
+		return
+	}
+	var current leaderRecord
+	if err := json.Unmarshal([]byte(endpoints.Annotations[LeaderAnnotation]), &current); err != nil || current.HolderIdentity != l.identity {
+		return
+	}
+	if err := l.setRecord(endpoints, leaderRecord{}); err != nil {
+		log.Errorf("Kubernetes: leader election: failed to release lease: %v", err)
+		return
+	}
+	if _, err := l.client.UpdateEndpoints(endpoints); err != nil {
+		log.Errorf("Kubernetes: leader election: failed to release lease: %v", err)
+	}
+}
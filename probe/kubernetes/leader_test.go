@@ -0,0 +1,102 @@
+package kubernetes_test
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/kubernetes"
+	"$GITHUB_URI/report"
+)
+
+func TestLeaderElectionFailover(t *testing.T) {
+	const lease = 15 * time.Second
+	now := time.Now()
+	mtime.NowForce(now)
+	defer mtime.NowReset()
+
+	client := newMockClient()
+	a := kubernetes.NewLeaderElector(client, "default", "scope", "probe-a", lease)
+	b := kubernetes.NewLeaderElector(client, "default", "scope", "probe-b", lease)
+
+	step := func(d time.Duration, elector *kubernetes.LeaderElector, want bool) {
+		now = now.Add(d)
+		mtime.NowForce(now)
+		if have := elector.TryAcquireOrRenew(); have != want {
+			t.Fatalf("at %v: expected leader=%v, got %v", d, want, have)
+		}
+		if have := elector.IsLeader(); have != want {
+			t.Fatalf("at %v: expected IsLeader()=%v, got %v", d, want, have)
+		}
+	}
+
+	step(0, a, true)  // a creates the lease
+	step(0, b, false) // b sees a valid lease
+	step(5*time.Second, a, true)
+	step(5*time.Second, b, false)
+
+	// a dies; b only takes over once a full lease has passed since it last
+	// saw a renewal
+	step(10*time.Second, b, false)
+	step(6*time.Second, b, true)
+
+	// a comes back, and has to stay a follower
+	step(time.Second, a, false)
+	step(5*time.Second, b, true)
+	step(5*time.Second, a, false)
+}
+
+type mockLeader bool
+
+func (l mockLeader) IsLeader() bool { return bool(l) }
+
+func TestReporterFollower(t *testing.T) {
+	lookups := 0
+	oldGetNodeName := kubernetes.GetNodeName
+	defer func() { kubernetes.GetNodeName = oldGetNodeName }()
+	kubernetes.GetNodeName = func(*kubernetes.Reporter) (string, error) {
+		lookups++
+		return nodeName, nil
+	}
+
+	offNodeUID := "k1l2m3n4o5"
+	offNodePod := kubernetes.NewPod(&api.Pod{
+		TypeMeta: podTypeMeta,
+		ObjectMeta: api.ObjectMeta{
+			Name:      "pong-c",
+			UID:       types.UID(offNodeUID),
+			Namespace: "ping",
+		},
+		Spec: api.PodSpec{
+			NodeName: "othernode",
+		},
+	})
+
+	for _, leader := range []bool{true, false} {
+		lookups = 0
+		client := newMockClient()
+		client.pods = append(client.pods, offNodePod)
+		reporter := kubernetes.NewReporter(client, nil, "", "foo", nil, mockLeader(leader))
+		for i := 0; i < 2; i++ {
+			rpt, err := reporter.Report()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := rpt.Pod.Nodes[report.MakePodNodeID(pod1UID)]; !ok {
+				t.Errorf("leader=%v: expected pods on this node to be reported", leader)
+			}
+			if _, ok := rpt.Pod.Nodes[report.MakePodNodeID(offNodeUID)]; ok {
+				t.Errorf("leader=%v: expected pods on other nodes to be excluded", leader)
+			}
+			if have := len(rpt.Service.Nodes) > 0; have != leader {
+				t.Errorf("leader=%v: expected services reported=%v, got %v", leader, leader, have)
+			}
+		}
+		if lookups != 1 {
+			t.Errorf("leader=%v: expected node name to be looked up once, got %d", leader, lookups)
+		}
+	}
+}
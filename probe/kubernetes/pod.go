@@ -1,15 +1,25 @@
 package kubernetes
 
 import (
+	"fmt"
+	"strconv"
+
 	"$GITHUB_URI/report"
 	"k8s.io/kubernetes/pkg/api"
 )
 
 // These constants are keys used in node metadata
 const (
-	State = "kubernetes_state"
+	State           = "kubernetes_state"
+	ContainerNames  = "kubernetes_container_names"
+	CPURequest      = "kubernetes_cpu_request"    // millicores
+	CPULimit        = "kubernetes_cpu_limit"      // millicores
+	MemoryRequest   = "kubernetes_memory_request" // bytes
+	MemoryLimit     = "kubernetes_memory_limit"   // bytes
+	ResourcesPrefix = "kubernetes_resources_"
 
 	StateDeleted = "deleted"
+	StateRunning = "Running"
 )
 
 // Pod represents a Kubernetes pod
@@ -17,6 +27,9 @@ type Pod interface {
 	Meta
 	AddParent(topology, id string)
 	NodeName() string
+	ContainerNames() []string
+	HasTTY(container string) bool
+	ClaimNames() []string
 	GetNode(probeID string) report.Node
 }
 
@@ -56,12 +69,105 @@ func (p *pod) NodeName() string {
 	return p.Spec.NodeName
 }
 
+// ContainerNames returns the names of the containers in the pod spec, in
+// the order they were declared.
+func (p *pod) ContainerNames() []string {
+	names := make([]string, 0, len(p.Spec.Containers))
+	for _, c := range p.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// ClaimNames returns the names of the persistent volume claims mounted by
+// the pod. Claims are in the same namespace as the pod.
+func (p *pod) ClaimNames() []string {
+	names := []string{}
+	for _, v := range p.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			names = append(names, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return names
+}
+
+// HasTTY returns true if the named container was started with a TTY.
+func (p *pod) HasTTY(container string) bool {
+	for _, c := range p.Spec.Containers {
+		if c.Name == container {
+			return c.TTY
+		}
+	}
+	return false
+}
+
+func formatQuantity(resources api.ResourceList, name api.ResourceName) string {
+	q, ok := resources[name]
+	if !ok {
+		return "-"
+	}
+	return q.String()
+}
+
+// resources returns the pod-wide requests and limits (the sum over all
+// containers), plus a table of the per-container values. A pod only has a
+// limit for a resource if every one of its containers has one.
+func (p *pod) resources() (map[string]string, map[string]string) {
+	var (
+		totals = map[string]string{}
+		table  = map[string]string{}
+		sums   = map[string]int64{}
+		counts = map[string]int{}
+	)
+	add := func(key string, resources api.ResourceList, name api.ResourceName) {
+		q, ok := resources[name]
+		if !ok {
+			return
+		}
+		if name == api.ResourceCPU {
+			sums[key] += q.MilliValue()
+		} else {
+			sums[key] += q.Value()
+		}
+		counts[key]++
+	}
+	for _, c := range p.Spec.Containers {
+		add(CPURequest, c.Resources.Requests, api.ResourceCPU)
+		add(CPULimit, c.Resources.Limits, api.ResourceCPU)
+		add(MemoryRequest, c.Resources.Requests, api.ResourceMemory)
+		add(MemoryLimit, c.Resources.Limits, api.ResourceMemory)
+		table[c.Name+" CPU"] = fmt.Sprintf("request %s, limit %s",
+			formatQuantity(c.Resources.Requests, api.ResourceCPU), formatQuantity(c.Resources.Limits, api.ResourceCPU))
+		table[c.Name+" memory"] = fmt.Sprintf("request %s, limit %s",
+			formatQuantity(c.Resources.Requests, api.ResourceMemory), formatQuantity(c.Resources.Limits, api.ResourceMemory))
+	}
+	for _, key := range []string{CPURequest, MemoryRequest} {
+		if counts[key] > 0 {
+			totals[key] = strconv.FormatInt(sums[key], 10)
+		}
+	}
+	for _, key := range []string{CPULimit, MemoryLimit} {
+		if counts[key] > 0 && counts[key] == len(p.Spec.Containers) {
+			totals[key] = strconv.FormatInt(sums[key], 10)
+		}
+	}
+	return totals, table
+}
+
 func (p *pod) GetNode(probeID string) report.Node {
+	controls := []string{GetLogs, DeletePod}
+	if p.State() == StateRunning {
+		controls = append(controls, ExecPod, AttachPod)
+	}
+	totals, table := p.resources()
 	return p.MetaNode(report.MakePodNodeID(p.UID())).WithLatests(map[string]string{
 		State: p.State(),
 		IP:    p.Status.PodIP,
 		report.ControlProbeID: probeID,
 	}).
+		WithLatests(totals).
+		AddTable(ResourcesPrefix, table).
+		WithSets(report.EmptySets.Add(ContainerNames, report.MakeStringSet(p.ContainerNames()...))).
 		WithParents(p.parents).
-		WithControls(GetLogs, DeletePod)
+		WithControls(controls...)
 }
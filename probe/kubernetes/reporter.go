@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/labels"
@@ -24,6 +25,15 @@ const (
 	DesiredReplicas    = "kubernetes_desired_replicas"
 )
 
+// These constants are keys used for metrics computed by the render layer,
+// comparing the observed container usage with the pods' requests and limits.
+const (
+	CPURequestUsage    = "kubernetes_cpu_request_usage"
+	CPULimitUsage      = "kubernetes_cpu_limit_usage"
+	MemoryRequestUsage = "kubernetes_memory_request_usage"
+	MemoryLimitUsage   = "kubernetes_memory_limit_usage"
+)
+
 // Exposed for testing
 var (
 	PodMetadataTemplates = report.MetadataTemplates{
@@ -33,15 +43,64 @@ var (
 		report.Container: {ID: report.Container, Label: "# Containers", From: report.FromCounters, Datatype: "number", Priority: 4},
 		Namespace:        {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 5},
 		Created:          {ID: Created, Label: "Created", From: report.FromLatest, Priority: 6},
+		ContainerNames:   {ID: ContainerNames, Label: "Containers", From: report.FromSets, Priority: 7},
+		CPURequest:       {ID: CPURequest, Label: "CPU Request (millicores)", From: report.FromLatest, Datatype: "number", Priority: 8},
+		CPULimit:         {ID: CPULimit, Label: "CPU Limit (millicores)", From: report.FromLatest, Datatype: "number", Priority: 9},
+		MemoryRequest:    {ID: MemoryRequest, Label: "Memory Request (bytes)", From: report.FromLatest, Datatype: "number", Priority: 10},
+		MemoryLimit:      {ID: MemoryLimit, Label: "Memory Limit (bytes)", From: report.FromLatest, Datatype: "number", Priority: 11},
+	}
+
+	// ResourceMetricTemplates apply to pods and the groupings of pods; the
+	// render layer sums the container metrics up to them.
+	ResourceMetricTemplates = report.MetricTemplates{
+		docker.CPUTotalUsage: {ID: docker.CPUTotalUsage, Label: "CPU", Format: report.PercentFormat, Priority: 1},
+		docker.MemoryUsage:   {ID: docker.MemoryUsage, Label: "Memory", Format: report.FilesizeFormat, Priority: 2},
+		CPURequestUsage:      {ID: CPURequestUsage, Label: "CPU / Request", Format: report.PercentFormat, Priority: 3},
+		CPULimitUsage:        {ID: CPULimitUsage, Label: "CPU / Limit", Format: report.PercentFormat, Priority: 4},
+		MemoryRequestUsage:   {ID: MemoryRequestUsage, Label: "Memory / Request", Format: report.PercentFormat, Priority: 5},
+		MemoryLimitUsage:     {ID: MemoryLimitUsage, Label: "Memory / Limit", Format: report.PercentFormat, Priority: 6},
 	}
 
 	ServiceMetadataTemplates = report.MetadataTemplates{
-		ID:         {ID: ID, Label: "ID", From: report.FromLatest, Priority: 1},
-		Namespace:  {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
-		Created:    {ID: Created, Label: "Created", From: report.FromLatest, Priority: 3},
-		PublicIP:   {ID: PublicIP, Label: "Public IP", From: report.FromLatest, Priority: 4},
-		IP:         {ID: IP, Label: "Internal IP", From: report.FromLatest, Priority: 5},
-		report.Pod: {ID: report.Pod, Label: "# Pods", From: report.FromCounters, Datatype: "number", Priority: 6},
+		ID:                {ID: ID, Label: "ID", From: report.FromLatest, Priority: 1},
+		Namespace:         {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
+		Created:           {ID: Created, Label: "Created", From: report.FromLatest, Priority: 3},
+		PublicIP:          {ID: PublicIP, Label: "Public IP", From: report.FromLatest, Priority: 4},
+		IP:                {ID: IP, Label: "Internal IP", From: report.FromLatest, Priority: 5},
+		report.Pod:        {ID: report.Pod, Label: "# Pods", From: report.FromCounters, Datatype: "number", Priority: 6},
+		ReadyAddresses:    {ID: ReadyAddresses, Label: "Ready Endpoints", From: report.FromSets, Priority: 7},
+		NotReadyAddresses: {ID: NotReadyAddresses, Label: "Not Ready Endpoints", From: report.FromSets, Priority: 8},
+		EndpointPorts:     {ID: EndpointPorts, Label: "Endpoint Ports", From: report.FromSets, Priority: 9},
+	}
+
+	PersistentVolumeMetadataTemplates = report.MetadataTemplates{
+		ID:            {ID: ID, Label: "ID", From: report.FromLatest, Priority: 1},
+		Created:       {ID: Created, Label: "Created", From: report.FromLatest, Priority: 2},
+		State:         {ID: State, Label: "State", From: report.FromLatest, Priority: 3},
+		Capacity:      {ID: Capacity, Label: "Capacity", From: report.FromLatest, Priority: 4},
+		AccessModes:   {ID: AccessModes, Label: "Access Modes", From: report.FromLatest, Priority: 5},
+		ReclaimPolicy: {ID: ReclaimPolicy, Label: "Reclaim Policy", From: report.FromLatest, Priority: 6},
+		VolumeType:    {ID: VolumeType, Label: "Type", From: report.FromLatest, Priority: 7},
+		VolumeSource:  {ID: VolumeSource, Label: "Source", From: report.FromLatest, Priority: 8},
+		StorageClass:  {ID: StorageClass, Label: "Storage Class", From: report.FromLatest, Priority: 9},
+		report.Pod:    {ID: report.Pod, Label: "# Pods", From: report.FromCounters, Datatype: "number", Priority: 10},
+	}
+
+	PersistentVolumeClaimMetadataTemplates = report.MetadataTemplates{
+		ID:           {ID: ID, Label: "ID", From: report.FromLatest, Priority: 1},
+		Namespace:    {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
+		Created:      {ID: Created, Label: "Created", From: report.FromLatest, Priority: 3},
+		State:        {ID: State, Label: "State", From: report.FromLatest, Priority: 4},
+		Capacity:     {ID: Capacity, Label: "Requested", From: report.FromLatest, Priority: 5},
+		AccessModes:  {ID: AccessModes, Label: "Access Modes", From: report.FromLatest, Priority: 6},
+		VolumeName:   {ID: VolumeName, Label: "Volume", From: report.FromLatest, Priority: 7},
+		StorageClass: {ID: StorageClass, Label: "Storage Class", From: report.FromLatest, Priority: 8},
+		report.Pod:   {ID: report.Pod, Label: "# Pods", From: report.FromCounters, Datatype: "number", Priority: 9},
+	}
+
+	StorageClassMetadataTemplates = report.MetadataTemplates{
+		Name:       {ID: Name, Label: "Name", From: report.FromLatest, Priority: 1},
+		report.Pod: {ID: report.Pod, Label: "# Pods", From: report.FromCounters, Datatype: "number", Priority: 2},
 	}
 
 	DeploymentMetadataTemplates = report.MetadataTemplates{
@@ -67,6 +126,10 @@ var (
 		LabelPrefix: {ID: LabelPrefix, Label: "Kubernetes Labels", Prefix: LabelPrefix},
 	}
 
+	PodTableTemplates = report.TableTemplates{
+		ResourcesPrefix: {ID: ResourcesPrefix, Label: "Container Resources", Prefix: ResourcesPrefix},
+	}.Merge(TableTemplates)
+
 	ScalingControls = []report.Control{
 		{
 			ID:    ScaleDown,
@@ -90,16 +153,24 @@ type Reporter struct {
 	probeID string
 	probe   *probe.Probe
 	hostID  string
+	leader  Leader
+
+	// nodeName is looked up once, as that walks every node.
+	nodeNameMtx sync.Mutex
+	nodeName    string
 }
 
-// NewReporter makes a new Reporter
-func NewReporter(client Client, pipes controls.PipeClient, probeID string, hostID string, probe *probe.Probe) *Reporter {
+// NewReporter makes a new Reporter. Cluster-scoped objects (services,
+// deployments and replica sets) are only reported while leader says this
+// probe is the leader; a nil leader means always report them.
+func NewReporter(client Client, pipes controls.PipeClient, probeID string, hostID string, probe *probe.Probe, leader Leader) *Reporter {
 	reporter := &Reporter{
 		client:  client,
 		pipes:   pipes,
 		probeID: probeID,
 		probe:   probe,
 		hostID:  hostID,
+		leader:  leader,
 	}
 	reporter.registerControls()
 	client.WatchPods(reporter.podEvent)
@@ -112,9 +183,19 @@ func (r *Reporter) Stop() {
 }
 
 // Name of this reporter, for metrics gathering
-func (Reporter) Name() string { return "K8s" }
+func (*Reporter) Name() string { return "K8s" }
+
+func (r *Reporter) isLeader() bool {
+	return r.leader == nil || r.leader.IsLeader()
+}
 
 func (r *Reporter) podEvent(e Event, pod Pod) {
+	// Followers only publish the pods on their own node
+	if !r.isLeader() {
+		if nodeName, err := r.thisNodeName(); err != nil || pod.NodeName() != nodeName {
+			return
+		}
+	}
 	switch e {
 	case ADD:
 		rpt := report.MakeReport()
@@ -179,7 +260,11 @@ func (r *Reporter) Tag(rpt report.Report) (report.Report, error) {
 // Report generates a Report containing Container and ContainerImage topologies
 func (r *Reporter) Report() (report.Report, error) {
 	result := report.MakeReport()
-	serviceTopology, services, err := r.serviceTopology()
+	endpoints, err := r.endpoints()
+	if err != nil {
+		return result, err
+	}
+	serviceTopology, services, err := r.serviceTopology(endpoints)
 	if err != nil {
 		return result, err
 	}
@@ -195,19 +280,39 @@ func (r *Reporter) Report() (report.Report, error) {
 	if err != nil {
 		return result, err
 	}
-	podTopology, err := r.podTopology(services, replicaSets)
+	volumeTopology, claimTopology, storageClassTopology, claimParents, err := r.storageTopologies()
+	if err != nil {
+		return result, err
+	}
+	podTopology, err := r.podTopology(services, replicaSets, endpoints, claimParents)
 	if err != nil {
 		return result, err
 	}
 	result.Pod = result.Pod.Merge(podTopology)
-	result.Service = result.Service.Merge(serviceTopology)
 	result.Host = result.Host.Merge(hostTopology)
-	result.Deployment = result.Deployment.Merge(deploymentTopology)
-	result.ReplicaSet = result.ReplicaSet.Merge(replicaSetTopology)
+	if r.isLeader() {
+		result.Service = result.Service.Merge(serviceTopology)
+		result.Deployment = result.Deployment.Merge(deploymentTopology)
+		result.ReplicaSet = result.ReplicaSet.Merge(replicaSetTopology)
+		result.PersistentVolume = result.PersistentVolume.Merge(volumeTopology)
+		result.PersistentVolumeClaim = result.PersistentVolumeClaim.Merge(claimTopology)
+		result.StorageClass = result.StorageClass.Merge(storageClassTopology)
+	}
 	return result, nil
 }
 
-func (r *Reporter) serviceTopology() (report.Topology, []Service, error) {
+// endpoints returns the endpoints objects, keyed by ID. They have the same
+// ID as the service they belong to.
+func (r *Reporter) endpoints() (map[string]Endpoints, error) {
+	result := map[string]Endpoints{}
+	err := r.client.WalkEndpoints(func(e Endpoints) error {
+		result[e.ID()] = e
+		return nil
+	})
+	return result, err
+}
+
+func (r *Reporter) serviceTopology(endpoints map[string]Endpoints) (report.Topology, []Service, error) {
 	var (
 		result = report.MakeTopology().
 			WithMetadataTemplates(ServiceMetadataTemplates).
@@ -215,7 +320,11 @@ func (r *Reporter) serviceTopology() (report.Topology, []Service, error) {
 		services = []Service{}
 	)
 	err := r.client.WalkServices(func(s Service) error {
-		result = result.AddNode(s.GetNode())
+		node := s.GetNode()
+		if e, ok := endpoints[s.ID()]; ok {
+			node = node.WithSets(e.Sets())
+		}
+		result = result.AddNode(node)
 		services = append(services, s)
 		return nil
 	})
@@ -233,7 +342,9 @@ func (r *Reporter) serviceTopology() (report.Topology, []Service, error) {
 func (r *Reporter) hostTopology(services []Service) report.Topology {
 	localNetworks := report.EmptyStringSet
 	for _, service := range services {
-		localNetworks = localNetworks.Add(service.ClusterIP() + "/32")
+		if ip := service.ClusterIP(); ip != "" && ip != api.ClusterIPNone {
+			localNetworks = localNetworks.Add(ip + "/32")
+		}
 	}
 	node := report.MakeNode(report.MakeHostNodeID(r.hostID))
 	node = node.WithSets(report.EmptySets.
@@ -245,6 +356,7 @@ func (r *Reporter) deploymentTopology(probeID string) (report.Topology, []Deploy
 	var (
 		result = report.MakeTopology().
 			WithMetadataTemplates(DeploymentMetadataTemplates).
+			WithMetricTemplates(ResourceMetricTemplates).
 			WithTableTemplates(TableTemplates)
 		deployments = []Deployment{}
 	)
@@ -262,6 +374,7 @@ func (r *Reporter) replicaSetTopology(probeID string, deployments []Deployment)
 	var (
 		result = report.MakeTopology().
 			WithMetadataTemplates(ReplicaSetMetadataTemplates).
+			WithMetricTemplates(ResourceMetricTemplates).
 			WithTableTemplates(TableTemplates)
 		replicaSets = []ReplicaSet{}
 		selectors   = []func(labelledChild){}
@@ -320,6 +433,22 @@ var GetNodeName = func(r *Reporter) (string, error) {
 	return nodeName, err
 }
 
+// thisNodeName returns the cached k8s node name for the current machine,
+// looking it up until it is found.
+func (r *Reporter) thisNodeName() (string, error) {
+	r.nodeNameMtx.Lock()
+	defer r.nodeNameMtx.Unlock()
+	if r.nodeName != "" {
+		return r.nodeName, nil
+	}
+	nodeName, err := GetNodeName(r)
+	if err != nil {
+		return "", err
+	}
+	r.nodeName = nodeName
+	return nodeName, nil
+}
+
 type labelledChild interface {
 	Labels() map[string]string
 	AddParent(string, string)
@@ -334,11 +463,72 @@ func match(selector labels.Selector, topology, id string) func(labelledChild) {
 	}
 }
 
-func (r *Reporter) podTopology(services []Service, replicaSets []ReplicaSet) (report.Topology, error) {
+// parent is a node a child should be linked to.
+type parent struct {
+	topology, id string
+}
+
+// storageTopologies returns the topologies of volumes, claims and storage
+// classes, along with the parents to give the pods mounting each claim,
+// keyed by claim ID. Storage classes are only known through the annotations
+// of the volumes and claims referring to them.
+func (r *Reporter) storageTopologies() (report.Topology, report.Topology, report.Topology, map[string][]parent, error) {
+	var (
+		volumes = report.MakeTopology().
+			WithMetadataTemplates(PersistentVolumeMetadataTemplates).
+			WithTableTemplates(TableTemplates)
+		claims = report.MakeTopology().
+			WithMetadataTemplates(PersistentVolumeClaimMetadataTemplates).
+			WithTableTemplates(TableTemplates)
+		classes = report.MakeTopology().
+			WithMetadataTemplates(StorageClassMetadataTemplates)
+		volumeIDs    = map[string]string{} // volume name -> node ID
+		claimParents = map[string][]parent{}
+	)
+
+	addClass := func(name string) string {
+		id := report.MakeStorageClassNodeID(name)
+		classes = classes.AddNode(storageClassNode(name))
+		return id
+	}
+
+	err := r.client.WalkPersistentVolumes(func(pv PersistentVolume) error {
+		if class := pv.StorageClass(); class != "" {
+			pv.AddParent(report.StorageClass, addClass(class))
+		}
+		node := pv.GetNode()
+		volumes = volumes.AddNode(node)
+		volumeIDs[pv.Name()] = node.ID
+		return nil
+	})
+	if err != nil {
+		return volumes, claims, classes, claimParents, err
+	}
+
+	err = r.client.WalkPersistentVolumeClaims(func(pvc PersistentVolumeClaim) error {
+		parents := []parent{{report.PersistentVolumeClaim, report.MakePersistentVolumeClaimNodeID(pvc.UID())}}
+		if id, ok := volumeIDs[pvc.VolumeName()]; ok {
+			pvc.AddParent(report.PersistentVolume, id)
+			parents = append(parents, parent{report.PersistentVolume, id})
+		}
+		if class := pvc.StorageClass(); class != "" {
+			id := addClass(class)
+			pvc.AddParent(report.StorageClass, id)
+			parents = append(parents, parent{report.StorageClass, id})
+		}
+		claims = claims.AddNode(pvc.GetNode())
+		claimParents[pvc.ID()] = parents
+		return nil
+	})
+	return volumes, claims, classes, claimParents, err
+}
+
+func (r *Reporter) podTopology(services []Service, replicaSets []ReplicaSet, endpoints map[string]Endpoints, claimParents map[string][]parent) (report.Topology, error) {
 	var (
 		pods = report.MakeTopology().
 			WithMetadataTemplates(PodMetadataTemplates).
-			WithTableTemplates(TableTemplates)
+			WithMetricTemplates(ResourceMetricTemplates).
+			WithTableTemplates(PodTableTemplates)
 		selectors = []func(labelledChild){}
 	)
 	pods.Controls.AddControl(report.Control{
@@ -353,6 +543,18 @@ func (r *Reporter) podTopology(services []Service, replicaSets []ReplicaSet) (re
 		Icon:  "fa-trash-o",
 		Rank:  1,
 	})
+	pods.Controls.AddControl(report.Control{
+		ID:    AttachPod,
+		Human: "Attach",
+		Icon:  "fa-desktop",
+		Rank:  2,
+	})
+	pods.Controls.AddControl(report.Control{
+		ID:    ExecPod,
+		Human: "Exec shell",
+		Icon:  "fa-terminal",
+		Rank:  3,
+	})
 	for _, service := range services {
 		selectors = append(selectors, match(
 			service.Selector(),
@@ -368,7 +570,20 @@ func (r *Reporter) podTopology(services []Service, replicaSets []ReplicaSet) (re
 		))
 	}
 
-	thisNodeName, err := GetNodeName(r)
+	// The endpoints catch the pods backing services without selectors, and
+	// those selected but not yet ready.
+	backedServices := map[string][]string{} // pod UID -> service node IDs
+	for _, service := range services {
+		e, ok := endpoints[service.ID()]
+		if !ok {
+			continue
+		}
+		for _, uid := range e.PodUIDs() {
+			backedServices[uid] = append(backedServices[uid], report.MakeServiceNodeID(service.UID()))
+		}
+	}
+
+	thisNodeName, err := r.thisNodeName()
 	if err != nil {
 		return pods, err
 	}
@@ -379,6 +594,14 @@ func (r *Reporter) podTopology(services []Service, replicaSets []ReplicaSet) (re
 		for _, selector := range selectors {
 			selector(p)
 		}
+		for _, serviceID := range backedServices[p.UID()] {
+			p.AddParent(report.Service, serviceID)
+		}
+		for _, claimName := range p.ClaimNames() {
+			for _, parent := range claimParents[p.Namespace()+"/"+claimName] {
+				p.AddParent(parent.topology, parent.id)
+			}
+		}
 		pods = pods.AddNode(p.GetNode(r.probeID))
 		return nil
 	})
@@ -5,13 +5,17 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"sync"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/api/unversioned"
 	"k8s.io/kubernetes/pkg/types"
 
 	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
 	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/probe/kubernetes"
 	"$GITHUB_URI/report"
@@ -23,6 +27,9 @@ var (
 	pod1UID     = "a1b2c3d4e5"
 	pod2UID     = "f6g7h8i9j0"
 	serviceUID  = "service1234"
+	service2UID = "service5678"
+	volumeUID   = "volume1234"
+	claimUID    = "claim1234"
 	podTypeMeta = unversioned.TypeMeta{
 		Kind:       "Pod",
 		APIVersion: "v1",
@@ -45,6 +52,28 @@ var (
 		},
 		Spec: api.PodSpec{
 			NodeName: nodeName,
+			Containers: []api.Container{
+				{Name: "pong", Resources: api.ResourceRequirements{
+					Requests: api.ResourceList{
+						api.ResourceCPU:    resource.MustParse("250m"),
+						api.ResourceMemory: resource.MustParse("64Mi"),
+					},
+					Limits: api.ResourceList{
+						api.ResourceCPU:    resource.MustParse("500m"),
+						api.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				}},
+				{Name: "sidecar", TTY: true, Resources: api.ResourceRequirements{
+					Requests: api.ResourceList{
+						api.ResourceCPU: resource.MustParse("100m"),
+					},
+				}},
+			},
+			Volumes: []api.Volume{
+				{Name: "data", VolumeSource: api.VolumeSource{
+					PersistentVolumeClaim: &api.PersistentVolumeClaimVolumeSource{ClaimName: "pong-data"},
+				}},
+			},
 		},
 	}
 	apiPod2 = api.Pod{
@@ -95,23 +124,107 @@ var (
 			},
 		},
 	}
+	// A headless service without a selector, with manually managed endpoints
+	apiService2 = api.Service{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:              "pongheadless",
+			UID:               types.UID(service2UID),
+			Namespace:         "ping",
+			CreationTimestamp: unversioned.Now(),
+		},
+		Spec: api.ServiceSpec{
+			ClusterIP: api.ClusterIPNone,
+		},
+	}
+	apiEndpoints2 = api.Endpoints{
+		ObjectMeta: api.ObjectMeta{
+			Name:      "pongheadless",
+			Namespace: "ping",
+		},
+		Subsets: []api.EndpointSubset{{
+			Addresses: []api.EndpointAddress{
+				{IP: "10.0.0.2", TargetRef: &api.ObjectReference{Kind: "Pod", UID: types.UID(pod2UID)}},
+			},
+			NotReadyAddresses: []api.EndpointAddress{
+				{IP: "10.0.0.1", TargetRef: &api.ObjectReference{Kind: "Pod", UID: types.UID(pod1UID)}},
+			},
+			Ports: []api.EndpointPort{{Port: 6379}},
+		}},
+	}
+	apiVolume1 = api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{
+			Name:        "pv1",
+			UID:         types.UID(volumeUID),
+			Annotations: map[string]string{"volume.beta.kubernetes.io/storage-class": "fast"},
+		},
+		Spec: api.PersistentVolumeSpec{
+			Capacity: api.ResourceList{api.ResourceStorage: resource.MustParse("10Gi")},
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				NFS: &api.NFSVolumeSource{Server: "nfs.local", Path: "/exports/pv1"},
+			},
+			AccessModes: []api.PersistentVolumeAccessMode{api.ReadWriteOnce},
+		},
+		Status: api.PersistentVolumeStatus{Phase: api.VolumeBound},
+	}
+	apiClaim1 = api.PersistentVolumeClaim{
+		ObjectMeta: api.ObjectMeta{
+			Name:        "pong-data",
+			Namespace:   "ping",
+			UID:         types.UID(claimUID),
+			Annotations: map[string]string{"volume.beta.kubernetes.io/storage-class": "fast"},
+		},
+		Spec: api.PersistentVolumeClaimSpec{
+			VolumeName: "pv1",
+		},
+		Status: api.PersistentVolumeClaimStatus{Phase: api.ClaimBound},
+	}
 	pod1     = kubernetes.NewPod(&apiPod1)
 	pod2     = kubernetes.NewPod(&apiPod2)
 	service1 = kubernetes.NewService(&apiService1)
+	service2 = kubernetes.NewService(&apiService2)
 )
 
 func newMockClient() *mockClient {
 	return &mockClient{
-		pods:     []kubernetes.Pod{pod1, pod2},
-		services: []kubernetes.Service{service1},
-		logs:     map[string]io.ReadCloser{},
+		pods:      []kubernetes.Pod{pod1, pod2},
+		services:  []kubernetes.Service{service1, service2},
+		logs:      map[string]io.ReadCloser{},
+		endpoints: map[string]api.Endpoints{"ping/pongheadless": apiEndpoints2},
+		volumes:   []*api.PersistentVolume{&apiVolume1},
+		claims:    []*api.PersistentVolumeClaim{&apiClaim1},
 	}
 }
 
 type mockClient struct {
-	pods     []kubernetes.Pod
-	services []kubernetes.Service
-	logs     map[string]io.ReadCloser
+	pods       []kubernetes.Pod
+	services   []kubernetes.Service
+	logs       map[string]io.ReadCloser
+	logOptions []controls.LogOptions
+	endpoints  map[string]api.Endpoints
+	version    int
+	volumes    []*api.PersistentVolume
+	claims     []*api.PersistentVolumeClaim
+
+	// streams are written by the pipe goroutines of the exec and attach
+	// controls, so are guarded.
+	sync.Mutex
+	streams []string
+}
+
+func (c *mockClient) addStream(format string, args ...interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	c.streams = append(c.streams, fmt.Sprintf(format, args...))
+}
+
+func (c *mockClient) allStreams() []string {
+	c.Lock()
+	defer c.Unlock()
+	return append([]string{}, c.streams...)
 }
 
 func (c *mockClient) Stop() {}
@@ -143,14 +256,50 @@ func (c *mockClient) WalkReplicationControllers(f func(kubernetes.ReplicationCon
 func (*mockClient) WalkNodes(f func(*api.Node) error) error {
 	return nil
 }
+func (c *mockClient) WalkPersistentVolumes(f func(kubernetes.PersistentVolume) error) error {
+	for _, pv := range c.volumes {
+		if err := f(kubernetes.NewPersistentVolume(pv)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkPersistentVolumeClaims(f func(kubernetes.PersistentVolumeClaim) error) error {
+	for _, pvc := range c.claims {
+		if err := f(kubernetes.NewPersistentVolumeClaim(pvc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkEndpoints(f func(kubernetes.Endpoints) error) error {
+	for _, e := range c.endpoints {
+		e := e
+		if err := f(kubernetes.NewEndpoints(&e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 func (*mockClient) WatchPods(func(kubernetes.Event, kubernetes.Pod)) {}
-func (c *mockClient) GetLogs(namespaceID, podName string) (io.ReadCloser, error) {
-	r, ok := c.logs[namespaceID+";"+podName]
+func (c *mockClient) GetLogs(namespaceID, podName, container string, opts controls.LogOptions) (io.ReadCloser, error) {
+	c.logOptions = append(c.logOptions, opts)
+	r, ok := c.logs[namespaceID+";"+podName+";"+container]
 	if !ok {
 		return nil, fmt.Errorf("Not found")
 	}
 	return r, nil
 }
+func (c *mockClient) Exec(namespaceID, podName, container string, cmd []string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	c.addStream("exec %s/%s/%s", namespaceID, podName, container)
+	_, err := fmt.Fprintf(stdout, "exec: %s", container)
+	return err
+}
+func (c *mockClient) Attach(namespaceID, podName, container string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	c.addStream("attach %s/%s/%s tty=%v", namespaceID, podName, container, tty)
+	_, err := fmt.Fprintf(stdout, "attach: %s", container)
+	return err
+}
 func (c *mockClient) DeletePod(namespaceID, podID string) error {
 	return nil
 }
@@ -161,17 +310,79 @@ func (c *mockClient) ScaleDown(resource, namespaceID, id string) error {
 	return nil
 }
 
-type mockPipeClient map[string]xfer.Pipe
+// The endpoints methods behave like the API server, including rejecting
+// updates to stale objects, so leader election can be tested against them.
+func (c *mockClient) GetEndpoints(namespaceID, name string) (*api.Endpoints, error) {
+	e, ok := c.endpoints[namespaceID+"/"+name]
+	if !ok {
+		return nil, errors.NewNotFound(api.Resource("endpoints"), name)
+	}
+	e.Annotations = copyAnnotations(e.Annotations)
+	return &e, nil
+}
+func (c *mockClient) CreateEndpoints(e *api.Endpoints) (*api.Endpoints, error) {
+	key := e.Namespace + "/" + e.Name
+	if _, ok := c.endpoints[key]; ok {
+		return nil, errors.NewAlreadyExists(api.Resource("endpoints"), e.Name)
+	}
+	return c.storeEndpoints(key, *e), nil
+}
+func (c *mockClient) UpdateEndpoints(e *api.Endpoints) (*api.Endpoints, error) {
+	key := e.Namespace + "/" + e.Name
+	if current, ok := c.endpoints[key]; !ok {
+		return nil, errors.NewNotFound(api.Resource("endpoints"), e.Name)
+	} else if current.ResourceVersion != e.ResourceVersion {
+		return nil, errors.NewConflict(api.Resource("endpoints"), e.Name, fmt.Errorf("stale resource version"))
+	}
+	return c.storeEndpoints(key, *e), nil
+}
+func (c *mockClient) storeEndpoints(key string, e api.Endpoints) *api.Endpoints {
+	c.version++
+	e.ResourceVersion = fmt.Sprint(c.version)
+	e.Annotations = copyAnnotations(e.Annotations)
+	c.endpoints[key] = e
+	return &e
+}
 
-func (c mockPipeClient) PipeConnection(appID, id string, pipe xfer.Pipe) error {
-	c[id] = pipe
+func copyAnnotations(annotations map[string]string) map[string]string {
+	result := map[string]string{}
+	for k, v := range annotations {
+		result[k] = v
+	}
+	return result
+}
+
+// mockPipeClient is shared with the goroutines which close finished pipes,
+// so is guarded.
+type mockPipeClient struct {
+	sync.Mutex
+	pipes map[string]xfer.Pipe
+}
+
+func newMockPipeClient() *mockPipeClient {
+	return &mockPipeClient{pipes: map[string]xfer.Pipe{}}
+}
+
+func (c *mockPipeClient) PipeConnection(appID, id string, pipe xfer.Pipe) error {
+	c.Lock()
+	defer c.Unlock()
+	c.pipes[id] = pipe
 	return nil
 }
 
-func (c mockPipeClient) PipeClose(appID, id string) error {
-	err := c[id].Close()
-	delete(c, id)
-	return err
+func (c *mockPipeClient) PipeClose(appID, id string) error {
+	c.Lock()
+	pipe := c.pipes[id]
+	delete(c.pipes, id)
+	c.Unlock()
+	return pipe.Close()
+}
+
+func (c *mockPipeClient) pipe(id string) (xfer.Pipe, bool) {
+	c.Lock()
+	defer c.Unlock()
+	pipe, ok := c.pipes[id]
+	return pipe, ok
 }
 
 func TestReporter(t *testing.T) {
@@ -184,7 +395,7 @@ func TestReporter(t *testing.T) {
 	pod1ID := report.MakePodNodeID(pod1UID)
 	pod2ID := report.MakePodNodeID(pod2UID)
 	serviceID := report.MakeServiceNodeID(serviceUID)
-	rpt, _ := kubernetes.NewReporter(newMockClient(), nil, "", "foo", nil).Report()
+	rpt, _ := kubernetes.NewReporter(newMockClient(), nil, "", "foo", nil, nil).Report()
 
 	// Reporter should have added the following pods
 	for _, pod := range []struct {
@@ -193,10 +404,12 @@ func TestReporter(t *testing.T) {
 		latest        map[string]string
 	}{
 		{pod1ID, serviceID, map[string]string{
-			kubernetes.ID:        "ping/pong-a",
-			kubernetes.Name:      "pong-a",
-			kubernetes.Namespace: "ping",
-			kubernetes.Created:   pod1.Created(),
+			kubernetes.ID:            "ping/pong-a",
+			kubernetes.Name:          "pong-a",
+			kubernetes.Namespace:     "ping",
+			kubernetes.Created:       pod1.Created(),
+			kubernetes.CPURequest:    "350",
+			kubernetes.MemoryRequest: "67108864",
 		}},
 		{pod2ID, serviceID, map[string]string{
 			kubernetes.ID:        "ping/pong-b",
@@ -221,6 +434,27 @@ func TestReporter(t *testing.T) {
 		}
 	}
 
+	// Limits are only reported when every container in the pod has one
+	{
+		node := rpt.Pod.Nodes[pod1ID]
+		for _, k := range []string{kubernetes.CPULimit, kubernetes.MemoryLimit} {
+			if have, ok := node.Latest.Lookup(k); ok {
+				t.Errorf("Expected pod %s to have no %q, got %q", pod1ID, k, have)
+			}
+		}
+		rows, _ := node.ExtractTable(kubernetes.ResourcesPrefix)
+		for k, want := range map[string]string{
+			"pong CPU":       "request 250m, limit 500m",
+			"pong memory":    "request 64Mi, limit 128Mi",
+			"sidecar CPU":    "request 100m, limit -",
+			"sidecar memory": "request -, limit -",
+		} {
+			if have := rows[k]; have != want {
+				t.Errorf("Expected pod %s resources %q: %q, got %q", pod1ID, k, want, have)
+			}
+		}
+	}
+
 	// Reporter should have added a service
 	{
 		node, ok := rpt.Service.Nodes[serviceID]
@@ -239,6 +473,29 @@ func TestReporter(t *testing.T) {
 			}
 		}
 	}
+
+	// The headless service is resolved through its endpoints
+	{
+		service2ID := report.MakeServiceNodeID(service2UID)
+		node, ok := rpt.Service.Nodes[service2ID]
+		if !ok {
+			t.Fatalf("Expected report to have service %q, but not found", service2ID)
+		}
+		for k, want := range map[string]report.StringSet{
+			kubernetes.ReadyAddresses:    report.MakeStringSet("10.0.0.2"),
+			kubernetes.NotReadyAddresses: report.MakeStringSet("10.0.0.1"),
+			kubernetes.EndpointPorts:     report.MakeStringSet("6379"),
+		} {
+			if have, ok := node.Sets.Lookup(k); !ok || !reflect.DeepEqual(want, have) {
+				t.Errorf("Expected service %s set %q: %v, got %v", service2ID, k, want, have)
+			}
+		}
+		for _, podID := range []string{pod1ID, pod2ID} {
+			if parents, ok := rpt.Pod.Nodes[podID].Parents.Lookup(report.Service); !ok || !parents.Contains(service2ID) {
+				t.Errorf("Expected pod %s to have parent service %q, got %q", podID, service2ID, parents)
+			}
+		}
+	}
 }
 
 func TestTagger(t *testing.T) {
@@ -247,7 +504,7 @@ func TestTagger(t *testing.T) {
 		docker.LabelPrefix + "io.kubernetes.pod.uid": "123456",
 	}))
 
-	rpt, err := kubernetes.NewReporter(newMockClient(), nil, "", "", nil).Tag(rpt)
+	rpt, err := kubernetes.NewReporter(newMockClient(), nil, "", "", nil, nil).Tag(rpt)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -274,12 +531,12 @@ func TestReporterGetLogs(t *testing.T) {
 	}
 
 	client := newMockClient()
-	pipes := mockPipeClient{}
-	reporter := kubernetes.NewReporter(client, pipes, "", "", nil)
+	pipes := newMockPipeClient()
+	reporter := kubernetes.NewReporter(client, pipes, "", "", nil, nil)
 
 	// Should error on invalid IDs
 	{
-		resp := reporter.CapturePod(reporter.GetLogs)(xfer.Request{
+		resp := reporter.CapturePodContainer(reporter.GetLogs)(xfer.Request{
 			NodeID:  "invalidID",
 			Control: kubernetes.GetLogs,
 		})
@@ -290,7 +547,7 @@ func TestReporterGetLogs(t *testing.T) {
 
 	// Should pass through errors from k8s (e.g if pod does not exist)
 	{
-		resp := reporter.CapturePod(reporter.GetLogs)(xfer.Request{
+		resp := reporter.CapturePodContainer(reporter.GetLogs)(xfer.Request{
 			AppID:   "appID",
 			NodeID:  report.MakePodNodeID("notfound"),
 			Control: kubernetes.GetLogs,
@@ -300,27 +557,32 @@ func TestReporterGetLogs(t *testing.T) {
 		}
 	}
 
-	podNamespaceAndID := "ping;pong-a"
+	podNamespaceAndID := "ping;pong-a;sidecar"
 	pod1Request := xfer.Request{
 		AppID:   "appID",
 		NodeID:  report.MakePodNodeID(pod1UID),
 		Control: kubernetes.GetLogs,
+		ControlArgs: map[string]string{
+			kubernetes.ContainerArg: "sidecar",
+			controls.LogsFollowArg:  "false",
+			controls.LogsTailArg:    "20",
+		},
 	}
 
 	// Inject our logs content, and watch for it to be closed
 	closed := false
-	wantContents := "logs: ping/pong-a"
+	wantContents := "logs: ping/pong-a/sidecar"
 	client.logs[podNamespaceAndID] = &callbackReadCloser{Reader: strings.NewReader(wantContents), close: func() error {
 		closed = true
 		return nil
 	}}
 
 	// Should create a new pipe for the stream
-	resp := reporter.CapturePod(reporter.GetLogs)(pod1Request)
+	resp := reporter.CapturePodContainer(reporter.GetLogs)(pod1Request)
 	if resp.Pipe == "" {
 		t.Errorf("Expected pipe id to be returned, but got %#v", resp)
 	}
-	pipe, ok := pipes[resp.Pipe]
+	pipe, ok := pipes.pipe(resp.Pipe)
 	if !ok {
 		t.Fatalf("Expected pipe %q to have been created, but wasn't", resp.Pipe)
 	}
@@ -342,4 +604,153 @@ func TestReporterGetLogs(t *testing.T) {
 	if !closed {
 		t.Errorf("Expected pipe to close the underlying log stream")
 	}
+
+	// Should pass the options on to k8s
+	if want := []controls.LogOptions{{Follow: false, Tail: 20}}; !reflect.DeepEqual(want, client.logOptions) {
+		t.Errorf("Expected log options %v, got %v", want, client.logOptions)
+	}
+}
+
+func TestReporterExecAndAttach(t *testing.T) {
+	client := newMockClient()
+	pipes := newMockPipeClient()
+	reporter := kubernetes.NewReporter(client, pipes, "", "", nil, nil)
+
+	for _, tc := range []struct {
+		control      func(xfer.Request, kubernetes.Pod, string) xfer.Response
+		nodeID       string
+		args         map[string]string
+		wantError    string
+		wantContents string
+		wantStream   string
+		wantRawTTY   bool
+	}{
+		{
+			control:   reporter.ExecPod,
+			nodeID:    report.MakePodNodeID(pod2UID),
+			wantError: "Pod has no containers: ping/pong-b",
+		},
+		{
+			control:   reporter.ExecPod,
+			nodeID:    report.MakePodNodeID(pod1UID),
+			args:      map[string]string{kubernetes.ContainerArg: "notfound"},
+			wantError: "Container \"notfound\" not found in pod ping/pong-a",
+		},
+		{
+			// Defaults to the first container
+			control:      reporter.ExecPod,
+			nodeID:       report.MakePodNodeID(pod1UID),
+			wantContents: "exec: pong",
+			wantStream:   "exec ping/pong-a/pong",
+			wantRawTTY:   true,
+		},
+		{
+			control:      reporter.AttachPod,
+			nodeID:       report.MakePodNodeID(pod1UID),
+			args:         map[string]string{kubernetes.ContainerArg: "sidecar"},
+			wantContents: "attach: sidecar",
+			wantStream:   "attach ping/pong-a/sidecar tty=true",
+			wantRawTTY:   true,
+		},
+		{
+			control:      reporter.AttachPod,
+			nodeID:       report.MakePodNodeID(pod1UID),
+			args:         map[string]string{kubernetes.ContainerArg: "pong"},
+			wantContents: "attach: pong",
+			wantStream:   "attach ping/pong-a/pong tty=false",
+		},
+	} {
+		client.Lock()
+		client.streams = nil
+		client.Unlock()
+		resp := reporter.CapturePodContainer(tc.control)(xfer.Request{
+			AppID:       "appID",
+			NodeID:      tc.nodeID,
+			ControlArgs: tc.args,
+		})
+		if resp.Error != tc.wantError {
+			t.Errorf("Expected error %q, got %q", tc.wantError, resp.Error)
+		}
+		if tc.wantError != "" {
+			continue
+		}
+		if resp.RawTTY != tc.wantRawTTY {
+			t.Errorf("Expected raw tty %v, got %v", tc.wantRawTTY, resp.RawTTY)
+		}
+		pipe, ok := pipes.pipe(resp.Pipe)
+		if !ok {
+			t.Fatalf("Expected pipe %q to have been created, but wasn't", resp.Pipe)
+		}
+
+		// Should push the container's output into the pipe
+		_, remote := pipe.Ends()
+		contents := make([]byte, len(tc.wantContents))
+		if _, err := io.ReadFull(remote, contents); err != nil {
+			t.Error(err)
+		}
+		if string(contents) != tc.wantContents {
+			t.Errorf("Expected pipe to contain %q, but got %q", tc.wantContents, string(contents))
+		}
+		if streams := client.allStreams(); len(streams) != 1 || streams[0] != tc.wantStream {
+			t.Errorf("Expected stream %q, got %v", tc.wantStream, streams)
+		}
+	}
+}
+
+func TestReporterStorage(t *testing.T) {
+	oldGetNodeName := kubernetes.GetNodeName
+	defer func() { kubernetes.GetNodeName = oldGetNodeName }()
+	kubernetes.GetNodeName = func(*kubernetes.Reporter) (string, error) {
+		return nodeName, nil
+	}
+
+	rpt, err := kubernetes.NewReporter(newMockClient(), nil, "", "foo", nil, nil).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		volumeID = report.MakePersistentVolumeNodeID(volumeUID)
+		claimID  = report.MakePersistentVolumeClaimNodeID(claimUID)
+		classID  = report.MakeStorageClassNodeID("fast")
+	)
+
+	volume, ok := rpt.PersistentVolume.Nodes[volumeID]
+	if !ok {
+		t.Fatalf("Expected report to have volume %q", volumeID)
+	}
+	for k, want := range map[string]string{
+		kubernetes.ID:           "pv1",
+		kubernetes.Capacity:     "10Gi",
+		kubernetes.VolumeType:   "NFS",
+		kubernetes.VolumeSource: "nfs.local:/exports/pv1",
+		kubernetes.State:        "Bound",
+		kubernetes.AccessModes:  "ReadWriteOnce",
+	} {
+		if have, ok := volume.Latest.Lookup(k); !ok || have != want {
+			t.Errorf("Expected volume latest %q: %q, got %q", k, want, have)
+		}
+	}
+	if _, ok := rpt.StorageClass.Nodes[classID]; !ok {
+		t.Errorf("Expected report to have storage class %q", classID)
+	}
+
+	for _, check := range []struct {
+		node     report.Node
+		topology string
+		parent   string
+	}{
+		{volume, report.StorageClass, classID},
+		{rpt.PersistentVolumeClaim.Nodes[claimID], report.PersistentVolume, volumeID},
+		{rpt.PersistentVolumeClaim.Nodes[claimID], report.StorageClass, classID},
+		{rpt.Pod.Nodes[report.MakePodNodeID(pod1UID)], report.PersistentVolumeClaim, claimID},
+		{rpt.Pod.Nodes[report.MakePodNodeID(pod1UID)], report.PersistentVolume, volumeID},
+		{rpt.Pod.Nodes[report.MakePodNodeID(pod1UID)], report.StorageClass, classID},
+	} {
+		if parents, ok := check.node.Parents.Lookup(check.topology); !ok || !parents.Contains(check.parent) {
+			t.Errorf("Expected %s to have parent %q, got %v", check.node.ID, check.parent, parents)
+		}
+	}
+	if parents, ok := rpt.Pod.Nodes[report.MakePodNodeID(pod2UID)].Parents.Lookup(report.PersistentVolume); ok {
+		t.Errorf("Expected pod2 not to have volume parents, got %v", parents)
+	}
 }
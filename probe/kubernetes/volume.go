@@ -0,0 +1,194 @@
+package kubernetes
+
+import (
+	"strings"
+
+	"$GITHUB_URI/report"
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// These constants are keys used in node metadata
+const (
+	Capacity      = "kubernetes_capacity"
+	AccessModes   = "kubernetes_access_modes"
+	ReclaimPolicy = "kubernetes_reclaim_policy"
+	VolumeType    = "kubernetes_volume_type"
+	VolumeSource  = "kubernetes_volume_source"
+	VolumeName    = "kubernetes_volume_name"
+	StorageClass  = "kubernetes_storage_class"
+)
+
+// The annotations holding the storage class of volumes and claims, beta
+// taking precedence over alpha.
+var storageClassAnnotations = []string{
+	"volume.beta.kubernetes.io/storage-class",
+	"volume.alpha.kubernetes.io/storage-class",
+}
+
+func storageClass(o api.ObjectMeta) string {
+	for _, annotation := range storageClassAnnotations {
+		if class, ok := o.Annotations[annotation]; ok {
+			return class
+		}
+	}
+	return ""
+}
+
+func accessModes(modes []api.PersistentVolumeAccessMode) string {
+	result := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		result = append(result, string(mode))
+	}
+	return strings.Join(result, ", ")
+}
+
+// PersistentVolume represents a Kubernetes persistent volume
+type PersistentVolume interface {
+	Meta
+	AddParent(topology, id string)
+	StorageClass() string
+	GetNode() report.Node
+}
+
+type persistentVolume struct {
+	*api.PersistentVolume
+	Meta
+	parents report.Sets
+}
+
+// NewPersistentVolume creates a new PersistentVolume
+func NewPersistentVolume(pv *api.PersistentVolume) PersistentVolume {
+	return &persistentVolume{
+		PersistentVolume: pv,
+		Meta:             meta{pv.ObjectMeta},
+		parents:          report.MakeSets(),
+	}
+}
+
+func (pv *persistentVolume) AddParent(topology, id string) {
+	pv.parents = pv.parents.Add(topology, report.MakeStringSet(id))
+}
+
+func (pv *persistentVolume) StorageClass() string {
+	return storageClass(pv.ObjectMeta)
+}
+
+// source returns the kind of storage backing the volume, and where it is.
+func (pv *persistentVolume) source() (string, string) {
+	s := pv.Spec.PersistentVolumeSource
+	switch {
+	case s.HostPath != nil:
+		return "Host Path", s.HostPath.Path
+	case s.NFS != nil:
+		return "NFS", s.NFS.Server + ":" + s.NFS.Path
+	case s.GCEPersistentDisk != nil:
+		return "GCE Persistent Disk", s.GCEPersistentDisk.PDName
+	case s.AWSElasticBlockStore != nil:
+		return "AWS Elastic Block Store", s.AWSElasticBlockStore.VolumeID
+	case s.Glusterfs != nil:
+		return "GlusterFS", s.Glusterfs.EndpointsName + ":" + s.Glusterfs.Path
+	case s.RBD != nil:
+		return "Ceph RBD", s.RBD.RBDPool + "/" + s.RBD.RBDImage
+	case s.ISCSI != nil:
+		return "iSCSI", s.ISCSI.TargetPortal + ":" + s.ISCSI.IQN
+	case s.Cinder != nil:
+		return "Cinder", s.Cinder.VolumeID
+	case s.CephFS != nil:
+		return "CephFS", strings.Join(s.CephFS.Monitors, ",") + ":" + s.CephFS.Path
+	case s.FC != nil:
+		return "Fibre Channel", strings.Join(s.FC.TargetWWNs, ",")
+	case s.Flocker != nil:
+		return "Flocker", s.Flocker.DatasetName
+	case s.AzureFile != nil:
+		return "Azure File", s.AzureFile.ShareName
+	case s.FlexVolume != nil:
+		return "Flex Volume", s.FlexVolume.Driver
+	}
+	return "", ""
+}
+
+func (pv *persistentVolume) GetNode() report.Node {
+	latest := map[string]string{
+		ID:            pv.Name(), // volumes are not namespaced
+		State:         string(pv.Status.Phase),
+		AccessModes:   accessModes(pv.Spec.AccessModes),
+		ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+	}
+	if capacity, ok := pv.Spec.Capacity[api.ResourceStorage]; ok {
+		latest[Capacity] = capacity.String()
+	}
+	if volumeType, source := pv.source(); volumeType != "" {
+		latest[VolumeType] = volumeType
+		latest[VolumeSource] = source
+	}
+	if class := pv.StorageClass(); class != "" {
+		latest[StorageClass] = class
+	}
+	return pv.MetaNode(report.MakePersistentVolumeNodeID(pv.UID())).
+		WithLatests(latest).
+		WithParents(pv.parents)
+}
+
+// PersistentVolumeClaim represents a Kubernetes persistent volume claim
+type PersistentVolumeClaim interface {
+	Meta
+	AddParent(topology, id string)
+	VolumeName() string
+	StorageClass() string
+	GetNode() report.Node
+}
+
+type persistentVolumeClaim struct {
+	*api.PersistentVolumeClaim
+	Meta
+	parents report.Sets
+}
+
+// NewPersistentVolumeClaim creates a new PersistentVolumeClaim
+func NewPersistentVolumeClaim(pvc *api.PersistentVolumeClaim) PersistentVolumeClaim {
+	return &persistentVolumeClaim{
+		PersistentVolumeClaim: pvc,
+		Meta:                  meta{pvc.ObjectMeta},
+		parents:               report.MakeSets(),
+	}
+}
+
+func (pvc *persistentVolumeClaim) AddParent(topology, id string) {
+	pvc.parents = pvc.parents.Add(topology, report.MakeStringSet(id))
+}
+
+// VolumeName returns the name of the volume bound to this claim, if any.
+func (pvc *persistentVolumeClaim) VolumeName() string {
+	return pvc.Spec.VolumeName
+}
+
+func (pvc *persistentVolumeClaim) StorageClass() string {
+	return storageClass(pvc.ObjectMeta)
+}
+
+func (pvc *persistentVolumeClaim) GetNode() report.Node {
+	latest := map[string]string{
+		State:       string(pvc.Status.Phase),
+		AccessModes: accessModes(pvc.Spec.AccessModes),
+	}
+	if request, ok := pvc.Spec.Resources.Requests[api.ResourceStorage]; ok {
+		latest[Capacity] = request.String()
+	}
+	if volumeName := pvc.VolumeName(); volumeName != "" {
+		latest[VolumeName] = volumeName
+	}
+	if class := pvc.StorageClass(); class != "" {
+		latest[StorageClass] = class
+	}
+	return pvc.MetaNode(report.MakePersistentVolumeClaimNodeID(pvc.UID())).
+		WithLatests(latest).
+		WithParents(pvc.parents)
+}
+
+// storageClassNode makes a node for a storage class. Classes are only known
+// by the names volumes and claims refer to them by.
+func storageClassNode(name string) report.Node {
+	return report.MakeNodeWith(report.MakeStorageClassNodeID(name), map[string]string{
+		Name: name,
+	})
+}
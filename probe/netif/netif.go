@@ -0,0 +1,183 @@
+// Package netif reports the network plumbing of a host: its network
+// interfaces, how they hang off each other, the containers behind its veths,
+// and its routing table.
+package netif
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of interfaces
+const (
+	Physical = "physical"
+	Bridge   = "bridge"
+	Bond     = "bond"
+	Veth     = "veth"
+	VLAN     = "vlan"
+	VXLAN    = "vxlan"
+	Tun      = "tun"
+	Virtual  = "virtual"
+)
+
+// Interface is a network interface, as described by sysfs.
+type Interface struct {
+	Name  string
+	Index int
+	// The index of the interface this one is linked to: the peer of a veth,
+	// or the lower device of a VLAN, VXLAN or macvlan device. It is that of
+	// the interface itself otherwise.
+	Link   int
+	Kind   string
+	MAC    string
+	MTU    int
+	State  string
+	Master string // the bridge or bond the interface is enslaved to
+	Lower  string // the lower device, when in the same namespace
+	VLANID string
+}
+
+// InterfaceAddrs returns the addresses of an interface of the host, in CIDR
+// notation. Exposed for testing.
+var InterfaceAddrs = func(name string) ([]string, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		result = append(result, addr.String())
+	}
+	return result, nil
+}
+
+// ReadInterfaces reads the interfaces of the network namespace sysfs was
+// mounted in, from its class/net directory, e.g. /sys/class/net. VLAN IDs
+// are read from procVLAN, e.g. /proc/net/vlan, if not empty.
+func ReadInterfaces(sysClassNet, procVLAN string) ([]Interface, error) {
+	entries, err := ioutil.ReadDir(sysClassNet)
+	if err != nil {
+		return nil, err
+	}
+	interfaces := []Interface{}
+	for _, entry := range entries {
+		dir := filepath.Join(sysClassNet, entry.Name())
+		index, err := readInt(dir, "ifindex")
+		if err != nil {
+			// Not an interface, e.g. bonding_masters
+			continue
+		}
+		iface := Interface{
+			Name:   entry.Name(),
+			Index:  index,
+			Link:   index,
+			MAC:    readString(dir, "address"),
+			State:  readString(dir, "operstate"),
+			Master: linkBase(dir, "master"),
+		}
+		if link, err := readInt(dir, "iflink"); err == nil && link != 0 {
+			iface.Link = link
+		}
+		iface.MTU, _ = readInt(dir, "mtu")
+		if lowers, _ := filepath.Glob(filepath.Join(dir, "lower_*")); len(lowers) > 0 {
+			iface.Lower = strings.TrimPrefix(filepath.Base(lowers[0]), "lower_")
+		}
+		iface.Kind = kind(dir, iface)
+		if iface.Kind == VLAN && procVLAN != "" {
+			iface.VLANID = readVLANID(filepath.Join(procVLAN, iface.Name))
+		}
+		interfaces = append(interfaces, iface)
+	}
+	sort.Sort(interfacesByIndex(interfaces))
+	return interfaces, nil
+}
+
+// kind tells the kind of an interface, from the device type the kernel gives
+// it, or else from what its sysfs directory holds.
+func kind(dir string, iface Interface) string {
+	switch devtype := ueventValue(dir, "DEVTYPE"); devtype {
+	case Bridge, Bond, VLAN, VXLAN:
+		return devtype
+	case "wlan":
+		return Physical
+	}
+	if exists(filepath.Join(dir, "device")) {
+		return Physical
+	}
+	if exists(filepath.Join(dir, "tun_flags")) {
+		return Tun
+	}
+	// Devices stacked on another, e.g. macvlans, link to it like veths do
+	// to their peer, but also have sysfs link to it when in the same
+	// namespace.
+	if iface.Link != iface.Index && iface.Lower == "" {
+		return Veth
+	}
+	return Virtual
+}
+
+// readVLANID reads the VLAN ID of a VLAN device from its /proc/net/vlan
+// file, e.g. "eth0.100  VID: 100	 REORDER_HDR: 1  dev->priv_flags: 1".
+func readVLANID(filename string) string {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(buf))
+	for i, field := range fields {
+		if field == "VID:" && i+1 < len(fields) {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+func ueventValue(dir, key string) string {
+	for _, line := range strings.Split(readString(dir, "uevent"), "\n") {
+		if strings.HasPrefix(line, key+"=") {
+			return strings.TrimPrefix(line, key+"=")
+		}
+	}
+	return ""
+}
+
+func readString(dir, name string) string {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(buf))
+}
+
+func readInt(dir, name string) (int, error) {
+	return strconv.Atoi(readString(dir, name))
+}
+
+// linkBase returns the name of the file a symlink points to, if any.
+func linkBase(dir, name string) string {
+	target, err := os.Readlink(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+func exists(filename string) bool {
+	_, err := os.Lstat(filename)
+	return err == nil
+}
+
+type interfacesByIndex []Interface
+
+func (s interfacesByIndex) Len() int           { return len(s) }
+func (s interfacesByIndex) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s interfacesByIndex) Less(i, j int) bool { return s[i].Index < s[j].Index }
@@ -0,0 +1,172 @@
+package netif_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"$GITHUB_URI/probe/netif"
+)
+
+// fixtureInterface is written out as the sysfs directory of an interface.
+type fixtureInterface struct {
+	index, link int
+	devtype     string
+	master      string
+	lower       string
+	physical    bool
+}
+
+// writeSysfs writes out the class/net directory of a sysfs at root.
+func writeSysfs(t *testing.T, root string, interfaces map[string]fixtureInterface) {
+	for name, iface := range interfaces {
+		dir := filepath.Join(root, "class", "net", name)
+		files := map[string]string{
+			"ifindex":   strconv.Itoa(iface.index),
+			"iflink":    strconv.Itoa(iface.link),
+			"address":   "02:42:ac:11:00:" + strconv.Itoa(10+iface.index),
+			"mtu":       "1500",
+			"operstate": "up",
+			"uevent":    "INTERFACE=" + name + "\nIFINDEX=" + strconv.Itoa(iface.index) + "\n",
+		}
+		if iface.devtype != "" {
+			files["uevent"] = "DEVTYPE=" + iface.devtype + "\n" + files["uevent"]
+		}
+		writeFiles(t, dir, files)
+		if iface.master != "" {
+			symlink(t, "../"+iface.master, filepath.Join(dir, "master"))
+		}
+		if iface.lower != "" {
+			symlink(t, "../"+iface.lower, filepath.Join(dir, "lower_"+iface.lower))
+		}
+		if iface.physical {
+			symlink(t, "../../../devices/pci0000:00/0000:00:03.0", filepath.Join(dir, "device"))
+		}
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func symlink(t *testing.T, target, name string) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The interfaces of a docker host with a VLAN, a VXLAN overlay and a
+// container, whose veth is eth0@if7 inside it.
+var hostInterfaces = map[string]fixtureInterface{
+	"lo":           {index: 1, link: 1},
+	"eth0":         {index: 2, link: 2, physical: true},
+	"docker0":      {index: 3, link: 3, devtype: "bridge"},
+	"eth0.100":     {index: 4, link: 2, devtype: "vlan", lower: "eth0"},
+	"vxlan.calico": {index: 5, link: 2, devtype: "vxlan"},
+	"veth1a2b3c":   {index: 7, link: 6, master: "docker0"},
+	"tap0":         {index: 8, link: 8},
+}
+
+func TestReadInterfaces(t *testing.T) {
+	root, err := ioutil.TempDir("", "sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeSysfs(t, root, hostInterfaces)
+	writeFiles(t, filepath.Join(root, "vlan"), map[string]string{
+		"eth0.100": "eth0.100  VID: 100\t REORDER_HDR: 1  dev->priv_flags: 1\n",
+	})
+
+	interfaces, err := netif.ReadInterfaces(filepath.Join(root, "class", "net"), filepath.Join(root, "vlan"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	have := map[string]netif.Interface{}
+	for _, iface := range interfaces {
+		have[iface.Name] = iface
+	}
+	for name, want := range map[string]struct {
+		kind, master, lower, vlanID string
+	}{
+		"lo":           {kind: netif.Virtual},
+		"eth0":         {kind: netif.Physical},
+		"docker0":      {kind: netif.Bridge},
+		"eth0.100":     {kind: netif.VLAN, lower: "eth0", vlanID: "100"},
+		"vxlan.calico": {kind: netif.VXLAN},
+		"veth1a2b3c":   {kind: netif.Veth, master: "docker0"},
+		"tap0":         {kind: netif.Virtual},
+	} {
+		iface, ok := have[name]
+		if !ok {
+			t.Errorf("Expected interface %s, but not found", name)
+			continue
+		}
+		if iface.Kind != want.kind || iface.Master != want.master || iface.Lower != want.lower || iface.VLANID != want.vlanID {
+			t.Errorf("Expected %s to be %+v, got %+v", name, want, iface)
+		}
+		if iface.MTU != 1500 || iface.State != "up" {
+			t.Errorf("Expected %s to be up with an MTU of 1500, got %+v", name, iface)
+		}
+	}
+	if veth := have["veth1a2b3c"]; veth.Index != 7 || veth.Link != 6 {
+		t.Errorf("Expected veth1a2b3c to be 7 linked to 6, got %+v", veth)
+	}
+}
+
+func TestReadRoutes(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeFiles(t, root, map[string]string{
+		"route": `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+eth0	0001A8C0	00000000	0001	0	0	100	00FFFFFF	0	0	0
+docker0	000011AC	00000000	0001	0	0	0	0000FFFF	0	0	0
+eth0	0002A8C0	00000000	0000	0	0	0	00FFFFFF	0	0	0
+`,
+		"ipv6_route": `20010db8000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001 eth0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 20010db8000000000000000000000001 00000400 00000001 00000000 00000003 eth0
+fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001 eth0
+20010db8000000000000000000000002 80 00000000000000000000000000000000 00 00000000000000000000000000000000 00000000 00000002 00000000 80200001 eth0
+00000000000000000000000000000001 80 00000000000000000000000000000000 00 00000000000000000000000000000000 00000000 00000002 00000000 80200001 lo
+`,
+	})
+
+	routes, err := netif.ReadRoutes(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have := []string{}
+	for _, route := range routes {
+		have = append(have, route.DestinationString()+" dev "+route.Interface+": "+route.Description())
+	}
+	sort.Strings(have)
+	want := []string{
+		"172.17.0.0/16 dev docker0: direct, metric 0",
+		"192.168.1.0/24 dev eth0: direct, metric 100",
+		"2001:db8::/64 dev eth0: direct, metric 256",
+		"default dev eth0: via 192.168.1.1, metric 100",
+		"default dev eth0: via 2001:db8::1, metric 1024",
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected routes %v, got %v", want, have)
+	}
+}
@@ -0,0 +1,272 @@
+package netif
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/report"
+)
+
+// These constants are keys used in node metadata
+const (
+	Name      = "netif_name"
+	Kind      = "netif_kind"
+	State     = "netif_state"
+	MAC       = "netif_mac"
+	MTU       = "netif_mtu"
+	Master    = "netif_master"
+	Lower     = "netif_lower"
+	VLANID    = "netif_vlan_id"
+	Peer      = "netif_peer"
+	Addresses = "netif_addresses"
+
+	// The ifindex of the interface, to link containers' interfaces to it
+	Index = "netif_index"
+
+	// RoutePrefix prefixes the rows of the table of the routes through an
+	// interface, keyed by destination, e.g. "10.32.0.0/12".
+	RoutePrefix = "netif_route_"
+
+	// HostRoutePrefix prefixes the rows of the routing table of hosts, keyed
+	// by destination and interface, e.g. "default dev eth0".
+	HostRoutePrefix = "host_route_"
+)
+
+// Exposed for testing
+var (
+	MetadataTemplates = report.MetadataTemplates{
+		Name:      {ID: Name, Label: "Name", From: report.FromLatest, Priority: 1},
+		Kind:      {ID: Kind, Label: "Kind", From: report.FromLatest, Priority: 2},
+		State:     {ID: State, Label: "State", From: report.FromLatest, Priority: 3},
+		Addresses: {ID: Addresses, Label: "Addresses", From: report.FromSets, Priority: 4},
+		MAC:       {ID: MAC, Label: "MAC", From: report.FromLatest, Priority: 5},
+		MTU:       {ID: MTU, Label: "MTU", From: report.FromLatest, Datatype: "number", Priority: 6},
+		Master:    {ID: Master, Label: "Master", From: report.FromLatest, Priority: 7},
+		Lower:     {ID: Lower, Label: "Lower device", From: report.FromLatest, Priority: 8},
+		VLANID:    {ID: VLANID, Label: "VLAN ID", From: report.FromLatest, Datatype: "number", Priority: 9},
+		Peer:      {ID: Peer, Label: "Container interface", From: report.FromLatest, Priority: 10},
+	}
+
+	TableTemplates = report.TableTemplates{
+		RoutePrefix: {ID: RoutePrefix, Label: "Routes", Prefix: RoutePrefix},
+	}
+
+	HostTableTemplates = report.TableTemplates{
+		HostRoutePrefix: {ID: HostRoutePrefix, Label: "Routes", Prefix: HostRoutePrefix},
+	}
+)
+
+// MakeInterfaceNodeID makes the ID of an interface node. Interfaces are only
+// unique per host.
+func MakeInterfaceNodeID(hostID, name string) string {
+	return report.MakeNetworkInterfaceNodeID(name + "@" + hostID)
+}
+
+// ParseInterfaceNodeID returns the interface name and host ID of an
+// interface node ID.
+func ParseInterfaceNodeID(nodeID string) (name, hostID string, ok bool) {
+	id, ok := report.ParseNetworkInterfaceNodeID(nodeID)
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(id, "@")
+	if i < 0 {
+		return "", "", false
+	}
+	return id[:i], id[i+1:], true
+}
+
+// Reporter generates Reports containing the NetworkInterface topology, and
+// the routing table of the host. As a tagger, it links the interfaces
+// containers' interfaces hang off to the containers.
+type Reporter struct {
+	hostID   string
+	sysRoot  string
+	procRoot string
+}
+
+// NewReporter makes a new Reporter, reading from the sysfs and proc
+// filesystems at sysRoot and procRoot, e.g. /sys and /proc.
+func NewReporter(hostID, sysRoot, procRoot string) *Reporter {
+	return &Reporter{
+		hostID:   hostID,
+		sysRoot:  sysRoot,
+		procRoot: procRoot,
+	}
+}
+
+// Name of this reporter, for metrics gathering
+func (*Reporter) Name() string { return "NetworkInterface" }
+
+// Report implements Reporter.
+func (r *Reporter) Report() (report.Report, error) {
+	result := report.MakeReport()
+	interfaces, err := ReadInterfaces(filepath.Join(r.sysRoot, "class", "net"), filepath.Join(r.procRoot, "net", "vlan"))
+	if os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return result, err
+	}
+	routes, err := ReadRoutes(filepath.Join(r.procRoot, "net"))
+	if err != nil {
+		return result, err
+	}
+
+	var (
+		hostNodeID = report.MakeHostNodeID(r.hostID)
+		parents    = report.EmptySets.Add(report.Host, report.MakeStringSet(hostNodeID))
+		byIndex    = map[int]Interface{}
+		byName     = map[string]Interface{}
+		routeRows  = map[string]map[string]string{}
+		hostRoutes = map[string]string{}
+	)
+	for _, iface := range interfaces {
+		if iface.Name != "lo" {
+			byIndex[iface.Index] = iface
+			byName[iface.Name] = iface
+		}
+	}
+	for _, route := range routes {
+		if _, ok := byName[route.Interface]; !ok {
+			continue
+		}
+		rows, ok := routeRows[route.Interface]
+		if !ok {
+			rows = map[string]string{}
+			routeRows[route.Interface] = rows
+		}
+		rows[route.DestinationString()] = route.Description()
+		hostRoutes[route.DestinationString()+" dev "+route.Interface] = route.Description()
+	}
+
+	topology := report.MakeTopology().
+		WithMetadataTemplates(MetadataTemplates).
+		WithTableTemplates(TableTemplates)
+	for _, iface := range interfaces {
+		if _, ok := byName[iface.Name]; !ok {
+			continue
+		}
+		lower := iface.Lower
+		if lower == "" && iface.Kind != Veth && iface.Link != iface.Index {
+			lower = byIndex[iface.Link].Name
+		}
+		latests := map[string]string{
+			report.HostNodeID: hostNodeID,
+			Index:             strconv.Itoa(iface.Index),
+			Name:              iface.Name,
+			Kind:              iface.Kind,
+			State:             iface.State,
+			MAC:               iface.MAC,
+			MTU:               strconv.Itoa(iface.MTU),
+			Master:            iface.Master,
+			Lower:             lower,
+			VLANID:            iface.VLANID,
+		}
+		for key, value := range latests {
+			if value == "" {
+				delete(latests, key)
+			}
+		}
+		node := report.MakeNodeWith(MakeInterfaceNodeID(r.hostID, iface.Name), latests).
+			WithTopology(report.NetworkInterface).
+			WithParents(parents)
+		if addrs, err := InterfaceAddrs(iface.Name); err == nil && len(addrs) > 0 {
+			node = node.WithSet(Addresses, report.MakeStringSet(addrs...))
+		}
+
+		// Edges go from interfaces to those they hang off: bridge ports to
+		// their bridge, stacked devices to their lower device, and the
+		// first of a pair of veths to the other.
+		for _, linked := range []string{iface.Master, lower} {
+			if _, ok := byName[linked]; ok {
+				node = node.WithAdjacent(MakeInterfaceNodeID(r.hostID, linked))
+			}
+		}
+		if peer, ok := byIndex[iface.Link]; ok && iface.Kind == Veth && peer.Link == iface.Index && iface.Index < peer.Index {
+			node = node.WithAdjacent(MakeInterfaceNodeID(r.hostID, peer.Name))
+		}
+
+		if rows, ok := routeRows[iface.Name]; ok {
+			node = node.AddTable(RoutePrefix, rows)
+		}
+		topology = topology.AddNode(node)
+	}
+	result.NetworkInterface = topology
+
+	if len(hostRoutes) > 0 {
+		result.Host = result.Host.WithTableTemplates(HostTableTemplates).AddNode(
+			report.MakeNode(hostNodeID).WithTopology(report.Host).AddTable(HostRoutePrefix, hostRoutes),
+		)
+	}
+	return result, nil
+}
+
+// Tag implements Tagger. Containers not in the host's network namespace see
+// their own interfaces in their sysfs, where the iflink of each is the index
+// of the interface of the host it hangs off: the peer of a veth, or the lower
+// device of a macvlan.
+func (r *Reporter) Tag(rpt report.Report) (report.Report, error) {
+	hostNetNS, err := os.Readlink(filepath.Join(r.procRoot, "self", "ns", "net"))
+	if err != nil {
+		return rpt, nil
+	}
+
+	indexes := map[int]string{}
+	for id, node := range rpt.NetworkInterface.Nodes {
+		if _, hostID, ok := ParseInterfaceNodeID(id); !ok || hostID != r.hostID {
+			continue
+		}
+		if index, ok := node.Latest.Lookup(Index); ok {
+			if index, err := strconv.Atoi(index); err == nil {
+				indexes[index] = id
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		return rpt, nil
+	}
+
+	// The lowest PID of each container, as any process of it will do.
+	pids := map[string]int{}
+	for _, node := range rpt.Process.Nodes {
+		containerID, ok := node.Latest.Lookup(docker.ContainerID)
+		if !ok {
+			continue
+		}
+		pidStr, ok := node.Latest.Lookup(process.PID)
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil {
+			continue
+		}
+		if lowest, ok := pids[containerID]; !ok || pid < lowest {
+			pids[containerID] = pid
+		}
+	}
+
+	for containerID, pid := range pids {
+		procDir := filepath.Join(r.procRoot, strconv.Itoa(pid))
+		if netNS, err := os.Readlink(filepath.Join(procDir, "ns", "net")); err != nil || netNS == hostNetNS {
+			continue
+		}
+		interfaces, err := ReadInterfaces(filepath.Join(procDir, "root", "sys", "class", "net"), "")
+		if err != nil {
+			continue
+		}
+		parents := report.EmptySets.Add(report.Container, report.MakeStringSet(report.MakeContainerNodeID(containerID)))
+		for _, iface := range interfaces {
+			nodeID, ok := indexes[iface.Link]
+			if !ok || iface.Link == iface.Index {
+				continue
+			}
+			rpt.NetworkInterface = rpt.NetworkInterface.AddNode(report.MakeNodeWith(nodeID, map[string]string{Peer: iface.Name}).WithParents(parents))
+		}
+	}
+	return rpt, nil
+}
@@ -0,0 +1,125 @@
+package netif_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/netif"
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/report"
+)
+
+func TestReporter(t *testing.T) {
+	root, err := ioutil.TempDir("", "netif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	var (
+		sysRoot  = filepath.Join(root, "sys")
+		procRoot = filepath.Join(root, "proc")
+	)
+	writeSysfs(t, sysRoot, hostInterfaces)
+	writeFiles(t, procRoot, map[string]string{
+		"net/route": `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+docker0	000011AC	00000000	0001	0	0	0	0000FFFF	0	0	0
+`,
+	})
+	symlink(t, "net:[4026531992]", filepath.Join(procRoot, "self", "ns", "net"))
+	// A container in a network namespace of its own, and one in the host's
+	symlink(t, "net:[4026532301]", filepath.Join(procRoot, "42", "ns", "net"))
+	writeSysfs(t, filepath.Join(procRoot, "42", "root", "sys"), map[string]fixtureInterface{
+		"lo":   {index: 1, link: 1},
+		"eth0": {index: 6, link: 7},
+	})
+	symlink(t, "net:[4026531992]", filepath.Join(procRoot, "43", "ns", "net"))
+	writeSysfs(t, filepath.Join(procRoot, "43", "root", "sys"), hostInterfaces)
+
+	oldInterfaceAddrs := netif.InterfaceAddrs
+	defer func() { netif.InterfaceAddrs = oldInterfaceAddrs }()
+	netif.InterfaceAddrs = func(name string) ([]string, error) {
+		if name == "eth0" {
+			return []string{"192.168.1.10/24"}, nil
+		}
+		return nil, nil
+	}
+
+	reporter := netif.NewReporter("host1", sysRoot, procRoot)
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := func(name string) string { return netif.MakeInterfaceNodeID("host1", name) }
+	if _, ok := rpt.NetworkInterface.Nodes[id("lo")]; ok {
+		t.Errorf("Expected the loopback to be left out")
+	}
+	for name, want := range map[string][]string{
+		"eth0":         nil,
+		"docker0":      nil,
+		"eth0.100":     {id("eth0")},
+		"vxlan.calico": {id("eth0")},
+		"veth1a2b3c":   {id("docker0")},
+		"tap0":         nil,
+	} {
+		node, ok := rpt.NetworkInterface.Nodes[id(name)]
+		if !ok {
+			t.Errorf("Expected interface %s, but not found", name)
+			continue
+		}
+		if have := []string(node.Adjacency); !reflect.DeepEqual(want, have) && len(want)+len(have) > 0 {
+			t.Errorf("Expected %s linked to %v, got %v", name, want, have)
+		}
+		if hostNodeID, _ := node.Parents.Lookup(report.Host); !hostNodeID.Contains(report.MakeHostNodeID("host1")) {
+			t.Errorf("Expected %s to have its host as parent, got %v", name, node.Parents)
+		}
+	}
+	eth0 := rpt.NetworkInterface.Nodes[id("eth0")]
+	if addrs, _ := eth0.Sets.Lookup(netif.Addresses); !reflect.DeepEqual(report.MakeStringSet("192.168.1.10/24"), addrs) {
+		t.Errorf("Expected the addresses of eth0, got %v", addrs)
+	}
+	if routes, _ := eth0.Latest.Lookup(netif.RoutePrefix + "default"); routes != "via 192.168.1.1, metric 100" {
+		t.Errorf("Expected the default route through eth0, got %q", routes)
+	}
+	if lower, _ := rpt.NetworkInterface.Nodes[id("vxlan.calico")].Latest.Lookup(netif.Lower); lower != "eth0" {
+		t.Errorf("Expected eth0 as the lower device of the VXLAN, got %q", lower)
+	}
+	host := rpt.Host.Nodes[report.MakeHostNodeID("host1")]
+	if route, _ := host.Latest.Lookup(netif.HostRoutePrefix + "172.17.0.0/16 dev docker0"); route != "direct, metric 0" {
+		t.Errorf("Expected the docker0 route in the host's table, got %q", route)
+	}
+
+	rpt.Process.AddNode(report.MakeNodeWith(report.MakeProcessNodeID("host1", "42"), map[string]string{
+		process.PID:        "42",
+		docker.ContainerID: "abc",
+	}))
+	rpt.Process.AddNode(report.MakeNodeWith(report.MakeProcessNodeID("host1", "43"), map[string]string{
+		process.PID:        "43",
+		docker.ContainerID: "def",
+	}))
+	rpt, err = reporter.Tag(rpt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, node := range rpt.NetworkInterface.Nodes {
+		containers, _ := node.Parents.Lookup(report.Container)
+		if name == id("veth1a2b3c") {
+			if !reflect.DeepEqual(report.MakeStringSet(report.MakeContainerNodeID("abc")), containers) {
+				t.Errorf("Expected the veth to have its container as parent, got %v", containers)
+			}
+			if peer, _ := node.Latest.Lookup(netif.Peer); peer != "eth0" {
+				t.Errorf("Expected the veth's peer to be eth0, got %q", peer)
+			}
+		} else if len(containers) > 0 {
+			t.Errorf("Expected %s to have no container, got %v", name, containers)
+		}
+	}
+	if err := rpt.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,160 @@
+package netif
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Flags of routes, from linux/route.h and linux/ipv6_route.h
+const (
+	rtfUp    = 0x1
+	rtfLocal = 0x80000000
+)
+
+// The addresses of /proc/net/route are in host byte order
+var nativeEndian binary.ByteOrder
+
+func init() {
+	i := uint16(1)
+	if *(*byte)(unsafe.Pointer(&i)) == 1 {
+		nativeEndian = binary.LittleEndian
+	} else {
+		nativeEndian = binary.BigEndian
+	}
+}
+
+// Route is a route of the routing table of the host.
+type Route struct {
+	Destination net.IPNet
+	Gateway     net.IP // nil for routes to directly connected networks
+	Interface   string
+	Metric      int
+}
+
+// DestinationString gives the destination of the route as ip route does,
+// e.g. "default" or "10.32.0.0/12".
+func (r Route) DestinationString() string {
+	if ones, _ := r.Destination.Mask.Size(); ones == 0 {
+		return "default"
+	}
+	return r.Destination.String()
+}
+
+// Description gives the gateway and metric of the route, e.g.
+// "via 10.0.0.1, metric 100".
+func (r Route) Description() string {
+	if r.Gateway == nil {
+		return fmt.Sprintf("direct, metric %d", r.Metric)
+	}
+	return fmt.Sprintf("via %s, metric %d", r.Gateway, r.Metric)
+}
+
+// ReadRoutes reads the IPv4 and IPv6 routes of the host, from procNet, e.g.
+// /proc/net. Routes which are down, local ones and those to link-local and
+// multicast addresses are left out.
+func ReadRoutes(procNet string) ([]Route, error) {
+	routes, err := readIPv4Routes(filepath.Join(procNet, "route"))
+	if err != nil {
+		return nil, err
+	}
+	// IPv6 may be disabled
+	if ipv6Routes, err := readIPv6Routes(filepath.Join(procNet, "ipv6_route")); err == nil {
+		routes = append(routes, ipv6Routes...)
+	}
+	return routes, nil
+}
+
+// readIPv4Routes parses /proc/net/route, e.g.
+// Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+// eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+func readIPv4Routes(filename string) ([]Route, error) {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	routes := []Route{}
+	for _, line := range strings.Split(string(buf), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		var (
+			dest, err1    = parseIPv4(fields[1])
+			gateway, err2 = parseIPv4(fields[2])
+			flags, err3   = strconv.ParseUint(fields[3], 16, 32)
+			metric, err4  = strconv.Atoi(fields[6])
+			mask, err5    = parseIPv4(fields[7])
+		)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || flags&rtfUp == 0 {
+			continue
+		}
+		route := Route{
+			Destination: net.IPNet{IP: dest, Mask: net.IPMask(mask)},
+			Interface:   fields[0],
+			Metric:      metric,
+		}
+		if !gateway.Equal(net.IPv4zero) {
+			route.Gateway = gateway
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// readIPv6Routes parses /proc/net/ipv6_route, e.g.
+// 20010db8000000000000000000000000 40 00000000000000000000000000000000 00 fe800000000000000000000000000001 00000400 00000001 00000000 00000003 eth0
+func readIPv6Routes(filename string) ([]Route, error) {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	routes := []Route{}
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[9] == "lo" {
+			continue
+		}
+		var (
+			dest, err1    = hex.DecodeString(fields[0])
+			ones, err2    = strconv.ParseUint(fields[1], 16, 8)
+			gateway, err3 = hex.DecodeString(fields[4])
+			metric, err4  = strconv.ParseUint(fields[5], 16, 32)
+			flags, err5   = strconv.ParseUint(fields[8], 16, 32)
+		)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil ||
+			len(dest) != net.IPv6len || len(gateway) != net.IPv6len ||
+			flags&rtfUp == 0 || flags&rtfLocal != 0 {
+			continue
+		}
+		if ip := net.IP(dest); ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+			continue
+		}
+		route := Route{
+			Destination: net.IPNet{IP: net.IP(dest), Mask: net.CIDRMask(int(ones), 128)},
+			Interface:   fields[9],
+			Metric:      int(metric),
+		}
+		if !net.IP(gateway).Equal(net.IPv6zero) {
+			route.Gateway = net.IP(gateway)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func parseIPv4(s string) (net.IP, error) {
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	ip := make(net.IP, net.IPv4len)
+	nativeEndian.PutUint32(ip, uint32(n))
+	return ip, nil
+}
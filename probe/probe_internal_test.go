@@ -85,10 +85,22 @@ func TestProbe(t *testing.T) {
 	want.Process.Controls = nil
 	want.Container.Controls = nil
 	want.ContainerImage.Controls = nil
+	want.DockerNetwork.Controls = nil
+	want.DockerVolume.Controls = nil
+	want.SwarmService.Controls = nil
+	want.SwarmTask.Controls = nil
+	want.SwarmNode.Controls = nil
+	want.ComposeProject.Controls = nil
+	want.ComposeService.Controls = nil
+	want.SystemdUnit.Controls = nil
+	want.NetworkInterface.Controls = nil
 	want.Pod.Controls = nil
 	want.Service.Controls = nil
 	want.Deployment.Controls = nil
 	want.ReplicaSet.Controls = nil
+	want.PersistentVolume.Controls = nil
+	want.PersistentVolumeClaim.Controls = nil
+	want.StorageClass.Controls = nil
 	want.Host.Controls = nil
 	want.Overlay.Controls = nil
 	want.Endpoint.AddNode(node)
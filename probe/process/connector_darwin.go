@@ -0,0 +1,16 @@
+package process
+
+import (
+	"fmt"
+)
+
+// Connector is only supported on Linux.
+type Connector struct{}
+
+// NewConnector fails, as there is no proc connector on Darwin.
+func NewConnector(_ string, _ *CachingWalker) (*Connector, error) {
+	return nil, fmt.Errorf("The proc connector is only supported on Linux")
+}
+
+// Stop does nothing.
+func (*Connector) Stop() {}
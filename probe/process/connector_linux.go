@@ -0,0 +1,214 @@
+package process
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path"
+	"strconv"
+	"syscall"
+	"time"
+	"unsafe"
+
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/mtime"
+)
+
+// From linux/connector.h and linux/cn_proc.h
+const (
+	netlinkConnector = 11
+	cnIdxProc        = 1
+	cnValProc        = 1
+
+	procCnMcastListen = 1
+	procCnMcastIgnore = 2
+
+	procEventFork = 0x00000001
+	procEventExec = 0x00000002
+	procEventExit = 0x80000000
+
+	cnMsgLen     = 20 // idx, val, seq, ack, len and flags
+	procEventLen = 16 // what, cpu and timestamp, before the event data
+)
+
+// How long reading events blocks for, so the connector notices being stopped.
+const connectorTimeout = time.Second
+
+var nativeEndian binary.ByteOrder
+
+func init() {
+	i := uint16(1)
+	if *(*byte)(unsafe.Pointer(&i)) == 1 {
+		nativeEndian = binary.LittleEndian
+	} else {
+		nativeEndian = binary.BigEndian
+	}
+}
+
+// procEvent is a process event sent by the kernel's proc connector. pid is
+// the ID of the process, and tid the one of its thread; on fork, of the
+// child.
+type procEvent struct {
+	what     uint32
+	pid, tid int
+}
+
+// Connector listens to the events the kernel's proc connector sends when
+// processes fork, exec and exit, so the CachingWalker gets to know about
+// processes which don't live long enough to be walked. It needs
+// CAP_NET_ADMIN.
+type Connector struct {
+	procRoot string
+	walker   *CachingWalker
+	fd       int
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+// NewConnector subscribes to the proc connector, and starts telling walker
+// about new processes.
+func NewConnector(procRoot string, walker *CachingWalker) (*Connector, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM, netlinkConnector)
+	if err != nil {
+		return nil, err
+	}
+	c := &Connector{
+		procRoot: procRoot,
+		walker:   walker,
+		fd:       fd,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	tv := syscall.NsecToTimeval(int64(connectorTimeout))
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: cnIdxProc}); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	if err := c.send(procCnMcastListen); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	go c.loop()
+	return c, nil
+}
+
+// Stop unsubscribes from the proc connector.
+func (c *Connector) Stop() {
+	close(c.quit)
+	<-c.done
+	if err := c.send(procCnMcastIgnore); err != nil {
+		log.Warnf("Process: error unsubscribing from the proc connector: %v", err)
+	}
+	syscall.Close(c.fd)
+}
+
+func (c *Connector) send(op uint32) error {
+	msg := make([]byte, syscall.NLMSG_HDRLEN+cnMsgLen+4)
+	nativeEndian.PutUint32(msg[0:], uint32(len(msg)))
+	nativeEndian.PutUint16(msg[4:], syscall.NLMSG_DONE)
+	cn := msg[syscall.NLMSG_HDRLEN:]
+	nativeEndian.PutUint32(cn[0:], cnIdxProc)
+	nativeEndian.PutUint32(cn[4:], cnValProc)
+	nativeEndian.PutUint16(cn[16:], 4)
+	nativeEndian.PutUint32(cn[cnMsgLen:], op)
+	return syscall.Sendto(c.fd, msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+}
+
+func (c *Connector) loop() {
+	defer close(c.done)
+	buf := make([]byte, syscall.Getpagesize())
+	for {
+		select {
+		case <-c.quit:
+			return
+		default:
+		}
+		n, _, err := syscall.Recvfrom(c.fd, buf, 0)
+		if err == syscall.EAGAIN || err == syscall.EINTR {
+			continue
+		} else if err == syscall.ENOBUFS {
+			// We fell behind, and the kernel dropped events; the next walk
+			// will catch up with the processes still there.
+			log.Warnf("Process: proc connector events lost")
+			continue
+		} else if err != nil {
+			log.Errorf("Process: error reading the proc connector: %v", err)
+			return
+		}
+		events, err := parseProcEvents(buf[:n])
+		if err != nil {
+			log.Errorf("Process: %v", err)
+			continue
+		}
+		for _, e := range events {
+			c.handle(e)
+		}
+	}
+}
+
+func (c *Connector) handle(e procEvent) {
+	// Threads are no new processes
+	if e.pid != e.tid {
+		return
+	}
+	// The cached command line is the one of the program before, or of the
+	// process which had the PID before.
+	fileCache.Del([]byte(path.Join(c.procRoot, strconv.Itoa(e.pid), "cmdline")))
+	if e.what == procEventExit {
+		return
+	}
+	bootTime, _ := readBootTime(path.Join(c.procRoot, "stat"))
+	p, err := readProcess(c.procRoot, e.pid, bootTime, mtime.Now())
+	if err != nil {
+		// Gone already
+		return
+	}
+	c.walker.Observe(p)
+}
+
+// parseProcEvents parses the fork, exec and exit events out of a netlink
+// message from the proc connector.
+func parseProcEvents(buf []byte) ([]procEvent, error) {
+	msgs, err := syscall.ParseNetlinkMessage(buf)
+	if err != nil {
+		return nil, err
+	}
+	events := []procEvent{}
+	for _, msg := range msgs {
+		if msg.Header.Type != syscall.NLMSG_DONE {
+			continue
+		}
+		if len(msg.Data) < cnMsgLen+procEventLen {
+			return nil, fmt.Errorf("Invalid proc connector message")
+		}
+		data := msg.Data[cnMsgLen:]
+		what, event := nativeEndian.Uint32(data), data[procEventLen:]
+		switch what {
+		case procEventFork:
+			// parent PID and TGID, then child PID and TGID
+			if len(event) < 16 {
+				return nil, fmt.Errorf("Invalid fork event")
+			}
+			events = append(events, procEvent{
+				what: what,
+				tid:  int(nativeEndian.Uint32(event[8:])),
+				pid:  int(nativeEndian.Uint32(event[12:])),
+			})
+		case procEventExec, procEventExit:
+			// PID and TGID first
+			if len(event) < 8 {
+				return nil, fmt.Errorf("Invalid exec or exit event")
+			}
+			events = append(events, procEvent{
+				what: what,
+				tid:  int(nativeEndian.Uint32(event[0:])),
+				pid:  int(nativeEndian.Uint32(event[4:])),
+			})
+		}
+	}
+	return events, nil
+}
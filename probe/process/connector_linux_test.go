@@ -0,0 +1,47 @@
+package process
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func procConnectorMessage(what uint32, ids ...uint32) []byte {
+	msg := make([]byte, syscall.NLMSG_HDRLEN+cnMsgLen+procEventLen+4*len(ids))
+	nativeEndian.PutUint32(msg[0:], uint32(len(msg)))
+	nativeEndian.PutUint16(msg[4:], syscall.NLMSG_DONE)
+	event := msg[syscall.NLMSG_HDRLEN+cnMsgLen:]
+	nativeEndian.PutUint32(event[0:], what)
+	for i, id := range ids {
+		nativeEndian.PutUint32(event[procEventLen+4*i:], id)
+	}
+	return msg
+}
+
+func TestParseProcEvents(t *testing.T) {
+	buf := []byte{}
+	buf = append(buf, procConnectorMessage(procEventFork, 1, 1, 30, 30)...)
+	buf = append(buf, procConnectorMessage(procEventFork, 30, 30, 31, 30)...)
+	buf = append(buf, procConnectorMessage(procEventExec, 30, 30)...)
+	buf = append(buf, procConnectorMessage(procEventExit, 30, 30, 0, 17)...)
+	// UID changes, and other events, are ignored
+	buf = append(buf, procConnectorMessage(0x00000004, 30, 30, 0, 0)...)
+
+	events, err := parseProcEvents(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []procEvent{
+		{what: procEventFork, pid: 30, tid: 30},
+		{what: procEventFork, pid: 30, tid: 31},
+		{what: procEventExec, pid: 30, tid: 30},
+		{what: procEventExit, pid: 30, tid: 30},
+	}
+	if !reflect.DeepEqual(want, events) {
+		t.Errorf("Expected %v, got %v", want, events)
+	}
+
+	if _, err := parseProcEvents(procConnectorMessage(procEventExec)); err == nil {
+		t.Errorf("Expected a truncated event to fail")
+	}
+}
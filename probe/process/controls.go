@@ -0,0 +1,255 @@
+package process
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/fs"
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/report"
+)
+
+// Control IDs used by the process reporter.
+const (
+	TerminateProcess = "process_terminate"
+	KillProcess      = "process_kill"
+	SignalProcess    = "process_signal"
+	ReniceProcess    = "process_renice"
+	DumpStacks       = "process_dump_stacks"
+)
+
+// Control arguments understood by the process controls.
+const (
+	// SignalArg is the signal to send, e.g. "HUP"
+	SignalArg = "signal"
+	// NiceArg is the niceness to give the process, from -20 to 19
+	NiceArg = "nice"
+	// StartTimeArg is the start time of the process the control is meant
+	// for, as reported, to make sure its PID has not been reused.
+	StartTimeArg = "start_time"
+)
+
+// How long the output of a JVM is captured for after asking it for a
+// thread dump, and how often its output is polled meanwhile.
+const (
+	dumpStacksTime     = 3 * time.Second
+	dumpStacksInterval = 100 * time.Millisecond
+)
+
+// The signals which can be sent to processes, by name
+var signals = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"KILL": syscall.SIGKILL,
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// Controls are the controls of process nodes. Exposed for testing.
+var Controls = []report.Control{
+	{
+		ID:    TerminateProcess,
+		Human: "Terminate",
+		Icon:  "fa-stop",
+		Rank:  1,
+	},
+	{
+		ID:    KillProcess,
+		Human: "Kill",
+		Icon:  "fa-times",
+		Rank:  2,
+	},
+	{
+		ID:    SignalProcess,
+		Human: "Send signal",
+		Icon:  "fa-bolt",
+		Rank:  3,
+	},
+	{
+		ID:    ReniceProcess,
+		Human: "Renice",
+		Icon:  "fa-sort-amount-desc",
+		Rank:  4,
+	},
+	{
+		ID:    DumpStacks,
+		Human: "Dump stacks",
+		Icon:  "fa-file-text-o",
+		Rank:  5,
+	},
+}
+
+// processControls returns the controls of a process. Only JVMs know how to
+// dump their stacks.
+func processControls(p Process) []string {
+	ids := []string{TerminateProcess, KillProcess, SignalProcess, ReniceProcess}
+	if path.Base(p.Name) == "java" {
+		ids = append(ids, DumpStacks)
+	}
+	return ids
+}
+
+// target returns the PID of the process a control is meant for, once sure
+// it is still the process that was reported, and not a later one which
+// reused its PID: requests carry the start time of the node they were made
+// from, which has to be that of the process now.
+func (r *Reporter) target(req xfer.Request) (int, error) {
+	scope, pidstr, ok := report.ParseNodeID(req.NodeID)
+	if !ok || scope != r.scope {
+		return 0, fmt.Errorf("Invalid ID: %s", req.NodeID)
+	}
+	pid, err := strconv.Atoi(pidstr)
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("Invalid ID: %s", req.NodeID)
+	}
+	s, ok := req.ControlArgs[StartTimeArg]
+	if !ok || s == "" {
+		return 0, fmt.Errorf("Missing %s", StartTimeArg)
+	}
+	started, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s: %q", StartTimeArg, s)
+	}
+
+	current, err := readIdentity(r.procRoot, pid)
+	if err != nil {
+		return 0, fmt.Errorf("Process %d not found: %v", pid, err)
+	}
+	if current.Started.IsZero() {
+		return 0, fmt.Errorf("Cannot tell when process %d started", pid)
+	}
+	// Start times are reported to the second
+	if !started.Truncate(time.Second).Equal(current.Started.Truncate(time.Second)) {
+		return 0, fmt.Errorf("Process %d has exited, and its PID has been reused", pid)
+	}
+	return pid, nil
+}
+
+func (r *Reporter) signalControl(signal string) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		if signal != "" {
+			return r.signal(req, signal)
+		}
+		return r.signal(req, strings.ToUpper(strings.TrimPrefix(req.ControlArgs[SignalArg], "SIG")))
+	}
+}
+
+func (r *Reporter) signal(req xfer.Request, name string) xfer.Response {
+	signal, ok := signals[name]
+	if !ok {
+		return xfer.ResponseErrorf("Invalid %s: %q", SignalArg, name)
+	}
+	pid, err := r.target(req)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	log.Infof("Sending SIG%s to process %d", name, pid)
+	return xfer.ResponseError(syscall.Kill(pid, signal))
+}
+
+func (r *Reporter) renice(req xfer.Request) xfer.Response {
+	nice, err := strconv.Atoi(req.ControlArgs[NiceArg])
+	if err != nil || nice < -20 || nice > 19 {
+		return xfer.ResponseErrorf("Invalid %s: %q", NiceArg, req.ControlArgs[NiceArg])
+	}
+	pid, err := r.target(req)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	log.Infof("Renicing process %d to %d", pid, nice)
+	return xfer.ResponseError(setNice(r.procRoot, pid, nice))
+}
+
+// dumpStacks asks a JVM for a thread dump, which it prints on its standard
+// output. When that goes to a file, what gets appended to it in the next
+// few seconds is streamed to a pipe.
+func (r *Reporter) dumpStacks(req xfer.Request) xfer.Response {
+	pid, err := r.target(req)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	stdout := path.Join(r.procRoot, strconv.Itoa(pid), "fd", "1")
+	var stat syscall.Stat_t
+	if err := fs.Stat(stdout, &stat); err != nil {
+		return xfer.ResponseError(err)
+	}
+	if stat.Mode&syscall.S_IFMT != syscall.S_IFREG {
+		return xfer.ResponseErrorf("Cannot capture the stacks of process %d: its output does not go to a file", pid)
+	}
+	f, err := fs.Open(stdout)
+	if err != nil {
+		return xfer.ResponseError(err)
+	}
+	if seeker, ok := f.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return xfer.ResponseError(err)
+		}
+	}
+
+	id, pipe, err := controls.NewPipe(r.pipes, req.AppID)
+	if err != nil {
+		f.Close()
+		return xfer.ResponseError(err)
+	}
+	log.Infof("Dumping the stacks of process %d", pid)
+	if err := syscall.Kill(pid, syscall.SIGQUIT); err != nil {
+		f.Close()
+		pipe.Close()
+		return xfer.ResponseError(err)
+	}
+	local, _ := pipe.Ends()
+	go func() {
+		defer pipe.Close()
+		defer f.Close()
+		deadline := time.After(dumpStacksTime)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				if _, err := local.Write(buf[:n]); err != nil {
+					return
+				}
+				continue
+			}
+			if err != nil && err != io.EOF {
+				log.Errorf("Error reading the output of process %d: %v", pid, err)
+				return
+			}
+			select {
+			case <-deadline:
+				return
+			case <-time.After(dumpStacksInterval):
+			}
+		}
+	}()
+	return xfer.Response{
+		Pipe: id,
+	}
+}
+
+func (r *Reporter) registerControls() {
+	controls.Register(TerminateProcess, r.signalControl("TERM"))
+	controls.Register(KillProcess, r.signalControl("KILL"))
+	controls.Register(SignalProcess, r.signalControl(""))
+	controls.Register(ReniceProcess, r.renice)
+	controls.Register(DumpStacks, r.dumpStacks)
+}
+
+func (r *Reporter) deregisterControls() {
+	controls.Rm(TerminateProcess)
+	controls.Rm(KillProcess)
+	controls.Rm(SignalProcess)
+	controls.Rm(ReniceProcess)
+	controls.Rm(DumpStacks)
+}
@@ -0,0 +1,128 @@
+package process_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/report"
+)
+
+// The controls are tried on the test process itself, as found in /proc.
+func TestControls(t *testing.T) {
+	cmdline, err := ioutil.ReadFile("/proc/self/cmdline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := os.Getpid()
+	walker := &mockWalker{processes: []process.Process{
+		{PID: pid, Name: "process.test", Cmdline: strings.Replace(string(cmdline), "\000", " ", -1), Started: startTime(t)},
+	}}
+	reporter := process.NewReporter(walker, "host1", "probe1", "/proc", func() (uint64, float64, error) { return 0, 0., nil }, nil)
+	defer reporter.Stop()
+
+	nodeID := report.MakeProcessNodeID("host1", strconv.Itoa(pid))
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := rpt.Process.Nodes[nodeID]
+	if have, ok := node.Latest.Lookup(report.ControlProbeID); !ok || have != "probe1" {
+		t.Errorf("Expected control probe ID %q, got %q", "probe1", have)
+	}
+	if have := node.Controls.Controls; !have.Contains(process.KillProcess) || have.Contains(process.DumpStacks) {
+		t.Errorf("Expected the controls of a process other than a JVM, got %v", have)
+	}
+	started, ok := node.Latest.Lookup(process.StartTime)
+	if !ok {
+		t.Fatal("Expected the start time of the process")
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+	response := controls.HandleControlRequest(xfer.Request{
+		NodeID:      nodeID,
+		Control:     process.SignalProcess,
+		ControlArgs: map[string]string{process.SignalArg: "usr1", process.StartTimeArg: started},
+	})
+	if response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	select {
+	case <-signals:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected SIGUSR1")
+	}
+
+	for _, req := range []xfer.Request{
+		// A process of the same PID, started at another time
+		{NodeID: nodeID, Control: process.SignalProcess, ControlArgs: map[string]string{
+			process.SignalArg:    "USR1",
+			process.StartTimeArg: "2001-01-01T00:00:00Z",
+		}},
+		// Requests without a start time
+		{NodeID: nodeID, Control: process.SignalProcess, ControlArgs: map[string]string{process.SignalArg: "USR1"}},
+		{NodeID: nodeID, Control: process.KillProcess},
+		{NodeID: nodeID, Control: process.SignalProcess, ControlArgs: map[string]string{process.SignalArg: "FOO", process.StartTimeArg: started}},
+		{NodeID: report.MakeProcessNodeID("host2", strconv.Itoa(pid)), Control: process.TerminateProcess, ControlArgs: map[string]string{process.StartTimeArg: started}},
+		{NodeID: nodeID, Control: process.ReniceProcess, ControlArgs: map[string]string{process.NiceArg: "20", process.StartTimeArg: started}},
+	} {
+		if response := controls.HandleControlRequest(req); response.Error == "" {
+			t.Errorf("Expected %v to fail", req.ControlArgs)
+		}
+	}
+	select {
+	case <-signals:
+		t.Fatal("Expected no more signals")
+	default:
+	}
+
+	// Renicing to the current niceness is always allowed
+	nice := selfStat(t)[16]
+	response = controls.HandleControlRequest(xfer.Request{
+		NodeID:      nodeID,
+		Control:     process.ReniceProcess,
+		ControlArgs: map[string]string{process.NiceArg: nice, process.StartTimeArg: started},
+	})
+	if response.Error != "" {
+		t.Error(response.Error)
+	}
+}
+
+// selfStat returns the fields of /proc/self/stat after the command.
+func selfStat(t *testing.T) []string {
+	stat, err := ioutil.ReadFile("/proc/self/stat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+}
+
+// startTime returns when the test process started, from its start time in
+// clock ticks after boot.
+func startTime(t *testing.T) time.Time {
+	stat, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bootTime int64
+	for _, line := range strings.Split(string(stat), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			bootTime, _ = strconv.ParseInt(strings.TrimPrefix(line, "btime "), 10, 64)
+		}
+	}
+	ticks, err := strconv.ParseInt(selfStat(t)[19], 10, 64)
+	if err != nil || bootTime == 0 {
+		t.Fatalf("Cannot tell when the test process started: %v", err)
+	}
+	return time.Unix(bootTime, 0).Add(time.Duration(ticks) * time.Second / 100)
+}
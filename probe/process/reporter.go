@@ -2,8 +2,10 @@ package process
 
 import (
 	"strconv"
+	"time"
 
 	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/probe/controls"
 	"$GITHUB_URI/report"
 )
 
@@ -17,41 +19,82 @@ const (
 	CPUUsage       = "process_cpu_usage_percent"
 	MemoryUsage    = "process_memory_usage_bytes"
 	OpenFilesCount = "open_files_count"
+	UID            = "uid"
+	GID            = "gid"
+	Exe            = "exe"
+	Cwd            = "cwd"
+	StartTime      = "start_time"
+	Cgroup         = "cgroup"
+	IOReadRate     = "process_io_read_rate"
+	IOWriteRate    = "process_io_write_rate"
+
+	VoluntaryCtxtSwitches    = "voluntary_ctxt_switches"
+	NonvoluntaryCtxtSwitches = "nonvoluntary_ctxt_switches"
+
+	// The usage of a process and its descendants, as shown in the process tree
+	SubtreeCPUUsage    = "process_subtree_cpu_usage_percent"
+	SubtreeMemoryUsage = "process_subtree_memory_usage_bytes"
 )
 
 // Exposed for testing
 var (
 	MetadataTemplates = report.MetadataTemplates{
-		PID:     {ID: PID, Label: "PID", From: report.FromLatest, Datatype: "number", Priority: 1},
-		Cmdline: {ID: Cmdline, Label: "Command", From: report.FromLatest, Priority: 2},
-		PPID:    {ID: PPID, Label: "Parent PID", From: report.FromLatest, Priority: 3},
-		Threads: {ID: Threads, Label: "# Threads", From: report.FromLatest, Priority: 4},
+		PID:       {ID: PID, Label: "PID", From: report.FromLatest, Datatype: "number", Priority: 1},
+		Cmdline:   {ID: Cmdline, Label: "Command", From: report.FromLatest, Priority: 2},
+		PPID:      {ID: PPID, Label: "Parent PID", From: report.FromLatest, Priority: 3},
+		Threads:   {ID: Threads, Label: "# Threads", From: report.FromLatest, Priority: 4},
+		Exe:       {ID: Exe, Label: "Executable", From: report.FromLatest, Priority: 5},
+		Cwd:       {ID: Cwd, Label: "Working directory", From: report.FromLatest, Priority: 6},
+		UID:       {ID: UID, Label: "User ID", From: report.FromLatest, Datatype: "number", Priority: 7},
+		GID:       {ID: GID, Label: "Group ID", From: report.FromLatest, Datatype: "number", Priority: 8},
+		StartTime: {ID: StartTime, Label: "Started", From: report.FromLatest, Priority: 9},
+		Cgroup:    {ID: Cgroup, Label: "Cgroup", From: report.FromLatest, Priority: 10},
+
+		VoluntaryCtxtSwitches:    {ID: VoluntaryCtxtSwitches, Label: "Voluntary context switches", From: report.FromLatest, Datatype: "number", Priority: 11},
+		NonvoluntaryCtxtSwitches: {ID: NonvoluntaryCtxtSwitches, Label: "Involuntary context switches", From: report.FromLatest, Datatype: "number", Priority: 12},
 	}
 
 	MetricTemplates = report.MetricTemplates{
-		CPUUsage:       {ID: CPUUsage, Label: "CPU", Format: report.PercentFormat, Priority: 1},
-		MemoryUsage:    {ID: MemoryUsage, Label: "Memory", Format: report.FilesizeFormat, Priority: 2},
-		OpenFilesCount: {ID: OpenFilesCount, Label: "Open Files", Format: report.IntegerFormat, Priority: 3},
+		CPUUsage:           {ID: CPUUsage, Label: "CPU", Format: report.PercentFormat, Priority: 1},
+		MemoryUsage:        {ID: MemoryUsage, Label: "Memory", Format: report.FilesizeFormat, Priority: 2},
+		OpenFilesCount:     {ID: OpenFilesCount, Label: "Open Files", Format: report.IntegerFormat, Priority: 3},
+		SubtreeCPUUsage:    {ID: SubtreeCPUUsage, Label: "CPU (with children)", Format: report.PercentFormat, Priority: 4},
+		SubtreeMemoryUsage: {ID: SubtreeMemoryUsage, Label: "Memory (with children)", Format: report.FilesizeFormat, Priority: 5},
+		IOReadRate:         {ID: IOReadRate, Label: "Disk read/s", Format: report.FilesizeFormat, Priority: 6},
+		IOWriteRate:        {ID: IOWriteRate, Label: "Disk write/s", Format: report.FilesizeFormat, Priority: 7},
 	}
 )
 
 // Reporter generates Reports containing the Process topology.
 type Reporter struct {
-	scope   string
-	walker  Walker
-	jiffies Jiffies
+	scope    string
+	probeID  string
+	procRoot string
+	walker   Walker
+	jiffies  Jiffies
+	pipes    controls.PipeClient
 }
 
 // Jiffies is the type for the function used to fetch the elapsed jiffies.
 type Jiffies func() (uint64, float64, error)
 
 // NewReporter makes a new Reporter.
-func NewReporter(walker Walker, scope string, jiffies Jiffies) *Reporter {
-	return &Reporter{
-		scope:   scope,
-		walker:  walker,
-		jiffies: jiffies,
+func NewReporter(walker Walker, scope, probeID, procRoot string, jiffies Jiffies, pipes controls.PipeClient) *Reporter {
+	r := &Reporter{
+		scope:    scope,
+		probeID:  probeID,
+		procRoot: procRoot,
+		walker:   walker,
+		jiffies:  jiffies,
+		pipes:    pipes,
 	}
+	r.registerControls()
+	return r
+}
+
+// Stop deregisters the controls of the reporter.
+func (r *Reporter) Stop() {
+	r.deregisterControls()
 }
 
 // Name of this reporter, for metrics gathering
@@ -72,6 +115,7 @@ func (r *Reporter) processTopology() (report.Topology, error) {
 	t := report.MakeTopology().
 		WithMetadataTemplates(MetadataTemplates).
 		WithMetricTemplates(MetricTemplates)
+	t.Controls.AddControls(Controls)
 	now := mtime.Now()
 	deltaTotal, maxCPU, err := r.jiffies()
 	if err != nil {
@@ -81,12 +125,17 @@ func (r *Reporter) processTopology() (report.Topology, error) {
 	err = r.walker.Walk(func(p, prev Process) {
 		pidstr := strconv.Itoa(p.PID)
 		nodeID := report.MakeProcessNodeID(r.scope, pidstr)
-		node := report.MakeNode(nodeID)
+		node := report.MakeNodeWith(nodeID, map[string]string{
+			report.ControlProbeID: r.probeID,
+		}).WithControls(processControls(p)...)
 		for _, tuple := range []struct{ key, value string }{
 			{PID, pidstr},
 			{Name, p.Name},
 			{Cmdline, p.Cmdline},
 			{Threads, strconv.Itoa(p.Threads)},
+			{Exe, p.Exe},
+			{Cwd, p.Cwd},
+			{Cgroup, p.Cgroup},
 		} {
 			if tuple.value != "" {
 				node = node.WithLatests(map[string]string{tuple.key: tuple.value})
@@ -97,6 +146,19 @@ func (r *Reporter) processTopology() (report.Topology, error) {
 			node = node.WithLatests(map[string]string{PPID: strconv.Itoa(p.PPID)})
 		}
 
+		if p.HasIDs {
+			node = node.WithLatests(map[string]string{
+				UID:                      strconv.Itoa(p.UID),
+				GID:                      strconv.Itoa(p.GID),
+				VoluntaryCtxtSwitches:    strconv.FormatUint(p.VoluntaryCtxtSwitches, 10),
+				NonvoluntaryCtxtSwitches: strconv.FormatUint(p.NonvoluntaryCtxtSwitches, 10),
+			})
+		}
+
+		if !p.Started.IsZero() {
+			node = node.WithLatests(map[string]string{StartTime: p.Started.UTC().Format(time.RFC3339)})
+		}
+
 		if deltaTotal > 0 {
 			cpuUsage := float64(p.Jiffies-prev.Jiffies) / float64(deltaTotal) * 100.
 			node = node.WithMetric(CPUUsage, report.MakeMetric().Add(now, cpuUsage).WithMax(maxCPU))
@@ -105,8 +167,21 @@ func (r *Reporter) processTopology() (report.Topology, error) {
 		node = node.WithMetric(MemoryUsage, report.MakeMetric().Add(now, float64(p.RSSBytes)).WithMax(float64(p.RSSBytesLimit)))
 		node = node.WithMetric(OpenFilesCount, report.MakeMetric().Add(now, float64(p.OpenFilesCount)).WithMax(float64(p.OpenFilesLimit)))
 
+		if seconds := p.Sampled.Sub(prev.Sampled).Seconds(); !prev.Sampled.IsZero() && seconds > 0 {
+			node = node.WithMetric(IOReadRate, report.MakeMetric().Add(now, ioRate(p.ReadBytes, prev.ReadBytes, seconds)))
+			node = node.WithMetric(IOWriteRate, report.MakeMetric().Add(now, ioRate(p.WriteBytes, prev.WriteBytes, seconds)))
+		}
+
 		t.AddNode(node)
 	})
 
 	return t, err
 }
+
+func ioRate(current, last uint64, seconds float64) float64 {
+	// A reused PID can make the counters go backwards
+	if current < last {
+		return 0
+	}
+	return float64(current-last) / seconds
+}
@@ -35,7 +35,7 @@ func TestReporter(t *testing.T) {
 	mtime.NowForce(now)
 	defer mtime.NowReset()
 
-	rpt, err := process.NewReporter(walker, "", getDeltaTotalJiffies).Report()
+	rpt, err := process.NewReporter(walker, "", "", "", getDeltaTotalJiffies, nil).Report()
 	if err != nil {
 		t.Error(err)
 	}
@@ -49,6 +49,11 @@ func TestReporter(t *testing.T) {
 		t.Errorf("Expected %q got %q", processes[0].Name, name)
 	}
 
+	// Processes whose user isn't known aren't reported as root's
+	if uid, ok := node.Latest.Lookup(process.UID); ok {
+		t.Errorf("Expected no user ID, got %q", uid)
+	}
+
 	// It reports plain processes (with parent pid, and metrics)
 	node, ok = rpt.Process.Nodes[report.MakeProcessNodeID("", "2")]
 	if !ok {
@@ -98,3 +103,58 @@ func TestReporter(t *testing.T) {
 		t.Errorf("Expected %q got %q", processes[4].Cmdline, cmdline)
 	}
 }
+
+type mockPrevWalker struct {
+	process, prev process.Process
+}
+
+func (m mockPrevWalker) Walk(f func(process.Process, process.Process)) error {
+	f(m.process, m.prev)
+	return nil
+}
+
+func TestReporterLinuxDetails(t *testing.T) {
+	now := time.Now()
+	mtime.NowForce(now)
+	defer mtime.NowReset()
+
+	started := time.Date(2017, time.July, 14, 2, 40, 0, 0, time.UTC)
+	walker := mockPrevWalker{
+		process: process.Process{
+			PID: 1, Name: "postgres", HasIDs: true, UID: 70, GID: 70, Exe: "/usr/bin/postgres", Started: started,
+			Cgroup: "/system.slice/postgresql.service", ReadBytes: 3000, WriteBytes: 1000, Sampled: now,
+		},
+		prev: process.Process{PID: 1, ReadBytes: 1000, WriteBytes: 2000, Sampled: now.Add(-2 * time.Second)},
+	}
+	jiffies := func() (uint64, float64, error) { return 0, 0., nil }
+
+	rpt, err := process.NewReporter(walker, "", "", "", jiffies, nil).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := rpt.Process.Nodes[report.MakeProcessNodeID("", "1")]
+	for key, want := range map[string]string{
+		process.UID:       "70",
+		process.Exe:       "/usr/bin/postgres",
+		process.StartTime: "2017-07-14T02:40:00Z",
+		process.Cgroup:    "/system.slice/postgresql.service",
+	} {
+		if have, ok := node.Latest.Lookup(key); !ok || have != want {
+			t.Errorf("Expected %s %q, got %q", key, want, have)
+		}
+	}
+	if _, ok := node.Latest.Lookup(process.Cwd); ok {
+		t.Errorf("Expected no working directory")
+	}
+
+	// The write counter went backwards, as if the PID had been reused
+	for key, want := range map[string]float64{
+		process.IOReadRate:  1000,
+		process.IOWriteRate: 0,
+	} {
+		metric, ok := node.Metrics.Lookup(key)
+		if !ok || metric.LastSample() == nil || metric.LastSample().Value != want {
+			t.Errorf("Expected %s of %v, got %v", key, want, metric.LastSample())
+		}
+	}
+}
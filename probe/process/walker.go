@@ -1,6 +1,9 @@
 package process
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // Process represents a single process.
 type Process struct {
@@ -13,6 +16,18 @@ type Process struct {
 	RSSBytesLimit  uint64
 	OpenFilesCount int
 	OpenFilesLimit uint64
+
+	// Only known on Linux. Exe and Cwd are only readable by the owner of the
+	// process or root, and so are the I/O counters.
+	HasIDs                   bool // whether UID and GID are known
+	UID, GID                 int
+	Exe, Cwd                 string
+	Started                  time.Time
+	Cgroup                   string // of the systemd hierarchy, or the unified one
+	VoluntaryCtxtSwitches    uint64
+	NonvoluntaryCtxtSwitches uint64
+	ReadBytes, WriteBytes    uint64
+	Sampled                  time.Time // when the counters were read
 }
 
 // Walker is something that walks the /proc directory
@@ -22,9 +37,16 @@ type Walker interface {
 
 // CachingWalker is a walker than caches a copy of the output from another
 // Walker, and then allows other concurrent readers to Walk that copy.
+//
+// Processes which have exited are kept for one more walk, so that the
+// connections they made can still be attributed to them. So are processes
+// which came and went between walks, if something told the walker about
+// them with Observe.
 type CachingWalker struct {
 	cache         map[int]Process
 	previousByPID map[int]Process
+	exited        map[int]struct{} // processes of the cache kept after they exited
+	observed      map[int]Process  // processes seen since the last walk
 	cacheLock     sync.RWMutex
 	source        Walker
 }
@@ -45,9 +67,25 @@ func (c *CachingWalker) Walk(f func(Process, Process)) error {
 	for _, p := range c.cache {
 		f(p, c.previousByPID[p.PID])
 	}
+	for pid, p := range c.observed {
+		if _, ok := c.cache[pid]; !ok {
+			f(p, Process{})
+		}
+	}
 	return nil
 }
 
+// Observe tells the walker about a process which started since the last
+// walk, in case it exits before the next one.
+func (c *CachingWalker) Observe(p Process) {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	if c.observed == nil {
+		c.observed = map[int]Process{}
+	}
+	c.observed[p.PID] = p
+}
+
 // Tick updates cached copy of process list
 func (c *CachingWalker) Tick() error {
 	newCache := map[int]Process{}
@@ -60,7 +98,26 @@ func (c *CachingWalker) Tick() error {
 
 	c.cacheLock.Lock()
 	defer c.cacheLock.Unlock()
+	exited := map[int]struct{}{}
+	for pid, p := range c.cache {
+		if _, ok := newCache[pid]; ok {
+			continue
+		}
+		if _, ok := c.exited[pid]; ok {
+			continue
+		}
+		newCache[pid] = p
+		exited[pid] = struct{}{}
+	}
+	for pid, p := range c.observed {
+		if _, ok := newCache[pid]; !ok {
+			newCache[pid] = p
+			exited[pid] = struct{}{}
+		}
+	}
 	c.previousByPID = c.cache
 	c.cache = newCache
+	c.exited = exited
+	c.observed = nil
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"syscall"
 )
 
 // NewWalker returns a Darwin (lsof-based) walker.
@@ -97,3 +98,12 @@ func parseLSOF(output string) (map[string]Process, error) {
 func GetDeltaTotalJiffies() (uint64, float64, error) {
 	return 0, 0.0, nil
 }
+
+// readIdentity is only supported on Linux, where start times can be read.
+func readIdentity(_ string, pid int) (Process, error) {
+	return Process{}, fmt.Errorf("Cannot check the identity of process %d on this platform", pid)
+}
+
+func setNice(_ string, pid, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, nice)
+}
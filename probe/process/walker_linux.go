@@ -7,10 +7,13 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	linuxproc "github.com/c9s/goprocinfo/linux"
 
 	"$GITHUB_URI/common/fs"
+	"$GITHUB_URI/common/mtime"
 	"$GITHUB_URI/probe/host"
 )
 
@@ -23,7 +26,10 @@ func NewWalker(procRoot string) Walker {
 	return &walker{procRoot: procRoot}
 }
 
-func readStats(path string) (ppid, threads int, jiffies, rss, rssLimit uint64, err error) {
+// userHZ is the frequency of the clock ticks times are given in by /proc.
+const userHZ = 100
+
+func readStats(path string) (ppid, threads int, jiffies, rss, rssLimit, startTicks uint64, err error) {
 	var (
 		buf                               []byte
 		userJiffies, sysJiffies, rssPages uint64
@@ -54,6 +60,10 @@ func readStats(path string) (ppid, threads int, jiffies, rss, rssLimit uint64, e
 		return
 	}
 	jiffies = userJiffies + sysJiffies
+	startTicks, err = strconv.ParseUint(splits[21], 10, 64)
+	if err != nil {
+		return
+	}
 	rssPages, err = strconv.ParseUint(splits[23], 10, 64)
 	if err != nil {
 		return
@@ -81,6 +91,107 @@ func readLimits(path string) (openFilesLimit uint64, err error) {
 	return 0, nil
 }
 
+func readStatus(path string) (uid, gid int, voluntary, nonvoluntary uint64, err error) {
+	buf, err := fs.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// The IDs are real, effective, saved and filesystem; ps shows the
+		// effective one
+		switch fields[0] {
+		case "Uid:", "Gid:":
+			if len(fields) < 3 {
+				return 0, 0, 0, 0, fmt.Errorf("Invalid /proc/PID/status")
+			}
+			id, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return 0, 0, 0, 0, err
+			}
+			if fields[0] == "Uid:" {
+				uid = id
+			} else {
+				gid = id
+			}
+		case "voluntary_ctxt_switches:":
+			voluntary, err = strconv.ParseUint(fields[1], 10, 64)
+		case "nonvoluntary_ctxt_switches:":
+			nonvoluntary, err = strconv.ParseUint(fields[1], 10, 64)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// readIO reads the bytes a process had read from and written to storage.
+func readIO(path string) (read, write uint64, err error) {
+	buf, err := fs.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "read_bytes:":
+			read, err = strconv.ParseUint(fields[1], 10, 64)
+		case "write_bytes:":
+			write, err = strconv.ParseUint(fields[1], 10, 64)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return read, write, nil
+}
+
+// parseCgroup picks the cgroup of a process in the hierarchy systemd
+// organises processes by: name=systemd with cgroups v1, else the unified one.
+func parseCgroup(buf []byte) string {
+	unified := ""
+	for _, line := range strings.Split(string(buf), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[1] == "name=systemd" {
+			return fields[2]
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			unified = fields[2]
+		}
+	}
+	return unified
+}
+
+// readBootTime reads when the host booted, which process start times are
+// relative to.
+func readBootTime(path string) (time.Time, error) {
+	buf, err := fs.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, line := range strings.Split(string(buf), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "btime" {
+			btime, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(btime, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("No btime in %s", path)
+}
+
 // Walk walks the supplied directory (expecting it to look like /proc)
 // and marshalls the files into instances of Process, which it then
 // passes one-by-one to the supplied function. Walk is only made public
@@ -90,55 +201,88 @@ func (w *walker) Walk(f func(Process, Process)) error {
 	if err != nil {
 		return err
 	}
+	now := mtime.Now()
+	// Start times are left out if the boot time can't be read
+	bootTime, _ := readBootTime(path.Join(w.procRoot, "stat"))
 
 	for _, filename := range dirEntries {
 		pid, err := strconv.Atoi(filename)
 		if err != nil {
 			continue
 		}
-
-		ppid, threads, jiffies, rss, rssLimit, err := readStats(path.Join(w.procRoot, filename, "stat"))
+		p, err := readProcess(w.procRoot, pid, bootTime, now)
 		if err != nil {
 			continue
 		}
+		f(p, Process{})
+	}
 
-		openFiles, err := fs.ReadDirNames(path.Join(w.procRoot, filename, "fd"))
-		if err != nil {
-			continue
-		}
+	return nil
+}
 
-		openFilesLimit, err := readLimits(path.Join(w.procRoot, filename, "limits"))
-		if err != nil {
-			continue
-		}
+// readProcess reads a process out of /proc. bootTime is zero if unknown.
+func readProcess(procRoot string, pid int, bootTime, now time.Time) (Process, error) {
+	dir := path.Join(procRoot, strconv.Itoa(pid))
+	ppid, threads, jiffies, rss, rssLimit, startTicks, err := readStats(path.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, err
+	}
 
-		cmdline, name := "", "(unknown)"
-		if cmdlineBuf, err := cachedReadFile(path.Join(w.procRoot, filename, "cmdline")); err == nil {
-			// like proc, treat name as the first element of command line
-			i := bytes.IndexByte(cmdlineBuf, '\000')
-			if i == -1 {
-				i = len(cmdlineBuf)
-			}
-			name = string(cmdlineBuf[:i])
-			cmdlineBuf = bytes.Replace(cmdlineBuf, []byte{'\000'}, []byte{' '}, -1)
-			cmdline = string(cmdlineBuf)
-		}
+	uid, gid, voluntary, nonvoluntary, err := readStatus(path.Join(dir, "status"))
+	hasIDs := err == nil
 
-		f(Process{
-			PID:            pid,
-			PPID:           ppid,
-			Name:           name,
-			Cmdline:        cmdline,
-			Threads:        threads,
-			Jiffies:        jiffies,
-			RSSBytes:       rss,
-			RSSBytesLimit:  rssLimit,
-			OpenFilesCount: len(openFiles),
-			OpenFilesLimit: openFilesLimit,
-		}, Process{})
+	openFiles, err := fs.ReadDirNames(path.Join(dir, "fd"))
+	if err != nil {
+		return Process{}, err
 	}
 
-	return nil
+	openFilesLimit, err := readLimits(path.Join(dir, "limits"))
+	if err != nil {
+		return Process{}, err
+	}
+
+	cmdline, name := "", "(unknown)"
+	if cmdlineBuf, err := cachedReadFile(path.Join(dir, "cmdline")); err == nil {
+		// like proc, treat name as the first element of command line
+		i := bytes.IndexByte(cmdlineBuf, '\000')
+		if i == -1 {
+			i = len(cmdlineBuf)
+		}
+		name = string(cmdlineBuf[:i])
+		cmdlineBuf = bytes.Replace(cmdlineBuf, []byte{'\000'}, []byte{' '}, -1)
+		cmdline = string(cmdlineBuf)
+	}
+
+	p := Process{
+		PID:                      pid,
+		PPID:                     ppid,
+		Name:                     name,
+		Cmdline:                  cmdline,
+		Threads:                  threads,
+		Jiffies:                  jiffies,
+		RSSBytes:                 rss,
+		RSSBytesLimit:            rssLimit,
+		OpenFilesCount:           len(openFiles),
+		OpenFilesLimit:           openFilesLimit,
+		HasIDs:                   hasIDs,
+		UID:                      uid,
+		GID:                      gid,
+		VoluntaryCtxtSwitches:    voluntary,
+		NonvoluntaryCtxtSwitches: nonvoluntary,
+	}
+	if !bootTime.IsZero() {
+		p.Started = bootTime.Add(time.Duration(startTicks) * time.Second / userHZ)
+	}
+	// These need privileges over the process
+	p.Exe, _ = fs.Readlink(path.Join(dir, "exe"))
+	p.Cwd, _ = fs.Readlink(path.Join(dir, "cwd"))
+	if read, write, err := readIO(path.Join(dir, "io")); err == nil {
+		p.ReadBytes, p.WriteBytes, p.Sampled = read, write, now
+	}
+	if buf, err := fs.ReadFile(path.Join(dir, "cgroup")); err == nil {
+		p.Cgroup = parseCgroup(buf)
+	}
+	return p, nil
 }
 
 var previousStat = linuxproc.CPUStat{}
@@ -163,3 +307,36 @@ func GetDeltaTotalJiffies() (uint64, float64, error) {
 	previousStat = currentStat
 	return currentTotal - prevTotal, float64(len(stat.CPUStats)) * 100., nil
 }
+
+// readIdentity reads what tells a process apart from a later one reusing its
+// PID: its start time.
+func readIdentity(procRoot string, pid int) (Process, error) {
+	_, _, _, _, _, startTicks, err := readStats(path.Join(procRoot, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return Process{}, err
+	}
+	p := Process{PID: pid}
+	if bootTime, err := readBootTime(path.Join(procRoot, "stat")); err == nil {
+		p.Started = bootTime.Add(time.Duration(startTicks) * time.Second / userHZ)
+	}
+	return p, nil
+}
+
+// setNice sets the niceness of every thread of a process, as on Linux it
+// only applies to the one thread its ID is given of.
+func setNice(procRoot string, pid, nice int) error {
+	tasks, err := fs.ReadDirNames(path.Join(procRoot, strconv.Itoa(pid), "task"))
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task)
+		if err != nil {
+			continue
+		}
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
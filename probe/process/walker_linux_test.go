@@ -3,8 +3,10 @@ package process_test
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	fs_hook "$GITHUB_URI/common/fs"
+	"$GITHUB_URI/common/mtime"
 	"$GITHUB_URI/probe/process"
 	"$GITHUB_URI/test"
 	"$GITHUB_URI/test/fs"
@@ -19,12 +21,26 @@ var mockFS = fs.Dir("",
 			},
 			fs.File{
 				FName:     "stat",
-				FContents: "3 na R 2 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 1 0 0 0 2 2048",
+				FContents: "3 na R 2 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 1 0 250 0 2 2048",
 			},
 			fs.File{
 				FName:     "limits",
 				FContents: `Max open files 32768 65536 files`,
 			},
+			fs.File{
+				FName:     "status",
+				FContents: "Name:\tcurl\nUid:\t1000\t1001\t1001\t1001\nGid:\t100\t100\t100\t100\nvoluntary_ctxt_switches:\t10\nnonvoluntary_ctxt_switches:\t2\n",
+			},
+			fs.File{
+				FName:     "io",
+				FContents: "rchar: 4096\nwchar: 512\nread_bytes: 2048\nwrite_bytes: 1024\n",
+			},
+			fs.File{
+				FName:     "cgroup",
+				FContents: "12:memory:/user.slice\n1:name=systemd:/user.slice/user-1000.slice/session-2.scope\n0::/user.slice\n",
+			},
+			fs.File{FName: "exe", FLink: "/usr/bin/curl"},
+			fs.File{FName: "cwd", FLink: "/home/user"},
 			fs.Dir("fd", fs.File{FName: "0"}, fs.File{FName: "1"}, fs.File{FName: "2"}),
 		),
 		fs.Dir("2",
@@ -40,6 +56,10 @@ var mockFS = fs.Dir("",
 				FName:     "limits",
 				FContents: ``,
 			},
+			fs.File{
+				FName:     "status",
+				FContents: "Uid:\t0\t0\t0\t0\nGid:\t0\t0\t0\t0\n",
+			},
 			fs.Dir("fd", fs.File{FName: "1"}, fs.File{FName: "2"}),
 		),
 		fs.Dir("4",
@@ -55,8 +75,16 @@ var mockFS = fs.Dir("",
 				FName:     "limits",
 				FContents: ``,
 			},
+			fs.File{
+				FName:     "status",
+				FContents: "Uid:\t0\t0\t0\t0\nGid:\t0\t0\t0\t0\n",
+			},
 			fs.Dir("fd", fs.File{FName: "0"}),
 		),
+		fs.File{
+			FName:     "stat",
+			FContents: "cpu  0 0 0 0 0 0 0 0 0 0\nbtime 1500000000\n",
+		},
 		fs.Dir("notapid"),
 		fs.Dir("1",
 			fs.File{
@@ -71,6 +99,10 @@ var mockFS = fs.Dir("",
 				FName:     "limits",
 				FContents: ``,
 			},
+			fs.File{
+				FName:     "status",
+				FContents: "Uid:\t0\t0\t0\t0\nGid:\t0\t0\t0\t0\n",
+			},
 			fs.Dir("fd"),
 		),
 	),
@@ -79,12 +111,19 @@ var mockFS = fs.Dir("",
 func TestWalker(t *testing.T) {
 	fs_hook.Mock(mockFS)
 	defer fs_hook.Restore()
+	now := time.Now()
+	mtime.NowForce(now)
+	defer mtime.NowReset()
 
+	booted := time.Unix(1500000000, 0)
 	want := map[int]process.Process{
-		3: {PID: 3, PPID: 2, Name: "curl", Cmdline: "curl google.com", Threads: 1, RSSBytes: 8192, RSSBytesLimit: 2048, OpenFilesCount: 3, OpenFilesLimit: 32768},
-		2: {PID: 2, PPID: 1, Name: "bash", Cmdline: "bash", Threads: 1, OpenFilesCount: 2},
-		4: {PID: 4, PPID: 3, Name: "apache", Cmdline: "apache", Threads: 1, OpenFilesCount: 1},
-		1: {PID: 1, PPID: 0, Name: "init", Cmdline: "init", Threads: 1, OpenFilesCount: 0},
+		3: {PID: 3, PPID: 2, Name: "curl", Cmdline: "curl google.com", Threads: 1, RSSBytes: 8192, RSSBytesLimit: 2048, OpenFilesCount: 3, OpenFilesLimit: 32768,
+			HasIDs: true, UID: 1001, GID: 100, Exe: "/usr/bin/curl", Cwd: "/home/user", Started: booted.Add(2500 * time.Millisecond),
+			Cgroup: "/user.slice/user-1000.slice/session-2.scope", VoluntaryCtxtSwitches: 10, NonvoluntaryCtxtSwitches: 2,
+			ReadBytes: 2048, WriteBytes: 1024, Sampled: now},
+		2: {PID: 2, PPID: 1, Name: "bash", Cmdline: "bash", Threads: 1, OpenFilesCount: 2, HasIDs: true, Started: booted},
+		4: {PID: 4, PPID: 3, Name: "apache", Cmdline: "apache", Threads: 1, OpenFilesCount: 1, HasIDs: true, Started: booted},
+		1: {PID: 1, PPID: 0, Name: "init", Cmdline: "init", Threads: 1, OpenFilesCount: 0, HasIDs: true, Started: booted},
 	}
 
 	have := map[int]process.Process{}
@@ -46,6 +46,17 @@ func TestCache(t *testing.T) {
 		t.Errorf("%v (%v)", test.Diff(want, have), err)
 	}
 
+	// Exited processes are kept for one more walk
+	err = cachingWalker.Tick()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	have, err = all(cachingWalker)
+	if err != nil || !reflect.DeepEqual(want, have) {
+		t.Errorf("%v (%v)", test.Diff(want, have), err)
+	}
+
 	err = cachingWalker.Tick()
 	if err != nil {
 		t.Fatal(err)
@@ -58,6 +69,36 @@ func TestCache(t *testing.T) {
 	}
 }
 
+func TestCacheObserve(t *testing.T) {
+	walker := &mockWalker{
+		processes: []process.Process{{PID: 1, Name: "init"}},
+	}
+	cachingWalker := process.NewCachingWalker(walker)
+	if err := cachingWalker.Tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A process which comes and goes between walks
+	cgi := process.Process{PID: 7, PPID: 1, Name: "cgi"}
+	cachingWalker.Observe(cgi)
+	want := map[process.Process]struct{}{walker.processes[0]: {}, cgi: {}}
+	for i := 0; i < 2; i++ {
+		have, err := all(cachingWalker)
+		if err != nil || !reflect.DeepEqual(want, have) {
+			t.Errorf("%d: %v (%v)", i, test.Diff(want, have), err)
+		}
+		if err := cachingWalker.Tick(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	have, err := all(cachingWalker)
+	want = map[process.Process]struct{}{walker.processes[0]: {}}
+	if err != nil || !reflect.DeepEqual(want, have) {
+		t.Errorf("%v (%v)", test.Diff(want, have), err)
+	}
+}
+
 func all(w process.Walker) (map[process.Process]struct{}, error) {
 	all := map[process.Process]struct{}{}
 	err := w.Walk(func(p, _ process.Process) {
@@ -0,0 +1,481 @@
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Just enough of the D-Bus wire protocol to call methods of the systemd
+// manager: see https://dbus.freedesktop.org/doc/dbus-specification.html
+
+// Message types
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+)
+
+// Header fields
+const (
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSignature   = 8
+)
+
+// Calls which take longer than this fail, so a stuck manager doesn't hold
+// up reports.
+const dbusTimeout = 10 * time.Second
+
+// dbusConn is a connection to a D-Bus peer: either the systemd manager
+// itself, on its private socket, or the system bus.
+type dbusConn struct {
+	mtx    sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	serial uint32
+}
+
+// dialDBus connects and authenticates to the D-Bus socket at addr. On the
+// bus, the connection has to say hello before making any other call.
+func dialDBus(addr string, bus bool) (*dbusConn, error) {
+	conn, err := net.DialTimeout("unix", addr, dbusTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &dbusConn{conn: conn, reader: bufio.NewReader(conn)}
+	if err := c.auth(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if bus {
+		if _, err := c.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello"); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// auth authenticates as the user the probe runs as.
+func (c *dbusConn) auth() error {
+	c.conn.SetDeadline(time.Now().Add(dbusTimeout))
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := io.WriteString(c.conn, "\x00AUTH EXTERNAL "+uid+"\r\n"); err != nil {
+		return err
+	}
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("dbus: authentication rejected: %s", strings.TrimSpace(line))
+	}
+	_, err = io.WriteString(c.conn, "BEGIN\r\n")
+	return err
+}
+
+func (c *dbusConn) Close() error {
+	return c.conn.Close()
+}
+
+// call calls a method with string arguments, returning the body of the
+// reply. An empty destination is for peer-to-peer connections.
+func (c *dbusConn) call(destination, objectPath, iface, member string, args ...string) ([]interface{}, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.conn.SetDeadline(time.Now().Add(dbusTimeout))
+
+	c.serial++
+	fields := []dbusField{
+		{dbusFieldPath, "o", objectPath},
+		{dbusFieldInterface, "s", iface},
+		{dbusFieldMember, "s", member},
+	}
+	if destination != "" {
+		fields = append(fields, dbusField{dbusFieldDestination, "s", destination})
+	}
+	body := &encoder{}
+	for _, arg := range args {
+		body.string(arg)
+	}
+	if len(args) > 0 {
+		fields = append(fields, dbusField{dbusFieldSignature, "g", strings.Repeat("s", len(args))})
+	}
+	if _, err := c.conn.Write(encodeMessage(dbusMethodCall, c.serial, fields, body.buf.Bytes())); err != nil {
+		return nil, err
+	}
+
+	// Skip signals, and replies to anything else
+	for {
+		msg, err := readMessage(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		if msg.replySerial != c.serial {
+			continue
+		}
+		switch msg.typ {
+		case dbusMethodReturn:
+			return msg.body, nil
+		case dbusError:
+			if len(msg.body) > 0 {
+				return nil, fmt.Errorf("%s: %v", msg.errorName, msg.body[0])
+			}
+			return nil, fmt.Errorf("%s", msg.errorName)
+		}
+	}
+}
+
+type dbusField struct {
+	code      byte
+	signature string
+	value     interface{}
+}
+
+func encodeMessage(typ byte, serial uint32, fields []dbusField, body []byte) []byte {
+	e := &encoder{}
+	e.buf.Write([]byte{'l', typ, 0, 1})
+	e.uint32(uint32(len(body)))
+	e.uint32(serial)
+	e.uint32(0) // length of the fields, filled in below
+	e.align(8)
+	start := e.buf.Len()
+	for _, field := range fields {
+		e.align(8)
+		e.buf.WriteByte(field.code)
+		e.signature(field.signature)
+		switch value := field.value.(type) {
+		case string:
+			if field.signature == "g" {
+				e.signature(value)
+			} else {
+				e.string(value)
+			}
+		case uint32:
+			e.uint32(value)
+		}
+	}
+	binary.LittleEndian.PutUint32(e.buf.Bytes()[12:], uint32(e.buf.Len()-start))
+	e.align(8)
+	e.buf.Write(body)
+	return e.buf.Bytes()
+}
+
+// encoder marshals little-endian values. Values are aligned relative to
+// the start of the buffer, so the bodies of messages need their own.
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) align(n int) {
+	for e.buf.Len()%n != 0 {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf.WriteString(s)
+	e.buf.WriteByte(0)
+}
+
+func (e *encoder) signature(s string) {
+	e.buf.WriteByte(byte(len(s)))
+	e.buf.WriteString(s)
+	e.buf.WriteByte(0)
+}
+
+type dbusMessage struct {
+	typ         byte
+	serial      uint32
+	replySerial uint32
+	errorName   string
+	fields      map[byte]interface{}
+	body        []interface{}
+}
+
+// readMessage reads and unmarshals a message, in either byte order.
+func readMessage(r io.Reader) (dbusMessage, error) {
+	msg := dbusMessage{}
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return msg, err
+	}
+	var order binary.ByteOrder
+	switch fixed[0] {
+	case 'l':
+		order = binary.LittleEndian
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return msg, fmt.Errorf("dbus: invalid byte order %q", fixed[0])
+	}
+	bodyLength, fieldsLength := order.Uint32(fixed[4:]), order.Uint32(fixed[12:])
+	if bodyLength > 1<<27 || fieldsLength > 1<<26 {
+		return msg, fmt.Errorf("dbus: message too long")
+	}
+	headerLength := 16 + int(fieldsLength)
+	if headerLength%8 != 0 {
+		headerLength += 8 - headerLength%8
+	}
+	data := make([]byte, headerLength+int(bodyLength))
+	copy(data, fixed)
+	if _, err := io.ReadFull(r, data[16:]); err != nil {
+		return msg, err
+	}
+	msg.typ = data[1]
+	msg.serial = order.Uint32(data[8:])
+
+	header := &decoder{data: data[:headerLength], pos: 12, order: order}
+	fields, err := header.value("a(yv)")
+	if err != nil {
+		return msg, err
+	}
+	msg.fields = map[byte]interface{}{}
+	for _, field := range fields.([]interface{}) {
+		field := field.([]interface{})
+		msg.fields[field[0].(byte)] = field[1]
+	}
+	msg.replySerial, _ = msg.fields[dbusFieldReplySerial].(uint32)
+	msg.errorName, _ = msg.fields[dbusFieldErrorName].(string)
+
+	signature, _ := msg.fields[dbusFieldSignature].(string)
+	body := &decoder{data: data[headerLength:], order: order}
+	for signature != "" {
+		t, err := nextType(signature)
+		if err != nil {
+			return msg, err
+		}
+		value, err := body.value(t)
+		if err != nil {
+			return msg, err
+		}
+		msg.body = append(msg.body, value)
+		signature = signature[len(t):]
+	}
+	return msg, nil
+}
+
+// decoder unmarshals values. Strings, object paths and signatures decode
+// to strings, arrays and structs to []interface{}, and variants to their
+// value.
+type decoder struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+var errTruncated = fmt.Errorf("dbus: message truncated")
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) align(n int) error {
+	if d.pos%n != 0 {
+		_, err := d.read(n - d.pos%n)
+		return err
+	}
+	return nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if err := d.align(4); err != nil {
+		return 0, err
+	}
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint32(b), nil
+}
+
+func (d *decoder) signature() (string, error) {
+	n, err := d.read(1)
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(n[0]) + 1)
+	if err != nil {
+		return "", err
+	}
+	return string(b[:len(b)-1]), nil
+}
+
+// value decodes a value of the single complete type t.
+func (d *decoder) value(t string) (interface{}, error) {
+	if err := d.align(alignment(t[0])); err != nil {
+		return nil, err
+	}
+	switch t[0] {
+	case 'y':
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case 'b':
+		v, err := d.uint32()
+		return v != 0, err
+	case 'n', 'q':
+		b, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		if t[0] == 'n' {
+			return int16(d.order.Uint16(b)), nil
+		}
+		return d.order.Uint16(b), nil
+	case 'i':
+		v, err := d.uint32()
+		return int32(v), err
+	case 'u', 'h':
+		return d.uint32()
+	case 'x', 't', 'd':
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		v := d.order.Uint64(b)
+		switch t[0] {
+		case 'x':
+			return int64(v), nil
+		case 'd':
+			return math.Float64frombits(v), nil
+		}
+		return v, nil
+	case 's', 'o':
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(int(n) + 1)
+		if err != nil {
+			return nil, err
+		}
+		return string(b[:n]), nil
+	case 'g':
+		return d.signature()
+	case 'v':
+		signature, err := d.signature()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := nextType(signature); err != nil {
+			return nil, err
+		}
+		return d.value(signature)
+	case 'a':
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		elem, err := nextType(t[1:])
+		if err != nil {
+			return nil, err
+		}
+		if err := d.align(alignment(elem[0])); err != nil {
+			return nil, err
+		}
+		end := d.pos + int(n)
+		if end > len(d.data) {
+			return nil, errTruncated
+		}
+		result := []interface{}{}
+		for d.pos < end {
+			value, err := d.value(elem)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	case '(', '{':
+		result := []interface{}{}
+		for inner := t[1 : len(t)-1]; inner != ""; {
+			elem, err := nextType(inner)
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.value(elem)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+			inner = inner[len(elem):]
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("dbus: unsupported type %q", t)
+}
+
+func alignment(t byte) int {
+	switch t {
+	case 'n', 'q':
+		return 2
+	case 'b', 'i', 'u', 'h', 's', 'o', 'a':
+		return 4
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	}
+	return 1
+}
+
+// nextType returns the first single complete type of a signature.
+func nextType(signature string) (string, error) {
+	if signature == "" {
+		return "", fmt.Errorf("dbus: invalid signature")
+	}
+	switch signature[0] {
+	case 'a':
+		elem, err := nextType(signature[1:])
+		if err != nil {
+			return "", err
+		}
+		return signature[:1+len(elem)], nil
+	case '(', '{':
+		depth := 0
+		for i := 0; i < len(signature); i++ {
+			switch signature[i] {
+			case '(', '{':
+				depth++
+			case ')', '}':
+				depth--
+				if depth == 0 {
+					return signature[:i+1], nil
+				}
+			}
+		}
+		return "", fmt.Errorf("dbus: invalid signature %q", signature)
+	case ')', '}':
+		return "", fmt.Errorf("dbus: invalid signature %q", signature)
+	}
+	return signature[:1], nil
+}
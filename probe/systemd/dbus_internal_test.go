@@ -0,0 +1,160 @@
+package systemd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type call struct {
+	member string
+	args   []interface{}
+}
+
+// mockManager serves the systemd manager's private socket, answering
+// ListUnits and the unit jobs.
+func mockManager(t *testing.T, listener net.Listener, calls chan<- call) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for _, prefix := range []string{"\x00AUTH EXTERNAL ", "BEGIN"} {
+		line, err := reader.ReadString('\n')
+		if err != nil || !strings.HasPrefix(line, prefix) {
+			t.Errorf("Expected %q, got %q (%v)", prefix, line, err)
+			return
+		}
+		if prefix != "BEGIN" {
+			conn.Write([]byte("OK 0123456789abcdef\r\n"))
+		}
+	}
+
+	for serial := uint32(1); ; serial++ {
+		msg, err := readMessage(reader)
+		if err != nil {
+			return
+		}
+		member, _ := msg.fields[dbusFieldMember].(string)
+		if _, ok := msg.fields[dbusFieldDestination]; ok {
+			t.Errorf("Expected no destination on the private socket")
+		}
+		calls <- call{member, msg.body}
+
+		// Signals are ignored by clients
+		conn.Write(encodeMessage(4, serial, []dbusField{
+			{dbusFieldPath, "o", managerPath},
+			{dbusFieldInterface, "s", managerInterface},
+			{dbusFieldMember, "s", "UnitNew"},
+		}, nil))
+
+		typ, signature, body := byte(dbusMethodReturn), "", &encoder{}
+		switch member {
+		case "ListUnits":
+			signature = "a(ssssssouso)"
+			body.uint32(0)
+			body.align(8)
+			start := body.buf.Len()
+			for _, unit := range [][]string{
+				{"nginx.service", "A high performance web server", "loaded", "active", "running"},
+				{"backup.service", "Nightly backup", "loaded", "inactive", "dead"},
+			} {
+				body.align(8)
+				for _, s := range unit {
+					body.string(s)
+				}
+				body.string("")
+				body.string("/org/freedesktop/systemd1/unit/" + strings.Replace(unit[0], ".", "_2e", -1))
+				body.uint32(0)
+				body.string("")
+				body.string("/")
+			}
+			binary.LittleEndian.PutUint32(body.buf.Bytes(), uint32(body.buf.Len()-start))
+		case "RestartUnit":
+			signature = "o"
+			body.string("/org/freedesktop/systemd1/job/1")
+		default:
+			typ, signature = dbusError, "s"
+			body.string("Unknown method " + member)
+		}
+		fields := []dbusField{
+			{dbusFieldReplySerial, "u", msg.serial},
+			{dbusFieldSignature, "g", signature},
+		}
+		if typ == dbusError {
+			fields = append(fields, dbusField{dbusFieldErrorName, "s", "org.freedesktop.DBus.Error.UnknownMethod"})
+		}
+		conn.Write(encodeMessage(typ, serial+1000, fields, body.buf.Bytes()))
+	}
+}
+
+func TestClient(t *testing.T) {
+	root, err := ioutil.TempDir("", "systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if err := os.MkdirAll(path.Join(root, "run/systemd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("unix", path.Join(root, "run/systemd/private"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	calls := make(chan call, 10)
+	go mockManager(t, listener, calls)
+
+	client, err := NewClient(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	units, err := client.Units("nginx.service", "gone.service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Unit{
+		"nginx.service": {
+			Name:        "nginx.service",
+			Description: "A high performance web server",
+			LoadState:   "loaded",
+			ActiveState: "active",
+			SubState:    "running",
+		},
+	}
+	if !reflect.DeepEqual(want, units) {
+		t.Errorf("Expected %v, got %v", want, units)
+	}
+	if have := <-calls; have.member != "ListUnits" || len(have.args) != 0 {
+		t.Errorf("Expected ListUnits(), got %v", have)
+	}
+
+	if err := client.Restart("nginx.service"); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := (call{"RestartUnit", []interface{}{"nginx.service", "replace"}}), <-calls; !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected %v, got %v", want, have)
+	}
+
+	if err := client.Stop("nginx.service"); err == nil || !strings.Contains(err.Error(), "Unknown method StopUnit") {
+		t.Errorf("Expected the error of the manager, got %v", err)
+	}
+}
+
+func TestNewClientUnreachable(t *testing.T) {
+	root, err := ioutil.TempDir("", "systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if _, err := NewClient(root); err == nil {
+		t.Error("Expected an error without any socket")
+	}
+}
@@ -0,0 +1,271 @@
+package systemd
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"$GITHUB_URI/common/mtime"
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/report"
+)
+
+// These constants are keys used in node metadata
+const (
+	UnitName        = "systemd_unit_name"
+	UnitType        = "systemd_unit_type"
+	UnitDescription = "systemd_unit_description"
+	UnitActiveState = "systemd_unit_active_state"
+	UnitSubState    = "systemd_unit_sub_state"
+
+	// The usage of the processes of a unit, summed up by the renderer
+	CPUUsage    = "systemd_unit_cpu_usage_percent"
+	MemoryUsage = "systemd_unit_memory_usage_bytes"
+)
+
+// Control IDs used by the systemd integration.
+const (
+	StartUnit   = "systemd_start_unit"
+	RestartUnit = "systemd_restart_unit"
+	StopUnit    = "systemd_stop_unit"
+)
+
+// How long unit states are cached for, as the manager is asked for them.
+const stateInterval = 10 * time.Second
+
+// Exposed for testing
+var (
+	MetadataTemplates = report.MetadataTemplates{
+		UnitName:        {ID: UnitName, Label: "Name", From: report.FromLatest, Priority: 1},
+		UnitDescription: {ID: UnitDescription, Label: "Description", From: report.FromLatest, Priority: 2},
+		UnitType:        {ID: UnitType, Label: "Type", From: report.FromLatest, Priority: 3},
+		UnitActiveState: {ID: UnitActiveState, Label: "State", From: report.FromLatest, Priority: 4},
+		UnitSubState:    {ID: UnitSubState, Label: "Sub-state", From: report.FromLatest, Priority: 5},
+		report.Process:  {ID: report.Process, Label: "# Processes", From: report.FromCounters, Datatype: "number", Priority: 6},
+	}
+
+	MetricTemplates = report.MetricTemplates{
+		CPUUsage:    {ID: CPUUsage, Label: "CPU", Format: report.PercentFormat, Priority: 1},
+		MemoryUsage: {ID: MemoryUsage, Label: "Memory", Format: report.FilesizeFormat, Priority: 2},
+	}
+
+	UnitControls = []report.Control{
+		{
+			ID:    StartUnit,
+			Human: "Start",
+			Icon:  "fa-play",
+			Rank:  1,
+		},
+		{
+			ID:    RestartUnit,
+			Human: "Restart",
+			Icon:  "fa-repeat",
+			Rank:  2,
+		},
+		{
+			ID:    StopUnit,
+			Human: "Stop",
+			Icon:  "fa-stop",
+			Rank:  3,
+		},
+	}
+)
+
+// MakeUnitNodeID makes the ID of a unit node. Units are only unique per
+// host.
+func MakeUnitNodeID(hostID, unit string) string {
+	return report.MakeSystemdUnitNodeID(unit + "@" + hostID)
+}
+
+// ParseUnitNodeID returns the unit name and host ID of a unit node ID.
+func ParseUnitNodeID(nodeID string) (unit, hostID string, ok bool) {
+	id, ok := report.ParseSystemdUnitNodeID(nodeID)
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(id, "@")
+	if i < 0 {
+		return "", "", false
+	}
+	return id[:i], id[i+1:], true
+}
+
+// Reporter generates Reports containing the SystemdUnit topology, and tags
+// processes with their unit.
+type Reporter struct {
+	hostID  string
+	probeID string
+	walker  process.Walker
+	client  Client // nil if the systemd manager can't be reached
+	root    string
+
+	mtx        sync.Mutex
+	states     map[string]Unit
+	statesTime time.Time
+}
+
+// NewReporter makes a new Reporter. Without a client, units are still
+// reported from the cgroups of processes, with their descriptions read
+// from the unit files under root, but without their state or controls.
+func NewReporter(walker process.Walker, client Client, root, hostID, probeID string) *Reporter {
+	r := &Reporter{
+		hostID:  hostID,
+		probeID: probeID,
+		walker:  walker,
+		client:  client,
+		root:    root,
+	}
+	if client != nil {
+		r.registerControls()
+	}
+	return r
+}
+
+// Name of this reporter/tagger, for metrics gathering
+func (*Reporter) Name() string { return "Systemd" }
+
+// Stop deregisters the controls of the reporter.
+func (r *Reporter) Stop() {
+	if r.client != nil {
+		r.deregisterControls()
+	}
+}
+
+// Report implements Reporter.
+func (r *Reporter) Report() (report.Report, error) {
+	result := report.MakeReport()
+	units := report.MakeTopology().
+		WithMetadataTemplates(MetadataTemplates).
+		WithMetricTemplates(MetricTemplates)
+	if r.client != nil {
+		units.Controls.AddControls(UnitControls)
+	}
+
+	names := map[string]struct{}{}
+	err := r.walker.Walk(func(p, _ process.Process) {
+		if unit, ok := UnitFromCgroup(p.Cgroup); ok {
+			names[unit] = struct{}{}
+		}
+	})
+	if err != nil {
+		return result, err
+	}
+
+	states := r.unitStates(names)
+	hostParent := report.EmptySets.
+		Add(report.Host, report.MakeStringSet(report.MakeHostNodeID(r.hostID)))
+	for name := range names {
+		node := report.MakeNodeWith(MakeUnitNodeID(r.hostID, name), map[string]string{
+			UnitName: name,
+			UnitType: unitType(name),
+		}).WithTopology(report.SystemdUnit).WithParents(hostParent)
+		state, ok := states[name]
+		if !ok {
+			units = units.AddNode(node)
+			continue
+		}
+		if state.Description != "" {
+			node = node.WithLatests(map[string]string{UnitDescription: state.Description})
+		}
+		if state.ActiveState != "" {
+			node = node.WithLatests(map[string]string{
+				UnitActiveState:       state.ActiveState,
+				UnitSubState:          state.SubState,
+				report.ControlProbeID: r.probeID,
+			})
+			if state.IsActive() {
+				node = node.WithControls(RestartUnit, StopUnit)
+			} else {
+				node = node.WithControls(StartUnit)
+			}
+		}
+		units = units.AddNode(node)
+	}
+	result.SystemdUnit = result.SystemdUnit.Merge(units)
+	return result, nil
+}
+
+// Tag implements Tagger. It gives processes their unit as parent.
+func (r *Reporter) Tag(rpt report.Report) (report.Report, error) {
+	for id, node := range rpt.Process.Nodes {
+		cgroup, ok := node.Latest.Lookup(process.Cgroup)
+		if !ok {
+			continue
+		}
+		unit, ok := UnitFromCgroup(cgroup)
+		if !ok {
+			continue
+		}
+		rpt.Process = rpt.Process.AddNode(report.MakeNode(id).WithParents(report.EmptySets.
+			Add(report.SystemdUnit, report.MakeStringSet(MakeUnitNodeID(r.hostID, unit))),
+		))
+	}
+	return rpt, nil
+}
+
+// unitStates returns the states of the units, asking systemd for them at
+// most every stateInterval. If it can't be asked, only what the unit files
+// tell is known.
+func (r *Reporter) unitStates(names map[string]struct{}) map[string]Unit {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	now := mtime.Now()
+	if now.Sub(r.statesTime) < stateInterval {
+		return r.states
+	}
+
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	var states map[string]Unit
+	if r.client != nil {
+		var err error
+		if states, err = r.client.Units(list...); err != nil {
+			log.Warnf("Systemd: cannot get unit states: %v", err)
+		}
+	}
+	if states == nil {
+		states = ReadUnitFiles(r.root, list...)
+	}
+	r.states, r.statesTime = states, now
+	return states
+}
+
+// invalidateStates makes the next report ask systemd for unit states, so
+// that the result of a control shows.
+func (r *Reporter) invalidateStates() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.statesTime = time.Time{}
+}
+
+func (r *Reporter) unitControl(verb string, f func(Client, string) error) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		unit, _, ok := ParseUnitNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		log.Infof("Systemd: %s unit %s", verb, unit)
+		defer r.invalidateStates()
+		return xfer.ResponseError(f(r.client, unit))
+	}
+}
+
+func (r *Reporter) registerControls() {
+	controls.Register(StartUnit, r.unitControl("start", Client.Start))
+	controls.Register(RestartUnit, r.unitControl("restart", Client.Restart))
+	controls.Register(StopUnit, r.unitControl("stop", Client.Stop))
+}
+
+func (r *Reporter) deregisterControls() {
+	controls.Rm(StartUnit)
+	controls.Rm(RestartUnit)
+	controls.Rm(StopUnit)
+}
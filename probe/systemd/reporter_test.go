@@ -0,0 +1,162 @@
+package systemd_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+
+	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/probe/systemd"
+	"$GITHUB_URI/report"
+)
+
+type mockWalker struct {
+	processes []process.Process
+}
+
+func (m *mockWalker) Walk(f func(process.Process, process.Process)) error {
+	for _, p := range m.processes {
+		f(p, process.Process{})
+	}
+	return nil
+}
+
+type mockClient struct {
+	units    map[string]systemd.Unit
+	restarts []string
+}
+
+func (c *mockClient) Units(names ...string) (map[string]systemd.Unit, error) {
+	return c.units, nil
+}
+
+func (c *mockClient) Start(name string) error { return nil }
+func (c *mockClient) Stop(name string) error  { return nil }
+
+func (c *mockClient) Restart(name string) error {
+	c.restarts = append(c.restarts, name)
+	return nil
+}
+
+var walker = &mockWalker{
+	processes: []process.Process{
+		{PID: 1, Name: "systemd", Cgroup: "/init.scope"},
+		{PID: 2, PPID: 1, Name: "nginx", Cgroup: "/system.slice/nginx.service"},
+		{PID: 3, PPID: 2, Name: "nginx", Cgroup: "/system.slice/nginx.service"},
+		{PID: 4, PPID: 1, Name: "kthreadd", Cgroup: "/"},
+		{PID: 5, PPID: 1, Name: "redis", Cgroup: "/system.slice/docker-4b1d5a1dd2e7bd8bad6ba41c06fdc2e2f4b4dcd1f8d2a3f4e2c3b4a5d6e7f809.scope"},
+	},
+}
+
+func TestReporter(t *testing.T) {
+	client := &mockClient{
+		units: map[string]systemd.Unit{
+			"nginx.service": {Name: "nginx.service", Description: "Web server", ActiveState: "active", SubState: "running"},
+			"init.scope":    {Name: "init.scope", ActiveState: "inactive", SubState: "dead"},
+		},
+	}
+	reporter := systemd.NewReporter(walker, client, "", "host1", "probe1")
+	defer reporter.Stop()
+
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 2, len(rpt.SystemdUnit.Nodes); want != have {
+		t.Fatalf("Expected %d units, got %d", want, have)
+	}
+	nginxID := systemd.MakeUnitNodeID("host1", "nginx.service")
+	nginx, ok := rpt.SystemdUnit.Nodes[nginxID]
+	if !ok {
+		t.Fatalf("Expected report to have unit %q", nginxID)
+	}
+	for key, want := range map[string]string{
+		systemd.UnitName:        "nginx.service",
+		systemd.UnitType:        "service",
+		systemd.UnitDescription: "Web server",
+		systemd.UnitActiveState: "active",
+		systemd.UnitSubState:    "running",
+		report.ControlProbeID:   "probe1",
+	} {
+		if have, ok := nginx.Latest.Lookup(key); !ok || want != have {
+			t.Errorf("Expected %s %q, got %q", key, want, have)
+		}
+	}
+	if hosts, ok := nginx.Parents.Lookup(report.Host); !ok || !hosts.Contains(report.MakeHostNodeID("host1")) {
+		t.Errorf("Expected unit to have host parent, got %v", hosts)
+	}
+	if want, have := []string{systemd.RestartUnit, systemd.StopUnit}, nginx.Controls.Controls; !have.Contains(want[0]) || !have.Contains(want[1]) || len(have) != 2 {
+		t.Errorf("Expected controls %v, got %v", want, have)
+	}
+	scope := rpt.SystemdUnit.Nodes[systemd.MakeUnitNodeID("host1", "init.scope")]
+	if have := scope.Controls.Controls; len(have) != 1 || !have.Contains(systemd.StartUnit) {
+		t.Errorf("Expected start control, got %v", have)
+	}
+
+	// The tagger gives processes their unit as parent
+	rpt = report.MakeReport()
+	for _, p := range walker.processes {
+		rpt.Process.AddNode(report.MakeNodeWith(report.MakeProcessNodeID("host1", strconv.Itoa(p.PID)), map[string]string{
+			process.Cgroup: p.Cgroup,
+		}))
+	}
+	rpt, err = reporter.Tag(rpt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if units, ok := rpt.Process.Nodes[report.MakeProcessNodeID("host1", "3")].Parents.Lookup(report.SystemdUnit); !ok || !units.Contains(nginxID) {
+		t.Errorf("Expected process to have unit parent, got %v", units)
+	}
+	if units, ok := rpt.Process.Nodes[report.MakeProcessNodeID("host1", "4")].Parents.Lookup(report.SystemdUnit); ok {
+		t.Errorf("Expected kernel thread to have no unit parent, got %v", units)
+	}
+
+	response := controls.HandleControlRequest(xfer.Request{
+		NodeID:  nginxID,
+		Control: systemd.RestartUnit,
+	})
+	if response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	if want, have := []string{"nginx.service"}, client.restarts; len(have) != 1 || want[0] != have[0] {
+		t.Errorf("Expected restarts %v, got %v", want, have)
+	}
+}
+
+func TestReporterWithoutClient(t *testing.T) {
+	root, err := ioutil.TempDir("", "systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if err := os.MkdirAll(path.Join(root, "lib/systemd/system"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(root, "lib/systemd/system/nginx.service"), []byte("[Unit]\nDescription=Web server\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rpt, err := systemd.NewReporter(walker, nil, root, "host1", "probe1").Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 2, len(rpt.SystemdUnit.Nodes); want != have {
+		t.Fatalf("Expected %d units, got %d", want, have)
+	}
+	nginx := rpt.SystemdUnit.Nodes[systemd.MakeUnitNodeID("host1", "nginx.service")]
+	if have, ok := nginx.Latest.Lookup(systemd.UnitDescription); !ok || have != "Web server" {
+		t.Errorf("Expected the description from the unit file, got %q", have)
+	}
+	if have, ok := nginx.Latest.Lookup(systemd.UnitActiveState); ok {
+		t.Errorf("Expected no state, got %q", have)
+	}
+	for id, node := range rpt.SystemdUnit.Nodes {
+		if _, ok := node.Latest.Lookup(report.ControlProbeID); ok || len(node.Controls.Controls) > 0 {
+			t.Errorf("Expected %s to have no controls, got %v", id, node.Controls.Controls)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package systemd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"$GITHUB_URI/probe/kmsg"
+)
+
+// Unit is the state of a systemd unit, as told by the systemd manager.
+type Unit struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+}
+
+// IsActive tells whether the unit is running, or about to.
+func (u Unit) IsActive() bool {
+	switch u.ActiveState {
+	case "active", "activating", "reloading":
+		return true
+	}
+	return false
+}
+
+// Client talks to the systemd manager of the host.
+type Client interface {
+	Units(names ...string) (map[string]Unit, error)
+	Start(name string) error
+	Stop(name string) error
+	Restart(name string) error
+}
+
+const (
+	managerName      = "org.freedesktop.systemd1"
+	managerPath      = "/org/freedesktop/systemd1"
+	managerInterface = "org.freedesktop.systemd1.Manager"
+)
+
+// dbusClient talks to the systemd manager over D-Bus, reconnecting after
+// errors.
+type dbusClient struct {
+	mtx         sync.Mutex
+	dial        func() (*dbusConn, error)
+	destination string
+	conn        *dbusConn
+}
+
+// NewClient makes a Client talking to the systemd manager of the host with
+// the given root filesystem: directly on its private socket, which only
+// root can use, or else via the system bus.
+func NewClient(root string) (Client, error) {
+	private := path.Join(root, "run/systemd/private")
+	if conn, err := dialDBus(private, false); err == nil {
+		return &dbusClient{
+			dial: func() (*dbusConn, error) { return dialDBus(private, false) },
+			conn: conn,
+		}, nil
+	}
+	bus := path.Join(root, "var/run/dbus/system_bus_socket")
+	conn, err := dialDBus(bus, true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s or %s: %v", private, bus, err)
+	}
+	return &dbusClient{
+		dial:        func() (*dbusConn, error) { return dialDBus(bus, true) },
+		destination: managerName,
+		conn:        conn,
+	}, nil
+}
+
+func (c *dbusClient) call(member string, args ...string) ([]interface{}, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.conn == nil {
+		conn, err := c.dial()
+		if err != nil {
+			return nil, err
+		}
+		c.conn = conn
+	}
+	result, err := c.conn.call(c.destination, managerPath, managerInterface, member, args...)
+	if _, ok := err.(net.Error); ok || err == io.EOF || err == io.ErrUnexpectedEOF {
+		c.conn.Close()
+		c.conn = nil
+	}
+	return result, err
+}
+
+// Units returns the state of the named units, by name. Units which aren't
+// loaded are left out.
+func (c *dbusClient) Units(names ...string) (map[string]Unit, error) {
+	units := map[string]Unit{}
+	if len(names) == 0 {
+		return units, nil
+	}
+	wanted := map[string]struct{}{}
+	for _, name := range names {
+		wanted[name] = struct{}{}
+	}
+	result, err := c.call("ListUnits")
+	if err != nil {
+		return nil, fmt.Errorf("ListUnits: %v", err)
+	}
+	if len(result) != 1 {
+		return nil, fmt.Errorf("ListUnits: unexpected reply %v", result)
+	}
+	list, _ := result[0].([]interface{})
+	for _, item := range list {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) < 5 {
+			return nil, fmt.Errorf("ListUnits: unexpected unit %v", item)
+		}
+		unit := Unit{}
+		for i, field := range []*string{&unit.Name, &unit.Description, &unit.LoadState, &unit.ActiveState, &unit.SubState} {
+			*field, _ = fields[i].(string)
+		}
+		if _, ok := wanted[unit.Name]; ok {
+			units[unit.Name] = unit
+		}
+	}
+	return units, nil
+}
+
+func (c *dbusClient) Start(name string) error   { return c.job("StartUnit", name) }
+func (c *dbusClient) Stop(name string) error    { return c.job("StopUnit", name) }
+func (c *dbusClient) Restart(name string) error { return c.job("RestartUnit", name) }
+
+// job queues a job for a unit, replacing any conflicting ones, as
+// systemctl does.
+func (c *dbusClient) job(method, name string) error {
+	if _, err := c.call(method, name, "replace"); err != nil {
+		return fmt.Errorf("%s %s: %v", method, name, err)
+	}
+	return nil
+}
+
+// unitPaths are where unit files are looked for, relative to the root of
+// the host, most specific first.
+var unitPaths = []string{
+	"etc/systemd/system",
+	"run/systemd/transient",
+	"run/systemd/system",
+	"usr/local/lib/systemd/system",
+	"lib/systemd/system",
+	"usr/lib/systemd/system",
+}
+
+// ReadUnitFiles returns the units found in the unit files of the host with
+// the given root filesystem, for when the manager can't be asked. Their
+// description is known, but not their state.
+func ReadUnitFiles(root string, names ...string) map[string]Unit {
+	units := map[string]Unit{}
+	for _, name := range names {
+		candidates := []string{name}
+		// Instances of templates, e.g. getty@tty1.service, share the file
+		// of their template, getty@.service
+		if i, j := strings.Index(name, "@"), strings.LastIndex(name, "."); i >= 0 && j > i {
+			candidates = append(candidates, name[:i+1]+name[j:])
+		}
+	lookup:
+		for _, candidate := range candidates {
+			for _, dir := range unitPaths {
+				if description, err := readDescription(path.Join(root, dir, candidate)); err == nil {
+					units[name] = Unit{Name: name, Description: description, LoadState: "loaded"}
+					break lookup
+				}
+			}
+		}
+	}
+	return units
+}
+
+// readDescription returns the Description from the [Unit] section of a
+// unit file.
+func readDescription(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	section, description := "", ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "["):
+			section = line
+		case section == "[Unit]" && strings.HasPrefix(line, "Description="):
+			description = strings.TrimSpace(strings.TrimPrefix(line, "Description="))
+		}
+	}
+	return description, scanner.Err()
+}
+
+// UnitFromCgroup returns the service or scope unit a cgroup path belongs
+// to: the deepest component of the path naming one. Processes outside of
+// any, like those of the kernel or directly in a slice, have none. Nor do
+// those of containers, though runtimes give them scopes.
+func UnitFromCgroup(cgroup string) (string, bool) {
+	for cgroup != "/" && cgroup != "." && cgroup != "" {
+		name := path.Base(cgroup)
+		if isContainerScope(name) {
+			return "", false
+		}
+		if strings.HasSuffix(name, ".service") || strings.HasSuffix(name, ".scope") {
+			return name, true
+		}
+		cgroup = path.Dir(cgroup)
+	}
+	return "", false
+}
+
+func isContainerScope(name string) bool {
+	if !strings.HasSuffix(name, ".scope") {
+		return false
+	}
+	for _, prefix := range kmsg.ContainerCgroupPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unitType returns the type of a unit, from the suffix of its name.
+func unitType(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package systemd_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"$GITHUB_URI/probe/systemd"
+)
+
+func TestUnitFromCgroup(t *testing.T) {
+	const containerID = "4b1d5a1dd2e7bd8bad6ba41c06fdc2e2f4b4dcd1f8d2a3f4e2c3b4a5d6e7f809"
+	for cgroup, want := range map[string]string{
+		"/system.slice/nginx.service":                              "nginx.service",
+		"/system.slice/docker.service/runc/foo":                    "docker.service",
+		"/user.slice/user-1000.slice/session-2.scope":              "session-2.scope",
+		"/system.slice/foo.service/bar.scope/baz":                  "bar.scope",
+		"/system.slice/docker-" + containerID + ".scope":           "",
+		"/kubepods.slice/cri-containerd-" + containerID + ".scope": "",
+		"/":             "",
+		"/system.slice": "",
+		"":              "",
+	} {
+		have, ok := systemd.UnitFromCgroup(cgroup)
+		if want != have || ok != (want != "") {
+			t.Errorf("%q: expected %q, got %q (%v)", cgroup, want, have, ok)
+		}
+	}
+}
+
+func TestReadUnitFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for filename, content := range map[string]string{
+		"lib/systemd/system/nginx.service":  "[Unit]\nDescription=A high performance web server\n\n[Service]\nDescription=not this one\n",
+		"etc/systemd/system/nginx.service":  "# overrides the packaged unit\n[Unit]\nDescription=Local web server\n",
+		"lib/systemd/system/getty@.service": "[Unit]\nDescription=Getty on %I\n",
+	} {
+		filename = path.Join(root, filename)
+		if err := os.MkdirAll(path.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	have := systemd.ReadUnitFiles(root, "nginx.service", "getty@tty1.service", "session-2.scope")
+	want := map[string]systemd.Unit{
+		"nginx.service":      {Name: "nginx.service", Description: "Local web server", LoadState: "loaded"},
+		"getty@tty1.service": {Name: "getty@tty1.service", Description: "Getty on %I", LoadState: "loaded"},
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected %v, got %v", want, have)
+	}
+}
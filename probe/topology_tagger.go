@@ -17,14 +17,26 @@ func (topologyTagger) Name() string { return "Topology" }
 // Tag implements Tagger
 func (topologyTagger) Tag(r report.Report) (report.Report, error) {
 	for name, t := range map[string]*report.Topology{
-		report.Endpoint:       &(r.Endpoint),
-		report.Process:        &(r.Process),
-		report.Container:      &(r.Container),
-		report.ContainerImage: &(r.ContainerImage),
-		report.Pod:            &(r.Pod),
-		report.Service:        &(r.Service),
-		report.Host:           &(r.Host),
-		report.Overlay:        &(r.Overlay),
+		report.Endpoint:              &(r.Endpoint),
+		report.Process:               &(r.Process),
+		report.Container:             &(r.Container),
+		report.ContainerImage:        &(r.ContainerImage),
+		report.Pod:                   &(r.Pod),
+		report.Service:               &(r.Service),
+		report.PersistentVolume:      &(r.PersistentVolume),
+		report.PersistentVolumeClaim: &(r.PersistentVolumeClaim),
+		report.StorageClass:          &(r.StorageClass),
+		report.DockerNetwork:         &(r.DockerNetwork),
+		report.DockerVolume:          &(r.DockerVolume),
+		report.SwarmService:          &(r.SwarmService),
+		report.SwarmTask:             &(r.SwarmTask),
+		report.SwarmNode:             &(r.SwarmNode),
+		report.ComposeProject:        &(r.ComposeProject),
+		report.ComposeService:        &(r.ComposeService),
+		report.SystemdUnit:           &(r.SystemdUnit),
+		report.NetworkInterface:      &(r.NetworkInterface),
+		report.Host:                  &(r.Host),
+		report.Overlay:               &(r.Overlay),
 	} {
 		for _, node := range t.Nodes {
 			t.AddNode(node.WithTopology(name))
@@ -15,3 +15,13 @@ func TestTagMissingID(t *testing.T) {
 		t.Error("TopologyTagger erroneously tagged a missing node ID")
 	}
 }
+
+func TestTagStorageTopologies(t *testing.T) {
+	const nodeID = "pv-1"
+	r := report.MakeReport()
+	r.PersistentVolume.AddNode(report.MakeNode(nodeID))
+	rpt, _ := NewTopologyTagger().Tag(r)
+	if topology := rpt.PersistentVolume.Nodes[nodeID].Topology; topology != report.PersistentVolume {
+		t.Errorf("Expected the persistent volume to be tagged with its topology, got %q", topology)
+	}
+}
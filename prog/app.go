@@ -214,7 +214,7 @@ func appMain(flags appFlags) {
 }
 
 func newWeavePublisher(dockerEndpoint, weaveAddr, weaveHostname, containerName string) (*app.WeavePublisher, error) {
-	dockerClient, err := docker.NewDockerClientStub(dockerEndpoint)
+	dockerClient, err := docker.NewDockerClientStub(docker.Endpoint{Address: dockerEndpoint})
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,9 @@ import (
 
 	"$GITHUB_URI/app"
 	"$GITHUB_URI/common/xfer"
+	"$GITHUB_URI/probe/cri"
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/kmsg"
 	"github.com/weaveworks/weave/common"
 )
 
@@ -62,6 +65,8 @@ type probeFlags struct {
 	spyInterval     time.Duration
 	spyProcs        bool
 	procRoot        string
+	sysRoot         string
+	procConnector   bool
 	pluginsRoot     string
 	useConntrack    bool
 	insecure        bool
@@ -70,14 +75,35 @@ type probeFlags struct {
 	resolver        string
 	noApp           bool
 
-	dockerEnabled  bool
-	dockerInterval time.Duration
-	dockerBridge   string
+	dockerEnabled   bool
+	dockerInterval  time.Duration
+	dockerBridge    string
+	dockerCgroups   bool
+	dockerEndpoints docker.Endpoints
+	cgroupRoot      string
+
+	criEnabled  bool
+	criEndpoint string
+	criInterval time.Duration
+
+	systemdEnabled bool
+
+	kmsgEnabled bool
+	kmsgPath    string
+
+	cloudEnabled  bool
+	cloudEndpoint string
+
+	netifEnabled bool
 
 	kubernetesEnabled  bool
 	kubernetesAPI      string
 	kubernetesInterval time.Duration
 
+	kubernetesLeaderElection  bool
+	kubernetesLeaderNamespace string
+	kubernetesLeaderLease     time.Duration
+
 	weaveAddr     string
 	weaveHostname string
 }
@@ -132,6 +158,8 @@ func main() {
 	flag.DurationVar(&flags.probe.spyInterval, "probe.spy.interval", time.Second, "spy (scan) interval")
 	flag.BoolVar(&flags.probe.spyProcs, "probe.processes", true, "report processes (needs root)")
 	flag.StringVar(&flags.probe.procRoot, "probe.proc.root", "/proc", "location of the proc filesystem")
+	flag.StringVar(&flags.probe.sysRoot, "probe.sys.root", "/sys", "location of the sysfs filesystem")
+	flag.BoolVar(&flags.probe.procConnector, "probe.proc.connector", false, "listen to the kernel for processes starting, to see those exiting before /proc is walked (Linux only, needs root)")
 	flag.StringVar(&flags.probe.pluginsRoot, "probe.plugins.root", "/var/run/scope/plugins", "Root directory to search for plugins")
 	flag.BoolVar(&flags.probe.useConntrack, "probe.conntrack", true, "also use conntrack to track connections")
 	flag.BoolVar(&flags.probe.insecure, "probe.insecure", false, "(SSL) explicitly allow \"insecure\" SSL connections and transfers")
@@ -141,9 +169,24 @@ func main() {
 	flag.BoolVar(&flags.probe.dockerEnabled, "probe.docker", false, "collect Docker-related attributes for processes")
 	flag.DurationVar(&flags.probe.dockerInterval, "probe.docker.interval", 10*time.Second, "how often to update Docker attributes")
 	flag.StringVar(&flags.probe.dockerBridge, "probe.docker.bridge", "docker0", "the docker bridge name")
-	flag.BoolVar(&flags.probe.kubernetesEnabled, "probe.kubernetes", false, "collect kubernetes-related attributes for containers, should only be enabled on the master node")
+	flag.Var(&flags.probe.dockerEndpoints, "probe.docker.endpoint", "Docker daemon to report on, as address[,host=id][,cert=file,key=file,ca=file]; repeat for several daemons (default the local socket)")
+	flag.BoolVar(&flags.probe.dockerCgroups, "probe.docker.cgroups", true, "read container metrics from the cgroup filesystem rather than the Docker stats API, where the cgroups can be found")
+	flag.StringVar(&flags.probe.cgroupRoot, "probe.cgroup.root", docker.DefaultCgroupRoot, "location of the cgroup filesystem")
+	flag.BoolVar(&flags.probe.criEnabled, "probe.cri", false, "collect container attributes from a CRI runtime (eg. containerd, CRI-O) instead of Docker")
+	flag.StringVar(&flags.probe.criEndpoint, "probe.cri.endpoint", cri.DefaultEndpoint, "location of the CRI runtime's socket")
+	flag.DurationVar(&flags.probe.criInterval, "probe.cri.interval", 3*time.Second, "how often to poll the CRI runtime for containers")
+	flag.BoolVar(&flags.probe.systemdEnabled, "probe.systemd", false, "report the systemd units processes run in, with controls to start and stop them over D-Bus")
+	flag.BoolVar(&flags.probe.kmsgEnabled, "probe.kmsg", false, "follow the kernel log for OOM kills, hung tasks and segfaults of processes and containers")
+	flag.StringVar(&flags.probe.kmsgPath, "probe.kmsg.path", kmsg.DefaultPath, "kernel log to follow: /dev/kmsg, or a log file such as /var/log/kern.log")
+	flag.BoolVar(&flags.probe.cloudEnabled, "probe.cloud", false, "report the cloud provider, instance, region and zone of the host, from the cloud's metadata service")
+	flag.StringVar(&flags.probe.cloudEndpoint, "probe.cloud.metadata-endpoint", "", "address of the cloud metadata service, eg. http://169.254.169.254 (default each provider's own)")
+	flag.BoolVar(&flags.probe.netifEnabled, "probe.netif", true, "report the network interfaces and routes of the host, and the containers behind its veths")
+	flag.BoolVar(&flags.probe.kubernetesEnabled, "probe.kubernetes", false, "collect kubernetes-related attributes for containers, should only be enabled on the master node, unless leader election is enabled")
 	flag.StringVar(&flags.probe.kubernetesAPI, "probe.kubernetes.api", "", "Address of kubernetes master api")
 	flag.DurationVar(&flags.probe.kubernetesInterval, "probe.kubernetes.interval", 10*time.Second, "how often to do a full resync of the kubernetes data")
+	flag.BoolVar(&flags.probe.kubernetesLeaderElection, "probe.kubernetes.leader-election", false, "elect a single probe to report cluster-wide kubernetes objects, so kubernetes can be enabled on every probe")
+	flag.StringVar(&flags.probe.kubernetesLeaderNamespace, "probe.kubernetes.leader-election.namespace", "default", "namespace of the endpoints object holding the leader election lease")
+	flag.DurationVar(&flags.probe.kubernetesLeaderLease, "probe.kubernetes.leader-election.lease", 15*time.Second, "how long the leader holds the lease without renewing it")
 	flag.StringVar(&flags.probe.weaveAddr, "probe.weave.addr", "127.0.0.1:6784", "IP address & port of the Weave router")
 	flag.StringVar(&flags.probe.weaveHostname, "probe.weave.hostname", app.DefaultHostname, "Hostname to lookup in WeaveDNS")
 
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -24,20 +25,28 @@ import (
 	"$GITHUB_URI/common/xfer"
 	"$GITHUB_URI/probe"
 	"$GITHUB_URI/probe/appclient"
+	"$GITHUB_URI/probe/cloud"
 	"$GITHUB_URI/probe/controls"
+	"$GITHUB_URI/probe/cri"
 	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/probe/endpoint"
 	"$GITHUB_URI/probe/endpoint/procspy"
 	"$GITHUB_URI/probe/host"
+	"$GITHUB_URI/probe/kmsg"
 	"$GITHUB_URI/probe/kubernetes"
+	"$GITHUB_URI/probe/netif"
 	"$GITHUB_URI/probe/overlay"
 	"$GITHUB_URI/probe/plugins"
 	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/probe/systemd"
 	"$GITHUB_URI/report"
 )
 
 const (
 	versionCheckPeriod = 6 * time.Hour
+
+	// Name of the endpoints object holding the kubernetes leader election lease
+	kubernetesLeaderElectionName = "weave-scope-probe"
 )
 
 var pluginAPIVersion = "1"
@@ -124,6 +133,13 @@ func probeMain(flags probeFlags) {
 	defer resolver.Stop()
 
 	processCache := process.NewCachingWalker(process.NewWalker(flags.procRoot))
+	if flags.procConnector {
+		if connector, err := process.NewConnector(flags.procRoot, processCache); err == nil {
+			defer connector.Stop()
+		} else {
+			log.Errorf("Process: failed to listen to the proc connector: %v", err)
+		}
+	}
 	scanner := procspy.NewConnectionScanner(processCache)
 
 	endpointReporter := endpoint.NewReporter(hostID, hostName, flags.spyProcs, flags.useConntrack, scanner)
@@ -133,14 +149,24 @@ func probeMain(flags probeFlags) {
 	p.AddTicker(processCache)
 	hostReporter := host.NewReporter(hostID, hostName, probeID, version, clients)
 	defer hostReporter.Stop()
+	processReporter := process.NewReporter(processCache, hostID, probeID, flags.procRoot, process.GetDeltaTotalJiffies, clients)
+	defer processReporter.Stop()
 	p.AddReporter(
 		endpointReporter,
 		hostReporter,
-		process.NewReporter(processCache, hostID, process.GetDeltaTotalJiffies),
+		processReporter,
 	)
 	p.AddTagger(probe.NewTopologyTagger(), host.NewTagger(hostID))
 
-	if flags.dockerEnabled {
+	if flags.criEnabled {
+		if registry, err := cri.NewRegistry(flags.criEndpoint, flags.criInterval, true, hostID); err == nil {
+			defer registry.Stop()
+			p.AddTagger(docker.NewTagger(registry, processCache))
+			p.AddReporter(docker.NewReporter(registry, hostID, probeID, p))
+		} else {
+			log.Errorf("CRI: failed to start registry: %v", err)
+		}
+	} else if flags.dockerEnabled {
 		// Don't add the bridge in Kubernetes since container IPs are global and
 		// shouldn't be scoped
 		if !flags.kubernetesEnabled {
@@ -148,19 +174,87 @@ func probeMain(flags probeFlags) {
 				log.Errorf("Docker: problem with bridge %s: %v", flags.dockerBridge, err)
 			}
 		}
-		if registry, err := docker.NewRegistry(flags.dockerInterval, clients, true, hostID); err == nil {
+		var cgroups *docker.CgroupCollector
+		if flags.dockerCgroups {
+			if _, err := os.Stat(flags.cgroupRoot); err == nil {
+				cgroups = docker.NewCgroupCollector(flags.cgroupRoot, flags.procRoot)
+			} else {
+				log.Warnf("Docker: cgroup filesystem not found, falling back to the stats API: %v", err)
+			}
+		}
+		endpoints := flags.dockerEndpoints
+		if len(endpoints) == 0 {
+			endpoints = docker.Endpoints{{Address: docker.DefaultEndpoint}}
+		}
+		for _, endpoint := range endpoints {
+			registry, err := docker.NewRegistry(endpoint, flags.dockerInterval, clients, true, hostID, cgroups)
+			if err != nil {
+				log.Errorf("Docker: failed to start registry for %s: %v", endpoint, err)
+				continue
+			}
 			defer registry.Stop()
-			p.AddTagger(docker.NewTagger(registry, processCache))
-			p.AddReporter(docker.NewReporter(registry, hostID, probeID, p))
+			if endpoint.Remote() {
+				// The processes of remote containers aren't ours to tag
+				p.AddReporter(docker.NewRemoteReporter(registry, endpoint.HostID, probeID, p))
+			} else {
+				p.AddTagger(docker.NewTagger(registry, processCache))
+				p.AddReporter(docker.NewReporter(registry, hostID, probeID, p))
+			}
+			if client, err := docker.NewSwarmClient(endpoint); err == nil {
+				reporter := docker.NewSwarmReporter(client, probeID)
+				defer reporter.Stop()
+				p.AddReporter(reporter)
+			} else {
+				log.Errorf("Docker: failed to start swarm client for %s: %v", endpoint, err)
+			}
+		}
+	}
+
+	if flags.systemdEnabled {
+		// The root filesystem of the host, as seen by its init
+		root := path.Join(flags.procRoot, "1", "root")
+		client, err := systemd.NewClient(root)
+		if err != nil {
+			log.Warnf("Systemd: unit states and controls unavailable: %v", err)
+		}
+		reporter := systemd.NewReporter(processCache, client, root, hostID, probeID)
+		defer reporter.Stop()
+		p.AddReporter(reporter)
+		p.AddTagger(reporter)
+	}
+
+	if flags.kmsgEnabled {
+		if tagger, err := kmsg.NewTagger(hostID, flags.kmsgPath); err == nil {
+			defer tagger.Stop()
+			p.AddTagger(tagger)
 		} else {
-			log.Errorf("Docker: failed to start registry: %v", err)
+			log.Warnf("Kmsg: kernel events unavailable: %v", err)
 		}
 	}
 
+	if flags.cloudEnabled {
+		tagger := cloud.NewTagger(hostID, flags.cloudEndpoint)
+		defer tagger.Stop()
+		p.AddTagger(tagger)
+	}
+
+	if flags.netifEnabled {
+		reporter := netif.NewReporter(hostID, flags.sysRoot, flags.procRoot)
+		p.AddReporter(reporter)
+		p.AddTagger(reporter)
+	}
+
 	if flags.kubernetesEnabled {
 		if client, err := kubernetes.NewClient(flags.kubernetesAPI, flags.kubernetesInterval); err == nil {
 			defer client.Stop()
-			reporter := kubernetes.NewReporter(client, clients, probeID, hostID, p)
+			var leader kubernetes.Leader
+			if flags.kubernetesLeaderElection {
+				elector := kubernetes.NewLeaderElector(client, flags.kubernetesLeaderNamespace, kubernetesLeaderElectionName, probeID, flags.kubernetesLeaderLease)
+				elector.Start()
+				defer elector.Stop()
+				leader = elector
+			}
+			reporter := kubernetes.NewReporter(client, clients, probeID, hostID, p, leader)
 			defer reporter.Stop()
 			p.AddReporter(reporter)
 			p.AddTagger(reporter)
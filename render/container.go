@@ -49,7 +49,7 @@ var ContainerRenderer = MakeFilter(
 				),
 				MakeMap(
 					MapEndpoint2IP,
-					SelectEndpoint,
+					ServiceEndpointRenderer,
 				),
 			),
 		)),
@@ -93,14 +93,72 @@ var ContainerWithImageNameRenderer = ApplyDecorators(containerWithImageNameRende
 
 // ContainerImageRenderer is a Renderer which produces a renderable container
 // image graph by merging the container graph and the container image topology.
-var ContainerImageRenderer = FilterEmpty(report.Container,
+// Images whose name resolves to more than one version are marked as drifting.
+var ContainerImageRenderer = imageDriftRenderer{FilterEmpty(report.Container,
 	MakeReduce(
 		MakeMap(
 			MapContainer2ContainerImage,
-			ContainerWithImageNameRenderer,
+			ApplyDecorators(containerDriftRenderer{containerWithImageNameRenderer{ContainerRenderer}}),
 		),
 		SelectContainerImage,
 	),
+)}
+
+// DockerNetworkRenderer is a Renderer which produces a renderable docker
+// network graph by merging the container graph and the network topology.
+// Networks without containers are kept, as they are worth knowing about.
+var DockerNetworkRenderer = ApplyDecorators(
+	MakeReduce(
+		MakeMap(
+			Map2DockerNetwork,
+			ContainerWithImageNameRenderer,
+		),
+		SelectDockerNetwork,
+	),
+)
+
+// DockerVolumeRenderer is a Renderer which produces a renderable docker
+// volume graph by merging the container graph and the volume topology.
+var DockerVolumeRenderer = ApplyDecorators(
+	MakeReduce(
+		MakeMap(
+			Map2DockerVolume,
+			ContainerWithImageNameRenderer,
+		),
+		SelectDockerVolume,
+	),
+)
+
+// ComposeProjectRenderer is a Renderer which produces a renderable compose
+// project graph by merging the container graph and the projects topology.
+var ComposeProjectRenderer = FilterEmpty(report.Container,
+	MakeReduce(
+		MakeMap(
+			Map2ComposeProject,
+			ContainerWithImageNameRenderer,
+		),
+		SelectComposeProject,
+	),
+)
+
+// ComposeServiceRenderer is a Renderer which produces a renderable compose
+// service graph by merging the container graph and the services topology.
+var ComposeServiceRenderer = FilterEmpty(report.Container,
+	MakeReduce(
+		MakeMap(
+			Map2ComposeService,
+			ContainerWithImageNameRenderer,
+		),
+		SelectComposeService,
+	),
+)
+
+// The ways of grouping containers by what they share
+var (
+	Map2DockerNetwork  = Map2Parent(report.DockerNetwork)
+	Map2DockerVolume   = Map2Parent(report.DockerVolume)
+	Map2ComposeProject = Map2Parent(report.ComposeProject)
+	Map2ComposeService = Map2Parent(report.ComposeService)
 )
 
 // ContainerHostnameRenderer is a Renderer which produces a renderable container
@@ -138,3 +138,53 @@ func TestContainerImageFilterRenderer(t *testing.T) {
 		t.Error(test.Diff(want, have))
 	}
 }
+
+func TestDockerNetworkRenderer(t *testing.T) {
+	networkID := report.MakeDockerNetworkNodeID("net1")
+	unusedID := report.MakeDockerNetworkNodeID("net2")
+	input := fixture.Report.Copy()
+	input.DockerNetwork.AddNode(report.MakeNodeWith(networkID, map[string]string{
+		docker.NetworkName: "frontend",
+	}).WithTopology(report.DockerNetwork))
+	input.DockerNetwork.AddNode(report.MakeNodeWith(unusedID, map[string]string{
+		docker.NetworkName: "unused",
+	}).WithTopology(report.DockerNetwork))
+	for _, id := range []string{fixture.ClientContainerNodeID, fixture.ServerContainerNodeID} {
+		input.Container.Nodes[id] = input.Container.Nodes[id].
+			WithParents(report.EmptySets.Add(report.DockerNetwork, report.MakeStringSet(networkID)))
+	}
+
+	have := render.DockerNetworkRenderer.Render(input, render.FilterNoop)
+	network, ok := have[networkID]
+	if !ok {
+		t.Fatalf("Expected output to have network %q", networkID)
+	}
+	if containers, ok := network.Counters.Lookup(report.Container); !ok || containers != 2 {
+		t.Errorf("Expected network to have 2 containers, got %d", containers)
+	}
+	if _, ok := have[unusedID]; !ok {
+		t.Errorf("Expected output to have unused network %q", unusedID)
+	}
+}
+
+func TestComposeProjectRenderer(t *testing.T) {
+	projectID := docker.MakeComposeProjectNodeID(fixture.ClientHostID, "myapp")
+	input := fixture.Report.Copy()
+	input.ComposeProject.AddNode(report.MakeNodeWith(projectID, map[string]string{
+		docker.ComposeProject: "myapp",
+	}).WithTopology(report.ComposeProject))
+	input.Container.Nodes[fixture.ClientContainerNodeID] = input.Container.Nodes[fixture.ClientContainerNodeID].
+		WithParents(report.EmptySets.Add(report.ComposeProject, report.MakeStringSet(projectID)))
+
+	have := render.ComposeProjectRenderer.Render(input, render.FilterNoop)
+	project, ok := have[projectID]
+	if !ok {
+		t.Fatalf("Expected output to have project %q", projectID)
+	}
+	if containers, ok := project.Counters.Lookup(report.Container); !ok || containers != 1 {
+		t.Errorf("Expected project to have 1 container, got %d", containers)
+	}
+	if _, ok := project.Children.Lookup(fixture.ClientContainerNodeID); !ok {
+		t.Errorf("Expected project to have the client container as a child")
+	}
+}
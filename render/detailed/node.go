@@ -28,6 +28,7 @@ type ControlInstance struct {
 	ProbeID string
 	NodeID  string
 	Control report.Control
+	Args    map[string]string // sent with the request, if any
 }
 
 // MarshalJSON shouldn't be used, use CodecEncodeSelf instead
@@ -41,12 +42,13 @@ func (*ControlInstance) UnmarshalJSON(b []byte) error {
 }
 
 type wiredControlInstance struct {
-	ProbeID string `json:"probeId"`
-	NodeID  string `json:"nodeId"`
-	ID      string `json:"id"`
-	Human   string `json:"human"`
-	Icon    string `json:"icon"`
-	Rank    int    `json:"rank"`
+	ProbeID string            `json:"probeId"`
+	NodeID  string            `json:"nodeId"`
+	ID      string            `json:"id"`
+	Human   string            `json:"human"`
+	Icon    string            `json:"icon"`
+	Rank    int               `json:"rank"`
+	Args    map[string]string `json:"args,omitempty"`
 }
 
 // CodecEncodeSelf marshals this ControlInstance. It takes the basic Metric
@@ -59,6 +61,7 @@ func (c *ControlInstance) CodecEncodeSelf(encoder *codec.Encoder) {
 		Human:   c.Control.Human,
 		Icon:    c.Control.Icon,
 		Rank:    c.Control.Rank,
+		Args:    c.Args,
 	})
 }
 
@@ -75,6 +78,7 @@ func (c *ControlInstance) CodecDecodeSelf(decoder *codec.Decoder) {
 			Icon:  in.Icon,
 			Rank:  in.Rank,
 		},
+		Args: in.Args,
 	}
 }
 
@@ -101,6 +105,7 @@ func controlsFor(topology report.Topology, nodeID string) []ControlInstance {
 		return result
 	}
 
+	args := controlArgs(node)
 	for _, id := range node.Controls.Controls {
 		if control, ok := topology.Controls[id]; ok {
 			probeID, ok := node.Latest.Lookup(report.ControlProbeID)
@@ -111,12 +116,26 @@ func controlsFor(topology report.Topology, nodeID string) []ControlInstance {
 				ProbeID: probeID,
 				NodeID:  nodeID,
 				Control: control,
+				Args:    args,
 			})
 		}
 	}
 	return result
 }
 
+// controlArgs returns what probes need to be sent with the controls of a
+// node. Processes are only controlled if they are still the one which was
+// reported, and not a later one which reused their PID.
+func controlArgs(node report.Node) map[string]string {
+	if node.Topology != report.Process {
+		return nil
+	}
+	if startTime, ok := node.Latest.Lookup(process.StartTime); ok {
+		return map[string]string{process.StartTimeArg: startTime}
+	}
+	return nil
+}
+
 func controls(r report.Report, n report.Node) []ControlInstance {
 	if t, ok := r.Topology(n.Topology); ok {
 		return controlsFor(t, n.ID)
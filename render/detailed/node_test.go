@@ -245,6 +245,11 @@ func TestMakeDetailedContainerNode(t *testing.T) {
 				Label:      "pong-b",
 				TopologyID: "pods",
 			},
+			{
+				ID:         fixture.SwarmServiceNodeID,
+				Label:      fixture.SwarmServiceName,
+				TopologyID: "swarm-services",
+			},
 		},
 		Connections: []detailed.ConnectionsSummary{
 			{
@@ -425,3 +430,26 @@ func TestMakeDetailedPodNode(t *testing.T) {
 		t.Errorf("%s", test.Diff(want, have))
 	}
 }
+
+func TestMakeDetailedProcessNodeControls(t *testing.T) {
+	const startTime = "2017-07-14T02:40:00Z"
+	nodeID := report.MakeProcessNodeID("host1", "1234")
+	rpt := report.MakeReport()
+	rpt.Process.Controls.AddControls(process.Controls)
+	rpt.Process = rpt.Process.AddNode(report.MakeNodeWith(nodeID, map[string]string{
+		process.PID:           "1234",
+		process.StartTime:     startTime,
+		report.ControlProbeID: "probe1",
+	}).WithTopology(report.Process).WithControls(process.KillProcess))
+
+	have := detailed.MakeNode("processes", rpt, rpt.Process.Nodes, rpt.Process.Nodes[nodeID]).Controls
+	want := []detailed.ControlInstance{{
+		ProbeID: "probe1",
+		NodeID:  nodeID,
+		Control: rpt.Process.Controls[process.KillProcess],
+		Args:    map[string]string{process.StartTimeArg: startTime},
+	}}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected the controls to carry the start time of the process: %s", test.Diff(want, have))
+	}
+}
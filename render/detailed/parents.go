@@ -6,6 +6,7 @@ import (
 	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/probe/host"
 	"$GITHUB_URI/probe/kubernetes"
+	"$GITHUB_URI/probe/systemd"
 	"$GITHUB_URI/report"
 )
 
@@ -23,13 +24,24 @@ func Parents(r report.Report, n report.Node) (result []Parent) {
 		report.Topology
 		render func(report.Node) Parent
 	}{
-		report.Container:      {r.Container, containerParent},
-		report.Pod:            {r.Pod, podParent},
-		report.ReplicaSet:     {r.ReplicaSet, replicaSetParent},
-		report.Deployment:     {r.Deployment, deploymentParent},
-		report.Service:        {r.Service, serviceParent},
-		report.ContainerImage: {r.ContainerImage, containerImageParent},
-		report.Host:           {r.Host, hostParent},
+		report.Container:             {r.Container, containerParent},
+		report.Pod:                   {r.Pod, podParent},
+		report.ReplicaSet:            {r.ReplicaSet, replicaSetParent},
+		report.Deployment:            {r.Deployment, deploymentParent},
+		report.Service:               {r.Service, serviceParent},
+		report.PersistentVolume:      {r.PersistentVolume, persistentVolumeParent},
+		report.PersistentVolumeClaim: {r.PersistentVolumeClaim, persistentVolumeClaimParent},
+		report.StorageClass:          {r.StorageClass, storageClassParent},
+		report.ContainerImage:        {r.ContainerImage, containerImageParent},
+		report.DockerNetwork:         {r.DockerNetwork, dockerNetworkParent},
+		report.DockerVolume:          {r.DockerVolume, dockerVolumeParent},
+		report.SwarmService:          {r.SwarmService, latestParent(docker.SwarmServiceName, "swarm-services")},
+		report.SwarmTask:             {r.SwarmTask, latestParent(docker.SwarmTaskName, "swarm-tasks")},
+		report.SwarmNode:             {r.SwarmNode, latestParent(docker.SwarmNodeHostname, "swarm-nodes")},
+		report.ComposeProject:        {r.ComposeProject, latestParent(docker.ComposeProject, "containers-by-compose-project")},
+		report.ComposeService:        {r.ComposeService, latestParent(docker.ComposeService, "containers-by-compose-service")},
+		report.SystemdUnit:           {r.SystemdUnit, latestParent(systemd.UnitName, "processes-by-systemd-unit")},
+		report.Host:                  {r.Host, hostParent},
 	}
 	topologyIDs := []string{}
 	for topologyID := range topologies {
@@ -69,6 +81,10 @@ var (
 	replicaSetParent = kubernetesParent("replica-sets")
 	deploymentParent = kubernetesParent("deployments")
 	serviceParent    = kubernetesParent("services")
+
+	persistentVolumeParent      = kubernetesParent("persistent-volumes")
+	persistentVolumeClaimParent = kubernetesParent("persistent-volume-claims")
+	storageClassParent          = kubernetesParent("storage-classes")
 )
 
 func kubernetesParent(topology string) func(report.Node) Parent {
@@ -91,6 +107,36 @@ func containerImageParent(n report.Node) Parent {
 	}
 }
 
+func dockerNetworkParent(n report.Node) Parent {
+	name, _ := n.Latest.Lookup(docker.NetworkName)
+	return Parent{
+		ID:         n.ID,
+		Label:      name,
+		TopologyID: "containers-by-network",
+	}
+}
+
+func dockerVolumeParent(n report.Node) Parent {
+	name, _ := n.Latest.Lookup(docker.VolumeName)
+	return Parent{
+		ID:         n.ID,
+		Label:      name,
+		TopologyID: "containers-by-volume",
+	}
+}
+
+// latestParent makes parents labelled by the given latest key.
+func latestParent(labelKey, topology string) func(report.Node) Parent {
+	return func(n report.Node) Parent {
+		label, _ := n.Latest.Lookup(labelKey)
+		return Parent{
+			ID:         n.ID,
+			Label:      label,
+			TopologyID: topology,
+		}
+	}
+}
+
 func hostParent(n report.Node) Parent {
 	hostName, _ := n.Latest.Lookup(host.HostName)
 	return Parent{
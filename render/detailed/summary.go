@@ -8,7 +8,9 @@ import (
 	"$GITHUB_URI/probe/endpoint"
 	"$GITHUB_URI/probe/host"
 	"$GITHUB_URI/probe/kubernetes"
+	"$GITHUB_URI/probe/netif"
 	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/probe/systemd"
 	"$GITHUB_URI/render"
 	"$GITHUB_URI/report"
 )
@@ -72,15 +74,27 @@ type NodeSummary struct {
 // MakeNodeSummary summarizes a node, if possible.
 func MakeNodeSummary(r report.Report, n report.Node) (NodeSummary, bool) {
 	renderers := map[string]func(NodeSummary, report.Node) (NodeSummary, bool){
-		render.Pseudo:         pseudoNodeSummary,
-		report.Process:        processNodeSummary,
-		report.Container:      containerNodeSummary,
-		report.ContainerImage: containerImageNodeSummary,
-		report.Pod:            podNodeSummary,
-		report.Service:        serviceNodeSummary,
-		report.Deployment:     deploymentNodeSummary,
-		report.ReplicaSet:     replicaSetNodeSummary,
-		report.Host:           hostNodeSummary,
+		render.Pseudo:                pseudoNodeSummary,
+		report.Process:               processNodeSummary,
+		report.Container:             containerNodeSummary,
+		report.ContainerImage:        containerImageNodeSummary,
+		report.DockerNetwork:         dockerNetworkNodeSummary,
+		report.DockerVolume:          dockerVolumeNodeSummary,
+		report.SwarmService:          swarmServiceNodeSummary,
+		report.SwarmTask:             swarmTaskNodeSummary,
+		report.SwarmNode:             swarmNodeNodeSummary,
+		report.ComposeProject:        composeProjectNodeSummary,
+		report.ComposeService:        composeServiceNodeSummary,
+		report.SystemdUnit:           systemdUnitNodeSummary,
+		report.NetworkInterface:      networkInterfaceNodeSummary,
+		report.Pod:                   podNodeSummary,
+		report.Service:               serviceNodeSummary,
+		report.Deployment:            deploymentNodeSummary,
+		report.ReplicaSet:            replicaSetNodeSummary,
+		report.PersistentVolume:      persistentVolumeNodeSummary,
+		report.PersistentVolumeClaim: persistentVolumeClaimNodeSummary,
+		report.StorageClass:          storageClassNodeSummary,
+		report.Host:                  hostNodeSummary,
 	}
 	if renderer, ok := renderers[n.Topology]; ok {
 		return renderer(baseNodeSummary(r, n), n)
@@ -304,6 +318,139 @@ func replicaSetNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool)
 	return base, true
 }
 
+func dockerNetworkNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(docker.NetworkName)
+	base.Rank = base.Label
+	base.LabelMinor, _ = n.Latest.Lookup(docker.NetworkDriver)
+	base.Stack = true
+	return base, true
+}
+
+func dockerVolumeNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(docker.VolumeName)
+	base.Rank = base.Label
+	base.LabelMinor, _ = n.Latest.Lookup(docker.VolumeDriver)
+	base.Stack = true
+	return base, true
+}
+
+func swarmServiceNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(docker.SwarmServiceName)
+	base.Rank, _ = n.Latest.Lookup(docker.SwarmServiceID)
+	base.Stack = true
+
+	if c, ok := n.Counters.Lookup(report.Container); ok {
+		if c == 1 {
+			base.LabelMinor = fmt.Sprintf("%d container", c)
+		} else {
+			base.LabelMinor = fmt.Sprintf("%d containers", c)
+		}
+	}
+
+	return base, true
+}
+
+func swarmTaskNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(docker.SwarmTaskName)
+	base.Rank, _ = n.Latest.Lookup(docker.SwarmTaskID)
+	base.LabelMinor, _ = n.Latest.Lookup(docker.SwarmTaskState)
+	return base, true
+}
+
+func swarmNodeNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(docker.SwarmNodeHostname)
+	base.Rank, _ = n.Latest.Lookup(docker.SwarmNodeID)
+	base.LabelMinor, _ = n.Latest.Lookup(docker.SwarmNodeRole)
+	return base, true
+}
+
+func composeProjectNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(docker.ComposeProject)
+	base.Rank = base.Label
+	base.Stack = true
+
+	if c, ok := n.Counters.Lookup(report.Container); ok {
+		if c == 1 {
+			base.LabelMinor = fmt.Sprintf("%d container", c)
+		} else {
+			base.LabelMinor = fmt.Sprintf("%d containers", c)
+		}
+	}
+
+	return base, true
+}
+
+func composeServiceNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(docker.ComposeService)
+	base.Rank = base.Label
+	base.LabelMinor, _ = n.Latest.Lookup(docker.ComposeProject)
+	base.Stack = true
+	return base, true
+}
+
+func systemdUnitNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(systemd.UnitName)
+	base.Rank = base.Label
+	base.Stack = true
+
+	if c, ok := n.Counters.Lookup(report.Process); ok {
+		if c == 1 {
+			base.LabelMinor = fmt.Sprintf("%d process", c)
+		} else {
+			base.LabelMinor = fmt.Sprintf("%d processes", c)
+		}
+	} else {
+		base.LabelMinor, _ = n.Latest.Lookup(systemd.UnitSubState)
+	}
+
+	return base, true
+}
+
+func networkInterfaceNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	name, ok := n.Latest.Lookup(netif.Name)
+	if !ok {
+		return NodeSummary{}, false
+	}
+	base.Label = name
+	base.Rank = report.ExtractHostID(n) + "/" + name
+	if kind, ok := n.Latest.Lookup(netif.Kind); ok {
+		base.LabelMinor = fmt.Sprintf("%s (%s)", report.ExtractHostID(n), kind)
+	} else {
+		base.LabelMinor = report.ExtractHostID(n)
+	}
+	return base, true
+}
+
+func persistentVolumeNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(kubernetes.Name)
+	base.Rank, _ = n.Latest.Lookup(kubernetes.ID)
+	base.LabelMinor, _ = n.Latest.Lookup(kubernetes.Capacity)
+	return base, true
+}
+
+func persistentVolumeClaimNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(kubernetes.Name)
+	base.Rank, _ = n.Latest.Lookup(kubernetes.ID)
+	base.LabelMinor, _ = n.Latest.Lookup(kubernetes.Namespace)
+	return base, true
+}
+
+func storageClassNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
+	base.Label, _ = n.Latest.Lookup(kubernetes.Name)
+	base.Rank, _ = n.Latest.Lookup(kubernetes.Name)
+	base.Stack = true
+
+	if p, ok := n.Counters.Lookup(report.Pod); ok {
+		if p == 1 {
+			base.LabelMinor = fmt.Sprintf("%d pod", p)
+		} else {
+			base.LabelMinor = fmt.Sprintf("%d pods", p)
+		}
+	}
+
+	return base, true
+}
+
 func hostNodeSummary(base NodeSummary, n report.Node) (NodeSummary, bool) {
 	var (
 		hostname, _ = n.Latest.Lookup(host.HostName)
@@ -1,9 +1,42 @@
 package detailed
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"$GITHUB_URI/probe/endpoint"
+	"$GITHUB_URI/render"
 	"$GITHUB_URI/report"
 )
 
+// Table IDs of the tables computed from the children of a node.
+const (
+	// ImageDriftTableID is the ID of the table listing the containers of a
+	// grouped workload which run different versions of the same image.
+	ImageDriftTableID = "image_drift"
+	// ListeningPortsTableID is the ID of the table listing the sockets the
+	// processes of a container or pod listen on.
+	ListeningPortsTableID = "listening_ports"
+)
+
+// listeningTopologies are the topologies whose nodes are given the
+// listening ports of their processes.
+var listeningTopologies = map[string]struct{}{
+	report.Container: {},
+	report.Pod:       {},
+}
+
+// workloadTopologies are the topologies whose nodes are replicas of the same
+// containers, which should all run the same image.
+var workloadTopologies = map[string]struct{}{
+	report.Deployment:     {},
+	report.ReplicaSet:     {},
+	report.Service:        {},
+	report.SwarmService:   {},
+	report.ComposeService: {},
+}
+
 // NodeTables produces a list of tables (to be consumed directly by the UI) based
 // on the report and the node.  It uses the report to get the templates for the node's
 // topology.
@@ -13,8 +46,95 @@ func NodeTables(r report.Report, n report.Node) []report.Table {
 		return nil
 	}
 
+	var tables []report.Table
 	if topology, ok := r.Topology(n.Topology); ok {
-		return topology.TableTemplates.Tables(n)
+		tables = topology.TableTemplates.Tables(n)
+	}
+	if _, ok := workloadTopologies[n.Topology]; ok {
+		if table, ok := imageDriftTable(n); ok {
+			tables = append(tables, table)
+		}
+	}
+	if _, ok := listeningTopologies[n.Topology]; ok {
+		if table, ok := listeningPortsTable(n); ok {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// listeningPortsTable lists the sockets the processes of a node listen on,
+// with the protocols they are expected to speak.
+func listeningPortsTable(n report.Node) (report.Table, bool) {
+	protocols := map[string]string{}
+	n.Children.ForEach(func(child report.Node) {
+		if child.Topology != report.Process {
+			return
+		}
+		child.Latest.ForEach(func(key, value string) {
+			if strings.HasPrefix(key, endpoint.ListeningPortPrefix) {
+				protocols[strings.TrimPrefix(key, endpoint.ListeningPortPrefix)] = value
+			}
+		})
+	})
+	if len(protocols) == 0 {
+		return report.Table{}, false
+	}
+	sockets := make([]string, 0, len(protocols))
+	for socket := range protocols {
+		sockets = append(sockets, socket)
+	}
+	sort.Strings(sockets)
+
+	table := report.Table{ID: ListeningPortsTableID, Label: "Listening ports", Rows: []report.MetadataRow{}}
+	for _, socket := range sockets {
+		table.Rows = append(table.Rows, report.MetadataRow{
+			ID:    "label_" + socket,
+			Label: socket,
+			Value: protocols[socket],
+		})
+	}
+	return table, true
+}
+
+// imageDriftTable lists the versions each role of a workload's containers
+// runs, if they disagree.
+func imageDriftTable(n report.Node) (report.Table, bool) {
+	drift := render.WorkloadImageDrift(n)
+	if len(drift) == 0 {
+		return report.Table{}, false
+	}
+	roles := make([]string, 0, len(drift))
+	for role := range drift {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	table := report.Table{ID: ImageDriftTableID, Label: "Image Drift", Rows: []report.MetadataRow{}}
+	for _, role := range roles {
+		versions := make([]string, 0, len(drift[role]))
+		for version, count := range drift[role] {
+			versions = append(versions, fmt.Sprintf("%s (%d)", shortImageVersion(version), count))
+		}
+		sort.Strings(versions)
+		table.Rows = append(table.Rows, report.MetadataRow{
+			ID:    "label_" + role,
+			Label: role,
+			Value: strings.Join(versions, ", "),
+		})
+	}
+	return table, true
+}
+
+// shortImageVersion truncates a digest as the UI does.
+func shortImageVersion(version string) string {
+	const length = 12
+	prefix := ""
+	if i := strings.Index(version, ":"); i >= 0 {
+		prefix, version = version[:i+1], version[i+1:]
+	}
+	if len(version) > length {
+		version = version[:length]
 	}
-	return nil
+	return prefix + version
 }
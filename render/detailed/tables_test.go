@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/endpoint"
 	"$GITHUB_URI/render/detailed"
 	"$GITHUB_URI/report"
 	"$GITHUB_URI/test"
@@ -25,9 +26,10 @@ func TestNodeTables(t *testing.T) {
 					WithTableTemplates(docker.ContainerTableTemplates),
 			},
 			node: report.MakeNodeWith(fixture.ClientContainerNodeID, map[string]string{
-				docker.ContainerID:            fixture.ClientContainerID,
-				docker.LabelPrefix + "label1": "label1value",
-				docker.ContainerState:         docker.StateRunning,
+				docker.ContainerID:                                    fixture.ClientContainerID,
+				docker.LabelPrefix + "label1":                         "label1value",
+				docker.EventPrefix + "2016-09-14T10:00:00.000000000Z": "die (exit code 137, OOM killed)",
+				docker.ContainerState:                                 docker.StateRunning,
 			}).WithTopology(report.Container).WithSets(report.EmptySets.
 				Add(docker.ContainerIPs, report.MakeStringSet("10.10.10.0/24", "10.10.10.1/24")),
 			),
@@ -37,6 +39,17 @@ func TestNodeTables(t *testing.T) {
 					Label: "Environment Variables",
 					Rows:  []report.MetadataRow{},
 				},
+				{
+					ID:    docker.EventPrefix,
+					Label: "Events",
+					Rows: []report.MetadataRow{
+						{
+							ID:    "label_2016-09-14T10:00:00.000000000Z",
+							Label: "2016-09-14T10:00:00.000000000Z",
+							Value: "die (exit code 137, OOM killed)",
+						},
+					},
+				},
 				{
 					ID:    docker.LabelPrefix,
 					Label: "Docker Labels",
@@ -48,6 +61,77 @@ func TestNodeTables(t *testing.T) {
 						},
 					},
 				},
+				{
+					ID:    docker.NetworkIPPrefix,
+					Label: "Networks",
+					Rows:  []report.MetadataRow{},
+				},
+			},
+		},
+		{
+			name: "drifting deployment",
+			rpt:  report.MakeReport(),
+			node: report.MakeNode("deployment").WithTopology(report.Deployment).WithChildren(report.MakeNodeSet(
+				report.MakeNodeWith(fixture.ClientContainerNodeID, map[string]string{
+					docker.ContainerImageDigest: "sha256:fedcba9876543210",
+					docker.ImageName:            "client:latest",
+				}).WithTopology(report.Container),
+				report.MakeNodeWith(fixture.ServerContainerNodeID, map[string]string{
+					docker.ContainerImageDigest: "sha256:0123456789abcdef",
+					docker.ImageName:            "client:latest",
+				}).WithTopology(report.Container),
+			)),
+			want: []report.Table{
+				{
+					ID:    detailed.ImageDriftTableID,
+					Label: "Image Drift",
+					Rows: []report.MetadataRow{
+						{
+							ID:    "label_client",
+							Label: "client",
+							Value: "sha256:0123456789ab (1), sha256:fedcba987654 (1)",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "listening pod",
+			rpt:  report.MakeReport(),
+			node: report.MakeNode(fixture.ClientPodNodeID).WithTopology(report.Pod).WithChildren(report.MakeNodeSet(
+				report.MakeNodeWith(fixture.ClientContainerNodeID, map[string]string{
+					docker.ContainerID: fixture.ClientContainerID,
+				}).WithTopology(report.Container),
+				report.MakeNodeWith(fixture.ClientProcess1NodeID, map[string]string{
+					endpoint.ListeningPortPrefix + "tcp 0.0.0.0:5432":                   "postgres",
+					endpoint.ListeningPortPrefix + "unix /run/postgresql/.s.PGSQL.5432": "postgres",
+				}).WithTopology(report.Process),
+				report.MakeNodeWith(fixture.ClientProcess2NodeID, map[string]string{
+					endpoint.ListeningPortPrefix + "udp [::]:8125": endpoint.UnknownProtocol,
+				}).WithTopology(report.Process),
+			)),
+			want: []report.Table{
+				{
+					ID:    detailed.ListeningPortsTableID,
+					Label: "Listening ports",
+					Rows: []report.MetadataRow{
+						{
+							ID:    "label_tcp 0.0.0.0:5432",
+							Label: "tcp 0.0.0.0:5432",
+							Value: "postgres",
+						},
+						{
+							ID:    "label_udp [::]:8125",
+							Label: "udp [::]:8125",
+							Value: endpoint.UnknownProtocol,
+						},
+						{
+							ID:    "label_unix /run/postgresql/.s.PGSQL.5432",
+							Label: "unix /run/postgresql/.s.PGSQL.5432",
+							Value: "postgres",
+						},
+					},
+				},
 			},
 		},
 		{
@@ -0,0 +1,154 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/report"
+)
+
+// kubernetesContainerNameLabel tells apart the containers of a pod, so
+// sidecars aren't mistaken for replicas running a different image.
+const kubernetesContainerNameLabel = docker.LabelPrefix + "io.kubernetes.container.name"
+
+// ImageVersion returns what a container is really running: the digest of its
+// image. Images built or loaded locally have none, and their IDs can't be
+// compared with digests, so such containers are left out of drift.
+func ImageVersion(n report.Node) (string, bool) {
+	return n.Latest.Lookup(docker.ContainerImageDigest)
+}
+
+// IsImageDrifting checks if the node is a container or image whose image
+// name resolves to more than one version across the running containers.
+func IsImageDrifting(n report.Node) bool {
+	_, ok := n.Latest.Lookup(docker.ImageTagDigests)
+	return ok
+}
+
+// containerDriftRenderer sets docker.ImageTagDigests on the running
+// containers whose image name resolves to more than one version amongst
+// them. It's only used to build the by-image view, so other container-derived
+// topologies don't pay for it.
+type containerDriftRenderer struct {
+	Renderer
+}
+
+func (r containerDriftRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	containers := r.Renderer.Render(rpt, dct)
+
+	versions := map[string]map[string]struct{}{}
+	for _, c := range containers {
+		name, ok := c.Latest.Lookup(docker.ImageName)
+		if !ok || !IsRunning(c) {
+			continue
+		}
+		version, ok := ImageVersion(c)
+		if !ok {
+			continue
+		}
+		if versions[name] == nil {
+			versions[name] = map[string]struct{}{}
+		}
+		versions[name][version] = struct{}{}
+	}
+
+	output := make(report.Nodes, len(containers))
+	for id, c := range containers {
+		name, _ := c.Latest.Lookup(docker.ImageName)
+		if _, ok := ImageVersion(c); !ok {
+			output[id] = c
+			continue
+		}
+		if n := len(versions[name]); n > 1 && IsRunning(c) {
+			c = c.WithLatests(map[string]string{docker.ImageTagDigests: strconv.Itoa(n)})
+		}
+		output[id] = c
+	}
+	return output
+}
+
+// imageDriftRenderer adds a table to each image whose name resolves to more
+// than one version, counting the containers running each of them.
+type imageDriftRenderer struct {
+	Renderer
+}
+
+func (r imageDriftRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	images := r.Renderer.Render(rpt, dct)
+
+	counts := map[string]map[string]int{}
+	for _, image := range images {
+		image.Children.ForEach(func(child report.Node) {
+			if child.Topology != report.Container || !IsImageDrifting(child) {
+				return
+			}
+			name, _ := child.Latest.Lookup(docker.ImageName)
+			version, _ := ImageVersion(child)
+			if counts[name] == nil {
+				counts[name] = map[string]int{}
+			}
+			counts[name][version]++
+		})
+	}
+	if len(counts) == 0 {
+		return images
+	}
+
+	output := make(report.Nodes, len(images))
+	for id, image := range images {
+		name, _ := image.Latest.Lookup(docker.ImageName)
+		versions, ok := counts[name]
+		if !ok || len(versions) < 2 {
+			output[id] = image
+			continue
+		}
+		rows := map[string]string{}
+		for version, count := range versions {
+			rows[version] = containerCount(count)
+		}
+		output[id] = image.
+			WithLatests(map[string]string{docker.ImageTagDigests: strconv.Itoa(len(versions))}).
+			AddTable(docker.ImageDriftPrefix, rows)
+	}
+	return output
+}
+
+// WorkloadImageDrift returns, for each role of the containers in a grouped
+// workload (a deployment, a compose service etc.) whose members run more than
+// one version of its image, how many containers run each version. Roles are
+// the container names in a pod, or the image repository otherwise.
+func WorkloadImageDrift(n report.Node) map[string]map[string]int {
+	roles := map[string]map[string]int{}
+	n.Children.ForEach(func(child report.Node) {
+		if child.Topology != report.Container || !IsRunning(child) {
+			return
+		}
+		version, ok := ImageVersion(child)
+		if !ok {
+			return
+		}
+		role, ok := child.Latest.Lookup(kubernetesContainerNameLabel)
+		if !ok {
+			name, _ := child.Latest.Lookup(docker.ImageName)
+			role = docker.ImageNameWithoutVersion(name)
+		}
+		if roles[role] == nil {
+			roles[role] = map[string]int{}
+		}
+		roles[role][version]++
+	})
+	for role, versions := range roles {
+		if len(versions) < 2 {
+			delete(roles, role)
+		}
+	}
+	return roles
+}
+
+func containerCount(n int) string {
+	if n == 1 {
+		return "1 container"
+	}
+	return fmt.Sprintf("%d containers", n)
+}
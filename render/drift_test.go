@@ -0,0 +1,139 @@
+package render_test
+
+import (
+	"testing"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/render"
+	"$GITHUB_URI/report"
+	"$GITHUB_URI/test/reflect"
+)
+
+func driftReport() report.Report {
+	rpt := report.MakeReport()
+	for id, image := range map[string]struct{ id, digest string }{
+		"old1":  {"old", "old"},
+		"old2":  {"old", "old"},
+		"new":   {"new", "new"},
+		"other": {"other", "other"},
+		// Loaded rather than pulled, so without a digest
+		"loaded": {"loaded", ""},
+	} {
+		latests := map[string]string{
+			docker.ContainerID:    id,
+			docker.ContainerState: docker.StateRunning,
+			docker.ImageID:        image.id,
+		}
+		if image.digest != "" {
+			latests[docker.ContainerImageDigest] = image.digest
+		}
+		rpt.Container.AddNode(report.MakeNodeWith(report.MakeContainerNodeID(id), latests).WithTopology(report.Container))
+	}
+	for id, name := range map[string]string{
+		"old":    "nginx:latest",
+		"new":    "nginx:latest",
+		"other":  "redis:3",
+		"loaded": "nginx:latest",
+	} {
+		rpt.ContainerImage.AddNode(report.MakeNodeWith(report.MakeContainerImageNodeID(id), map[string]string{
+			docker.ImageID:   id,
+			docker.ImageName: name,
+		}).WithTopology(report.ContainerImage))
+	}
+	return rpt
+}
+
+func TestImageDrift(t *testing.T) {
+	images := render.ContainerImageRenderer.Render(driftReport(), nil)
+
+	for id, want := range map[string]map[string]string{
+		"old": {"old": "2 containers", "new": "1 container"},
+		"new": {"old": "2 containers", "new": "1 container"},
+	} {
+		node, ok := images[report.MakeContainerImageNodeID(id)]
+		if !ok {
+			t.Fatalf("Expected image %q", id)
+		}
+		if have, ok := node.Latest.Lookup(docker.ImageTagDigests); !ok || have != "2" {
+			t.Errorf("Expected image %q to resolve to 2 digests, got %q", id, have)
+		}
+		have, _ := node.ExtractTable(docker.ImageDriftPrefix)
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("Expected image %q drift %v, got %v", id, want, have)
+		}
+	}
+
+	other := images[report.MakeContainerImageNodeID("other")]
+	if render.IsImageDrifting(other) {
+		t.Errorf("Expected image %q not to be drifting", other.ID)
+	}
+}
+
+func TestImageDriftFilter(t *testing.T) {
+	drifting := func(r render.Renderer) render.Renderer {
+		return render.MakeFilter(render.IsImageDrifting, r)
+	}
+	images := render.ContainerImageRenderer.Render(driftReport(), drifting)
+	if _, ok := images[report.MakeContainerImageNodeID("other")]; ok {
+		t.Errorf("Expected image other to be filtered out")
+	}
+	if want, have := 2, len(images); want != have {
+		t.Errorf("Expected %d drifting images, got %d", want, have)
+	}
+}
+
+// Containers of images without a digest are left out, rather than having
+// their image ID compared with the digests of the others.
+func TestImageDriftWithoutDigests(t *testing.T) {
+	rpt := report.MakeReport()
+	for id, digest := range map[string]string{"pulled": "sha256:aaa", "loaded": ""} {
+		latests := map[string]string{
+			docker.ContainerID:    id,
+			docker.ContainerState: docker.StateRunning,
+			docker.ImageID:        id,
+		}
+		if digest != "" {
+			latests[docker.ContainerImageDigest] = digest
+		}
+		rpt.Container.AddNode(report.MakeNodeWith(report.MakeContainerNodeID(id), latests).WithTopology(report.Container))
+		rpt.ContainerImage.AddNode(report.MakeNodeWith(report.MakeContainerImageNodeID(id), map[string]string{
+			docker.ImageID:   id,
+			docker.ImageName: "nginx:latest",
+		}).WithTopology(report.ContainerImage))
+	}
+	for id, image := range render.ContainerImageRenderer.Render(rpt, nil) {
+		if render.IsImageDrifting(image) {
+			t.Errorf("Expected image %q not to be drifting", id)
+		}
+	}
+}
+
+func TestContainersNotMarkedDrifting(t *testing.T) {
+	for id, c := range render.ContainerWithImageNameRenderer.Render(driftReport(), nil) {
+		if render.IsImageDrifting(c) {
+			t.Errorf("Expected container %q not to be marked outside the by-image view", id)
+		}
+	}
+}
+
+func TestWorkloadImageDrift(t *testing.T) {
+	container := func(id, name, version string) report.Node {
+		return report.MakeNodeWith(report.MakeContainerNodeID(id), map[string]string{
+			docker.ContainerImageDigest:                         version,
+			docker.LabelPrefix + "io.kubernetes.container.name": name,
+		}).WithTopology(report.Container)
+	}
+	deployment := report.MakeNode("deployment").WithTopology(report.Deployment).WithChildren(report.MakeNodeSet(
+		container("a", "app", "sha256:aaa"),
+		container("b", "app", "sha256:bbb"),
+		container("c", "sidecar", "sha256:ccc"),
+		container("d", "sidecar", "sha256:ccc"),
+	))
+
+	want := map[string]map[string]int{
+		"app": {"sha256:aaa": 1, "sha256:bbb": 1},
+	}
+	if have := render.WorkloadImageDrift(deployment); !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected drift %v, got %v", want, have)
+	}
+}
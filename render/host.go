@@ -1,6 +1,7 @@
 package render
 
 import (
+	"$GITHUB_URI/probe/cloud"
 	"$GITHUB_URI/report"
 )
 
@@ -71,3 +72,75 @@ func MapEndpoint2Host(n report.Node, local report.Networks) report.Nodes {
 	result.Counters = result.Counters.Add(n.Topology, 1)
 	return report.Nodes{id: result}
 }
+
+// HostRegionRenderer is a Renderer which produces a renderable graph of the
+// cloud regions hosts are in.
+var HostRegionRenderer = MakeMap(
+	MapHost2Cloud(cloud.Region),
+	HostRenderer,
+)
+
+// HostZoneRenderer is a Renderer which produces a renderable graph of the
+// cloud availability zones hosts are in.
+var HostZoneRenderer = MakeMap(
+	MapHost2Cloud(cloud.Zone),
+	HostRenderer,
+)
+
+// MapHost2Cloud maps host Nodes to nodes grouping them by a key of their
+// cloud metadata, eg their region. Hosts without it, eg not in a cloud, are
+// dropped.
+func MapHost2Cloud(key string) MapFunc {
+	return func(n report.Node, _ report.Networks) report.Nodes {
+		// Propagate all pseudo nodes
+		if n.Topology == Pseudo {
+			return report.Nodes{n.ID: n}
+		}
+
+		id, timestamp, ok := n.Latest.LookupEntry(key)
+		if !ok {
+			return report.Nodes{}
+		}
+
+		node := NewDerivedNode(id, n).WithTopology(MakeGroupNodeTopology(n.Topology, key))
+		node.Latest = node.Latest.Set(key, timestamp, id)
+		node.Counters = node.Counters.Add(n.Topology, 1)
+		return report.Nodes{id: node}
+	}
+}
+
+// NetworkInterfaceRenderer is a Renderer which produces a renderable graph of
+// the network plumbing of hosts: their interfaces, linked to the bridges and
+// devices they hang off, and the containers linked to the veths they are
+// behind.
+var NetworkInterfaceRenderer = Memoise(networkInterfaceRenderer{SelectNetworkInterface})
+
+type networkInterfaceRenderer struct {
+	Renderer
+}
+
+// Render implements Renderer
+func (r networkInterfaceRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	interfaces := r.Renderer.Render(rpt, dct)
+	output := make(report.Nodes, len(interfaces))
+	for id, iface := range interfaces {
+		output[id] = iface
+	}
+	for id, iface := range interfaces {
+		containerIDs, ok := iface.Parents.Lookup(report.Container)
+		if !ok {
+			continue
+		}
+		for _, containerID := range containerIDs {
+			container, ok := output[containerID]
+			if !ok {
+				if container, ok = rpt.Container.Nodes[containerID]; !ok {
+					continue
+				}
+				container = container.WithTopology(report.Container)
+			}
+			output[containerID] = container.WithAdjacent(id)
+		}
+	}
+	return output
+}
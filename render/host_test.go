@@ -3,8 +3,11 @@ package render_test
 import (
 	"testing"
 
+	"$GITHUB_URI/probe/cloud"
+	"$GITHUB_URI/probe/netif"
 	"$GITHUB_URI/render"
 	"$GITHUB_URI/render/expected"
+	"$GITHUB_URI/report"
 	"$GITHUB_URI/test"
 	"$GITHUB_URI/test/fixture"
 	"$GITHUB_URI/test/reflect"
@@ -17,3 +20,59 @@ func TestHostRenderer(t *testing.T) {
 		t.Error(test.Diff(want, have))
 	}
 }
+
+func TestHostRegionRenderer(t *testing.T) {
+	input := fixture.Report.Copy()
+	input.Host.Nodes[fixture.ClientHostNodeID] = input.Host.Nodes[fixture.ClientHostNodeID].WithLatests(map[string]string{
+		cloud.Region: "eu-west-1",
+	})
+	have := render.HostRegionRenderer.Render(input, render.FilterNoop)
+
+	region, ok := have["eu-west-1"]
+	if !ok {
+		t.Fatalf("Expected a node for the region, got %v", have)
+	}
+	if want := render.MakeGroupNodeTopology(report.Host, cloud.Region); region.Topology != want {
+		t.Errorf("Expected topology %q, got %q", want, region.Topology)
+	}
+	if count, _ := region.Counters.Lookup(report.Host); count != 1 {
+		t.Errorf("Expected 1 host in the region, got %d", count)
+	}
+	if _, ok := region.Children.Lookup(fixture.ClientHostNodeID); !ok {
+		t.Errorf("Expected the client host in the region, got %v", region.Children)
+	}
+	for id, node := range have {
+		if node.Topology != render.Pseudo && id != "eu-west-1" {
+			t.Errorf("Expected hosts without a region to be dropped, got %s", id)
+		}
+	}
+}
+
+func TestNetworkInterfaceRenderer(t *testing.T) {
+	var (
+		rpt         = report.MakeReport()
+		bridgeID    = netif.MakeInterfaceNodeID("host", "docker0")
+		vethID      = netif.MakeInterfaceNodeID("host", "veth1a2b3c")
+		containerID = report.MakeContainerNodeID("abc")
+	)
+	rpt.NetworkInterface.AddNode(report.MakeNodeWith(bridgeID, map[string]string{
+		netif.Name: "docker0",
+	}).WithTopology(report.NetworkInterface))
+	rpt.NetworkInterface.AddNode(report.MakeNodeWith(vethID, map[string]string{
+		netif.Name: "veth1a2b3c",
+	}).WithTopology(report.NetworkInterface).WithAdjacent(bridgeID).WithParents(report.EmptySets.
+		Add(report.Container, report.MakeStringSet(containerID, report.MakeContainerNodeID("gone"))),
+	))
+	rpt.Container.AddNode(report.MakeNode(containerID).WithTopology(report.Container))
+
+	have := render.NetworkInterfaceRenderer.Render(rpt, nil)
+	if len(have) != 3 {
+		t.Fatalf("Expected the interfaces and the container, got %v", have)
+	}
+	if want := report.MakeIDList(bridgeID); !reflect.DeepEqual(want, have[vethID].Adjacency) {
+		t.Errorf("Expected the veth linked to the bridge, got %v", have[vethID].Adjacency)
+	}
+	if want := report.MakeIDList(vethID); !reflect.DeepEqual(want, have[containerID].Adjacency) {
+		t.Errorf("Expected the container linked to its veth, got %v", have[containerID].Adjacency)
+	}
+}
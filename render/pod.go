@@ -1,9 +1,11 @@
 package render
 
 import (
+	"path"
 	"strings"
 
 	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/host"
 	"$GITHUB_URI/probe/kubernetes"
 	"$GITHUB_URI/report"
 )
@@ -21,7 +23,7 @@ func renderKubernetesTopologies(rpt report.Report) bool {
 // PodRenderer is a Renderer which produces a renderable kubernetes
 // graph by merging the container graph and the pods topology.
 var PodRenderer = ConditionalRenderer(renderKubernetesTopologies,
-	ApplyDecorators(MakeFilter(
+	ApplyDecorators(resourceUsageRenderer{MakeFilter(
 		func(n report.Node) bool {
 			state, ok := n.Latest.Lookup(kubernetes.State)
 			return (!ok || state != kubernetes.StateDeleted)
@@ -33,7 +35,7 @@ var PodRenderer = ConditionalRenderer(renderKubernetesTopologies,
 			),
 			SelectPod,
 		),
-	)),
+	)}),
 )
 
 // PodServiceRenderer is a Renderer which produces a renderable kubernetes services
@@ -53,7 +55,7 @@ var PodServiceRenderer = ConditionalRenderer(renderKubernetesTopologies,
 // DeploymentRenderer is a Renderer which produces a renderable kubernetes deployments
 // graph by merging the pods graph and the deployments topology.
 var DeploymentRenderer = ConditionalRenderer(renderKubernetesTopologies,
-	ApplyDecorators(
+	ApplyDecorators(resourceUsageRenderer{
 		MakeReduce(
 			MakeMap(
 				Map2Deployment,
@@ -61,13 +63,13 @@ var DeploymentRenderer = ConditionalRenderer(renderKubernetesTopologies,
 			),
 			SelectDeployment,
 		),
-	),
+	}),
 )
 
 // ReplicaSetRenderer is a Renderer which produces a renderable kubernetes replica sets
 // graph by merging the pods graph and the replica sets topology.
 var ReplicaSetRenderer = ConditionalRenderer(renderKubernetesTopologies,
-	ApplyDecorators(
+	ApplyDecorators(resourceUsageRenderer{
 		MakeReduce(
 			MakeMap(
 				Map2ReplicaSet,
@@ -75,9 +77,92 @@ var ReplicaSetRenderer = ConditionalRenderer(renderKubernetesTopologies,
 			),
 			SelectReplicaSet,
 		),
+	}),
+)
+
+// PersistentVolumeRenderer is a Renderer which produces a renderable kubernetes
+// persistent volumes graph by merging the pods graph and the volumes topology.
+// Volumes are linked to the hosts they are mounted on.
+var PersistentVolumeRenderer = ConditionalRenderer(renderKubernetesTopologies,
+	ApplyDecorators(volumeHostRenderer{
+		MakeReduce(
+			MakeMap(
+				Map2PersistentVolume,
+				PodRenderer,
+			),
+			SelectPersistentVolume,
+		),
+	}),
+)
+
+// PersistentVolumeClaimRenderer is a Renderer which produces a renderable kubernetes
+// persistent volume claims graph by merging the pods graph and the claims topology.
+var PersistentVolumeClaimRenderer = ConditionalRenderer(renderKubernetesTopologies,
+	ApplyDecorators(
+		MakeReduce(
+			MakeMap(
+				Map2PersistentVolumeClaim,
+				PodRenderer,
+			),
+			SelectPersistentVolumeClaim,
+		),
 	),
 )
 
+// StorageClassRenderer is a Renderer which produces a renderable kubernetes
+// storage classes graph by merging the pods graph and the storage classes topology.
+var StorageClassRenderer = ConditionalRenderer(renderKubernetesTopologies,
+	ApplyDecorators(
+		MakeReduce(
+			MakeMap(
+				Map2StorageClass,
+				PodRenderer,
+			),
+			SelectStorageClass,
+		),
+	),
+)
+
+// volumeHostRenderer adds the hosts a persistent volume is mounted on as
+// parents of the volume, by finding the kubelet's mount points for it in the
+// mounts of each host.
+type volumeHostRenderer struct {
+	Renderer
+}
+
+// Render implements Renderer
+func (r volumeHostRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	volumes := r.Renderer.Render(rpt, dct)
+
+	// The kubelet mounts volumes at
+	// /var/lib/kubelet/pods/<pod uid>/volumes/<plugin>/<volume name>
+	hosts := map[string]report.StringSet{} // volume name -> host node IDs
+	for id, n := range rpt.Host.Nodes {
+		mounts, _ := n.Sets.Lookup(host.Mounts)
+		for _, mount := range mounts {
+			if path.Base(path.Dir(path.Dir(mount))) != "volumes" {
+				continue
+			}
+			name := path.Base(mount)
+			hosts[name] = hosts[name].Add(id)
+		}
+	}
+	if len(hosts) == 0 {
+		return volumes
+	}
+
+	outputs := report.Nodes{}
+	for id, volume := range volumes {
+		outputs[id] = volume
+		name, ok := volume.Latest.Lookup(kubernetes.Name)
+		if !ok || len(hosts[name]) == 0 || volume.Topology != report.PersistentVolume {
+			continue
+		}
+		outputs[id] = volume.WithParents(report.EmptySets.Add(report.Host, hosts[name]))
+	}
+	return outputs
+}
+
 // MapContainer2Pod maps container Nodes to pod
 // Nodes.
 //
@@ -128,6 +213,10 @@ var (
 	Map2Service    = Map2Parent(report.Service)
 	Map2Deployment = Map2Parent(report.Deployment)
 	Map2ReplicaSet = Map2Parent(report.ReplicaSet)
+
+	Map2PersistentVolume      = Map2Parent(report.PersistentVolume)
+	Map2PersistentVolumeClaim = Map2Parent(report.PersistentVolumeClaim)
+	Map2StorageClass          = Map2Parent(report.StorageClass)
 )
 
 // Map2Parent maps Nodes to some parent grouping.
@@ -155,3 +244,115 @@ func Map2Parent(topology string) func(n report.Node, _ report.Networks) report.N
 		return result
 	}
 }
+
+// ServiceEndpointRenderer is a Renderer which produces the endpoint graph,
+// with connections to kubernetes service IPs attributed to the endpoints of
+// the ready pods backing each service.
+var ServiceEndpointRenderer = Memoise(serviceEndpointRenderer{SelectEndpoint})
+
+type serviceEndpointRenderer struct {
+	Renderer
+}
+
+// Render implements Renderer
+func (r serviceEndpointRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	endpoints := r.Renderer.Render(rpt, dct)
+	backends := serviceBackends(rpt.Service.Nodes, endpoints)
+	if len(backends) == 0 {
+		return endpoints
+	}
+
+	output := report.Nodes{}
+	for id, n := range endpoints {
+		if _, ok := backends[id]; ok {
+			continue
+		}
+		rewritten := false
+		adjacency := report.MakeIDList()
+		for _, dstID := range n.Adjacency {
+			if backendIDs, ok := backends[dstID]; ok {
+				adjacency = adjacency.Merge(backendIDs)
+				rewritten = true
+			} else {
+				adjacency = adjacency.Add(dstID)
+			}
+		}
+		if rewritten {
+			n = n.Copy()
+			n.Adjacency = adjacency
+		}
+		output[id] = n
+	}
+
+	// Connections recorded from the service IP's side move to its backends
+	for id, backendIDs := range backends {
+		adjacency := endpoints[id].Adjacency
+		if len(adjacency) == 0 {
+			continue
+		}
+		for _, backendID := range backendIDs {
+			if backend, ok := output[backendID]; ok {
+				output[backendID] = backend.WithAdjacent(adjacency...)
+			}
+		}
+	}
+	return output
+}
+
+// serviceBackends maps the endpoint node IDs of service IPs to the endpoint
+// node IDs of the ready addresses backing the service, on the ports the
+// service targets.
+func serviceBackends(services report.Nodes, endpoints report.Nodes) map[string]report.IDList {
+	type backend struct {
+		addresses, ports report.StringSet
+	}
+	var (
+		serviceIPs = map[string]backend{}
+		byAddress  = map[string][]string{} // address -> endpoint node IDs
+		result     = map[string]report.IDList{}
+	)
+	for _, service := range services {
+		ip, ok := service.Latest.Lookup(kubernetes.IP)
+		if !ok || ip == "" || ip == "None" {
+			continue
+		}
+		addresses, ok := service.Sets.Lookup(kubernetes.ReadyAddresses)
+		if !ok || len(addresses) == 0 {
+			continue
+		}
+		ports, _ := service.Sets.Lookup(kubernetes.EndpointPorts)
+		serviceIPs[ip] = backend{addresses, ports}
+	}
+	if len(serviceIPs) == 0 {
+		return result
+	}
+
+	for id := range endpoints {
+		if _, address, _, ok := report.ParseEndpointNodeID(id); ok {
+			byAddress[address] = append(byAddress[address], id)
+		}
+	}
+	for id := range endpoints {
+		_, address, _, ok := report.ParseEndpointNodeID(id)
+		if !ok {
+			continue
+		}
+		b, ok := serviceIPs[address]
+		if !ok {
+			continue
+		}
+		backendIDs := report.MakeIDList()
+		for _, backendAddress := range b.addresses {
+			for _, backendID := range byAddress[backendAddress] {
+				_, _, port, _ := report.ParseEndpointNodeID(backendID)
+				if len(b.ports) == 0 || b.ports.Contains(port) {
+					backendIDs = backendIDs.Add(backendID)
+				}
+			}
+		}
+		if len(backendIDs) > 0 {
+			result[id] = backendIDs
+		}
+	}
+	return result
+}
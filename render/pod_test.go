@@ -3,9 +3,12 @@ package render_test
 import (
 	"testing"
 
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/host"
 	"$GITHUB_URI/probe/kubernetes"
 	"$GITHUB_URI/render"
 	"$GITHUB_URI/render/expected"
+	"$GITHUB_URI/report"
 	"$GITHUB_URI/test"
 	"$GITHUB_URI/test/fixture"
 	"$GITHUB_URI/test/reflect"
@@ -38,6 +41,61 @@ func TestPodFilterRenderer(t *testing.T) {
 	}
 }
 
+func TestPodResourceUsage(t *testing.T) {
+	input := fixture.Report.Copy()
+	input.Pod.Nodes[fixture.ClientPodNodeID] = input.Pod.Nodes[fixture.ClientPodNodeID].WithLatests(map[string]string{
+		kubernetes.CPURequest:    "10",
+		kubernetes.MemoryRequest: "1",
+	})
+	pod, ok := render.PodRenderer.Render(input, nil)[fixture.ClientPodNodeID]
+	if !ok {
+		t.Fatalf("Expected output to have pod %q", fixture.ClientPodNodeID)
+	}
+	for key, want := range map[string]float64{
+		docker.CPUTotalUsage:          fixture.ClientContainerCPUMetric.LastSample().Value,
+		docker.MemoryUsage:            fixture.ClientContainerMemoryMetric.LastSample().Value,
+		kubernetes.CPURequestUsage:    3,
+		kubernetes.MemoryRequestUsage: 4,
+	} {
+		metric, ok := pod.Metrics.Lookup(key)
+		if !ok {
+			t.Errorf("Expected pod to have metric %q", key)
+			continue
+		}
+		if have := metric.LastSample().Value; have < want-1e-9 || have > want+1e-9 {
+			t.Errorf("Expected pod metric %q to be %v, got %v", key, want, have)
+		}
+	}
+	for _, key := range []string{kubernetes.CPULimitUsage, kubernetes.MemoryLimitUsage} {
+		if _, ok := pod.Metrics.Lookup(key); ok {
+			t.Errorf("Expected pod to have no metric %q", key)
+		}
+	}
+}
+
+func TestPersistentVolumeRenderer(t *testing.T) {
+	volumeID := report.MakePersistentVolumeNodeID("volume1234")
+	input := fixture.Report.Copy()
+	input.PersistentVolume.AddNode(report.MakeNodeWith(volumeID, map[string]string{
+		kubernetes.Name: "pv1",
+	}).WithTopology(report.PersistentVolume))
+	input.Pod.Nodes[fixture.ClientPodNodeID] = input.Pod.Nodes[fixture.ClientPodNodeID].
+		WithParents(report.EmptySets.Add(report.PersistentVolume, report.MakeStringSet(volumeID)))
+	input.Host.Nodes[fixture.ClientHostNodeID] = input.Host.Nodes[fixture.ClientHostNodeID].
+		WithSet(host.Mounts, report.MakeStringSet("/var/lib/kubelet/pods/"+fixture.ClientPodUID+"/volumes/kubernetes.io~nfs/pv1"))
+
+	volume, ok := render.PersistentVolumeRenderer.Render(input, nil)[volumeID]
+	if !ok {
+		t.Fatalf("Expected output to have volume %q", volumeID)
+	}
+	if pods, ok := volume.Counters.Lookup(report.Pod); !ok || pods != 1 {
+		t.Errorf("Expected volume to have 1 pod, got %d", pods)
+	}
+	if hosts, ok := volume.Parents.Lookup(report.Host); !ok || !hosts.Contains(fixture.ClientHostNodeID) {
+		t.Errorf("Expected volume to have host parent %q, got %v", fixture.ClientHostNodeID, hosts)
+	}
+}
+
 func TestPodServiceRenderer(t *testing.T) {
 	have := Prune(render.PodServiceRenderer.Render(fixture.Report, nil))
 	want := Prune(expected.RenderedPodServices)
@@ -61,3 +119,34 @@ func TestPodServiceFilterRenderer(t *testing.T) {
 		t.Error(test.Diff(want, have))
 	}
 }
+
+func TestServiceEndpointRenderer(t *testing.T) {
+	var (
+		clientID   = report.MakeEndpointNodeID("", "10.1.2.2", "34567")
+		serviceIP  = report.MakeEndpointNodeID("", "10.0.0.1", "80")
+		backend1ID = report.MakeEndpointNodeID("", "10.1.1.1", "8080")
+		backend2ID = report.MakeEndpointNodeID("", "10.1.1.2", "8080")
+		notReadyID = report.MakeEndpointNodeID("", "10.1.1.3", "8080")
+		otherPort  = report.MakeEndpointNodeID("", "10.1.1.1", "9090")
+	)
+	rpt := report.MakeReport()
+	rpt.Endpoint.AddNode(report.MakeNode(clientID).WithTopology(report.Endpoint).WithAdjacent(serviceIP))
+	for _, id := range []string{serviceIP, backend1ID, backend2ID, notReadyID, otherPort} {
+		rpt.Endpoint.AddNode(report.MakeNode(id).WithTopology(report.Endpoint))
+	}
+	rpt.Service.AddNode(report.MakeNodeWith(report.MakeServiceNodeID("service"), map[string]string{
+		kubernetes.IP: "10.0.0.1",
+	}).WithSets(report.EmptySets.
+		Add(kubernetes.ReadyAddresses, report.MakeStringSet("10.1.1.1", "10.1.1.2")).
+		Add(kubernetes.NotReadyAddresses, report.MakeStringSet("10.1.1.3")).
+		Add(kubernetes.EndpointPorts, report.MakeStringSet("8080")),
+	))
+
+	have := render.ServiceEndpointRenderer.Render(rpt, nil)
+	if _, ok := have[serviceIP]; ok {
+		t.Errorf("Expected service IP endpoint %q to be removed", serviceIP)
+	}
+	if want := report.MakeIDList(backend1ID, backend2ID); !reflect.DeepEqual(want, have[clientID].Adjacency) {
+		t.Error(test.Diff(want, have[clientID].Adjacency))
+	}
+}
@@ -2,10 +2,13 @@ package render
 
 import (
 	"net"
+	"path"
+	"strings"
 
 	"$GITHUB_URI/probe/docker"
 	"$GITHUB_URI/probe/endpoint"
 	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/probe/systemd"
 	"$GITHUB_URI/report"
 )
 
@@ -24,7 +27,7 @@ const (
 )
 
 // EndpointRenderer is a Renderer which produces a renderable endpoint graph.
-var EndpointRenderer = FilterNonProcspied(SelectEndpoint)
+var EndpointRenderer = FilterNonProcspied(ServiceEndpointRenderer)
 
 // ProcessRenderer is a Renderer which produces a renderable process
 // graph by merging the endpoint graph and the process topology.
@@ -78,6 +81,178 @@ var ProcessNameRenderer = MakeMap(
 	ProcessRenderer,
 )
 
+// ProcessTreeRenderer is a Renderer which produces a process graph where the
+// edges go from parents to their children, rather than along connections.
+// Every process is also given the CPU and memory usage of its subtree.
+var ProcessTreeRenderer = processTreeRenderer{ApplyDecorators(processWithContainerNameRenderer{SelectProcess})}
+
+type processTreeRenderer struct {
+	Renderer
+}
+
+// Render links each process to its nearest ancestor amongst those rendered, so
+// that filtering a process out (e.g. collapsing supervisors) hands its
+// children over to its own parent.
+func (r processTreeRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	processes := r.Renderer.Render(rpt, dct)
+	all := SelectProcess.Render(rpt, nil)
+
+	parents := map[string]string{}
+	for id, p := range all {
+		hostID, _, ok := report.ParseNodeID(id)
+		if !ok {
+			continue
+		}
+		if ppid, ok := p.Latest.Lookup(process.PPID); ok {
+			parents[id] = report.MakeProcessNodeID(hostID, ppid)
+		}
+	}
+	// ancestors calls f on id and each of its ancestors, bottom up, until f
+	// returns false. A reused PID can make a loop, hence the bound.
+	ancestors := func(id string, f func(string) bool) {
+		for depth := 0; id != "" && depth <= len(all) && f(id); depth++ {
+			id = parents[id]
+		}
+	}
+
+	subtreeCPU, subtreeMemory := map[string]float64{}, map[string]float64{}
+	for id, p := range all {
+		cpu, memory := lastValue(p, process.CPUUsage), lastValue(p, process.MemoryUsage)
+		ancestors(id, func(ancestor string) bool {
+			if _, ok := all[ancestor]; !ok {
+				return false
+			}
+			subtreeCPU[ancestor] += cpu
+			subtreeMemory[ancestor] += memory
+			return true
+		})
+	}
+
+	output := make(report.Nodes, len(processes))
+	for id, p := range processes {
+		p = p.Copy()
+		p.Adjacency = report.MakeIDList()
+		p = withSubtreeMetric(p, process.CPUUsage, process.SubtreeCPUUsage, subtreeCPU[id])
+		p = withSubtreeMetric(p, process.MemoryUsage, process.SubtreeMemoryUsage, subtreeMemory[id])
+		output[id] = p
+	}
+	for id := range processes {
+		ancestors(parents[id], func(ancestor string) bool {
+			parent, ok := output[ancestor]
+			if ok {
+				parent.Adjacency = parent.Adjacency.Add(id)
+				output[ancestor] = parent
+			}
+			return !ok
+		})
+	}
+	return output
+}
+
+func lastValue(n report.Node, key string) float64 {
+	if metric, ok := n.Metrics.Lookup(key); ok {
+		if sample := metric.LastSample(); sample != nil {
+			return sample.Value
+		}
+	}
+	return 0
+}
+
+// withSubtreeMetric sets the subtree version of a process metric, sampled
+// when and bounded like the process' own.
+func withSubtreeMetric(n report.Node, key, subtreeKey string, value float64) report.Node {
+	metric, ok := n.Metrics.Lookup(key)
+	if !ok {
+		return n
+	}
+	sample := metric.LastSample()
+	if sample == nil {
+		return n
+	}
+	return n.WithMetric(subtreeKey, report.MakeMetric().Add(sample.Timestamp, value).WithMax(metric.Max))
+}
+
+// supervisors are the names of the processes whose job is to start others and
+// watch over them, including the inits of containers.
+var supervisors = map[string]struct{}{
+	"init":                    {},
+	"systemd":                 {},
+	"upstart":                 {},
+	"supervisord":             {},
+	"runsvdir":                {},
+	"runsv":                   {},
+	"s6-svscan":               {},
+	"s6-supervise":            {},
+	"tini":                    {},
+	"dumb-init":               {},
+	"containerd-shim":         {},
+	"docker-containerd-shim":  {},
+	"containerd-shim-runc-v2": {},
+}
+
+// IsSupervisor checks if the node is a supervisor process, e.g. systemd or
+// supervisord. Interpreted supervisors are told by their script.
+func IsSupervisor(n report.Node) bool {
+	name, _ := n.Latest.Lookup(process.Name)
+	if _, ok := supervisors[path.Base(name)]; ok {
+		return true
+	}
+	cmdline, _ := n.Latest.Lookup(process.Cmdline)
+	if args := strings.Fields(cmdline); len(args) > 1 {
+		_, ok := supervisors[path.Base(args[1])]
+		return ok
+	}
+	return false
+}
+
+// SystemdUnitRenderer is a Renderer which produces a renderable systemd unit
+// graph by merging the process graph and the units topology. Every unit is
+// also given the CPU and memory usage of its processes.
+var SystemdUnitRenderer = systemdUnitRenderer{FilterEmpty(report.Process,
+	MakeReduce(
+		MakeMap(
+			Map2SystemdUnit,
+			ProcessRenderer,
+		),
+		SelectSystemdUnit,
+	),
+)}
+
+// Map2SystemdUnit maps processes to the units they run in.
+var Map2SystemdUnit = Map2Parent(report.SystemdUnit)
+
+type systemdUnitRenderer struct {
+	Renderer
+}
+
+func (r systemdUnitRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	units := r.Renderer.Render(rpt, dct)
+	output := make(report.Nodes, len(units))
+	for id, unit := range units {
+		if unit.Topology == report.SystemdUnit {
+			unit = withChildrenMetric(unit, process.CPUUsage, systemd.CPUUsage)
+			unit = withChildrenMetric(unit, process.MemoryUsage, systemd.MemoryUsage)
+		}
+		output[id] = unit
+	}
+	return output
+}
+
+// withChildrenMetric sets key on n to the sum of childKey over its process
+// children.
+func withChildrenMetric(n report.Node, childKey, key string) report.Node {
+	processes := []report.Node{}
+	n.Children.ForEach(func(child report.Node) {
+		if child.Topology == report.Process {
+			processes = append(processes, child)
+		}
+	})
+	if metric, ok := sumMetric(processes, childKey); ok {
+		return n.WithMetric(key, metric)
+	}
+	return n
+}
+
 // MapEndpoint2Pseudo makes internet of host pesudo nodes from a endpoint node.
 func MapEndpoint2Pseudo(n report.Node, local report.Networks) report.Nodes {
 	var node report.Node
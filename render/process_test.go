@@ -1,10 +1,15 @@
 package render_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"$GITHUB_URI/probe/process"
+	"$GITHUB_URI/probe/systemd"
 	"$GITHUB_URI/render"
 	"$GITHUB_URI/render/expected"
+	"$GITHUB_URI/report"
 	"$GITHUB_URI/test"
 	"$GITHUB_URI/test/fixture"
 	"$GITHUB_URI/test/reflect"
@@ -33,3 +38,102 @@ func TestProcessNameRenderer(t *testing.T) {
 		t.Error(test.Diff(want, have))
 	}
 }
+
+func processTreeReport() report.Report {
+	rpt := report.MakeReport()
+	now := time.Now()
+	for _, p := range []struct {
+		pid, ppid, cmdline string
+		cpu, memory        float64
+	}{
+		{"1", "0", "/sbin/init", 1, 10},
+		{"2", "1", "bash", 1, 10},
+		{"5", "2", "python /usr/bin/supervisord", 1, 10},
+		{"20", "5", "nginx master", 2, 20},
+		{"21", "20", "nginx worker", 30, 300},
+		{"22", "20", "nginx worker", 40, 400},
+	} {
+		rpt.Process.AddNode(report.MakeNodeWith(report.MakeProcessNodeID("host", p.pid), map[string]string{
+			process.PID:     p.pid,
+			process.PPID:    p.ppid,
+			process.Name:    strings.Fields(p.cmdline)[0],
+			process.Cmdline: p.cmdline,
+		}).WithTopology(report.Process).
+			WithMetric(process.CPUUsage, report.MakeMetric().Add(now, p.cpu).WithMax(100)).
+			WithMetric(process.MemoryUsage, report.MakeMetric().Add(now, p.memory)))
+	}
+	return rpt
+}
+
+func TestProcessTreeRenderer(t *testing.T) {
+	nodeID := func(pid string) string { return report.MakeProcessNodeID("host", pid) }
+	processes := render.ProcessTreeRenderer.Render(processTreeReport(), nil)
+
+	for parent, children := range map[string][]string{
+		"1":  {nodeID("2")},
+		"2":  {nodeID("5")},
+		"5":  {nodeID("20")},
+		"20": {nodeID("21"), nodeID("22")},
+		"21": {},
+	} {
+		if want, have := report.MakeIDList(children...), processes[nodeID(parent)].Adjacency; !reflect.DeepEqual(want, have) {
+			t.Errorf("Expected process %s to have children %v, got %v", parent, want, have)
+		}
+	}
+
+	master := processes[nodeID("20")]
+	for key, want := range map[string]float64{
+		process.SubtreeCPUUsage:    72,
+		process.SubtreeMemoryUsage: 720,
+	} {
+		metric, ok := master.Metrics.Lookup(key)
+		if !ok || metric.LastSample() == nil || metric.LastSample().Value != want {
+			t.Errorf("Expected %s of %v, got %v", key, want, metric.LastSample())
+		}
+	}
+
+	// Collapsed supervisors hand their children over
+	collapsed := func(r render.Renderer) render.Renderer {
+		return render.MakeFilter(render.Complement(render.IsSupervisor), r)
+	}
+	processes = render.ProcessTreeRenderer.Render(processTreeReport(), collapsed)
+	for _, pid := range []string{"1", "5"} {
+		if _, ok := processes[nodeID(pid)]; ok {
+			t.Errorf("Expected supervisor %s to be collapsed", pid)
+		}
+	}
+	if want, have := report.MakeIDList(nodeID("20")), processes[nodeID("2")].Adjacency; !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected bash to have children %v, got %v", want, have)
+	}
+}
+
+func TestSystemdUnitRenderer(t *testing.T) {
+	rpt := processTreeReport()
+	unitID := systemd.MakeUnitNodeID("host", "nginx.service")
+	rpt.SystemdUnit.AddNode(report.MakeNodeWith(unitID, map[string]string{
+		systemd.UnitName: "nginx.service",
+	}).WithTopology(report.SystemdUnit))
+	for _, pid := range []string{"20", "21", "22"} {
+		rpt.Process.AddNode(report.MakeNode(report.MakeProcessNodeID("host", pid)).WithParents(report.EmptySets.
+			Add(report.SystemdUnit, report.MakeStringSet(unitID)),
+		))
+	}
+
+	units := render.SystemdUnitRenderer.Render(rpt, nil)
+	if want, have := 1, len(units); want != have {
+		t.Fatalf("Expected %d unit, got %v", want, units)
+	}
+	unit := units[unitID]
+	if count, ok := unit.Counters.Lookup(report.Process); !ok || count != 3 {
+		t.Errorf("Expected unit to have 3 processes, got %d", count)
+	}
+	for key, want := range map[string]float64{
+		systemd.CPUUsage:    72,
+		systemd.MemoryUsage: 720,
+	} {
+		metric, ok := unit.Metrics.Lookup(key)
+		if !ok || metric.LastSample() == nil || metric.LastSample().Value != want {
+			t.Errorf("Expected %s of %v, got %v", key, want, metric.LastSample())
+		}
+	}
+}
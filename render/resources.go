@@ -0,0 +1,132 @@
+package render
+
+import (
+	"strconv"
+
+	"$GITHUB_URI/probe/docker"
+	"$GITHUB_URI/probe/kubernetes"
+	"$GITHUB_URI/report"
+)
+
+// resourceUsageRenderer decorates pods, and the groupings of pods, with the
+// CPU and memory usage of their containers, and with that usage expressed as
+// a percentage of the requests and limits declared in their pod specs.
+type resourceUsageRenderer struct {
+	Renderer
+}
+
+// Render implements Renderer
+func (r resourceUsageRenderer) Render(rpt report.Report, dct Decorator) report.Nodes {
+	nodes := r.Renderer.Render(rpt, dct)
+	outputs := report.Nodes{}
+	for id, n := range nodes {
+		outputs[id] = withResourceUsage(n)
+	}
+	return outputs
+}
+
+// sumMetric adds together the last samples of a metric across nodes,
+// returning a single-sample metric whose max is the sum of the maxes.
+func sumMetric(nodes []report.Node, key string) (report.Metric, bool) {
+	var (
+		total, max float64
+		found      bool
+		sample     *report.Sample
+	)
+	for _, n := range nodes {
+		metric, ok := n.Metrics.Lookup(key)
+		if !ok {
+			continue
+		}
+		last := metric.LastSample()
+		if last == nil {
+			continue
+		}
+		if sample == nil || last.Timestamp.After(sample.Timestamp) {
+			sample = last
+		}
+		total += last.Value
+		max += metric.Max
+		found = true
+	}
+	if !found {
+		return report.Metric{}, false
+	}
+	return report.MakeMetric().Add(sample.Timestamp, total).WithMax(max), true
+}
+
+// sumLatest adds together the integer value of key across nodes. It is only
+// ok if every node has the key.
+func sumLatest(nodes []report.Node, key string) (int64, bool) {
+	if len(nodes) == 0 {
+		return 0, false
+	}
+	var total int64
+	for _, n := range nodes {
+		value, ok := n.Latest.Lookup(key)
+		if !ok {
+			return 0, false
+		}
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		total += i
+	}
+	return total, true
+}
+
+func withResourceUsage(n report.Node) report.Node {
+	var containers, pods []report.Node
+	n.Children.ForEach(func(child report.Node) {
+		switch child.Topology {
+		case report.Container:
+			containers = append(containers, child)
+		case report.Pod:
+			pods = append(pods, child)
+		}
+	})
+	if n.Topology == report.Pod {
+		pods = []report.Node{n}
+	}
+
+	metrics := report.Metrics{}
+	cpu, hasCPU := sumMetric(containers, docker.CPUTotalUsage)
+	if hasCPU {
+		metrics[docker.CPUTotalUsage] = cpu
+	}
+	memory, hasMemory := sumMetric(containers, docker.MemoryUsage)
+	if hasMemory {
+		metrics[docker.MemoryUsage] = memory
+	}
+
+	// CPU usage is a percentage of a single core, and so is 1/10th of the
+	// requested millicores.
+	for key, quota := range map[string]string{
+		kubernetes.CPURequestUsage: kubernetes.CPURequest,
+		kubernetes.CPULimitUsage:   kubernetes.CPULimit,
+	} {
+		millicores, ok := sumLatest(pods, quota)
+		if !hasCPU || !ok || millicores == 0 {
+			continue
+		}
+		sample := cpu.LastSample()
+		metrics[key] = report.MakeMetric().Add(sample.Timestamp, sample.Value/(float64(millicores)/10)*100).WithMax(100)
+	}
+	for key, quota := range map[string]string{
+		kubernetes.MemoryRequestUsage: kubernetes.MemoryRequest,
+		kubernetes.MemoryLimitUsage:   kubernetes.MemoryLimit,
+	} {
+		bytes, ok := sumLatest(pods, quota)
+		if !hasMemory || !ok || bytes == 0 {
+			continue
+		}
+		sample := memory.LastSample()
+		metrics[key] = report.MakeMetric().Add(sample.Timestamp, sample.Value/float64(bytes)*100).WithMax(100)
+	}
+
+	if len(metrics) == 0 {
+		return n
+	}
+	return n.WithMetrics(metrics)
+}
@@ -26,9 +26,24 @@ var (
 	SelectProcess        = TopologySelector(report.Process)
 	SelectContainer      = TopologySelector(report.Container)
 	SelectContainerImage = TopologySelector(report.ContainerImage)
+	SelectDockerNetwork  = TopologySelector(report.DockerNetwork)
+	SelectDockerVolume   = TopologySelector(report.DockerVolume)
+	SelectSwarmService   = TopologySelector(report.SwarmService)
+	SelectSwarmTask      = TopologySelector(report.SwarmTask)
+	SelectSwarmNode      = TopologySelector(report.SwarmNode)
+
+	SelectComposeProject = TopologySelector(report.ComposeProject)
+	SelectComposeService = TopologySelector(report.ComposeService)
+	SelectSystemdUnit    = TopologySelector(report.SystemdUnit)
 	SelectHost           = TopologySelector(report.Host)
 	SelectPod            = TopologySelector(report.Pod)
 	SelectService        = TopologySelector(report.Service)
 	SelectDeployment     = TopologySelector(report.Deployment)
 	SelectReplicaSet     = TopologySelector(report.ReplicaSet)
+
+	SelectNetworkInterface = TopologySelector(report.NetworkInterface)
+
+	SelectPersistentVolume      = TopologySelector(report.PersistentVolume)
+	SelectPersistentVolumeClaim = TopologySelector(report.PersistentVolumeClaim)
+	SelectStorageClass          = TopologySelector(report.StorageClass)
 )
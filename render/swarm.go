@@ -0,0 +1,48 @@
+package render
+
+import (
+	"$GITHUB_URI/report"
+)
+
+// SwarmServiceRenderer is a Renderer which produces a renderable swarm
+// services graph by merging the container graph and the services topology.
+var SwarmServiceRenderer = ApplyDecorators(
+	MakeReduce(
+		MakeMap(
+			Map2SwarmService,
+			ContainerWithImageNameRenderer,
+		),
+		SelectSwarmService,
+	),
+)
+
+// SwarmTaskRenderer is a Renderer which produces a renderable swarm tasks
+// graph by merging the container graph and the tasks topology.
+var SwarmTaskRenderer = ApplyDecorators(
+	MakeReduce(
+		MakeMap(
+			Map2SwarmTask,
+			ContainerWithImageNameRenderer,
+		),
+		SelectSwarmTask,
+	),
+)
+
+// SwarmNodeRenderer is a Renderer which produces a renderable swarm nodes
+// graph by merging the tasks graph and the nodes topology.
+var SwarmNodeRenderer = ApplyDecorators(
+	MakeReduce(
+		MakeMap(
+			Map2SwarmNode,
+			SwarmTaskRenderer,
+		),
+		SelectSwarmNode,
+	),
+)
+
+// The ways of grouping swarm containers
+var (
+	Map2SwarmService = Map2Parent(report.SwarmService)
+	Map2SwarmTask    = Map2Parent(report.SwarmTask)
+	Map2SwarmNode    = Map2Parent(report.SwarmNode)
+)
@@ -138,6 +138,78 @@ var (
 
 	// ParseReplicaSetNodeID parses a replica set node ID
 	ParseReplicaSetNodeID = parseSingleComponentID("replica_set")
+
+	// MakePersistentVolumeNodeID produces a persistent volume node ID from its composite parts.
+	MakePersistentVolumeNodeID = makeSingleComponentID("persistent_volume")
+
+	// ParsePersistentVolumeNodeID parses a persistent volume node ID
+	ParsePersistentVolumeNodeID = parseSingleComponentID("persistent_volume")
+
+	// MakePersistentVolumeClaimNodeID produces a persistent volume claim node ID from its composite parts.
+	MakePersistentVolumeClaimNodeID = makeSingleComponentID("persistent_volume_claim")
+
+	// ParsePersistentVolumeClaimNodeID parses a persistent volume claim node ID
+	ParsePersistentVolumeClaimNodeID = parseSingleComponentID("persistent_volume_claim")
+
+	// MakeStorageClassNodeID produces a storage class node ID from its composite parts.
+	MakeStorageClassNodeID = makeSingleComponentID("storage_class")
+
+	// ParseStorageClassNodeID parses a storage class node ID
+	ParseStorageClassNodeID = parseSingleComponentID("storage_class")
+
+	// MakeDockerNetworkNodeID produces a Docker network node ID from its composite parts.
+	MakeDockerNetworkNodeID = makeSingleComponentID("docker_network")
+
+	// ParseDockerNetworkNodeID parses a Docker network node ID
+	ParseDockerNetworkNodeID = parseSingleComponentID("docker_network")
+
+	// MakeDockerVolumeNodeID produces a Docker volume node ID from its composite parts.
+	MakeDockerVolumeNodeID = makeSingleComponentID("docker_volume")
+
+	// ParseDockerVolumeNodeID parses a Docker volume node ID
+	ParseDockerVolumeNodeID = parseSingleComponentID("docker_volume")
+
+	// MakeSwarmServiceNodeID produces a Swarm service node ID from its composite parts.
+	MakeSwarmServiceNodeID = makeSingleComponentID("swarm_service")
+
+	// ParseSwarmServiceNodeID parses a Swarm service node ID
+	ParseSwarmServiceNodeID = parseSingleComponentID("swarm_service")
+
+	// MakeSwarmTaskNodeID produces a Swarm task node ID from its composite parts.
+	MakeSwarmTaskNodeID = makeSingleComponentID("swarm_task")
+
+	// ParseSwarmTaskNodeID parses a Swarm task node ID
+	ParseSwarmTaskNodeID = parseSingleComponentID("swarm_task")
+
+	// MakeSwarmNodeNodeID produces a Swarm node ID from its composite parts.
+	MakeSwarmNodeNodeID = makeSingleComponentID("swarm_node")
+
+	// ParseSwarmNodeNodeID parses a Swarm node ID
+	ParseSwarmNodeNodeID = parseSingleComponentID("swarm_node")
+
+	// MakeComposeProjectNodeID produces a Compose project node ID from its composite parts.
+	MakeComposeProjectNodeID = makeSingleComponentID("compose_project")
+
+	// ParseComposeProjectNodeID parses a Compose project node ID
+	ParseComposeProjectNodeID = parseSingleComponentID("compose_project")
+
+	// MakeComposeServiceNodeID produces a Compose service node ID from its composite parts.
+	MakeComposeServiceNodeID = makeSingleComponentID("compose_service")
+
+	// ParseComposeServiceNodeID parses a Compose service node ID
+	ParseComposeServiceNodeID = parseSingleComponentID("compose_service")
+
+	// MakeSystemdUnitNodeID produces a systemd unit node ID from its composite parts.
+	MakeSystemdUnitNodeID = makeSingleComponentID("systemd_unit")
+
+	// ParseSystemdUnitNodeID parses a systemd unit node ID
+	ParseSystemdUnitNodeID = parseSingleComponentID("systemd_unit")
+
+	// MakeNetworkInterfaceNodeID produces a network interface node ID from its composite parts.
+	MakeNetworkInterfaceNodeID = makeSingleComponentID("network_interface")
+
+	// ParseNetworkInterfaceNodeID parses a network interface node ID
+	ParseNetworkInterfaceNodeID = parseSingleComponentID("network_interface")
 )
 
 // makeSingleComponentID makes a single-component node id encoder
@@ -19,9 +19,23 @@ const (
 	Deployment     = "deployment"
 	ReplicaSet     = "replica_set"
 	ContainerImage = "container_image"
+	DockerNetwork  = "docker_network"
+	DockerVolume   = "docker_volume"
+	SwarmService   = "swarm_service"
+	SwarmTask      = "swarm_task"
+	SwarmNode      = "swarm_node"
+	ComposeProject = "compose_project"
+	ComposeService = "compose_service"
+	SystemdUnit    = "systemd_unit"
 	Host           = "host"
 	Overlay        = "overlay"
 
+	NetworkInterface = "network_interface"
+
+	PersistentVolume      = "persistent_volume"
+	PersistentVolumeClaim = "persistent_volume_claim"
+	StorageClass          = "storage_class"
+
 	// Shapes used for different nodes
 	Circle   = "circle"
 	Square   = "square"
@@ -70,11 +84,64 @@ type Report struct {
 	// present.
 	ReplicaSet Topology
 
+	// PersistentVolume nodes represent all Kubernetes persistent volumes in
+	// the cluster. They are parents of the claims and pods using them. Edges
+	// are not present.
+	PersistentVolume Topology
+
+	// PersistentVolumeClaim nodes represent all Kubernetes persistent volume
+	// claims in the cluster. They are parents of the pods mounting them.
+	// Edges are not present.
+	PersistentVolumeClaim Topology
+
+	// StorageClass nodes represent the Kubernetes storage classes referenced
+	// by volumes and claims. Edges are not present.
+	StorageClass Topology
+
 	// ContainerImages nodes represent all Docker containers images on
 	// hosts running probes. Metadata includes things like image id, name etc.
 	// Edges are not present.
 	ContainerImage Topology
 
+	// DockerNetwork nodes represent the user-defined Docker networks on
+	// hosts running probes. Containers attached to a network have it as a
+	// parent. Edges are not present.
+	DockerNetwork Topology
+
+	// DockerVolume nodes represent the named Docker volumes on hosts running
+	// probes. Containers mounting a volume have it as a parent. Edges are not
+	// present.
+	DockerVolume Topology
+
+	// SwarmService nodes represent Docker Swarm services. Only managers
+	// report them; containers are linked to them by their swarm labels.
+	SwarmService Topology
+
+	// SwarmTask nodes represent the tasks of Docker Swarm services, with
+	// their service and the swarm node they're scheduled on as parents.
+	SwarmTask Topology
+
+	// SwarmNode nodes represent the members of a Docker Swarm, with the
+	// host of the same name as their parent.
+	SwarmNode Topology
+
+	// ComposeProject nodes represent Docker Compose projects, grouping the
+	// containers labelled as part of them on a host.
+	ComposeProject Topology
+
+	// ComposeService nodes represent the services of Docker Compose projects,
+	// with their project as parent.
+	ComposeService Topology
+
+	// SystemdUnit nodes represent the systemd units processes run in, as
+	// told by their cgroups. Their processes have them as parents.
+	SystemdUnit Topology
+
+	// NetworkInterface nodes represent the network interfaces of hosts:
+	// physical, bridges, veths, VLAN and VXLAN devices. Edges link them to
+	// the interfaces they hang off, eg a bridge port to its bridge.
+	NetworkInterface Topology
+
 	// Host nodes are physical hosts that run probes. Metadata includes things
 	// like operating system, load, etc. The information is scraped by the
 	// probes with each published report. Edges are not present.
@@ -126,6 +193,42 @@ func MakeReport() Report {
 			WithShape(Hexagon).
 			WithLabel("image", "images"),
 
+		DockerNetwork: MakeTopology().
+			WithShape(Hexagon).
+			WithLabel("network", "networks"),
+
+		DockerVolume: MakeTopology().
+			WithShape(Hexagon).
+			WithLabel("volume", "volumes"),
+
+		SwarmService: MakeTopology().
+			WithShape(Heptagon).
+			WithLabel("service", "services"),
+
+		SwarmTask: MakeTopology().
+			WithShape(Hexagon).
+			WithLabel("task", "tasks"),
+
+		SwarmNode: MakeTopology().
+			WithShape(Circle).
+			WithLabel("node", "nodes"),
+
+		ComposeProject: MakeTopology().
+			WithShape(Hexagon).
+			WithLabel("project", "projects"),
+
+		ComposeService: MakeTopology().
+			WithShape(Hexagon).
+			WithLabel("service", "services"),
+
+		SystemdUnit: MakeTopology().
+			WithShape(Square).
+			WithLabel("unit", "units"),
+
+		NetworkInterface: MakeTopology().
+			WithShape(Square).
+			WithLabel("interface", "interfaces"),
+
 		Host: MakeTopology().
 			WithShape(Circle).
 			WithLabel("host", "hosts"),
@@ -146,6 +249,18 @@ func MakeReport() Report {
 			WithShape(Heptagon).
 			WithLabel("replica set", "replica sets"),
 
+		PersistentVolume: MakeTopology().
+			WithShape(Heptagon).
+			WithLabel("volume", "volumes"),
+
+		PersistentVolumeClaim: MakeTopology().
+			WithShape(Heptagon).
+			WithLabel("volume claim", "volume claims"),
+
+		StorageClass: MakeTopology().
+			WithShape(Heptagon).
+			WithLabel("storage class", "storage classes"),
+
 		Overlay: MakeTopology(),
 
 		Sampling: Sampling{},
@@ -158,20 +273,32 @@ func MakeReport() Report {
 // Copy returns a value copy of the report.
 func (r Report) Copy() Report {
 	return Report{
-		Endpoint:       r.Endpoint.Copy(),
-		Process:        r.Process.Copy(),
-		Container:      r.Container.Copy(),
-		ContainerImage: r.ContainerImage.Copy(),
-		Host:           r.Host.Copy(),
-		Pod:            r.Pod.Copy(),
-		Service:        r.Service.Copy(),
-		Deployment:     r.Deployment.Copy(),
-		ReplicaSet:     r.ReplicaSet.Copy(),
-		Overlay:        r.Overlay.Copy(),
-		Sampling:       r.Sampling,
-		Window:         r.Window,
-		Plugins:        r.Plugins.Copy(),
-		ID:             fmt.Sprintf("%d", rand.Int63()),
+		Endpoint:              r.Endpoint.Copy(),
+		Process:               r.Process.Copy(),
+		Container:             r.Container.Copy(),
+		ContainerImage:        r.ContainerImage.Copy(),
+		DockerNetwork:         r.DockerNetwork.Copy(),
+		DockerVolume:          r.DockerVolume.Copy(),
+		SwarmService:          r.SwarmService.Copy(),
+		SwarmTask:             r.SwarmTask.Copy(),
+		SwarmNode:             r.SwarmNode.Copy(),
+		ComposeProject:        r.ComposeProject.Copy(),
+		ComposeService:        r.ComposeService.Copy(),
+		SystemdUnit:           r.SystemdUnit.Copy(),
+		NetworkInterface:      r.NetworkInterface.Copy(),
+		Host:                  r.Host.Copy(),
+		Pod:                   r.Pod.Copy(),
+		Service:               r.Service.Copy(),
+		Deployment:            r.Deployment.Copy(),
+		ReplicaSet:            r.ReplicaSet.Copy(),
+		PersistentVolume:      r.PersistentVolume.Copy(),
+		PersistentVolumeClaim: r.PersistentVolumeClaim.Copy(),
+		StorageClass:          r.StorageClass.Copy(),
+		Overlay:               r.Overlay.Copy(),
+		Sampling:              r.Sampling,
+		Window:                r.Window,
+		Plugins:               r.Plugins.Copy(),
+		ID:                    fmt.Sprintf("%d", rand.Int63()),
 	}
 }
 
@@ -183,11 +310,23 @@ func (r Report) Merge(other Report) Report {
 	cp.Process = r.Process.Merge(other.Process)
 	cp.Container = r.Container.Merge(other.Container)
 	cp.ContainerImage = r.ContainerImage.Merge(other.ContainerImage)
+	cp.DockerNetwork = r.DockerNetwork.Merge(other.DockerNetwork)
+	cp.DockerVolume = r.DockerVolume.Merge(other.DockerVolume)
+	cp.SwarmService = r.SwarmService.Merge(other.SwarmService)
+	cp.SwarmTask = r.SwarmTask.Merge(other.SwarmTask)
+	cp.SwarmNode = r.SwarmNode.Merge(other.SwarmNode)
+	cp.ComposeProject = r.ComposeProject.Merge(other.ComposeProject)
+	cp.ComposeService = r.ComposeService.Merge(other.ComposeService)
+	cp.SystemdUnit = r.SystemdUnit.Merge(other.SystemdUnit)
+	cp.NetworkInterface = r.NetworkInterface.Merge(other.NetworkInterface)
 	cp.Host = r.Host.Merge(other.Host)
 	cp.Pod = r.Pod.Merge(other.Pod)
 	cp.Service = r.Service.Merge(other.Service)
 	cp.Deployment = r.Deployment.Merge(other.Deployment)
 	cp.ReplicaSet = r.ReplicaSet.Merge(other.ReplicaSet)
+	cp.PersistentVolume = r.PersistentVolume.Merge(other.PersistentVolume)
+	cp.PersistentVolumeClaim = r.PersistentVolumeClaim.Merge(other.PersistentVolumeClaim)
+	cp.StorageClass = r.StorageClass.Merge(other.StorageClass)
 	cp.Overlay = r.Overlay.Merge(other.Overlay)
 	cp.Sampling = r.Sampling.Merge(other.Sampling)
 	cp.Window += other.Window
@@ -202,10 +341,22 @@ func (r Report) Topologies() []Topology {
 		r.Process,
 		r.Container,
 		r.ContainerImage,
+		r.DockerNetwork,
+		r.DockerVolume,
+		r.SwarmService,
+		r.SwarmTask,
+		r.SwarmNode,
+		r.ComposeProject,
+		r.ComposeService,
+		r.SystemdUnit,
+		r.NetworkInterface,
 		r.Pod,
 		r.Service,
 		r.Deployment,
 		r.ReplicaSet,
+		r.PersistentVolume,
+		r.PersistentVolumeClaim,
+		r.StorageClass,
 		r.Host,
 		r.Overlay,
 	}
@@ -214,16 +365,28 @@ func (r Report) Topologies() []Topology {
 // Topology gets a topology by name
 func (r Report) Topology(name string) (Topology, bool) {
 	t, ok := map[string]Topology{
-		Endpoint:       r.Endpoint,
-		Process:        r.Process,
-		Container:      r.Container,
-		ContainerImage: r.ContainerImage,
-		Pod:            r.Pod,
-		Service:        r.Service,
-		Deployment:     r.Deployment,
-		ReplicaSet:     r.ReplicaSet,
-		Host:           r.Host,
-		Overlay:        r.Overlay,
+		Endpoint:              r.Endpoint,
+		Process:               r.Process,
+		Container:             r.Container,
+		ContainerImage:        r.ContainerImage,
+		DockerNetwork:         r.DockerNetwork,
+		DockerVolume:          r.DockerVolume,
+		SwarmService:          r.SwarmService,
+		SwarmTask:             r.SwarmTask,
+		SwarmNode:             r.SwarmNode,
+		ComposeProject:        r.ComposeProject,
+		ComposeService:        r.ComposeService,
+		SystemdUnit:           r.SystemdUnit,
+		NetworkInterface:      r.NetworkInterface,
+		Pod:                   r.Pod,
+		Service:               r.Service,
+		Deployment:            r.Deployment,
+		ReplicaSet:            r.ReplicaSet,
+		PersistentVolume:      r.PersistentVolume,
+		PersistentVolumeClaim: r.PersistentVolumeClaim,
+		StorageClass:          r.StorageClass,
+		Host:                  r.Host,
+		Overlay:               r.Overlay,
 	}[name]
 	return t, ok
 }
@@ -17,14 +17,19 @@ const (
 )
 
 // AddTable appends arbirary key-value pairs to the Node, returning a new node.
+// Only the first MaxTableRows keys, in sorted order, are kept, so the same
+// rows are reported each time.
 func (node Node) AddTable(prefix string, labels map[string]string) Node {
-	count := 0
-	for key, value := range labels {
-		if count >= MaxTableRows {
-			break
-		}
-		node = node.WithLatest(prefix+key, mtime.Now(), value)
-		count++
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) > MaxTableRows {
+		keys = keys[:MaxTableRows]
+	}
+	for _, key := range keys {
+		node = node.WithLatest(prefix+key, mtime.Now(), labels[key])
 	}
 	if len(labels) > MaxTableRows {
 		truncationCount := fmt.Sprintf("%d", len(labels)-MaxTableRows)
@@ -51,3 +51,21 @@ func TestTruncation(t *testing.T) {
 		)
 	}
 }
+
+func TestTruncationKeepsFirstKeys(t *testing.T) {
+	labels := map[string]string{}
+	want := map[string]string{}
+	for i := 0; i < report.MaxTableRows+5; i++ {
+		key := fmt.Sprintf("key%02d", i)
+		labels[key] = "value"
+		if i < report.MaxTableRows {
+			want[key] = "value"
+		}
+	}
+	for i := 0; i < 10; i++ {
+		have, _ := report.MakeNode("foo1").AddTable("foo_", labels).ExtractTable("foo_")
+		if !reflect.DeepEqual(want, have) {
+			t.Fatal(test.Diff(want, have))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// timelineTimeFormat is used for the keys of timeline tables. It is fixed
+// width and in UTC, so keys sort chronologically.
+const timelineTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// TimelineRow is a row of a timeline: a table of events keyed by the time
+// they happened.
+type TimelineRow struct {
+	Time  time.Time
+	Value string
+}
+
+// AddTimeline adds the most recent MaxTableRows rows to a timeline table of
+// the Node, returning a new node. Rows at the same time are told apart by a
+// nanosecond, as their time is their key. Entries are timestamped with the
+// time of their row, so they don't change from one report to the next.
+func (node Node) AddTimeline(prefix string, rows []TimelineRow) Node {
+	sorted := make([]TimelineRow, len(rows))
+	copy(sorted, rows)
+	sort.Stable(timelineRowsByTime(sorted))
+	for i := 1; i < len(sorted); i++ {
+		if !sorted[i].Time.After(sorted[i-1].Time) {
+			sorted[i].Time = sorted[i-1].Time.Add(time.Nanosecond)
+		}
+	}
+	if len(sorted) > MaxTableRows {
+		sorted = sorted[len(sorted)-MaxTableRows:]
+	}
+	for _, row := range sorted {
+		node = node.WithLatest(prefix+row.Time.UTC().Format(timelineTimeFormat), row.Time, row.Value)
+	}
+	return node
+}
+
+// ExtractTimeline returns the rows of a timeline table of this Node, oldest
+// first.
+func (node Node) ExtractTimeline(prefix string) []TimelineRow {
+	rows := []TimelineRow{}
+	node.Latest.ForEach(func(key, value string) {
+		if !strings.HasPrefix(key, prefix) {
+			return
+		}
+		if t, err := time.Parse(timelineTimeFormat, key[len(prefix):]); err == nil {
+			rows = append(rows, TimelineRow{Time: t, Value: value})
+		}
+	})
+	sort.Sort(timelineRowsByTime(rows))
+	return rows
+}
+
+type timelineRowsByTime []TimelineRow
+
+func (t timelineRowsByTime) Len() int           { return len(t) }
+func (t timelineRowsByTime) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t timelineRowsByTime) Less(i, j int) bool { return t[i].Time.Before(t[j].Time) }
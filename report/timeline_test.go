@@ -0,0 +1,35 @@
+package report_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"$GITHUB_URI/report"
+)
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2016, time.September, 14, 10, 0, 0, 0, time.UTC)
+	rows := []report.TimelineRow{}
+	for i := report.MaxTableRows + 4; i >= 0; i-- {
+		rows = append(rows, report.TimelineRow{Time: start.Add(time.Duration(i) * time.Second), Value: fmt.Sprintf("event%d", i)})
+	}
+	// Rows at the same time are kept apart
+	rows = append(rows, report.TimelineRow{Time: start.Add(time.Duration(report.MaxTableRows+4) * time.Second), Value: "last"})
+
+	have := report.MakeNode("foo1").AddTimeline("foo_", rows).ExtractTimeline("foo_")
+	if len(have) != report.MaxTableRows {
+		t.Fatalf("Expected the %d most recent rows, got %d", report.MaxTableRows, len(have))
+	}
+	for i := 1; i < len(have); i++ {
+		if !have[i-1].Time.Before(have[i].Time) {
+			t.Errorf("Expected rows in time order, got %v then %v", have[i-1].Time, have[i].Time)
+		}
+	}
+	if first, want := have[0].Value, fmt.Sprintf("event%d", 6); first != want {
+		t.Errorf("Expected the oldest row to be %q, got %q", want, first)
+	}
+	if last := have[len(have)-1]; last.Value != "last" || last.Time.Sub(have[len(have)-2].Time) != time.Nanosecond {
+		t.Errorf("Expected the last row a nanosecond after the one before, got %v", last)
+	}
+}
@@ -101,6 +101,10 @@ var (
 	ServiceUID          = "service1234"
 	ServiceNodeID       = report.MakeServiceNodeID(ServiceUID)
 
+	SwarmServiceID     = "s3rv1c3"
+	SwarmServiceName   = "server"
+	SwarmServiceNodeID = report.MakeSwarmServiceNodeID(SwarmServiceID)
+
 	ClientProcess1CPUMetric    = report.MakeMetric().Add(Now, 0.01).WithFirst(Now.Add(-1 * time.Second))
 	ClientProcess1MemoryMetric = report.MakeMetric().Add(Now, 0.02).WithFirst(Now.Add(-2 * time.Second))
 
@@ -299,7 +303,8 @@ var (
 					WithTopology(report.Container).WithParents(report.EmptySets.
 					Add("host", report.MakeStringSet(ServerHostNodeID)).
 					Add("container_image", report.MakeStringSet(ServerContainerImageNodeID)).
-					Add("pod", report.MakeStringSet(ServerPodNodeID)),
+					Add("pod", report.MakeStringSet(ServerPodNodeID)).
+					Add("swarm_service", report.MakeStringSet(SwarmServiceNodeID)),
 				).WithMetrics(report.Metrics{
 					docker.CPUTotalUsage: ServerContainerCPUMetric,
 					docker.MemoryUsage:   ServerContainerMemoryMetric,
@@ -405,6 +410,18 @@ var (
 					WithTopology(report.Service),
 			},
 		}.WithShape(report.Heptagon).WithLabel("service", "services"),
+		SwarmService: report.Topology{
+			Nodes: report.Nodes{
+				SwarmServiceNodeID: report.MakeNodeWith(
+
+					SwarmServiceNodeID, map[string]string{
+						docker.SwarmServiceID:   SwarmServiceID,
+						docker.SwarmServiceName: SwarmServiceName,
+					}).
+					WithTopology(report.SwarmService),
+			},
+			MetadataTemplates: docker.SwarmServiceMetadataTemplates,
+		}.WithShape(report.Heptagon).WithLabel("service", "services"),
 		Sampling: report.Sampling{
 			Count: 1024,
 			Total: 4096,
@@ -31,6 +31,7 @@ type File struct {
 	FWriter   io.Writer
 	FCloser   io.Closer
 	FStat     syscall.Stat_t
+	FLink     string // makes the file a symlink to FLink
 }
 
 // Entry is an entry in the mock filesystem
@@ -170,6 +171,20 @@ func (p dir) Open(path string) (io.ReadWriteCloser, error) {
 	return fs.Open(tail)
 }
 
+func (p dir) Readlink(path string) (string, error) {
+	if path == "/" {
+		return "", fmt.Errorf("I'm a directory!")
+	}
+
+	head, tail := split(path)
+	fs, ok := p.entries[head]
+	if !ok {
+		return "", fmt.Errorf("Not found: %s", path)
+	}
+
+	return fs.Readlink(tail)
+}
+
 func (p dir) Add(path string, e Entry) error {
 	if path == "/" {
 		p.entries[e.Name()] = e
@@ -270,6 +285,17 @@ func (p File) Open(path string) (io.ReadWriteCloser, error) {
 	return s, nil
 }
 
+// Readlink implements FS
+func (p File) Readlink(path string) (string, error) {
+	if path != "/" {
+		return "", fmt.Errorf("I'm a file!")
+	}
+	if p.FLink == "" {
+		return "", fmt.Errorf("I'm not a link!")
+	}
+	return p.FLink, nil
+}
+
 // Add adds a new node to the fs
 func (p File) Add(path string, e Entry) error {
 	if path != "/" {